@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/etl"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/node"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/urfave/cli"
+)
+
+var (
+	importCommand = cli.Command{
+		Action:    importCmd,
+		Name:      "import",
+		Usage:     "Import a blockchain file",
+		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
+		Description: `
+The import command imports blocks from an RLP-encoded form. The form can be one file
+with several RLP-encoded blocks, or several files can be used. Files ending with .gz are
+decompressed. Blocks are validated and executed by the same stages as blocks downloaded
+from the network. Node must be stopped.`,
+	}
+	exportCommand = cli.Command{
+		Action:    exportCmd,
+		Name:      "export",
+		Usage:     "Export blockchain into file",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Description: `
+Requires a first argument of the file to write to.
+Optional second and third arguments control the first and
+last block to write (whole canonical chain by default). If the file ends with .gz,
+the output will be gzipped.`,
+	}
+	initCommand = cli.Command{
+		Action:    initCmd,
+		Name:      "init",
+		Usage:     "Bootstrap and initialize a new custom chain",
+		ArgsUsage: "<specPath>",
+		Description: `
+The init command writes the genesis block of a custom chain spec (the same .json or .toml
+file --chain accepts) into an empty database, together with its chain config, network id and
+bootnodes. The node can then be started with --datadir only. Node must be stopped.`,
+	}
+)
+
+func importCmd(cliCtx *cli.Context) error {
+	if cliCtx.NArg() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	nodeCfg := node.NewNodConfigUrfave(cliCtx)
+	ethCfg := node.NewEthConfigUrfave(cliCtx, nodeCfg)
+	db := utils.MakeChainDatabase(nodeCfg)
+	defer db.Close()
+	ctx, cancel := utils.RootContext()
+	defer cancel()
+
+	chainConfig, err := initChain(ctx, db, ethCfg)
+	if err != nil {
+		return err
+	}
+	var consensusConfig interface{}
+	if chainConfig.Clique != nil {
+		consensusConfig = &ethCfg.Clique
+	} else if chainConfig.Aura != nil {
+		consensusConfig = &ethCfg.Aura
+	} else {
+		consensusConfig = &ethCfg.Ethash
+	}
+	engine := ethconfig.CreateConsensusEngine(chainConfig, consensusConfig, nil, false)
+	defer engine.Close()
+
+	importer, err := stages2.NewBlockImporter(ctx, db, *ethCfg, chainConfig, engine, path.Join(nodeCfg.DataDir, etl.TmpDirName))
+	if err != nil {
+		return err
+	}
+	for _, fn := range cliCtx.Args() {
+		if err = utils.ImportChain(ctx, importer, fn); err != nil {
+			return fmt.Errorf("import %s: %w", fn, err)
+		}
+	}
+	return nil
+}
+
+// initChain - writes genesis and storage mode into empty db, or checks that flags are compatible with existing db
+func initChain(ctx context.Context, db ethdb.RwKV, ethCfg *ethconfig.Config) (*params.ChainConfig, error) {
+	if err := db.View(ctx, func(tx ethdb.Tx) error {
+		h, err := rawdb.ReadCanonicalHash(tx, 0)
+		if err != nil {
+			return err
+		}
+		if h != (common.Hash{}) {
+			ethCfg.Genesis = nil // fallback to db content
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	var chainConfig *params.ChainConfig
+	var err error
+	if ethCfg.ChainSpec != nil {
+		chainConfig, _, err = core.CommitChainSpec(db, ethCfg.ChainSpec, ethCfg.StorageMode.History)
+	} else {
+		chainConfig, _, err = core.CommitGenesisBlock(db, ethCfg.Genesis, ethCfg.StorageMode.History)
+	}
+	if _, ok := err.(*params.ConfigCompatError); err != nil && !ok {
+		return nil, err
+	}
+	if err = db.Update(ctx, func(tx ethdb.RwTx) error {
+		if err := ethdb.SetStorageModeIfNotExist(tx, ethCfg.StorageMode); err != nil {
+			return err
+		}
+		sm, err := ethdb.GetStorageModeFromDB(tx)
+		if err != nil {
+			return err
+		}
+		if ethCfg.StorageMode.Initialised && !reflect.DeepEqual(sm, ethCfg.StorageMode) {
+			return errors.New("mode is " + ethCfg.StorageMode.ToString() + " original mode is " + sm.ToString())
+		}
+		ethCfg.StorageMode = sm
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return chainConfig, nil
+}
+
+func initCmd(cliCtx *cli.Context) error {
+	if cliCtx.NArg() != 1 {
+		return errors.New("this command requires the path to the chain spec")
+	}
+	spec, err := core.LoadChainSpec(cliCtx.Args().First())
+	if err != nil {
+		return err
+	}
+	nodeCfg := node.NewNodConfigUrfave(cliCtx)
+	ethCfg := node.NewEthConfigUrfave(cliCtx, nodeCfg)
+	ethCfg.Genesis, ethCfg.ChainSpec = spec.Genesis, spec
+	db := utils.MakeChainDatabase(nodeCfg)
+	defer db.Close()
+	ctx, cancel := utils.RootContext()
+	defer cancel()
+
+	chainConfig, err := initChain(ctx, db, ethCfg)
+	if err != nil {
+		return err
+	}
+	log.Info("Successfully wrote chain spec", "name", spec.Name, "networkId", spec.NetworkID, "config", chainConfig)
+	return nil
+}
+
+func exportCmd(cliCtx *cli.Context) error {
+	if cliCtx.NArg() != 1 && cliCtx.NArg() != 3 {
+		return errors.New("this command requires an argument")
+	}
+	nodeCfg := node.NewNodConfigUrfave(cliCtx)
+	db := utils.MakeChainDatabase(nodeCfg)
+	defer db.Close()
+	ctx, cancel := utils.RootContext()
+	defer cancel()
+
+	var first, last uint64
+	if cliCtx.NArg() == 3 {
+		var err error
+		if first, err = strconv.ParseUint(cliCtx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("export error in parsing parameters: %w", err)
+		}
+		if last, err = strconv.ParseUint(cliCtx.Args().Get(2), 10, 64); err != nil {
+			return fmt.Errorf("export error in parsing parameters: %w", err)
+		}
+	} else if err := db.View(ctx, func(tx ethdb.Tx) error {
+		var err error
+		last, err = stages.GetStageProgress(tx, stages.Finish)
+		return err
+	}); err != nil {
+		return err
+	}
+	return utils.ExportChain(ctx, db, cliCtx.Args().First(), first, last)
+}
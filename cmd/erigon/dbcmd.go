@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/backup"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/torquem-ch/mdbx-go/mdbx"
+	"github.com/urfave/cli"
+)
+
+var (
+	backupBucketsFlag = cli.StringFlag{
+		Name:  "buckets",
+		Usage: "Comma separated list of buckets to copy (default = all buckets)",
+	}
+	backupCompactFlag = cli.BoolFlag{
+		Name:  "compact",
+		Usage: "Fill pages of the target database densely (smaller file, but slower random writes after restore)",
+	}
+	backupRateLimitFlag = cli.StringFlag{
+		Name:  "ratelimit",
+		Usage: "Limit read speed from the source database, bytes per second (e.g. 100MB)",
+	}
+
+	backupCommand = cli.Command{
+		Action:    backupCmd,
+		Name:      "backup",
+		Usage:     "Make consistent copy of chaindata, node may keep running",
+		ArgsUsage: "<backup dir>",
+		Flags:     []cli.Flag{backupBucketsFlag, backupCompactFlag, backupRateLimitFlag},
+		Description: `
+The backup command copies the whole chaindata or given buckets into new database in <backup dir>.
+Copy is done inside one read transaction - it represents the database at the moment of backup start.`,
+	}
+	restoreCommand = cli.Command{
+		Action:    restoreCmd,
+		Name:      "restore",
+		Usage:     "Restore chaindata from backup, node must be stopped",
+		ArgsUsage: "<backup dir>",
+		Flags:     []cli.Flag{backupBucketsFlag, backupCompactFlag, backupRateLimitFlag},
+		Description: `
+The restore command replaces content of the whole chaindata or given buckets by content from <backup dir>.`,
+	}
+)
+
+func chaindataPath(ctx *cli.Context) string {
+	return path.Join(ctx.GlobalString(utils.DataDirFlag.Name), "erigon", "chaindata")
+}
+
+func backupOpts(ctx *cli.Context) (backup.Opts, error) {
+	opts := backup.Opts{Compact: ctx.Bool(backupCompactFlag.Name)}
+	if buckets := ctx.String(backupBucketsFlag.Name); buckets != "" {
+		opts.Buckets = strings.Split(buckets, ",")
+	}
+	if limit := ctx.String(backupRateLimitFlag.Name); limit != "" {
+		if err := opts.RateLimit.UnmarshalText([]byte(limit)); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func backupCmd(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("backup dir is required")
+	}
+	opts, err := backupOpts(ctx)
+	if err != nil {
+		return err
+	}
+	db, err := kv.NewMDBX().Path(chaindataPath(ctx)).Label(ethdb.Chain).
+		Flags(func(flags uint) uint { return flags | mdbx.Readonly | mdbx.Accede }).Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	rootCtx, cancel := utils.RootContext()
+	defer cancel()
+	return backup.Backup(rootCtx, db, ctx.Args().First(), opts)
+}
+
+func restoreCmd(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("backup dir is required")
+	}
+	opts, err := backupOpts(ctx)
+	if err != nil {
+		return err
+	}
+	rootCtx, cancel := utils.RootContext()
+	defer cancel()
+	return backup.Restore(rootCtx, ctx.Args().First(), chaindataPath(ctx), opts)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
@@ -21,6 +22,13 @@ func main() {
 	defer debug.LogPanic()
 	// creating a erigon-api app with all defaults
 	app := erigoncli.MakeApp(runErigon, erigoncli.DefaultFlags)
+	app.Commands = []cli.Command{
+		backupCommand,
+		restoreCommand,
+		importCommand,
+		exportCommand,
+		initCommand,
+	}
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -44,6 +52,15 @@ func runErigon(cliCtx *cli.Context) {
 			if h != (common.Hash{}) {
 				ethCfg.Genesis = nil // fallback to db content
 			}
+			if !cliCtx.GlobalIsSet(utils.ChainFlag.Name) {
+				spec, err := core.ReadChainSpec(tx)
+				if err != nil {
+					return err
+				}
+				if spec != nil { // custom chain initialised by `erigon init` or --chain=<file>
+					utils.SetStoredChainSpec(cliCtx, spec, nodeCfg, ethCfg)
+				}
+			}
 			return nil
 		}); err != nil {
 			panic(err)
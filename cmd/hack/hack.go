@@ -57,7 +57,6 @@ var (
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile `file`")
 	rewind     = flag.Int("rewind", 1, "rewind to given number of blocks")
 	block      = flag.Int("block", 1, "specifies a block number for operation")
-	blockTotal = flag.Int("blocktotal", 1, "specifies a total amount of blocks to process")
 	account    = flag.String("account", "0x", "specifies account to investigate")
 	name       = flag.String("name", "", "name to add to the file names")
 	chaindata  = flag.String("chaindata", "chaindata", "path to the chaindata database file")
@@ -1505,47 +1504,6 @@ func mint(chaindata string, block uint64) error {
 	return tx.Commit()
 }
 
-func extractHashes(chaindata string, blockStep uint64, blockTotal uint64, name string) error {
-	db := kv2.MustOpen(chaindata)
-	defer db.Close()
-
-	f, err := os.Create(fmt.Sprintf("preverified_hashes_%s.go", name))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	w := bufio.NewWriter(f)
-	defer w.Flush()
-
-	fmt.Fprintf(w, "package headerdownload\n\n")
-	fmt.Fprintf(w, "var %sPreverifiedHashes = []string{\n", name)
-
-	b := uint64(0)
-	tool.Check(db.View(context.Background(), func(tx ethdb.Tx) error {
-		for b <= blockTotal {
-			hash, err := rawdb.ReadCanonicalHash(tx, b)
-			if err != nil {
-				return err
-			}
-
-			if hash == (common.Hash{}) {
-				break
-			}
-
-			fmt.Fprintf(w, "	\"%x\",\n", hash)
-			b += blockStep
-		}
-		return nil
-	}))
-
-	b -= blockStep
-	fmt.Fprintf(w, "}\n\n")
-	fmt.Fprintf(w, "const %sPreverifiedHeight uint64 = %d\n", name, b)
-	fmt.Printf("Last block is %d\n", b)
-	return nil
-}
-
 func extractHeaders(chaindata string, block uint64) error {
 	db := kv2.MustOpen(chaindata)
 	defer db.Close()
@@ -2261,7 +2219,7 @@ func runBlock(ibs *state.IntraBlockState, txnWriter state.StateWriter, blockWrit
 
 	if !vmConfig.ReadOnly {
 		// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-		if _, err := engine.FinalizeAndAssemble(chainConfig, header, ibs, block.Transactions(), block.Uncles(), receipts, nil, nil); err != nil {
+		if _, err := engine.FinalizeAndAssemble(chainConfig, header, ibs, block.Transactions(), block.Uncles(), receipts, nil, nil, nil); err != nil {
 			return nil, fmt.Errorf("finalize of block %d failed: %v", block.NumberU64(), err)
 		}
 
@@ -2364,9 +2322,6 @@ func main() {
 	case "extractHeaders":
 		err = extractHeaders(*chaindata, uint64(*block))
 
-	case "extractHashes":
-		err = extractHashes(*chaindata, uint64(*block), uint64(*blockTotal), *name)
-
 	case "defrag":
 		err = db.Defrag()
 
@@ -20,10 +20,13 @@ var (
 	unwind             uint64
 	unwindEvery        uint64
 	batchSizeStr       string
+	etlCompressionStr  string
 	reset              bool
 	bucket             string
 	datadir            string
 	migration          string
+	migrationDown      bool
+	migrationDryRun    bool
 	integritySlow      bool
 	integrityFast      bool
 	file               string
@@ -111,6 +114,10 @@ func withBatchSize(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&batchSizeStr, "batchSize", "512M", "batch size for execution stage")
 }
 
+func withEtlCompression(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&etlCompressionStr, "etl.compression", "none", "compression of etl temporary files: none, snappy or zstd")
+}
+
 func withIntegrityChecks(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&integritySlow, "integrity.slow", true, "enable slow data-integrity checks")
 	cmd.Flags().BoolVar(&integrityFast, "integrity.fast", true, "enable fast data-integrity checks")
@@ -120,6 +127,11 @@ func withMigration(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&migration, "migration", "", "action to apply to given migration")
 }
 
+func withMigrationActions(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&migrationDown, "down", false, "revert migration given by --migration, it must be the last applied one")
+	cmd.Flags().BoolVar(&migrationDryRun, "dry-run", false, "print pending migrations and how much data they will touch, without applying them")
+}
+
 func withTxTrace(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&txtrace, "txtrace", false, "enable tracing of transactions")
 }
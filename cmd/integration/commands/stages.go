@@ -225,6 +225,19 @@ var cmdRunMigrations = &cobra.Command{
 	Use:   "run_migrations",
 	Short: "",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if migrationDryRun {
+			db := openDB(chaindata, false)
+			defer db.Close()
+			return printPendingMigrations(db)
+		}
+		if migrationDown {
+			if migration == "" {
+				return fmt.Errorf("--down requires --migration")
+			}
+			db := openKV(ethdb.Chain, chaindata, true)
+			defer db.Close()
+			return migrations.NewMigrator(ethdb.Chain).Revert(db, datadir, migration)
+		}
 		db := openDB(chaindata, true)
 		defer db.Close()
 		// Nothing to do, migrations will be applied automatically
@@ -276,6 +289,7 @@ func init() {
 	withBlock(cmdStageHashState)
 	withUnwind(cmdStageHashState)
 	withBatchSize(cmdStageHashState)
+	withEtlCompression(cmdStageHashState)
 	withChain(cmdStageHashState)
 
 	rootCmd.AddCommand(cmdStageHashState)
@@ -293,6 +307,7 @@ func init() {
 	withReset(cmdStageHistory)
 	withBlock(cmdStageHistory)
 	withUnwind(cmdStageHistory)
+	withEtlCompression(cmdStageHistory)
 	withChain(cmdStageHistory)
 
 	rootCmd.AddCommand(cmdStageHistory)
@@ -301,6 +316,7 @@ func init() {
 	withReset(cmdLogIndex)
 	withBlock(cmdLogIndex)
 	withUnwind(cmdLogIndex)
+	withEtlCompression(cmdLogIndex)
 	withChain(cmdLogIndex)
 
 	rootCmd.AddCommand(cmdLogIndex)
@@ -331,6 +347,8 @@ func init() {
 
 	withDatadir(cmdRunMigrations)
 	withChain(cmdRunMigrations)
+	withMigration(cmdRunMigrations)
+	withMigrationActions(cmdRunMigrations)
 	rootCmd.AddCommand(cmdRunMigrations)
 
 	withDatadir(cmdSetStorageMode)
@@ -430,7 +448,7 @@ func stageExec(db ethdb.RwKV, ctx context.Context) error {
 	}
 
 	log.Info("Stage", "name", s.ID, "progress", s.BlockNumber)
-	cfg := stagedsync.StageExecuteBlocksCfg(db, sm.Receipts, sm.CallTraces, sm.TEVM, 0, batchSize, nil, chainConfig, engine, vmConfig, nil, false, tmpDBPath)
+	cfg := stagedsync.StageExecuteBlocksCfg(db, sm.Receipts, sm.CallTraces, sm.TEVM, false, 0, batchSize, nil, chainConfig, engine, vmConfig, nil, false, nil, tmpDBPath)
 	if unwind > 0 {
 		u := sync.NewUnwindState(stages.Execution, s.BlockNumber-unwind, s.BlockNumber)
 		err := stagedsync.UnwindExecutionStage(u, s, nil, ctx, cfg, false)
@@ -508,7 +526,9 @@ func stageHashState(db ethdb.RwKV, ctx context.Context) error {
 
 	s := stage(sync, tx, stages.HashState)
 	log.Info("Stage", "name", s.ID, "progress", s.BlockNumber)
-	cfg := stagedsync.StageHashStateCfg(db, tmpdir)
+	compression, err := etl.ParseCompression(etlCompressionStr)
+	must(err)
+	cfg := stagedsync.StageHashStateCfg(db, tmpdir, compression)
 	if unwind > 0 {
 		u := sync.NewUnwindState(stages.HashState, s.BlockNumber-unwind, s.BlockNumber)
 		err = stagedsync.UnwindHashStateStage(u, s, tx, cfg, ctx)
@@ -547,7 +567,9 @@ func stageLogIndex(db ethdb.RwKV, ctx context.Context) error {
 	log.Info("Stage exec", "progress", execAt)
 	log.Info("Stage", "name", s.ID, "progress", s.BlockNumber)
 
-	cfg := stagedsync.StageLogIndexCfg(db, tmpdir)
+	compression, err := etl.ParseCompression(etlCompressionStr)
+	must(err)
+	cfg := stagedsync.StageLogIndexCfg(db, tmpdir, compression)
 	if unwind > 0 {
 		u := sync.NewUnwindState(stages.LogIndex, s.BlockNumber-unwind, s.BlockNumber)
 		err = stagedsync.UnwindLogIndex(u, s, tx, cfg, ctx)
@@ -630,7 +652,9 @@ func stageHistory(db ethdb.RwKV, ctx context.Context) error {
 	log.Info("ID acc history", "progress", stageAcc.BlockNumber)
 	log.Info("ID storage history", "progress", stageStorage.BlockNumber)
 
-	cfg := stagedsync.StageHistoryCfg(db, tmpdir)
+	compression, err := etl.ParseCompression(etlCompressionStr)
+	must(err)
+	cfg := stagedsync.StageHistoryCfg(db, tmpdir, compression)
 	if unwind > 0 { //nolint:staticcheck
 		u := sync.NewUnwindState(stages.StorageHistoryIndex, stageStorage.BlockNumber-unwind, stageStorage.BlockNumber)
 		if err := stagedsync.UnwindStorageHistoryIndex(u, stageStorage, tx, cfg, ctx); err != nil {
@@ -693,7 +717,7 @@ func printAllStages(db ethdb.RoKV, ctx context.Context) error {
 }
 
 func printAppliedMigrations(db ethdb.RwKV, ctx context.Context) error {
-	return db.View(ctx, func(tx ethdb.Tx) error {
+	if err := db.View(ctx, func(tx ethdb.Tx) error {
 		applied, err := migrations.AppliedMigrations(tx, false /* withPayload */)
 		if err != nil {
 			return err
@@ -707,7 +731,25 @@ func printAppliedMigrations(db ethdb.RwKV, ctx context.Context) error {
 		sort.Strings(appliedStrs)
 		log.Info("Applied", "migrations", strings.Join(appliedStrs, " "))
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	return printPendingMigrations(db)
+}
+
+func printPendingMigrations(db ethdb.RwKV) error {
+	plans, err := migrations.NewMigrator(ethdb.Chain).DryRun(db)
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		logArgs := []interface{}{"name", plan.Name, "reversible", plan.Reversible}
+		if plan.Estimated {
+			logArgs = append(logArgs, "keys", plan.Keys, "size", datasize.ByteSize(plan.Bytes).HumanReadable())
+		}
+		log.Info("Pending migration", logArgs...)
+	}
+	return nil
 }
 
 func removeMigration(db ethdb.RwKV, ctx context.Context) error {
@@ -821,8 +863,8 @@ func newSync(ctx context.Context, db ethdb.RwKV, miningConfig *params.MiningConf
 	miningSync := stagedsync.New(
 		stagedsync.MiningStages(ctx,
 			stagedsync.StageMiningCreateBlockCfg(db, miner, *chainConfig, engine, txPool, tmpdir),
-			stagedsync.StageMiningExecCfg(db, miner, events, *chainConfig, engine, &vm.Config{}, tmpdir),
-			stagedsync.StageHashStateCfg(db, tmpdir),
+			stagedsync.StageMiningExecCfg(db, miner, events, *chainConfig, engine, &vm.Config{}, nil, tmpdir),
+			stagedsync.StageHashStateCfg(db, tmpdir, cfg.EtlCompression),
 			stagedsync.StageTrieCfg(db, false, true, tmpdir),
 			stagedsync.StageMiningFinishCfg(db, *chainConfig, engine, miner, ctx.Done()),
 		),
@@ -3,8 +3,10 @@ package cli
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"path"
 	"time"
 
@@ -47,6 +49,12 @@ type Flags struct {
 	RpcAllowListFilePath string
 	RpcBatchConcurrency  uint
 	TraceCompatibility   bool // Bug for bug compatibility for trace_ routines with OpenEthereum
+	DevForkSource        string
+	DevForkBlock         uint64
+	EngineAddr           string
+	EngineJWTSecret      string
+	CliqueDataDir        string
+	BackupDir            string
 }
 
 var rootCmd = &cobra.Command{
@@ -76,7 +84,7 @@ func RootCommand() (*cobra.Command, *Flags) {
 	rootCmd.PersistentFlags().StringSliceVar(&cfg.HttpCORSDomain, "http.corsdomain", []string{}, "Comma separated list of domains from which to accept cross origin requests (browser enforced)")
 	rootCmd.PersistentFlags().StringSliceVar(&cfg.HttpVirtualHost, "http.vhosts", node.DefaultConfig.HTTPVirtualHosts, "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.")
 	rootCmd.PersistentFlags().BoolVar(&cfg.HttpCompression, "http.compression", true, "Disable http compression")
-	rootCmd.PersistentFlags().StringSliceVar(&cfg.API, "http.api", []string{"eth", "erigon"}, "API's offered over the HTTP-RPC interface: eth,erigon,web3,net,debug,trace,txpool,shh,db. Supported methods: https://github.com/ledgerwatch/erigon/tree/devel/cmd/rpcdaemon")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.API, "http.api", []string{"eth", "erigon"}, "API's offered over the HTTP-RPC interface: eth,erigon,web3,net,debug,trace,txpool,shh,db,clique,evm. Supported methods: https://github.com/ledgerwatch/erigon/tree/devel/cmd/rpcdaemon")
 	rootCmd.PersistentFlags().Uint64Var(&cfg.Gascap, "rpc.gascap", 25000000, "Sets a cap on gas that can be used in eth_call/estimateGas")
 	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxTraces, "trace.maxtraces", 200, "Sets a limit on traces that can be returned in trace_filter")
 	rootCmd.PersistentFlags().BoolVar(&cfg.WebsocketEnabled, "ws", false, "Enable Websockets")
@@ -84,6 +92,12 @@ func RootCommand() (*cobra.Command, *Flags) {
 	rootCmd.PersistentFlags().StringVar(&cfg.RpcAllowListFilePath, "rpc.accessList", "", "Specify granular (method-by-method) API allowlist")
 	rootCmd.PersistentFlags().UintVar(&cfg.RpcBatchConcurrency, "rpc.batch.concurrency", 50, "Does limit amount of goroutines to process 1 batch request. Means 1 bach request can't overload server. 1 batch still can have unlimited amount of request")
 	rootCmd.PersistentFlags().BoolVar(&cfg.TraceCompatibility, "trace.compat", false, "Bug for bug compatibility with OE for trace_ routines")
+	rootCmd.PersistentFlags().StringVar(&cfg.DevForkSource, "dev.fork.source", "", "Chaindata or state snapshot directory the developer chain is forked from (same as Erigon's --dev.fork.source)")
+	rootCmd.PersistentFlags().Uint64Var(&cfg.DevForkBlock, "dev.fork.block", 0, "Block of --dev.fork.source the developer chain is forked from (same as Erigon's --dev.fork.block)")
+	rootCmd.PersistentFlags().StringVar(&cfg.EngineAddr, "engine.addr", "", "Engine API network address of the consensus client driving the chain of the node (Erigon's --beacon), for example: 127.0.0.1:8550, empty string means not to start the listener")
+	rootCmd.PersistentFlags().StringVar(&cfg.EngineJWTSecret, "engine.jwtsecret", "", "File with the hex encoded 32 bytes secret shared with the consensus client, generated if it doesn't exist (default: <datadir>/jwt.hex)")
+	rootCmd.PersistentFlags().StringVar(&cfg.CliqueDataDir, "clique.datadir", "", "Path to the folder of clique db of the node, whose checkpoint snapshots are served by clique_ methods (same as Erigon's --clique.datadir, default: datadir)")
+	rootCmd.PersistentFlags().StringVar(&cfg.BackupDir, "backup.dir", "", "Folder of the backups made by admin_backup, its directory argument is relative to it (default: <datadir>/backups)")
 
 	if err := rootCmd.MarkPersistentFlagFilename("rpc.accessList", "json"); err != nil {
 		panic(err)
@@ -97,6 +111,18 @@ func RootCommand() (*cobra.Command, *Flags) {
 	if err := rootCmd.MarkPersistentFlagDirname("snapshot.dir"); err != nil {
 		panic(err)
 	}
+	if err := rootCmd.MarkPersistentFlagDirname("dev.fork.source"); err != nil {
+		panic(err)
+	}
+	if err := rootCmd.MarkPersistentFlagFilename("engine.jwtsecret"); err != nil {
+		panic(err)
+	}
+	if err := rootCmd.MarkPersistentFlagDirname("clique.datadir"); err != nil {
+		panic(err)
+	}
+	if err := rootCmd.MarkPersistentFlagDirname("backup.dir"); err != nil {
+		panic(err)
+	}
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if err := utils.SetupCobra(cmd); err != nil {
@@ -110,6 +136,12 @@ func RootCommand() (*cobra.Command, *Flags) {
 			if cfg.Chaindata == "" {
 				cfg.Chaindata = path.Join(cfg.Datadir, "erigon", "chaindata")
 			}
+			if cfg.CliqueDataDir == "" {
+				cfg.CliqueDataDir = cfg.Datadir
+			}
+			if cfg.BackupDir == "" {
+				cfg.BackupDir = path.Join(cfg.Datadir, "backups")
+			}
 			//if cfg.SnapshotDir == "" {
 			//	cfg.SnapshotDir = path.Join(cfg.Datadir, "erigon", "snapshot")
 			//}
@@ -124,6 +156,24 @@ func RootCommand() (*cobra.Command, *Flags) {
 	return rootCmd, cfg
 }
 
+// OpenCliqueDB opens read-only the database where the clique engine of the node stores its checkpoint
+// snapshots on the same machine (--datadir or --clique.datadir). Returns nil if there is no such database,
+// then the snapshots are read through the remote KV interface of the node.
+func OpenCliqueDB(cfg Flags) (ethdb.RoKV, error) {
+	if cfg.CliqueDataDir == "" {
+		return nil, nil
+	}
+	dbPath := path.Join(cfg.CliqueDataDir, "clique", "db")
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	db, err := kv2.NewMDBX().Path(dbPath).Readonly().Open()
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
 func checkDbCompatibility(db ethdb.RoKV) error {
 	// DB schema version compatibility check
 	var version []byte
@@ -161,9 +211,9 @@ func checkDbCompatibility(db ethdb.RoKV) error {
 	return nil
 }
 
-func RemoteServices(cfg Flags, rootCancel context.CancelFunc) (kv ethdb.RoKV, eth services.ApiBackend, txPool *services.TxPoolService, mining *services.MiningService, err error) {
+func RemoteServices(cfg Flags, rootCancel context.CancelFunc) (kv ethdb.RoKV, eth services.ApiBackend, txPool *services.TxPoolService, mining *services.MiningService, clique *services.CliqueService, dev *services.DevService, engine *services.EngineService, err error) {
 	if !cfg.SingleNodeMode && cfg.PrivateApiAddr == "" {
-		return nil, nil, nil, nil, fmt.Errorf("either remote db or local db must be specified")
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("either remote db or local db must be specified")
 	}
 	// Do not change the order of these checks. Chaindata needs to be checked first, because PrivateApiAddr has default value which is not ""
 	// If PrivateApiAddr is checked first, the Chaindata option will never work
@@ -171,20 +221,20 @@ func RemoteServices(cfg Flags, rootCancel context.CancelFunc) (kv ethdb.RoKV, et
 		var rwKv ethdb.RwKV
 		rwKv, err = kv2.NewMDBX().Path(cfg.Chaindata).Readonly().Open()
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, err
 		}
 		if compatErr := checkDbCompatibility(rwKv); compatErr != nil {
-			return nil, nil, nil, nil, compatErr
+			return nil, nil, nil, nil, nil, nil, nil, compatErr
 		}
 		kv = rwKv
 		if cfg.SnapshotMode != "" {
 			mode, innerErr := snapshotsync.SnapshotModeFromString(cfg.SnapshotMode)
 			if innerErr != nil {
-				return nil, nil, nil, nil, fmt.Errorf("can't process snapshot-mode err:%w", innerErr)
+				return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("can't process snapshot-mode err:%w", innerErr)
 			}
 			snapKv, innerErr := snapshotsync.WrapBySnapshotsFromDir(rwKv, cfg.SnapshotDir, mode)
 			if innerErr != nil {
-				return nil, nil, nil, nil, fmt.Errorf("can't wrap by snapshots err:%w", innerErr)
+				return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("can't wrap by snapshots err:%w", innerErr)
 			}
 			kv = snapKv
 		}
@@ -194,11 +244,14 @@ func RemoteServices(cfg Flags, rootCancel context.CancelFunc) (kv ethdb.RoKV, et
 	if cfg.PrivateApiAddr != "" {
 		remoteKv, err := kv2.NewRemote(gointerfaces.VersionFromProto(remotedbserver.KvServiceAPIVersion)).Path(cfg.PrivateApiAddr).Open(cfg.TLSCertfile, cfg.TLSKeyFile, cfg.TLSCACert)
 		if err != nil {
-			return nil, nil, nil, nil, fmt.Errorf("could not connect to remoteKv: %w", err)
+			return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not connect to remoteKv: %w", err)
 		}
 		remoteEth := services.NewRemoteBackend(remoteKv.GrpcConn())
 		mining = services.NewMiningService(remoteKv.GrpcConn())
 		txPool = services.NewTxPoolService(remoteKv.GrpcConn())
+		clique = services.NewCliqueService(remoteKv.GrpcConn())
+		dev = services.NewDevService(remoteKv.GrpcConn())
+		engine = services.NewEngineService(remoteKv.GrpcConn())
 		if kv == nil {
 			kv = remoteKv
 		}
@@ -216,9 +269,18 @@ func RemoteServices(cfg Flags, rootCancel context.CancelFunc) (kv ethdb.RoKV, et
 			if !txPool.EnsureVersionCompatibility() {
 				rootCancel()
 			}
+			if !clique.EnsureVersionCompatibility() {
+				rootCancel()
+			}
+			if !dev.EnsureVersionCompatibility() {
+				rootCancel()
+			}
+			if !engine.EnsureVersionCompatibility() {
+				rootCancel()
+			}
 		}()
 	}
-	return kv, eth, txPool, mining, err
+	return kv, eth, txPool, mining, clique, dev, engine, err
 }
 
 func StartRpcServer(ctx context.Context, cfg Flags, rpcAPI []rpc.API) error {
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/node"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// jwtMaxClockSkew - how far the issued-at time of the token of the consensus client may be from the local clock
+const jwtMaxClockSkew = 60 * time.Second
+
+// StartEngineServer serves the engine_ APIs of the consensus client on --engine.addr. Every request must carry
+// a HS256 JWT signed with the shared secret (--engine.jwtsecret) in the Authorization header.
+func StartEngineServer(ctx context.Context, cfg Flags, engineAPI []rpc.API) error {
+	secretPath := cfg.EngineJWTSecret
+	if secretPath == "" {
+		if cfg.Datadir == "" {
+			return fmt.Errorf("--engine.addr requires --engine.jwtsecret or --datadir")
+		}
+		secretPath = path.Join(cfg.Datadir, "jwt.hex")
+	}
+	secret, err := obtainJWTSecret(secretPath)
+	if err != nil {
+		return err
+	}
+
+	srv := rpc.NewServer(cfg.RpcBatchConcurrency)
+	if err := node.RegisterApisFromWhitelist(engineAPI, nil, srv, true /* exposeAll */); err != nil {
+		return fmt.Errorf("could not start register engine apis: %w", err)
+	}
+	handler := newJWTHandler(secret, node.NewHTTPHandlerStack(srv, nil, []string{"*"}, false /* compression */))
+	listener, _, err := node.StartHTTPEndpoint(cfg.EngineAddr, rpc.DefaultHTTPTimeouts, handler)
+	if err != nil {
+		return fmt.Errorf("could not start engine api: %w", err)
+	}
+	log.Info("Engine endpoint opened", "url", cfg.EngineAddr, "jwtsecret", secretPath)
+
+	defer func() {
+		srv.Stop()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = listener.Shutdown(shutdownCtx)
+		log.Info("Engine endpoint closed", "url", cfg.EngineAddr)
+	}()
+	<-ctx.Done()
+	return nil
+}
+
+// obtainJWTSecret reads the hex encoded 32 bytes secret shared with the consensus client, generates it if the file doesn't exist
+func obtainJWTSecret(fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return nil, err
+		}
+		if err = ioutil.WriteFile(fileName, []byte(hex.EncodeToString(secret)), 0600); err != nil {
+			return nil, fmt.Errorf("could not write jwt secret: %w", err)
+		}
+		log.Info("Generated jwt secret of the engine api", "path", fileName)
+		return secret, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read jwt secret: %w", err)
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt secret in %s: %w", fileName, err)
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid jwt secret in %s: %d bytes instead of 32", fileName, len(secret))
+	}
+	return secret, nil
+}
+
+func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if err := verifyJWT(secret, token, time.Now()); err != nil {
+			http.Error(w, "invalid token: "+err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifyJWT checks the HS256 signature of the token and its issued-at claim, other claims are ignored
+func verifyJWT(secret []byte, token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return err
+	}
+	if header.Alg != "HS256" {
+		return fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errors.New("malformed signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("wrong signature")
+	}
+	var claims struct {
+		IssuedAt *int64 `json:"iat"`
+	}
+	if err = decodeJWTPart(parts[1], &claims); err != nil {
+		return err
+	}
+	if claims.IssuedAt == nil {
+		return errors.New("missing issued-at")
+	}
+	if skew := now.Sub(time.Unix(*claims.IssuedAt, 0)); skew > jwtMaxClockSkew || skew < -jwtMaxClockSkew {
+		return errors.New("stale token")
+	}
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func signJWT(secret []byte, alg string, claims string) string {
+	token := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"alg":%q,"typ":"JWT"}`, alg))) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(token))
+	return token + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	secret := make([]byte, 32)
+	secret[0] = 1
+	now := time.Unix(1000000, 0)
+	iat := func(t time.Time) string { return fmt.Sprintf(`{"iat":%d}`, t.Unix()) }
+
+	require.NoError(t, verifyJWT(secret, signJWT(secret, "HS256", iat(now)), now))
+	require.NoError(t, verifyJWT(secret, signJWT(secret, "HS256", iat(now.Add(-59*time.Second))), now))
+	require.NoError(t, verifyJWT(secret, signJWT(secret, "HS256", iat(now.Add(59*time.Second))), now))
+
+	require.EqualError(t, verifyJWT(secret, signJWT(secret, "HS256", iat(now.Add(-61*time.Second))), now), "stale token")
+	require.EqualError(t, verifyJWT(secret, signJWT(secret, "HS256", iat(now.Add(61*time.Second))), now), "stale token")
+	require.EqualError(t, verifyJWT(secret, signJWT(secret, "HS256", `{}`), now), "missing issued-at")
+	require.EqualError(t, verifyJWT(secret, signJWT(make([]byte, 32), "HS256", iat(now)), now), "wrong signature")
+	require.EqualError(t, verifyJWT(secret, signJWT(secret, "none", iat(now)), now), `unsupported algorithm "none"`)
+	require.EqualError(t, verifyJWT(secret, "", now), "malformed token")
+}
+
+func TestJWTHandler(t *testing.T) {
+	secret, err := obtainJWTSecret(path.Join(t.TempDir(), "jwt.hex"))
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+	handler := newJWTHandler(secret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(auth string) int {
+		r := httptest.NewRequest("POST", "/", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, request("Bearer "+signJWT(secret, "HS256", fmt.Sprintf(`{"iat":%d}`, time.Now().Unix()))))
+	require.Equal(t, http.StatusForbidden, request(""))
+	require.Equal(t, http.StatusForbidden, request("Bearer "+signJWT(make([]byte, 32), "HS256", fmt.Sprintf(`{"iat":%d}`, time.Now().Unix()))))
+}
+
+func TestObtainJWTSecret(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "jwt.hex")
+	generated, err := obtainJWTSecret(fileName)
+	require.NoError(t, err)
+	read, err := obtainJWTSecret(fileName)
+	require.NoError(t, err)
+	require.Equal(t, generated, read)
+}
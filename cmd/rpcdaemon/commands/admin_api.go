@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/backup"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+)
+
+// AdminAPI the interface for the admin_ RPC commands
+type AdminAPI interface {
+	Backup(ctx context.Context, dir string, args *BackupArgs) (bool, error)
+}
+
+// BackupArgs - optional settings of admin_backup
+type BackupArgs struct {
+	Buckets   []string          `json:"buckets"`
+	Compact   bool              `json:"compact"`
+	RateLimit datasize.ByteSize `json:"rateLimit"` // bytes per second, accepts "100MB"
+}
+
+// AdminAPIImpl data structure to store things needed for admin_ commands
+type AdminAPIImpl struct {
+	*BaseAPI
+	db        ethdb.RoKV
+	backupDir string
+}
+
+// NewAdminAPI returns AdminAPIImpl instance
+func NewAdminAPI(base *BaseAPI, db ethdb.RoKV, backupDir string) *AdminAPIImpl {
+	return &AdminAPIImpl{
+		BaseAPI:   base,
+		db:        db,
+		backupDir: backupDir,
+	}
+}
+
+// Backup implements admin_backup. Makes consistent copy of chaindata into `dir` inside --backup.dir on the machine
+// of rpcdaemon, database keeps serving reads and writes while backup is in progress. Backup is cancelled when
+// the client disconnects.
+func (api *AdminAPIImpl) Backup(ctx context.Context, dir string, args *BackupArgs) (bool, error) {
+	if _, ok := api.db.(*kv.RemoteKV); ok {
+		return false, fmt.Errorf("admin_backup requires rpcdaemon to open database locally (--datadir or --chaindata)")
+	}
+	to, err := api.backupPath(dir)
+	if err != nil {
+		return false, err
+	}
+	var opts backup.Opts
+	if args != nil {
+		opts.Buckets, opts.Compact, opts.RateLimit = args.Buckets, args.Compact, args.RateLimit
+	}
+	if err = backup.Backup(ctx, api.db, to, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// backupPath - `dir` of admin_backup must be relative and stay inside --backup.dir
+func (api *AdminAPIImpl) backupPath(dir string) (string, error) {
+	if api.backupDir == "" {
+		return "", fmt.Errorf("admin_backup requires --backup.dir")
+	}
+	rel := filepath.Clean(dir)
+	if filepath.IsAbs(rel) || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("backup directory %q must be relative to --backup.dir", dir)
+	}
+	return filepath.Join(api.backupDir, rel), nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminBackup(t *testing.T) {
+	ctx, root := context.Background(), t.TempDir()
+	api := NewAdminAPI(NewBaseApi(nil), kv.NewTestKV(t), root)
+
+	ok, err := api.Backup(ctx, "daily/1", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, err = os.Stat(filepath.Join(root, "daily", "1", "mdbx.dat"))
+	require.NoError(t, err)
+
+	// backups stay inside --backup.dir
+	for _, dir := range []string{"/tmp/backup", "../backup", "daily/../../backup", ".", ""} {
+		_, err = api.Backup(ctx, dir, nil)
+		require.Error(t, err, dir)
+	}
+	_, err = NewAdminAPI(NewBaseApi(nil), kv.NewTestKV(t), "").Backup(ctx, "daily/2", nil)
+	require.Error(t, err)
+
+	// cancelled by the client
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = api.Backup(cancelled, "daily/3", nil)
+	require.Error(t, err)
+	_, err = os.Stat(filepath.Join(root, "daily", "3"))
+	require.True(t, os.IsNotExist(err))
+}
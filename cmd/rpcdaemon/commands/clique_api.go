@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// CliqueAPI the interface for the clique_ RPC commands
+type CliqueAPI interface {
+	GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*clique.Snapshot, error)
+	GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error)
+	GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error)
+	GetSignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error)
+	Status(ctx context.Context) (*clique.Status, error)
+
+	// Forwarded to the clique engine of the node
+	Proposals(ctx context.Context) (map[common.Address]bool, error)
+	Propose(ctx context.Context, address common.Address, auth bool) error
+	Discard(ctx context.Context, address common.Address) error
+}
+
+// CliqueImpl data structure to store things needed for clique_ commands.
+// Snapshots are built from the checkpoint snapshots stored by the clique engine of the node in its database
+// and the headers of the chain after them. cliqueDB is either that database opened locally or the remote KV
+// interface of the node, which serves the clique snapshot buckets.
+type CliqueImpl struct {
+	*BaseAPI
+	db            ethdb.RoKV
+	cliqueDB      ethdb.RoKV
+	cliqueService *services.CliqueService
+}
+
+// NewCliqueAPI returns CliqueImpl instance
+func NewCliqueAPI(base *BaseAPI, db ethdb.RoKV, cliqueDB ethdb.RoKV, cliqueService *services.CliqueService) *CliqueImpl {
+	return &CliqueImpl{
+		BaseAPI:       base,
+		db:            db,
+		cliqueDB:      cliqueDB,
+		cliqueService: cliqueService,
+	}
+}
+
+// GetSnapshot implements clique_getSnapshot. Retrieves the state snapshot at a given block (latest if not specified).
+func (api *CliqueImpl) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*clique.Snapshot, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := headerByNumber(tx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.snapshot(ctx, tx, header)
+}
+
+// GetSnapshotAtHash implements clique_getSnapshotAtHash. Retrieves the state snapshot at a given block.
+func (api *CliqueImpl) GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := rawdb.ReadHeaderByHash(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block header not found: %s", hash.String())
+	}
+	return api.snapshot(ctx, tx, header)
+}
+
+// GetSigners implements clique_getSigners. Retrieves the list of authorized signers at the specified block.
+func (api *CliqueImpl) GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error) {
+	snap, err := api.GetSnapshot(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetSigners(), nil
+}
+
+// GetSignersAtHash implements clique_getSignersAtHash. Retrieves the list of authorized signers at the specified block.
+func (api *CliqueImpl) GetSignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	snap, err := api.GetSnapshotAtHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetSigners(), nil
+}
+
+// Status implements clique_status. Returns the signing activity and in-turn percentage of the last 64 blocks.
+func (api *CliqueImpl) Status(ctx context.Context) (*clique.Status, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	header, err := headerByNumber(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var status *clique.Status
+	err = api.withCliqueTx(ctx, tx, func(cc *params.ChainConfig, cliqueTx ethdb.Tx) (err error) {
+		status, err = clique.StoredStatus(cc.Clique, cliqueTx, &cliqueChainReader{cfg: cc, tx: tx}, header)
+		return err
+	})
+	return status, err
+}
+
+// Proposals implements clique_proposals. Returns the current proposals the node tries to uphold and vote on.
+func (api *CliqueImpl) Proposals(ctx context.Context) (map[common.Address]bool, error) {
+	if api.cliqueService == nil {
+		return nil, fmt.Errorf("clique_proposals requires rpcdaemon to be connected to the node (--private.api.addr)")
+	}
+	return api.cliqueService.GetProposals(ctx)
+}
+
+// Propose implements clique_propose. Injects a new authorization proposal that the signer of the node will attempt to push through.
+func (api *CliqueImpl) Propose(ctx context.Context, address common.Address, auth bool) error {
+	if api.cliqueService == nil {
+		return fmt.Errorf("clique_propose requires rpcdaemon to be connected to the node (--private.api.addr)")
+	}
+	return api.cliqueService.ProposeSigner(ctx, address, auth)
+}
+
+// Discard implements clique_discard. Drops a currently running proposal, stopping the signer of the node from casting further votes.
+func (api *CliqueImpl) Discard(ctx context.Context, address common.Address) error {
+	if api.cliqueService == nil {
+		return fmt.Errorf("clique_discard requires rpcdaemon to be connected to the node (--private.api.addr)")
+	}
+	return api.cliqueService.DiscardSigner(ctx, address)
+}
+
+// snapshot returns the snapshot at the header, built from the checkpoint snapshots stored by the node
+func (api *CliqueImpl) snapshot(ctx context.Context, tx ethdb.Tx, header *types.Header) (*clique.Snapshot, error) {
+	var snap *clique.Snapshot
+	err := api.withCliqueTx(ctx, tx, func(cc *params.ChainConfig, cliqueTx ethdb.Tx) (err error) {
+		snap, err = clique.StoredSnapshot(cc.Clique, cliqueTx, &cliqueChainReader{cfg: cc, tx: tx}, header.Number.Uint64(), header.Hash())
+		return err
+	})
+	return snap, err
+}
+
+// withCliqueTx runs f with a read-only transaction of the database of the clique engine of the node
+func (api *CliqueImpl) withCliqueTx(ctx context.Context, tx ethdb.Tx, f func(cc *params.ChainConfig, cliqueTx ethdb.Tx) error) error {
+	cc, err := api.chainConfig(tx)
+	if err != nil {
+		return err
+	}
+	if cc.Clique == nil {
+		return fmt.Errorf("chain is not running clique consensus")
+	}
+	if api.cliqueDB == nil {
+		return fmt.Errorf("clique snapshots are stored in the clique db of the node, connect rpcdaemon to the node (--private.api.addr) or run it with --clique.datadir on the machine of the node")
+	}
+	cliqueTx, err := api.cliqueDB.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer cliqueTx.Rollback()
+	return f(cc, cliqueTx)
+}
+
+func headerByNumber(tx ethdb.Tx, number *rpc.BlockNumber) (*types.Header, error) {
+	blockNumber := rpc.LatestBlockNumber
+	if number != nil {
+		blockNumber = *number
+	}
+	n, err := getBlockNumber(blockNumber, tx)
+	if err != nil {
+		return nil, err
+	}
+	header := rawdb.ReadHeaderByNumber(tx, n)
+	if header == nil {
+		return nil, fmt.Errorf("block header not found: %d", n)
+	}
+	return header, nil
+}
+
+// cliqueChainReader implements consensus.ChainHeaderReader over a read-only transaction
+type cliqueChainReader struct {
+	cfg *params.ChainConfig
+	tx  ethdb.Tx
+}
+
+func (cr *cliqueChainReader) Config() *params.ChainConfig { return cr.cfg }
+
+func (cr *cliqueChainReader) CurrentHeader() *types.Header {
+	return rawdb.ReadCurrentHeader(cr.tx)
+}
+
+func (cr *cliqueChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return rawdb.ReadHeader(cr.tx, hash, number)
+}
+
+func (cr *cliqueChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	return rawdb.ReadHeaderByNumber(cr.tx, number)
+}
+
+func (cr *cliqueChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, _ := rawdb.ReadHeaderByHash(cr.tx, hash)
+	return header
+}
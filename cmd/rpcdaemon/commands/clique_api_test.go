@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestCliqueProposals(t *testing.T) {
+	engine := clique.New(params.RinkebyChainConfig, params.CliqueSnapshot, kv.NewTestKV(t))
+	conn := createCliqueGrpcConn(t, engine)
+
+	ctx := context.Background()
+	api := NewCliqueAPI(NewBaseApi(nil), nil, nil, services.NewCliqueService(conn))
+	add, drop := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	require.NoError(t, api.Propose(ctx, add, true))
+	require.NoError(t, api.Propose(ctx, drop, false))
+	require.Equal(t, map[common.Address]bool{add: true, drop: false}, engine.Proposals())
+
+	proposals, err := api.Proposals(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[common.Address]bool{add: true, drop: false}, proposals)
+
+	require.NoError(t, api.Discard(ctx, drop))
+	proposals, err = api.Proposals(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[common.Address]bool{add: true}, proposals)
+}
+
+func TestCliqueProposalsNotClique(t *testing.T) {
+	conn := createCliqueGrpcConn(t, nil)
+
+	api := NewCliqueAPI(NewBaseApi(nil), nil, nil, services.NewCliqueService(conn))
+	_, err := api.Proposals(context.Background())
+	require.EqualError(t, err, "not supported, consensus engine is not clique")
+}
+
+func TestCliqueStoredSnapshots(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, voted, stored := crypto.PubkeyToAddress(key.PublicKey), common.HexToAddress("0x0b"), common.HexToAddress("0x0c")
+
+	db, cliqueDB := kv.NewTestKV(t), kv.NewTestKV(t)
+	genesis := &core.Genesis{
+		Config:    params.AllCliqueProtocolChanges,
+		ExtraData: make([]byte, clique.ExtraVanity+common.AddressLength+clique.ExtraSeal),
+	}
+	copy(genesis.ExtraData[clique.ExtraVanity:], signer[:])
+	genesisBlock := genesis.MustCommit(db)
+
+	// the only signer votes to add another one, the vote passes immediately
+	header := &types.Header{
+		ParentHash: genesisBlock.Hash(),
+		Number:     big.NewInt(1),
+		Coinbase:   voted,
+		Difficulty: clique.DiffInTurn,
+		Extra:      make([]byte, clique.ExtraVanity+clique.ExtraSeal),
+		Time:       1,
+	}
+	copy(header.Nonce[:], clique.NonceAuthVote)
+	sig, err := crypto.Sign(clique.SealHash(header).Bytes(), key)
+	require.NoError(t, err)
+	copy(header.Extra[clique.ExtraVanity:], sig)
+	require.NoError(t, db.Update(ctx, func(tx ethdb.RwTx) error {
+		rawdb.WriteHeader(tx, header)
+		return rawdb.WriteCanonicalHash(tx, header.Hash(), 1)
+	}))
+
+	api := NewCliqueAPI(NewBaseApi(nil), db, cliqueDB, nil)
+	zero, one := rpc.BlockNumber(0), rpc.BlockNumber(1)
+	signers, err := api.GetSigners(ctx, &zero)
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{signer}, signers)
+	snap, err := api.GetSnapshot(ctx, &one)
+	require.NoError(t, err)
+	require.Equal(t, header.Hash(), snap.Hash)
+	require.Len(t, snap.Signers, 2)
+
+	// the checkpoint snapshot stored by the engine of the node is used instead of the headers
+	storedSnap := &clique.Snapshot{Number: 1, Hash: header.Hash(), Signers: map[common.Address]struct{}{stored: {}}}
+	blob, err := json.Marshal(storedSnap)
+	require.NoError(t, err)
+	require.NoError(t, cliqueDB.Update(ctx, func(tx ethdb.RwTx) error {
+		return tx.Put(dbutils.CliqueSeparateBucket, clique.SnapshotFullKey(1, header.Hash()), blob)
+	}))
+	signers, err = api.GetSignersAtHash(ctx, header.Hash())
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{stored}, signers)
+
+	// the clique db of the node is required
+	_, err = NewCliqueAPI(NewBaseApi(nil), db, nil, nil).GetSnapshot(ctx, &one)
+	require.Error(t, err)
+}
+
+func createCliqueGrpcConn(t *testing.T, engine remotedbserver.CliqueBackend) *grpc.ClientConn {
+	server := grpc.NewServer()
+	remotedbserver.RegisterCLIQUEServer(server, remotedbserver.NewCliqueServer(engine))
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+	conn, err := grpc.DialContext(context.Background(), "", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
@@ -7,15 +7,17 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/filters"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/rpc"
 )
 
 // APIList describes the list of available RPC apis
-func APIList(ctx context.Context, db ethdb.RoKV, eth services.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient, filters *filters.Filters, cfg cli.Flags, customAPIList []rpc.API) []rpc.API {
+func APIList(ctx context.Context, db ethdb.RoKV, eth services.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient, clique *services.CliqueService, cliqueDB ethdb.RoKV, dev *services.DevService, fork *state.ForkSource, filters *filters.Filters, cfg cli.Flags, customAPIList []rpc.API) []rpc.API {
 	var defaultAPIList []rpc.API
 
 	base := NewBaseApi(filters)
+	base.fork = fork
 	ethImpl := NewEthAPI(base, db, eth, txPool, mining, cfg.Gascap)
 	erigonImpl := NewErigonAPI(base, db)
 	txpoolImpl := NewTxPoolAPI(base, db, txPool)
@@ -23,6 +25,9 @@ func APIList(ctx context.Context, db ethdb.RoKV, eth services.ApiBackend, txPool
 	debugImpl := NewPrivateDebugAPI(base, db, cfg.Gascap)
 	traceImpl := NewTraceAPI(base, db, &cfg)
 	web3Impl := NewWeb3APIImpl(eth)
+	adminImpl := NewAdminAPI(base, db, cfg.BackupDir)
+	cliqueImpl := NewCliqueAPI(base, db, cliqueDB, clique)
+	evmImpl := NewEvmAPI(base, dev)
 	dbImpl := NewDBAPIImpl()   /* deprecated */
 	shhImpl := NewSHHAPIImpl() /* deprecated */
 
@@ -91,8 +96,40 @@ func APIList(ctx context.Context, db ethdb.RoKV, eth services.ApiBackend, txPool
 				Service:   ErigonAPI(erigonImpl),
 				Version:   "1.0",
 			})
+		case "admin":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "admin",
+				Public:    false,
+				Service:   AdminAPI(adminImpl),
+				Version:   "1.0",
+			})
+		case "clique":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "clique",
+				Public:    false,
+				Service:   CliqueAPI(cliqueImpl),
+				Version:   "1.0",
+			})
+		case "evm":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "evm",
+				Public:    false,
+				Service:   EvmAPI(evmImpl),
+				Version:   "1.0",
+			})
 		}
 	}
 
 	return append(defaultAPIList, customAPIList...)
 }
+
+// EngineAPIList - APIs of the authenticated endpoint of the consensus client (see cli.StartEngineServer),
+// they are never served on the public HTTP endpoint
+func EngineAPIList(engine *services.EngineService) []rpc.API {
+	return []rpc.API{{
+		Namespace: "engine",
+		Public:    false,
+		Service:   EngineAPI(NewEngineAPI(engine)),
+		Version:   "1.0",
+	}}
+}
@@ -12,12 +12,15 @@ import (
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/eth"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/adapter"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/transactions"
 )
 
@@ -30,6 +33,9 @@ type PrivateDebugAPI interface {
 	GetModifiedAccountsByHash(_ context.Context, startHash common.Hash, endHash *common.Hash) ([]common.Address, error)
 	TraceCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *tracers.TraceConfig, stream *jsoniter.Stream) error
 	AccountAt(ctx context.Context, blockHash common.Hash, txIndex uint64, account common.Address) (*AccountResult, error)
+	ControlFlowGraph(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash, format *string) (interface{}, error)
+	ProfileTransaction(ctx context.Context, hash common.Hash, format *string) (interface{}, error)
+	ProfileBlocks(ctx context.Context, startNumber rpc.BlockNumber, endNumber *rpc.BlockNumber, format *string) (interface{}, error)
 }
 
 // PrivateDebugAPIImpl is implementation of the PrivateDebugAPI interface based on remote Db access
@@ -71,7 +77,7 @@ func (api *PrivateDebugAPIImpl) StorageRangeAt(ctx context.Context, blockHash co
 	getHeader := func(hash common.Hash, number uint64) *types.Header {
 		return rawdb.ReadHeader(tx, hash, number)
 	}
-	checkTEVM := ethdb.GetCheckTEVM(tx)
+	checkTEVM := vm.GetCheckTEVM(tx)
 	_, _, _, _, stateReader, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, checkTEVM, ethash.NewFaker(), tx, blockHash, txIndex)
 	if err != nil {
 		return StorageRangeResult{}, err
@@ -234,7 +240,7 @@ func (api *PrivateDebugAPIImpl) AccountAt(ctx context.Context, blockHash common.
 	getHeader := func(hash common.Hash, number uint64) *types.Header {
 		return rawdb.ReadHeader(tx, hash, number)
 	}
-	checkTEVM := ethdb.GetCheckTEVM(tx)
+	checkTEVM := vm.GetCheckTEVM(tx)
 	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, checkTEVM, ethash.NewFaker(), tx, blockHash, txIndex)
 	if err != nil {
 		return nil, err
@@ -253,3 +259,48 @@ type AccountResult struct {
 	Code     hexutil.Bytes  `json:"code"`
 	CodeHash common.Hash    `json:"codeHash"`
 }
+
+// rpcCfgLimits - limits of debug_controlFlowGraph analysis, lower than vm.DefaultCfgLimits to bound the work of one request
+var rpcCfgLimits = vm.CfgLimits{AnlyCounter: 1 << 16, MaxStackLen: 1024, MaxStackCount: 1 << 12}
+
+// ControlFlowGraph implements debug_controlFlowGraph. Returns control flow graph of the contract code built by abstract interpretation,
+// as JSON object or as string in DOT format if format is "dot".
+func (api *PrivateDebugAPIImpl) ControlFlowGraph(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash, format *string) (interface{}, error) {
+	if format != nil && *format != "json" && *format != "dot" {
+		return nil, fmt.Errorf("unknown format %q, expected json or dot", *format)
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	blockNumber, _, err := rpchelper.GetBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	reader := adapter.NewStateReader(tx, blockNumber)
+	acc, err := reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("account %x not found", address)
+	}
+	code, err := reader.ReadAccountCode(address, acc.Incarnation, acc.CodeHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("account %x has no code", address)
+	}
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+	report, err := vm.AnalyseCfg(ctx, code, rpcCfgLimits)
+	if err != nil {
+		return nil, err
+	}
+	if format != nil && *format == "dot" {
+		return report.Dot(), nil
+	}
+	return report, nil
+}
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rpc"
 )
 
 var debugTraceTransactionTests = []struct {
@@ -93,3 +97,90 @@ func TestTraceTransactionNoRefund(t *testing.T) {
 		}
 	}
 }
+
+func TestControlFlowGraph(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	api := NewPrivateDebugAPI(NewBaseApi(nil), db, 0)
+	token := common.HexToAddress("0x3cb5b6e26e0f37f2514d45641f15bd6fec2e0c4c")
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	result, err := api.ControlFlowGraph(context.Background(), token, latest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, ok := result.(*vm.CfgReport)
+	if !ok {
+		t.Fatalf("unexpected result type %T", result)
+	}
+	if len(report.Blocks) == 0 || report.Blocks[0].Entry != 0 || len(report.Jumps) == 0 {
+		t.Errorf("unexpected graph %+v", report)
+	}
+
+	dot := "dot"
+	result, err = api.ControlFlowGraph(context.Background(), token, latest, &dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := result.(string); !ok || !strings.HasPrefix(s, "digraph") {
+		t.Errorf("unexpected dot result %v", result)
+	}
+
+	if _, err = api.ControlFlowGraph(context.Background(), common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7"), latest, nil); err == nil {
+		t.Errorf("expected error for account without code")
+	}
+}
+
+func TestProfileTransaction(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	api := NewPrivateDebugAPI(NewBaseApi(nil), db, 0)
+	tt := debugTraceTransactionTests[1]
+
+	result, err := api.ProfileTransaction(context.Background(), common.HexToHash(tt.txHash), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, ok := result.(*vm.GasProfile)
+	if !ok {
+		t.Fatalf("unexpected result type %T", result)
+	}
+	// intrinsic gas and refunds are not profiled
+	if profile.Gas == 0 || profile.Gas >= tt.gas || len(profile.Opcodes) == 0 || len(profile.Depths) == 0 || len(profile.Storage) == 0 {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+	var sum uint64
+	for _, op := range profile.Opcodes {
+		sum += op.Gas
+	}
+	if sum != profile.Gas {
+		t.Errorf("gas of opcodes %d, total gas %d", sum, profile.Gas)
+	}
+
+	folded := "folded"
+	result, err = api.ProfileTransaction(context.Background(), common.HexToHash(tt.txHash), &folded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := result.(string); !ok || s != profile.Folded {
+		t.Errorf("unexpected folded result %v", result)
+	}
+
+	latest := rpc.LatestBlockNumber
+	result, err = api.ProfileBlocks(context.Background(), 1, &latest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks := result.(*vm.GasProfile); blocks.Gas <= profile.Gas {
+		t.Errorf("gas of all blocks %d is not greater than gas of one transaction %d", blocks.Gas, profile.Gas)
+	}
+
+	unknown := "svg"
+	if _, err = api.ProfileBlocks(context.Background(), 1, nil, &unknown); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = api.ProfileBlocks(ctx, 1, &latest, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+}
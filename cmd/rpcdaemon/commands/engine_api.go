@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// EngineAPI the interface for the engine_ RPC commands, used by the consensus client driving the chain of the node
+// (--beacon). Served on the separate authenticated endpoint only (--engine.addr).
+type EngineAPI interface {
+	ExecutePayload(ctx context.Context, payload hexutil.Bytes) (*ExecutePayloadResult, error)
+	ForkchoiceUpdated(ctx context.Context, state ForkchoiceStateArgs) error
+	AssembleBlock(ctx context.Context, args AssembleBlockArgs) (*AssembledBlock, error)
+}
+
+// ExecutePayloadResult - result of engine_executePayload, status is VALID, INVALID or SYNCING
+type ExecutePayloadResult struct {
+	Status remotedbserver.PayloadStatus `json:"status"`
+}
+
+// ForkchoiceStateArgs - argument of engine_forkchoiceUpdated
+type ForkchoiceStateArgs struct {
+	HeadBlockHash      common.Hash `json:"headBlockHash"`
+	FinalizedBlockHash common.Hash `json:"finalizedBlockHash"`
+}
+
+// AssembleBlockArgs - argument of engine_assembleBlock, zero feeRecipient means etherbase of the node
+type AssembleBlockArgs struct {
+	ParentHash   common.Hash    `json:"parentHash"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	FeeRecipient common.Address `json:"feeRecipient"`
+}
+
+// AssembledBlock - result of engine_assembleBlock, block is RLP encoded as expected by engine_executePayload
+type AssembledBlock struct {
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	Timestamp   hexutil.Uint64 `json:"timestamp"`
+	Block       hexutil.Bytes  `json:"block"`
+}
+
+// EngineImpl data structure to store things needed for engine_ commands, they are forwarded to the node
+type EngineImpl struct {
+	engine *services.EngineService
+}
+
+// NewEngineAPI returns EngineImpl instance
+func NewEngineAPI(engine *services.EngineService) *EngineImpl {
+	return &EngineImpl{engine: engine}
+}
+
+// ExecutePayload implements engine_executePayload. Executes the RLP encoded block, the head of the chain is moved by engine_forkchoiceUpdated.
+func (api *EngineImpl) ExecutePayload(ctx context.Context, payload hexutil.Bytes) (*ExecutePayloadResult, error) {
+	if api.engine == nil {
+		return nil, fmt.Errorf("engine_executePayload requires --private.api.addr")
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(payload, block); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	status, err := api.engine.ExecuteBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutePayloadResult{Status: status}, nil
+}
+
+// ForkchoiceUpdated implements engine_forkchoiceUpdated. Makes the known block the head of the chain, unwinding
+// the chain if necessary, and marks the finalized block. Zero finalizedBlockHash keeps the finalized block.
+func (api *EngineImpl) ForkchoiceUpdated(ctx context.Context, state ForkchoiceStateArgs) error {
+	if api.engine == nil {
+		return fmt.Errorf("engine_forkchoiceUpdated requires --private.api.addr")
+	}
+	return api.engine.UpdateForkchoice(ctx, state.HeadBlockHash, state.FinalizedBlockHash)
+}
+
+// AssembleBlock implements engine_assembleBlock. Builds a block with the transactions of the pool on top of the
+// head of the chain. The block is not inserted, the consensus client executes it with engine_executePayload.
+func (api *EngineImpl) AssembleBlock(ctx context.Context, args AssembleBlockArgs) (*AssembledBlock, error) {
+	if api.engine == nil {
+		return nil, fmt.Errorf("engine_assembleBlock requires --private.api.addr")
+	}
+	block, err := api.engine.Assemble(ctx, args.ParentHash, uint64(args.Timestamp), args.FeeRecipient)
+	if err != nil {
+		return nil, err
+	}
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AssembledBlock{
+		BlockHash:   block.Hash(),
+		BlockNumber: hexutil.Uint64(block.NumberU64()),
+		ParentHash:  block.ParentHash(),
+		Timestamp:   hexutil.Uint64(block.Time()),
+		Block:       data,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testEngine struct {
+	head, finalized common.Hash
+	executed        []*types.Block
+}
+
+func (e *testEngine) ExecutePayload(_ context.Context, block *types.Block) (remotedbserver.PayloadStatus, error) {
+	if block.ParentHash() != e.head {
+		return remotedbserver.PayloadSyncing, nil
+	}
+	e.executed = append(e.executed, block)
+	return remotedbserver.PayloadValid, nil
+}
+
+func (e *testEngine) ForkchoiceUpdated(_ context.Context, head, finalized common.Hash) error {
+	if head == (common.Hash{}) {
+		return errors.New("unknown head")
+	}
+	e.head, e.finalized = head, finalized
+	return nil
+}
+
+func (e *testEngine) AssembleBlock(_ context.Context, parent common.Hash, timestamp uint64, feeRecipient common.Address) (*types.Block, error) {
+	return types.NewBlock(&types.Header{ParentHash: parent, Number: big.NewInt(1), Time: timestamp, Coinbase: feeRecipient, Difficulty: big.NewInt(1)}, nil, nil, nil), nil
+}
+
+func TestEngineAPI(t *testing.T) {
+	ctx := context.Background()
+	backend := &testEngine{head: common.Hash{1}}
+	api := NewEngineAPI(services.NewEngineService(createEngineGrpcConn(t, backend)))
+
+	assembled, err := api.AssembleBlock(ctx, AssembleBlockArgs{ParentHash: common.Hash{1}, Timestamp: 100, FeeRecipient: common.Address{5}})
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{1}, assembled.ParentHash)
+	require.Equal(t, hexutil.Uint64(1), assembled.BlockNumber)
+	require.Equal(t, hexutil.Uint64(100), assembled.Timestamp)
+	block := new(types.Block)
+	require.NoError(t, rlp.DecodeBytes(assembled.Block, block))
+	require.Equal(t, assembled.BlockHash, block.Hash())
+	require.Equal(t, common.Address{5}, block.Coinbase())
+
+	res, err := api.ExecutePayload(ctx, assembled.Block)
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadValid, res.Status)
+	require.Len(t, backend.executed, 1)
+	require.Equal(t, assembled.BlockHash, backend.executed[0].Hash())
+
+	require.NoError(t, api.ForkchoiceUpdated(ctx, ForkchoiceStateArgs{HeadBlockHash: assembled.BlockHash, FinalizedBlockHash: common.Hash{1}}))
+	require.Equal(t, assembled.BlockHash, backend.head)
+	require.Equal(t, common.Hash{1}, backend.finalized)
+	require.EqualError(t, api.ForkchoiceUpdated(ctx, ForkchoiceStateArgs{}), "unknown head")
+
+	res, err = api.ExecutePayload(ctx, assembled.Block)
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadSyncing, res.Status)
+	_, err = api.ExecutePayload(ctx, hexutil.Bytes{1, 2, 3})
+	require.Error(t, err)
+}
+
+func TestEngineAPINotBeacon(t *testing.T) {
+	api := NewEngineAPI(services.NewEngineService(createEngineGrpcConn(t, nil)))
+	err := api.ForkchoiceUpdated(context.Background(), ForkchoiceStateArgs{HeadBlockHash: common.Hash{1}})
+	require.EqualError(t, err, "not supported, chain of the node is not driven by a consensus client (--beacon)")
+
+	api = NewEngineAPI(nil)
+	_, err = api.AssembleBlock(context.Background(), AssembleBlockArgs{})
+	require.EqualError(t, err, "engine_assembleBlock requires --private.api.addr")
+}
+
+func createEngineGrpcConn(t *testing.T, backend remotedbserver.EngineBackend) *grpc.ClientConn {
+	server := grpc.NewServer()
+	remotedbserver.RegisterENGINEServer(server, remotedbserver.NewEngineServer(backend))
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+	conn, err := grpc.DialContext(context.Background(), "", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
@@ -15,12 +15,14 @@ import (
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
 	ethFilters "github.com/ledgerwatch/erigon/eth/filters"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/adapter"
 )
 
 // EthAPI is a collection of functions that are exposed in the
@@ -100,12 +102,23 @@ type BaseAPI struct {
 	_chainConfig    *params.ChainConfig
 	_genesis        *types.Block
 	_genesisSetOnce sync.Once
+
+	fork *state.ForkSource // developer chain forked from the state of another one
 }
 
 func NewBaseApi(f *filters.Filters) *BaseAPI {
 	return &BaseAPI{filters: f}
 }
 
+// stateReader of the state after blockNumber, for the forked developer chain it is layered over the source
+func (api *BaseAPI) stateReader(tx ethdb.Tx, blockNumber uint64) state.StateReader {
+	var reader state.StateReader = adapter.NewStateReader(tx, blockNumber)
+	if api.fork != nil {
+		reader = api.fork.Reader(reader, tx)
+	}
+	return reader
+}
+
 func (api *BaseAPI) chainConfig(tx ethdb.Tx) (*params.ChainConfig, error) {
 	cfg, _, err := api.chainConfigWithGenesis(tx)
 	return cfg, err
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+)
+
+// EvmAPI the interface for the evm_ RPC commands of the developer chain (as in ganache and hardhat)
+type EvmAPI interface {
+	Mine(ctx context.Context) (hexutil.Uint64, error)
+	IncreaseTime(ctx context.Context, seconds uint64) (uint64, error)
+}
+
+// EvmImpl data structure to store things needed for evm_ commands, they are forwarded to the
+// miner of the node, which must mine the developer chain with instant sealing
+type EvmImpl struct {
+	*BaseAPI
+	devService *services.DevService
+}
+
+// NewEvmAPI returns EvmImpl instance
+func NewEvmAPI(base *BaseAPI, devService *services.DevService) *EvmImpl {
+	return &EvmImpl{
+		BaseAPI:    base,
+		devService: devService,
+	}
+}
+
+// Mine implements evm_mine. Mines a block, with or without transactions, and returns its number once it is in the chain.
+func (api *EvmImpl) Mine(ctx context.Context) (hexutil.Uint64, error) {
+	if api.devService == nil {
+		return 0, fmt.Errorf("evm_mine requires --private.api.addr")
+	}
+	number, err := api.devService.MineBlock(ctx)
+	return hexutil.Uint64(number), err
+}
+
+// IncreaseTime implements evm_increaseTime. Moves the clock of the chain forward by the given number of seconds,
+// the following blocks get later timestamps. Returns the total time adjustment in seconds.
+func (api *EvmImpl) IncreaseTime(ctx context.Context, seconds uint64) (uint64, error) {
+	if api.devService == nil {
+		return 0, fmt.Errorf("evm_increaseTime requires --private.api.addr")
+	}
+	return api.devService.AddTime(ctx, seconds)
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/services"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testDevMiner struct {
+	number, offset uint64
+}
+
+func (m *testDevMiner) Mine(context.Context) (uint64, error) {
+	m.number++
+	return m.number, nil
+}
+
+func (m *testDevMiner) IncreaseTime(seconds uint64) (uint64, error) {
+	m.offset += seconds
+	return m.offset, nil
+}
+
+func TestEvmMine(t *testing.T) {
+	ctx := context.Background()
+	api := NewEvmAPI(NewBaseApi(nil), services.NewDevService(createDevGrpcConn(t, &testDevMiner{number: 5})))
+
+	number, err := api.Mine(ctx)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(6), number)
+
+	offset, err := api.IncreaseTime(ctx, 60)
+	require.NoError(t, err)
+	require.Equal(t, uint64(60), offset)
+	offset, err = api.IncreaseTime(ctx, 3600)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3660), offset)
+}
+
+func TestEvmMineNotInstantSeal(t *testing.T) {
+	api := NewEvmAPI(NewBaseApi(nil), services.NewDevService(createDevGrpcConn(t, nil)))
+	_, err := api.Mine(context.Background())
+	require.EqualError(t, err, "not supported, node doesn't mine developer chain with instant sealing (--chain dev --dev.period 0 --dev.instantseal --mine)")
+
+	api = NewEvmAPI(NewBaseApi(nil), nil)
+	_, err = api.IncreaseTime(context.Background(), 1)
+	require.EqualError(t, err, "evm_increaseTime requires --private.api.addr")
+}
+
+func createDevGrpcConn(t *testing.T, miner remotedbserver.DevBackend) *grpc.ClientConn {
+	server := grpc.NewServer()
+	remotedbserver.RegisterDEVServer(server, remotedbserver.NewDevServer(miner))
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+	conn, err := grpc.DialContext(context.Background(), "", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
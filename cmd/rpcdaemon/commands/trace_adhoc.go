@@ -657,6 +657,7 @@ func (api *TraceAPIImpl) ReplayBlockTransactions(ctx context.Context, blockNrOrH
 	if traceTypeStateDiff || traceTypeTrace {
 		vmConfig = vm.Config{Debug: traceTypeTrace, Tracer: &ot}
 	}
+	vmConfig.JumpDestCache = core.NewDBJumpDestCache(vm.SharedJumpDests, tx, nil)
 	stateWriter = state.NewNoopWriter()
 	var sd *StateDiff
 	for i, txn := range block.Transactions() {
@@ -775,7 +776,7 @@ func (api *TraceAPIImpl) Call(ctx context.Context, args TraceCallParam, traceTyp
 	blockCtx.GasLimit = math.MaxUint64
 	blockCtx.MaxGasLimit = true
 
-	evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: traceTypeTrace, Tracer: &ot})
+	evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: traceTypeTrace, Tracer: &ot, JumpDestCache: core.NewDBJumpDestCache(vm.SharedJumpDests, tx, nil)})
 
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
@@ -963,7 +964,7 @@ func (api *TraceAPIImpl) doCallMany(ctx context.Context, dbtx ethdb.Tx, callPara
 		ibs := state.New(cachedReader)
 		// Create initial IntraBlockState, we will compare it with ibs (IntraBlockState after the transaction)
 
-		evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: traceTypeTrace, Tracer: &ot})
+		evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: traceTypeTrace, Tracer: &ot, JumpDestCache: core.NewDBJumpDestCache(vm.SharedJumpDests, dbtx, nil)})
 
 		gp := new(core.GasPool).AddGas(msg.Gas())
 		var execResult *core.ExecutionResult
@@ -7,12 +7,22 @@ import (
 	"github.com/holiman/uint256"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/u256"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/consensus/aura"
+	"github.com/ledgerwatch/erigon/consensus/aura/consensusconfig"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/misc"
+	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/transactions"
@@ -59,7 +69,7 @@ func (api *PrivateDebugAPIImpl) TraceTransaction(ctx context.Context, hash commo
 		return err
 	}
 	// Trace the transaction and return
-	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream)
+	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, core.NewDBJumpDestCache(vm.SharedJumpDests, tx, nil), stream)
 }
 
 func (api *PrivateDebugAPIImpl) TraceCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *tracers.TraceConfig, stream *jsoniter.Stream) error {
@@ -108,5 +118,209 @@ func (api *PrivateDebugAPIImpl) TraceCall(ctx context.Context, args ethapi.CallA
 	}
 	blockCtx, txCtx := transactions.GetEvmContext(msg, header, blockNrOrHash.RequireCanonical, dbtx)
 	// Trace the transaction and return
-	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream)
+	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, core.NewDBJumpDestCache(vm.SharedJumpDests, dbtx, nil), stream)
+}
+
+// ProfileTransaction implements debug_profileTransaction. Replays the transaction and returns gas used by opcodes,
+// aggregated per contract, opcode and pc, per call depth and per storage slot. If format is "folded",
+// returns only stacks in the folded format of flamegraph tools.
+func (api *PrivateDebugAPIImpl) ProfileTransaction(ctx context.Context, hash common.Hash, format *string) (interface{}, error) {
+	if err := checkProfileFormat(format); err != nil {
+		return nil, err
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txn, blockHash, _, txIndex, err := rawdb.ReadTransaction(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if txn == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	block, _, err := rawdb.ReadBlockByHashWithSenders(tx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %x not found", blockHash)
+	}
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		return rawdb.ReadHeader(tx, hash, number)
+	}
+	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, nil /* checkTEVM */, ethash.NewFaker(), tx, blockHash, txIndex)
+	if err != nil {
+		return nil, err
+	}
+	profiler := vm.NewGasProfiler()
+	evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: true, Tracer: profiler, JumpDestCache: core.NewDBJumpDestCache(vm.SharedJumpDests, tx, nil)})
+	if _, err = core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */, false /* gasBailout */); err != nil {
+		return nil, fmt.Errorf("transaction %x failed: %w", hash, err)
+	}
+	return profileResult(profiler, format), nil
+}
+
+// maxProfileBlocks - limit of the range of blocks profiled by one debug_profileBlocks request
+const maxProfileBlocks = 1000
+
+// ProfileBlocks implements debug_profileBlocks. Same as debug_profileTransaction, for all transactions of the range of blocks,
+// including system calls of the consensus engine, made at the beginning and at the end of the blocks.
+func (api *PrivateDebugAPIImpl) ProfileBlocks(ctx context.Context, startNumber rpc.BlockNumber, endNumber *rpc.BlockNumber, format *string) (interface{}, error) {
+	if err := checkProfileFormat(format); err != nil {
+		return nil, err
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	startNum, err := getBlockNumber(startNumber, tx)
+	if err != nil {
+		return nil, err
+	}
+	endNum := startNum
+	if endNumber != nil {
+		if endNum, err = getBlockNumber(*endNumber, tx); err != nil {
+			return nil, err
+		}
+	}
+	if startNum > endNum {
+		return nil, fmt.Errorf("start block (%d) must be less than or equal to end block (%d)", startNum, endNum)
+	}
+	if endNum-startNum >= maxProfileBlocks {
+		return nil, fmt.Errorf("too many blocks to profile: %d, max %d", endNum-startNum+1, maxProfileBlocks)
+	}
+	if startNum == 0 {
+		return nil, fmt.Errorf("genesis block has no transactions to profile")
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := profileEngine(chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer engine.Close()
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		return rawdb.ReadHeader(tx, hash, number)
+	}
+	profiler := vm.NewGasProfiler()
+	vmConfig := vm.Config{Debug: true, Tracer: profiler, JumpDestCache: core.NewDBJumpDestCache(vm.SharedJumpDests, tx, nil)}
+	for blockNum := startNum; blockNum <= endNum; blockNum++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		block, _, err := rawdb.ReadBlockByNumberWithSenders(tx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", blockNum)
+		}
+		header := block.Header()
+		ibs := state.New(state.NewPlainKvState(tx, blockNum-1))
+		signer := types.MakeSigner(chainConfig, blockNum)
+		blockCtx := core.NewEVMBlockContext(header, getHeader, engine, nil, nil /* checkTEVM */)
+		evm := vm.NewEVM(blockCtx, vm.TxContext{}, ibs, chainConfig, vmConfig)
+		if chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(block.Number()) == 0 {
+			misc.ApplyDAOHardFork(ibs)
+		}
+		engine.Initialize(chainConfig, profileEpochReader{tx: tx}, header, ibs, block.Transactions(), block.Uncles(), func(contract common.Address, data []byte) ([]byte, error) {
+			res, err := profileSysCall(evm, ibs, core.SystemAddress, contract, data)
+			ibs.SetNonce(core.SystemAddress, 0) // same as core.SysCallContract
+			return res, err
+		})
+		for idx, txn := range block.Transactions() {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+			ibs.Prepare(txn.Hash(), block.Hash(), idx)
+			msg, err := txn.AsMessage(*signer, block.BaseFee())
+			if err != nil {
+				return nil, err
+			}
+			evm.Reset(core.NewEVMTxContext(msg), ibs)
+			if _, err = core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */, false /* gasBailout */); err != nil {
+				return nil, fmt.Errorf("transaction %x failed: %w", txn.Hash(), err)
+			}
+			if err = ibs.FinalizeTx(evm.ChainRules, state.NewNoopWriter()); err != nil {
+				return nil, err
+			}
+		}
+		// receipts and chain reader are not needed: epoch transitions are already stored by the node
+		if err = engine.Finalize(chainConfig, header, ibs, block.Transactions(), block.Uncles(), nil, profileEpochReader{tx: tx}, nil, func(contract common.Address, data []byte) ([]byte, error) {
+			return profileSysCall(evm, ibs, common.Address{}, contract, data) // same sender as core.CallContract
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return profileResult(profiler, format), nil
+}
+
+// profileEngine - engine making the system calls of the blocks. Only AuRa makes them, state changes of the others
+// in Finalize (block rewards) don't affect gas, because every block is executed on the state stored in the database.
+func profileEngine(cc *params.ChainConfig) (consensus.Engine, error) {
+	if cc.Aura == nil {
+		return ethash.NewFaker(), nil
+	}
+	spec := consensusconfig.Sokol
+	if len(cc.Aura.Params) > 0 { // custom chain
+		spec = cc.Aura.Params
+	}
+	return aura.NewAuRa(cc.Aura, kv.NewMemKV(), common.Address{}, spec)
+}
+
+// profileSysCall - system call of the engine, executed by evm with the gas profiler
+func profileSysCall(evm *vm.EVM, ibs *state.IntraBlockState, from common.Address, contract common.Address, data []byte) ([]byte, error) {
+	msg := types.NewMessage(from, &contract, ibs.GetNonce(from), u256.Num0, 50_000_000, u256.Num0, nil, nil, data, nil, false /* checkNonce */)
+	evm.Reset(core.NewEVMTxContext(msg), ibs)
+	res, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas()), false /* refunds */, false /* gasBailout */)
+	if err != nil {
+		return nil, fmt.Errorf("system call %x: %w", contract, err)
+	}
+	return res.ReturnData, nil
+}
+
+// profileEpochReader - reads epochs stored by the node, writes of the engine are dropped
+type profileEpochReader struct {
+	tx ethdb.Tx
+}
+
+func (r profileEpochReader) GetEpoch(hash common.Hash, number uint64) ([]byte, error) {
+	return rawdb.ReadEpoch(r.tx, number, hash)
+}
+func (r profileEpochReader) PutEpoch(common.Hash, uint64, []byte) error { return nil }
+func (r profileEpochReader) GetPendingEpoch(hash common.Hash, number uint64) ([]byte, error) {
+	return rawdb.ReadPendingEpoch(r.tx, number, hash)
+}
+func (r profileEpochReader) PutPendingEpoch(common.Hash, uint64, []byte) error { return nil }
+func (r profileEpochReader) FindBeforeOrEqualNumber(number uint64) (uint64, common.Hash, []byte, error) {
+	return rawdb.FindEpochBeforeOrEqualNumber(r.tx, number)
+}
+
+func checkProfileFormat(format *string) error {
+	if format != nil && *format != "json" && *format != "folded" {
+		return fmt.Errorf("unknown format %q, expected json or folded", *format)
+	}
+	return nil
+}
+
+func profileResult(profiler *vm.GasProfiler, format *string) interface{} {
+	if format != nil && *format == "folded" {
+		return profiler.Folded()
+	}
+	return profiler.Profile()
 }
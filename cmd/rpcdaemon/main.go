@@ -8,6 +8,8 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/filters"
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/common/fdlimit"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/log"
 	"github.com/spf13/cobra"
 )
@@ -17,13 +19,33 @@ func main() {
 	cmd, cfg := cli.RootCommand()
 	rootCtx, rootCancel := utils.RootContext()
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		db, backend, txPool, mining, err := cli.RemoteServices(*cfg, rootCancel)
+		db, backend, txPool, mining, clique, dev, engine, err := cli.RemoteServices(*cfg, rootCancel)
 		if err != nil {
 			log.Error("Could not connect to DB", "error", err)
 			return nil
 		}
 		defer db.Close()
 
+		cliqueDB, err := cli.OpenCliqueDB(*cfg)
+		if err != nil {
+			log.Error("Could not open clique snapshots DB", "error", err)
+			return nil
+		}
+		if cliqueDB != nil {
+			defer cliqueDB.Close()
+		} else if !cfg.SingleNodeMode {
+			cliqueDB = db // the node serves the clique snapshots through the remote KV interface
+		}
+
+		var fork *state.ForkSource
+		if cfg.DevForkSource != "" {
+			if fork, _, err = core.OpenForkSource(cfg.DevForkSource, cfg.DevForkBlock); err != nil {
+				log.Error("Could not open the source of developer chain", "error", err)
+				return nil
+			}
+			defer fork.Close()
+		}
+
 		var ff *filters.Filters
 		if backend != nil {
 			ff = filters.New(rootCtx, backend, txPool, mining)
@@ -31,7 +53,15 @@ func main() {
 			log.Info("filters are not supported in chaindata mode")
 		}
 
-		if err := cli.StartRpcServer(cmd.Context(), *cfg, commands.APIList(cmd.Context(), db, backend, txPool, mining, ff, *cfg, nil)); err != nil {
+		if cfg.EngineAddr != "" {
+			go func() {
+				if err := cli.StartEngineServer(cmd.Context(), *cfg, commands.EngineAPIList(engine)); err != nil {
+					log.Error(err.Error())
+				}
+			}()
+		}
+
+		if err := cli.StartRpcServer(cmd.Context(), *cfg, commands.APIList(cmd.Context(), db, backend, txPool, mining, clique, cliqueDB, dev, fork, ff, *cfg, nil)); err != nil {
 			log.Error(err.Error())
 			return nil
 		}
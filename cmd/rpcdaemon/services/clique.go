@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CliqueService - proposals of the clique engine of the remote node
+type CliqueService struct {
+	remotedbserver.CLIQUEClient
+	log     log.Logger
+	version gointerfaces.Version
+}
+
+func NewCliqueService(cc grpc.ClientConnInterface) *CliqueService {
+	return &CliqueService{
+		CLIQUEClient: remotedbserver.NewCLIQUEClient(cc),
+		version:      gointerfaces.VersionFromProto(remotedbserver.CliqueAPIVersion),
+		log:          log.New("remote_service", "clique"),
+	}
+}
+
+func (s *CliqueService) EnsureVersionCompatibility() bool {
+	versionReply, err := s.Version(context.Background(), &emptypb.Empty{}, grpc.WaitForReady(true))
+	if err != nil {
+		s.log.Error("getting Version", "error", err)
+		return false
+	}
+	if !gointerfaces.EnsureVersion(s.version, versionReply) {
+		s.log.Error("incompatible interface versions", "client", s.version.String(),
+			"server", fmt.Sprintf("%d.%d.%d", versionReply.Major, versionReply.Minor, versionReply.Patch))
+		return false
+	}
+	s.log.Info("interfaces compatible", "client", s.version.String(),
+		"server", fmt.Sprintf("%d.%d.%d", versionReply.Major, versionReply.Minor, versionReply.Patch))
+	return true
+}
+
+func (s *CliqueService) GetProposals(ctx context.Context) (map[common.Address]bool, error) {
+	res, err := s.Proposals(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	proposals := make(map[common.Address]bool, len(res.GetProposals()))
+	for _, proposal := range res.GetProposals() {
+		proposals[gointerfaces.ConvertH160toAddress(proposal.GetAddress())] = proposal.GetAuth()
+	}
+	return proposals, nil
+}
+
+func (s *CliqueService) ProposeSigner(ctx context.Context, address common.Address, auth bool) error {
+	if _, err := s.Propose(ctx, &remotedbserver.Proposal{Address: gointerfaces.ConvertAddressToH160(address), Auth: auth}); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+func (s *CliqueService) DiscardSigner(ctx context.Context, address common.Address) error {
+	if _, err := s.Discard(ctx, gointerfaces.ConvertAddressToH160(address)); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// fromStatus strips grpc status wrapping from errors returned by the node
+func fromStatus(err error) error {
+	if s, ok := status.FromError(err); ok {
+		return errors.New(s.Message())
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/log"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// DevService - miner of the instant sealing developer chain of the remote node
+type DevService struct {
+	remotedbserver.DEVClient
+	log     log.Logger
+	version gointerfaces.Version
+}
+
+func NewDevService(cc grpc.ClientConnInterface) *DevService {
+	return &DevService{
+		DEVClient: remotedbserver.NewDEVClient(cc),
+		version:   gointerfaces.VersionFromProto(remotedbserver.DevAPIVersion),
+		log:       log.New("remote_service", "dev"),
+	}
+}
+
+func (s *DevService) EnsureVersionCompatibility() bool {
+	versionReply, err := s.Version(context.Background(), &emptypb.Empty{}, grpc.WaitForReady(true))
+	if err != nil {
+		s.log.Error("getting Version", "error", err)
+		return false
+	}
+	if !gointerfaces.EnsureVersion(s.version, versionReply) {
+		s.log.Error("incompatible interface versions", "client", s.version.String(),
+			"server", fmt.Sprintf("%d.%d.%d", versionReply.Major, versionReply.Minor, versionReply.Patch))
+		return false
+	}
+	s.log.Info("interfaces compatible", "client", s.version.String(),
+		"server", fmt.Sprintf("%d.%d.%d", versionReply.Major, versionReply.Minor, versionReply.Patch))
+	return true
+}
+
+// MineBlock mines a block now and returns its number once it is in the chain
+func (s *DevService) MineBlock(ctx context.Context) (uint64, error) {
+	res, err := s.Mine(ctx, &emptypb.Empty{})
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return res.GetBlockNumber(), nil
+}
+
+// AddTime moves the clock of the chain forward, returns the total offset in seconds
+func (s *DevService) AddTime(ctx context.Context, seconds uint64) (uint64, error) {
+	res, err := s.IncreaseTime(ctx, &remotedbserver.IncreaseTimeRequest{Seconds: seconds})
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return res.GetOffset(), nil
+}
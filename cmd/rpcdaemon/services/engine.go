@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/rlp"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// EngineService - execution side of the remote node driven by a consensus client (--beacon)
+type EngineService struct {
+	remotedbserver.ENGINEClient
+	log     log.Logger
+	version gointerfaces.Version
+}
+
+func NewEngineService(cc grpc.ClientConnInterface) *EngineService {
+	return &EngineService{
+		ENGINEClient: remotedbserver.NewENGINEClient(cc),
+		version:      gointerfaces.VersionFromProto(remotedbserver.EngineAPIVersion),
+		log:          log.New("remote_service", "engine"),
+	}
+}
+
+func (s *EngineService) EnsureVersionCompatibility() bool {
+	versionReply, err := s.Version(context.Background(), &emptypb.Empty{}, grpc.WaitForReady(true))
+	if err != nil {
+		s.log.Error("getting Version", "error", err)
+		return false
+	}
+	if !gointerfaces.EnsureVersion(s.version, versionReply) {
+		s.log.Error("incompatible interface versions", "client", s.version.String(),
+			"server", fmt.Sprintf("%d.%d.%d", versionReply.Major, versionReply.Minor, versionReply.Patch))
+		return false
+	}
+	s.log.Info("interfaces compatible", "client", s.version.String(),
+		"server", fmt.Sprintf("%d.%d.%d", versionReply.Major, versionReply.Minor, versionReply.Patch))
+	return true
+}
+
+// ExecuteBlock executes the block on the remote node and returns its validity
+func (s *EngineService) ExecuteBlock(ctx context.Context, block *types.Block) (remotedbserver.PayloadStatus, error) {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return "", err
+	}
+	res, err := s.ExecutePayload(ctx, &remotedbserver.ExecutePayloadRequest{Block: data})
+	if err != nil {
+		return "", fromStatus(err)
+	}
+	return remotedbserver.PayloadStatus(res.GetStatus().String()), nil
+}
+
+// UpdateForkchoice moves the head of the chain of the remote node, zero finalized hash keeps the finalized block
+func (s *EngineService) UpdateForkchoice(ctx context.Context, head, finalized common.Hash) error {
+	req := &remotedbserver.ForkchoiceUpdatedRequest{
+		Head:      gointerfaces.ConvertHashToH256(head),
+		Finalized: gointerfaces.ConvertHashToH256(finalized),
+	}
+	if _, err := s.ForkchoiceUpdated(ctx, req); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// Assemble builds a block on top of the parent, which must be the head of the chain of the remote node
+func (s *EngineService) Assemble(ctx context.Context, parent common.Hash, timestamp uint64, feeRecipient common.Address) (*types.Block, error) {
+	req := &remotedbserver.AssembleBlockRequest{
+		Parent:       gointerfaces.ConvertHashToH256(parent),
+		Timestamp:    timestamp,
+		FeeRecipient: gointerfaces.ConvertAddressToH160(feeRecipient),
+	}
+	res, err := s.AssembleBlock(ctx, req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	block := new(types.Block)
+	if err = rlp.DecodeBytes(res.GetBlock(), block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,84 @@
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/p2p"
+)
+
+// Consensus messages of AuRa validators (signed empty steps) are exchanged over a separate capability: eth protocol
+// stays unchanged and peers which don't support it never receive them. Sentry gRPC API has no message id for them,
+// so they are delivered only by sentries running inside of the node.
+const (
+	consensusProtocolName    = "aura"
+	consensusProtocolVersion = 1
+	consensusProtocolLength  = 1
+	consensusMsg             = 0x00
+	consensusMaxMsgSize      = 64 * 1024
+)
+
+// EnableConsensusMessages - advertises the capability of consensus messages, received messages are passed to handle.
+// Must be called before the p2p server is started by the first SetStatus.
+func (ss *SentryServerImpl) EnableConsensusMessages(handle func(msg []byte) error) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	ss.consensusHandler = handle
+}
+
+// BroadcastConsensusMessage - sends the message to all peers supporting consensus messages, returns amount of them
+func (ss *SentryServerImpl) BroadcastConsensusMessage(msg []byte) int {
+	sent := 0
+	ss.consensusPeers.Range(func(key, value interface{}) bool {
+		rw := value.(p2p.MsgReadWriter)
+		if err := rw.WriteMsg(p2p.Msg{Code: consensusMsg, Size: uint32(len(msg)), Payload: bytes.NewReader(msg)}); err != nil {
+			log.Debug(fmt.Sprintf("[%s] Sending consensus message failed", key), "err", err)
+			return true
+		}
+		sent++
+		return true
+	})
+	return sent
+}
+
+func (ss *SentryServerImpl) consensusProtocol(handle func(msg []byte) error) p2p.Protocol {
+	return p2p.Protocol{
+		Name:    consensusProtocolName,
+		Version: consensusProtocolVersion,
+		Length:  consensusProtocolLength,
+		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+			peerID := peer.ID().String()
+			ss.consensusPeers.Store(peerID, rw)
+			defer ss.consensusPeers.Delete(peerID)
+			return runConsensusPeer(peerID, rw, handle)
+		},
+	}
+}
+
+func runConsensusPeer(peerID string, rw p2p.MsgReadWriter, handle func(msg []byte) error) error {
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return fmt.Errorf("reading consensus message: %w", err)
+		}
+		if msg.Code != consensusMsg {
+			msg.Discard()
+			return fmt.Errorf("unknown consensus message code: %d", msg.Code)
+		}
+		if msg.Size > consensusMaxMsgSize {
+			msg.Discard()
+			return fmt.Errorf("consensus message is too large %d, limit %d", msg.Size, consensusMaxMsgSize)
+		}
+		b := make([]byte, msg.Size)
+		if _, err := io.ReadFull(msg.Payload, b); err != nil {
+			return fmt.Errorf("reading consensus message: %w", err)
+		}
+		msg.Discard()
+		// messages of not validators or of the steps out of range are expected, they are just dropped
+		if err := handle(b); err != nil {
+			log.Debug(fmt.Sprintf("[%s] Consensus message dropped", peerID), "err", err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package download
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusMessages(t *testing.T) {
+	received := make(chan []byte, 1)
+	s1, s2 := &SentryServerImpl{}, &SentryServerImpl{}
+	rw1, rw2 := p2p.MsgPipe()
+	defer rw1.Close()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- s1.consensusProtocol(func(msg []byte) error {
+			t.Error("unexpected message")
+			return nil
+		}).Run(p2p.NewPeer(enode.ID{2}, "peer2", nil), rw1)
+	}()
+	go func() {
+		errs <- s2.consensusProtocol(func(msg []byte) error {
+			if len(msg) == 0 {
+				return errors.New("empty")
+			}
+			received <- msg
+			return nil
+		}).Run(p2p.NewPeer(enode.ID{1}, "peer1", nil), rw2)
+	}()
+	require.Eventually(t, func() bool {
+		_, ok1 := s1.consensusPeers.Load(enode.ID{2}.String())
+		_, ok2 := s2.consensusPeers.Load(enode.ID{1}.String())
+		return ok1 && ok2
+	}, time.Second, 10*time.Millisecond)
+
+	// invalid message is dropped, peer stays connected
+	require.Equal(t, 1, s1.BroadcastConsensusMessage([]byte{}))
+	require.Equal(t, 1, s1.BroadcastConsensusMessage([]byte{0xc1, 0x01}))
+	require.Equal(t, []byte{0xc1, 0x01}, <-received)
+
+	// too large message disconnects the peer
+	require.Equal(t, 1, s1.BroadcastConsensusMessage(make([]byte, consensusMaxMsgSize+1)))
+	require.Error(t, <-errs)
+	require.Eventually(t, func() bool {
+		_, ok := s2.consensusPeers.Load(enode.ID{1}.String())
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
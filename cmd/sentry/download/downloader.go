@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"math/rand"
 	"sync"
 	"time"
@@ -18,6 +19,8 @@ import (
 	debug2 "github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core/forkid"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
@@ -584,6 +587,45 @@ func (cs *ControlServerImpl) newBlock65(ctx context.Context, inreq *proto_sentry
 	return nil
 }
 
+// AddMinedBlock - passes the block sealed by this node to the header and body downloaders, the same way as
+// a new block received from a peer, so the sync loop inserts it into the chain
+func (cs *ControlServerImpl) AddMinedBlock(block *types.Block) error {
+	header := block.Header()
+	headerRaw, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return err
+	}
+	segments, _, err := cs.Hd.SingleHeaderAsSegment(headerRaw, header)
+	if err != nil {
+		return fmt.Errorf("singleHeaderAsSegment failed: %w", err)
+	}
+	cs.Hd.ProcessSegment(segments[0], true /* newBlock */, "" /* peerID */) // There is only one segment in this case
+	cs.Bd.AddToPrefetch(block)
+	return nil
+}
+
+// PropagateMinedBlock - inserts the block sealed by this node into its own chain and announces it to the peers
+// with the total difficulty of the chain ending with it. Blocks of all engines (ethash, clique, AuRa) are
+// propagated the same way.
+func (cs *ControlServerImpl) PropagateMinedBlock(ctx context.Context, block *types.Block) error {
+	if err := cs.AddMinedBlock(block); err != nil {
+		return err
+	}
+	var parentTd *big.Int
+	if err := cs.db.View(ctx, func(tx ethdb.Tx) error {
+		var err error
+		parentTd, err = rawdb.ReadTd(tx, block.ParentHash(), block.NumberU64()-1)
+		return err
+	}); err != nil {
+		return err
+	}
+	if parentTd == nil {
+		return fmt.Errorf("total difficulty of the parent of mined block %d is unknown", block.NumberU64())
+	}
+	cs.BroadcastNewBlock(ctx, block, new(big.Int).Add(parentTd, block.Difficulty()))
+	return nil
+}
+
 func (cs *ControlServerImpl) blockBodies66(inreq *proto_sentry.InboundMessage, sentry remote.SentryClient) error {
 	var request eth.BlockRawBodiesPacket66
 	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
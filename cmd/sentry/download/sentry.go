@@ -100,7 +100,7 @@ func (pi *PeerInfo) Removed() bool {
 func makeP2PServer(
 	p2pConfig p2p.Config,
 	genesisHash common.Hash,
-	protocol p2p.Protocol,
+	protocols ...p2p.Protocol,
 ) (*p2p.Server, error) {
 	var urls []string
 	switch genesisHash {
@@ -129,7 +129,7 @@ func makeP2PServer(
 		}
 	}
 	p2pConfig.BootstrapNodesV5 = p2pConfig.BootstrapNodes
-	p2pConfig.Protocols = []p2p.Protocol{protocol}
+	p2pConfig.Protocols = protocols
 	return &p2p.Server{Config: p2pConfig}, nil
 }
 
@@ -570,6 +570,8 @@ type SentryServerImpl struct {
 	messageStreamsLock sync.RWMutex
 	peersStreams       *PeersStreams
 	p2p                *p2p.Config
+	consensusHandler   func(msg []byte) error // see EnableConsensusMessages
+	consensusPeers     sync.Map               // peer id -> p2p.MsgReadWriter of consensus protocol
 }
 
 func (ss *SentryServerImpl) startSync(ctx context.Context, bestHash common.Hash, peerID string) error {
@@ -840,7 +842,11 @@ func (ss *SentryServerImpl) SetStatus(_ context.Context, statusData *proto_sentr
 			}
 		}
 
-		ss.P2pServer, err = makeP2PServer(*ss.p2p, genesisHash, ss.Protocol)
+		protocols := []p2p.Protocol{ss.Protocol}
+		if ss.consensusHandler != nil {
+			protocols = append(protocols, ss.consensusProtocol(ss.consensusHandler))
+		}
+		ss.P2pServer, err = makeP2PServer(*ss.p2p, genesisHash, protocols...)
 		if err != nil {
 			return reply, err
 		}
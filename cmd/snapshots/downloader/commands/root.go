@@ -25,7 +25,7 @@ import (
 
 func init() {
 	flags := append(debug.Flags, utils.MetricFlags...)
-	flags = append(flags, PreDownloadMainnetFlag, Addr, Dir, HttpApi)
+	flags = append(flags, PreDownloadMainnetFlag, Addr, Dir, HttpApi, Source)
 	utils.CobraFlags(rootCmd, flags)
 
 	rootCmd.PersistentFlags().Bool("seeding", true, "Seed snapshots")
@@ -50,12 +50,17 @@ var (
 		Name:  "http",
 		Usage: "Enable http",
 	}
+	Source = cli.StringFlag{
+		Name:  "source",
+		Usage: "directory or http(s) mirror to download snapshots from, instead of bittorrent",
+	}
 )
 
 type Config struct {
 	Addr    string
 	Dir     string
 	Seeding bool
+	Source  string
 }
 
 func Execute() {
@@ -113,7 +118,11 @@ func runDownloader(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	log.Info("Run snapshot downloader", "addr", cfg.Addr, "dir", cfg.Dir, "seeding", cfg.Seeding)
+	cfg.Source, err = cmd.Flags().GetString(Source.Name)
+	if err != nil {
+		return err
+	}
+	log.Info("Run snapshot downloader", "addr", cfg.Addr, "dir", cfg.Dir, "seeding", cfg.Seeding, "source", cfg.Source)
 	lis, err := net.Listen("tcp", cfg.Addr)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
@@ -140,6 +149,9 @@ func runDownloader(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("new server: %w", err)
 	}
+	if cfg.Source != "" {
+		bittorrentServer.SetSource(snapshotsync.NewMirrorSource(cfg.Dir, cfg.Source))
+	}
 	log.Info("Load")
 	err = bittorrentServer.Load()
 	if err != nil {
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	kv2 "github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/spf13/cobra"
+
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+)
+
+func init() {
+	withDatadir(generateReceiptsSnapshotCmd)
+	withSnapshotFile(generateReceiptsSnapshotCmd)
+	withBlock(generateReceiptsSnapshotCmd)
+
+	rootCmd.AddCommand(generateReceiptsSnapshotCmd)
+}
+
+var generateReceiptsSnapshotCmd = &cobra.Command{
+	Use:     "receipts",
+	Short:   "Generate receipts and logs snapshot",
+	Example: "go run cmd/snapshots/generator/main.go receipts --block 11000000 --datadir /media/b00ris/nvme/snapshotsync/ --snapshot /media/b00ris/nvme/snapshots/receipts_test",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ReceiptsSnapshot(cmd.Context(), chaindata, snapshotFile, block)
+	},
+}
+
+func ReceiptsSnapshot(ctx context.Context, dbPath, snapshotPath string, toBlock uint64) error {
+	if snapshotPath == "" {
+		return errors.New("empty snapshot path")
+	}
+	kv := kv2.NewMDBX().Path(dbPath).MustOpen()
+	defer kv.Close()
+
+	tx, err := kv.BeginRo(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t := time.Now()
+	if err = snapshotsync.CreateReceiptsSnapshot(ctx, tx, toBlock, snapshotPath); err != nil {
+		return err
+	}
+	err = os.Remove(snapshotPath + "/mdbx.lck")
+	if err != nil {
+		log.Warn("Remove lock", "err", err)
+		return err
+	}
+
+	log.Info("Finished", "duration", time.Since(t))
+	return nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	kv2 "github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/spf13/cobra"
+
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+)
+
+var fromBlock uint64
+
+func init() {
+	withDatadir(generateStateDiffCmd)
+	withSnapshotFile(generateStateDiffCmd)
+	withBlock(generateStateDiffCmd)
+	generateStateDiffCmd.Flags().Uint64Var(&fromBlock, "from", 0, "block of base state, diff has changes of blocks (from, block]")
+
+	rootCmd.AddCommand(generateStateDiffCmd)
+}
+
+var generateStateDiffCmd = &cobra.Command{
+	Use:     "state_diff",
+	Short:   "Generate state diff segment from changesets",
+	Example: "go run cmd/snapshots/generator/main.go state_diff --from 11000000 --block 11100000 --datadir /media/b00ris/nvme/snapshotsync/ --snapshot /media/b00ris/nvme/snapshots/state_diff11000000-11100000",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return StateDiff(cmd.Context(), chaindata, snapshotFile, fromBlock, block)
+	},
+}
+
+func StateDiff(ctx context.Context, dbPath, snapshotPath string, from, to uint64) error {
+	if snapshotPath == "" {
+		return errors.New("empty snapshot path")
+	}
+	kv := kv2.NewMDBX().Path(dbPath).MustOpen()
+	defer kv.Close()
+
+	tx, err := kv.BeginRo(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t := time.Now()
+	if err = snapshotsync.CreateStateDiffSnapshot(ctx, tx, from, to, snapshotPath); err != nil {
+		return err
+	}
+	err = os.Remove(snapshotPath + "/mdbx.lck")
+	if err != nil {
+		log.Warn("Remove lock", "err", err)
+		return err
+	}
+
+	log.Info("Finished", "duration", time.Since(t))
+	return nil
+}
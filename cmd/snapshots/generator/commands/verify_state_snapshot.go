@@ -7,18 +7,25 @@ import (
 	"os"
 	"time"
 
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/eth/stagedsync"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/spf13/cobra"
 )
 
+var stateDiffs []string
+
 func init() {
 	withDatadir(verifyStateSnapshotCmd)
 	withSnapshotFile(verifyStateSnapshotCmd)
 	withBlock(verifyStateSnapshotCmd)
+	verifyStateSnapshotCmd.Flags().StringSliceVar(&stateDiffs, "diffs", nil, "paths of state diffs to apply after snapshot, in order of blocks")
 
 	rootCmd.AddCommand(verifyStateSnapshotCmd)
 }
@@ -27,19 +34,28 @@ func init() {
 var verifyStateSnapshotCmd = &cobra.Command{
 	Use:     "verify_state",
 	Short:   "Verify state snapshot",
-	Example: "go run cmd/snapshots/generator/main.go verify_state --block 11000000 --snapshot /media/b00ris/nvme/snapshots/state/ --datadir /media/b00ris/nvme/backup/snapshotsync/",
+	Example: "go run cmd/snapshots/generator/main.go verify_state --block 11000000 --snapshot /media/b00ris/nvme/snapshots/state/ --datadir /media/b00ris/nvme/backup/snapshotsync/ --diffs /media/b00ris/nvme/snapshots/state_diff11000000-11100000",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return VerifyStateSnapshot(cmd.Context(), chaindata, snapshotFile, block)
+		return VerifyStateSnapshot(cmd.Context(), chaindata, snapshotFile, block, stateDiffs...)
 	},
 }
 
-func VerifyStateSnapshot(ctx context.Context, dbPath, snapshotPath string, block uint64) error {
+// VerifyStateSnapshot - checks state root of snapshot at block, and state root after applying of every diff
+func VerifyStateSnapshot(ctx context.Context, dbPath, snapshotPath string, block uint64, diffs ...string) error {
 	var snkv, tmpDB ethdb.RwKV
 	tmpPath, err := ioutil.TempDir(os.TempDir(), "vrf*")
 	if err != nil {
 		return err
 	}
 
+	chainDB := kv.NewMDBX().Path(dbPath).Readonly().MustOpen()
+	defer chainDB.Close()
+	chainTx, err := chainDB.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer chainTx.Rollback()
+
 	snkv = kv.NewMDBX().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
 		return dbutils.BucketsCfg{
 			dbutils.PlainStateBucket:        dbutils.BucketsConfigs[dbutils.PlainStateBucket],
@@ -57,26 +73,119 @@ func VerifyStateSnapshot(ctx context.Context, dbPath, snapshotPath string, block
 		return err
 	}
 	defer tx.Rollback()
-	hash, err := rawdb.ReadCanonicalHash(tx, block)
+	expectedRootHash, err := stateRoot(chainTx, block)
 	if err != nil {
 		return err
 	}
-
-	syncHeadHeader := rawdb.ReadHeader(tx, hash, block)
-	if syncHeadHeader == nil {
-		return fmt.Errorf("empty header")
-	}
-	expectedRootHash := syncHeadHeader.Root
 	tt := time.Now()
-	err = stagedsync.PromoteHashedStateCleanly("", tx, stagedsync.StageHashStateCfg(snkv, os.TempDir()), ctx.Done())
+	err = stagedsync.PromoteHashedStateCleanly("", tx, stagedsync.StageHashStateCfg(snkv, os.TempDir(), etl.CompressNone), ctx.Done())
 	fmt.Println("Promote took", time.Since(tt))
 	if err != nil {
 		return fmt.Errorf("promote state err: %w", err)
 	}
 
-	_, err = stagedsync.RegenerateIntermediateHashes("", tx, stagedsync.StageTrieCfg(snkv, true, true, os.TempDir()), expectedRootHash, ctx.Done())
+	root, err := stagedsync.RegenerateIntermediateHashes("", tx, stagedsync.StageTrieCfg(snkv, true, true, os.TempDir()), expectedRootHash, ctx.Done())
 	if err != nil {
 		return fmt.Errorf("regenerateIntermediateHashes err: %w", err)
 	}
+	if root != expectedRootHash {
+		return fmt.Errorf("root of snapshot at block %d is %x, expected %x", block, root, expectedRootHash)
+	}
+
+	for _, diffPath := range diffs {
+		if block, err = applyStateDiff(ctx, tx, diffPath, block); err != nil {
+			return fmt.Errorf("apply diff %s: %w", diffPath, err)
+		}
+		expectedRootHash, err = stateRoot(chainTx, block)
+		if err != nil {
+			return err
+		}
+		root, err = stagedsync.RegenerateIntermediateHashes("", tx, stagedsync.StageTrieCfg(snkv, true, true, os.TempDir()), expectedRootHash, ctx.Done())
+		if err != nil {
+			return fmt.Errorf("regenerateIntermediateHashes after diff %s err: %w", diffPath, err)
+		}
+		if root != expectedRootHash {
+			return fmt.Errorf("root after diff %s at block %d is %x, expected %x", diffPath, block, root, expectedRootHash)
+		}
+		log.Info("State diff verified", "diff", diffPath, "block", block, "root", expectedRootHash)
+	}
 	return nil
 }
+
+func stateRoot(chainTx ethdb.Tx, block uint64) (common.Hash, error) {
+	hash, err := rawdb.ReadCanonicalHash(chainTx, block)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header := rawdb.ReadHeader(chainTx, hash, block)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("empty header %d", block)
+	}
+	return header.Root, nil
+}
+
+// applyStateDiff - applies diff to plain and hashed state, returns block of new state
+func applyStateDiff(ctx context.Context, tx ethdb.RwTx, diffPath string, block uint64) (uint64, error) {
+	diffKV, err := snapshotsync.OpenStateDiffSnapshot(diffPath)
+	if err != nil {
+		return 0, err
+	}
+	defer diffKV.Close()
+	diffTx, err := diffKV.BeginRo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer diffTx.Rollback()
+
+	to, err := snapshotsync.ApplyStateDiff(tx, diffTx, block)
+	if err != nil {
+		return 0, err
+	}
+	if err = snapshotsync.WalkStateDiff(diffTx, func(bucket string, k, v []byte) error {
+		var hashedBucket string
+		var hashedKey []byte
+		switch bucket {
+		case dbutils.PlainStateBucket:
+			hashedKey, err = hashPlainStateKey(k)
+			hashedBucket = dbutils.HashedAccountsBucket
+			if len(k) != common.AddressLength {
+				hashedBucket = dbutils.HashedStorageBucket
+			}
+		case dbutils.PlainContractCodeBucket:
+			address, incarnation := dbutils.PlainParseStoragePrefix(k)
+			var addrHash common.Hash
+			addrHash, err = common.HashData(address[:])
+			hashedKey = dbutils.GenerateStoragePrefix(addrHash[:], incarnation)
+			hashedBucket = dbutils.ContractCodeBucket
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(v) == 0 {
+			return tx.Delete(hashedBucket, hashedKey, nil)
+		}
+		return tx.Put(hashedBucket, hashedKey, common.CopyBytes(v))
+	}); err != nil {
+		return 0, err
+	}
+	return to, nil
+}
+
+func hashPlainStateKey(k []byte) ([]byte, error) {
+	if len(k) == common.AddressLength {
+		hash, err := common.HashData(k)
+		return hash[:], err
+	}
+	address, incarnation, location := dbutils.PlainParseCompositeStorageKey(k)
+	addrHash, err := common.HashData(address[:])
+	if err != nil {
+		return nil, err
+	}
+	locHash, err := common.HashData(location[:])
+	if err != nil {
+		return nil, err
+	}
+	return dbutils.GenerateCompositeStorageKey(addrHash, incarnation, locHash), nil
+}
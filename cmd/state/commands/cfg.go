@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cfgAddress string
+	cfgCode    string
+	cfgFormat  string
+	cfgOutput  string
+)
+
+func init() {
+	withDatadir(cfgCmd)
+	withBlock(cfgCmd)
+	cfgCmd.Flags().StringVar(&cfgAddress, "address", "", "address of the contract")
+	cfgCmd.Flags().StringVar(&cfgCode, "code", "", "hex of bytecode to analyse instead of contract code from the db")
+	cfgCmd.Flags().StringVar(&cfgFormat, "format", "json", "output format: json or dot")
+	cfgCmd.Flags().StringVar(&cfgOutput, "output", "", "output file, stdout if not set")
+	rootCmd.AddCommand(cfgCmd)
+}
+
+var cfgCmd = &cobra.Command{
+	Use:   "cfg",
+	Short: "Control flow graph of contract code by abstract interpretation: blocks, jump edges, unreachable code and dynamic jumps",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var code []byte
+		if cfgCode != "" {
+			var err error
+			if code, err = hex.DecodeString(strings.TrimPrefix(cfgCode, "0x")); err != nil {
+				return err
+			}
+		} else {
+			if !common.IsHexAddress(cfgAddress) {
+				return fmt.Errorf("invalid --address %q", cfgAddress)
+			}
+			db := kv.MustOpen(chaindata)
+			defer db.Close()
+			if err := db.View(context.Background(), func(tx ethdb.Tx) error {
+				var err error
+				code, err = contractCode(tx, common.HexToAddress(cfgAddress), block)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+		out, err := contractCfg(code, cfgFormat)
+		if err != nil {
+			return err
+		}
+		if cfgOutput == "" {
+			_, err = os.Stdout.Write(out)
+			return err
+		}
+		return ioutil.WriteFile(cfgOutput, out, 0600)
+	},
+}
+
+func contractCode(tx ethdb.Tx, address common.Address, blockNum uint64) ([]byte, error) {
+	reader := state.NewPlainKvState(tx, blockNum)
+	acc, err := reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("account %x not found at block %d", address, blockNum)
+	}
+	code, err := reader.ReadAccountCode(address, acc.Incarnation, acc.CodeHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("account %x has no code at block %d", address, blockNum)
+	}
+	return code, nil
+}
+
+// contractCfg - control flow graph of code in json or dot format
+func contractCfg(code []byte, format string) ([]byte, error) {
+	report, err := vm.AnalyseCfg(context.Background(), code, vm.DefaultCfgLimits)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "json":
+		return json.MarshalIndent(report, "", "  ")
+	case "dot":
+		return []byte(report.Dot()), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected json or dot", format)
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ledgerwatch/erigon/eth/integrity"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	integrityChecks     []string
+	integrityFrom       uint64
+	integrityTo         uint64
+	integrityWorkers    int
+	integrityChunk      uint64
+	integrityCheckpoint string
+	integrityReport     string
+)
+
+func init() {
+	withDatadir(integrityCmd)
+	integrityCmd.Flags().StringSliceVar(&integrityChecks, "checks", nil, "comma separated list of checks (default = all): stages,headers,td,bodies,senders,receipts,logindex,accounthistory,storagehistory,txlookup")
+	integrityCmd.Flags().Uint64Var(&integrityFrom, "from", 0, "first block to check")
+	integrityCmd.Flags().Uint64Var(&integrityTo, "to", 0, "last block to check (default = progress of corresponding stage)")
+	integrityCmd.Flags().IntVar(&integrityWorkers, "workers", 0, "amount of parallel workers (default = amount of CPUs)")
+	integrityCmd.Flags().Uint64Var(&integrityChunk, "chunk", integrity.DefaultChunkSize, "amount of blocks checked by worker in one transaction")
+	integrityCmd.Flags().StringVar(&integrityCheckpoint, "checkpoint", "", "file to save progress, to continue check after restart (removed when check is done)")
+	integrityCmd.Flags().StringVar(&integrityReport, "report", "", "file to write JSON report (default = stdout)")
+	rootCmd.AddCommand(integrityCmd)
+}
+
+var integrityCmd = &cobra.Command{
+	Use:   "integrity",
+	Short: "Check invariants between all buckets of chaindata, exits with error if any of them is violated",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db := kv.NewMDBX().Path(chaindata).Readonly().MustOpen()
+		defer db.Close()
+		report, err := integrity.DB(rootContext(), db, integrity.DBConfig{
+			Checks:     integrityChecks,
+			From:       integrityFrom,
+			To:         integrityTo,
+			Workers:    integrityWorkers,
+			ChunkSize:  integrityChunk,
+			Checkpoint: integrityCheckpoint,
+		})
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if integrityReport == "" {
+			fmt.Fprintln(os.Stdout, string(data))
+		} else if err = ioutil.WriteFile(integrityReport, data, 0644); err != nil {
+			return err
+		}
+		if !report.OK {
+			return fmt.Errorf("found %d issues", len(report.Issues))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+	"github.com/spf13/cobra"
+)
+
+var (
+	preverifiedChain  string
+	preverifiedStep   uint64
+	preverifiedTo     uint64
+	preverifiedOutput string
+)
+
+func init() {
+	withDatadir(preverifiedHashesCmd)
+	preverifiedHashesCmd.Flags().StringVar(&preverifiedChain, "chain", "", "name of the chain synced in the db")
+	must(preverifiedHashesCmd.MarkFlagRequired("chain"))
+	preverifiedHashesCmd.Flags().Uint64Var(&preverifiedStep, "step", headerdownload.PreverifiedHashesStep, "interval between the preverified blocks")
+	preverifiedHashesCmd.Flags().Uint64Var(&preverifiedTo, "block", math.MaxUint64, "last block of the list, latest canonical block if not set")
+	preverifiedHashesCmd.Flags().StringVar(&preverifiedOutput, "output", ".", "directory to write the generated file to (turbo/stages/headerdownload)")
+	rootCmd.AddCommand(preverifiedHashesCmd)
+}
+
+var preverifiedHashesCmd = &cobra.Command{
+	Use:   "preverifiedHashes",
+	Short: "Generate preverified header hashes of the chain from canonical headers of the db",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fileName := filepath.Join(preverifiedOutput, headerdownload.PreverifiedHashesFile(preverifiedChain))
+		f, err := os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		db := kv.MustOpen(chaindata)
+		defer db.Close()
+		var height uint64
+		if err := db.View(context.Background(), func(tx ethdb.Tx) error {
+			height, err = headerdownload.WritePreverifiedHashes(f, tx, preverifiedChain, preverifiedStep, preverifiedTo)
+			return err
+		}); err != nil {
+			os.Remove(fileName)
+			return fmt.Errorf("%s: %w", fileName, err)
+		}
+		log.Info("Generated preverified hashes", "chain", preverifiedChain, "file", fileName, "height", height)
+		return nil
+	},
+}
@@ -18,21 +18,30 @@
 package utils
 
 import (
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/urfave/cli"
 
 	_debug "github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/internal/debug"
 	"github.com/ledgerwatch/erigon/log"
 	"github.com/ledgerwatch/erigon/node"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/stages"
 )
 
 // Fatalf formats a message to standard error and exits the program.
@@ -110,3 +119,125 @@ func RootContext() (context.Context, context.CancelFunc) {
 	}()
 	return ctx, cancel
 }
+
+// ImportChain imports blocks from the RLP file (gzip'd if name ends with .gz), compatible with geth `export`
+func ImportChain(ctx context.Context, importer *stages.BlockImporter, fn string) error {
+	log.Info("Importing blockchain", "file", fn)
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	stream := rlp.NewStream(reader, 0)
+
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	blocks := make([]*types.Block, 0, stages.ImportBatchSize)
+	var imported uint64
+	for eof := false; !eof; {
+		blocks = blocks[:0]
+		for len(blocks) < stages.ImportBatchSize {
+			var b types.Block
+			if err = stream.Decode(&b); errors.Is(err, io.EOF) {
+				eof = true
+				break
+			} else if err != nil {
+				return fmt.Errorf("at block %d: %w", imported+uint64(len(blocks)), err)
+			}
+			blocks = append(blocks, &b)
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		if err = importer.InsertBlocks(ctx, blocks); err != nil {
+			return fmt.Errorf("invalid block %d: %w", blocks[0].NumberU64(), err)
+		}
+		imported += uint64(len(blocks))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-logEvery.C:
+			log.Info("Importing blockchain", "block", blocks[len(blocks)-1].NumberU64())
+		default:
+		}
+	}
+	log.Info("Imported blockchain", "file", fn, "blocks", imported)
+	return nil
+}
+
+// ExportChain exports canonical blocks [first, last] into the RLP file (gzip'd if name ends with .gz), compatible with geth `import`
+func ExportChain(ctx context.Context, db ethdb.RoKV, fn string, first, last uint64) error {
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	log.Info("Exporting blockchain", "file", fn, "first", first, "last", last)
+
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	if err = exportBlocks(ctx, db, fh, strings.HasSuffix(fn, ".gz"), first, last); err != nil {
+		fh.Close()
+		return err
+	}
+	// error of close is the last chance to learn that the file is truncated
+	if err = fh.Close(); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	log.Info("Exported blockchain", "file", fn)
+	return nil
+}
+
+func exportBlocks(ctx context.Context, db ethdb.RoKV, fh io.Writer, compress bool, first, last uint64) error {
+	writer := fh
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(fh)
+		writer = gz
+	}
+
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	if err := db.View(ctx, func(tx ethdb.Tx) error {
+		for nr := first; nr <= last; nr++ {
+			block, err := rawdb.ReadBlockByNumber(tx, nr)
+			if err != nil {
+				return err
+			}
+			if block == nil {
+				return fmt.Errorf("export failed on #%d: not found", nr)
+			}
+			if err = block.EncodeRLP(writer); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-logEvery.C:
+				log.Info("Exporting blocks", "exported", nr-first, "block", nr)
+			default:
+			}
+		}
+		return nil
+	}); err != nil {
+		if gz != nil {
+			// keep the blocks exported before the failure readable
+			gz.Close()
+		}
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+	}
+	return nil
+}
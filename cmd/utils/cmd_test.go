@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+func generateChain(t *testing.T, m *stages.MockSentry, n int) *core.ChainPack {
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(m.Address), common.Address{2}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, m.Key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	return chain
+}
+
+func newImporter(t *testing.T, m *stages.MockSentry) *stages.BlockImporter {
+	importer, err := stages.NewBlockImporter(m.Ctx, m.DB, ethconfig.Defaults, m.ChainConfig, m.Engine, t.TempDir())
+	require.NoError(t, err)
+	return importer
+}
+
+func canonicalHash(t *testing.T, db ethdb.RoKV, number uint64) (hash common.Hash) {
+	require.NoError(t, db.View(context.Background(), func(tx ethdb.Tx) (err error) {
+		hash, err = rawdb.ReadCanonicalHash(tx, number)
+		return err
+	}))
+	return hash
+}
+
+func TestExportImportChain(t *testing.T) {
+	for _, fn := range []string{"chain.rlp", "chain.rlp.gz"} {
+		fn := fn
+		t.Run(fn, func(t *testing.T) {
+			m := stages.Mock(t)
+			chain := generateChain(t, m, 2*stages.ImportBatchSize+10)
+			require.NoError(t, newImporter(t, m).InsertBlocks(m.Ctx, chain.Blocks[:stages.ImportBatchSize]))
+			require.NoError(t, newImporter(t, m).InsertBlocks(m.Ctx, chain.Blocks[stages.ImportBatchSize:2*stages.ImportBatchSize]))
+			require.NoError(t, newImporter(t, m).InsertBlocks(m.Ctx, chain.Blocks[2*stages.ImportBatchSize:]))
+			require.Equal(t, chain.TopBlock.Hash(), canonicalHash(t, m.DB, chain.TopBlock.NumberU64()))
+
+			file := path.Join(t.TempDir(), fn)
+			require.NoError(t, ExportChain(m.Ctx, m.DB, file, 0, chain.TopBlock.NumberU64()))
+			require.Error(t, ExportChain(m.Ctx, m.DB, file, 0, chain.TopBlock.NumberU64()+1))
+
+			m2 := stages.Mock(t)
+			require.NoError(t, ImportChain(m2.Ctx, newImporter(t, m2), file))
+			require.Equal(t, chain.TopBlock.Hash(), canonicalHash(t, m2.DB, chain.TopBlock.NumberU64()))
+			// importing same blocks again is no-op
+			require.NoError(t, ImportChain(m2.Ctx, newImporter(t, m2), file))
+		})
+	}
+}
+
+func TestImportInvalidBlock(t *testing.T) {
+	m := stages.Mock(t)
+	chain := generateChain(t, m, 3)
+
+	// block with wrong state root is rejected by execution
+	header := chain.Blocks[2].Header()
+	header.Root = common.Hash{1}
+	blocks := append(chain.Blocks[:2:2], chain.Blocks[2].WithSeal(header))
+	require.Error(t, newImporter(t, m).InsertBlocks(m.Ctx, blocks))
+	require.Equal(t, chain.Blocks[1].Hash(), canonicalHash(t, m.DB, 2))
+	require.Equal(t, common.Hash{}, canonicalHash(t, m.DB, 3))
+
+	// block which doesn't connect to the chain is not inserted
+	header = chain.Blocks[2].Header()
+	header.ParentHash = common.Hash{2}
+	require.Error(t, newImporter(t, m).InsertBlocks(m.Ctx, []*types.Block{chain.Blocks[2].WithSeal(header)}))
+	require.Equal(t, common.Hash{}, canonicalHash(t, m.DB, 3))
+
+	// block whose body doesn't match the header fails instead of waiting for the body from peers
+	require.Error(t, newImporter(t, m).InsertBlocks(m.Ctx, []*types.Block{types.NewBlockWithHeader(chain.Blocks[2].Header())}))
+	require.Equal(t, common.Hash{}, canonicalHash(t, m.DB, 3))
+}
@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"text/template"
 
@@ -52,6 +53,7 @@ import (
 	"github.com/ledgerwatch/erigon/p2p/nat"
 	"github.com/ledgerwatch/erigon/p2p/netutil"
 	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 )
 
 func init() {
@@ -111,11 +113,23 @@ var (
 	}
 	DeveloperPeriodFlag = cli.IntFlag{
 		Name:  "dev.period",
-		Usage: "Block period to use in developer mode (0 = mine only if transaction pending)",
+		Usage: "Block period to use in developer mode (0 = mine on-demand, blocks with transactions only)",
+	}
+	DeveloperInstantSealFlag = cli.BoolFlag{
+		Name:  "dev.instantseal",
+		Usage: "Instant sealing of the developer chain with --dev.period=0: mine as soon as a transaction is pending, also empty blocks on evm_mine",
+	}
+	DeveloperForkSourceFlag = DirectoryFlag{
+		Name:  "dev.fork.source",
+		Usage: "Chaindata or state snapshot directory to fork the developer chain from, its state is read without copying",
+	}
+	DeveloperForkBlockFlag = cli.Uint64Flag{
+		Name:  "dev.fork.block",
+		Usage: "Block of --dev.fork.source to fork from (0 = latest executed block of the chaindata, required for state snapshots)",
 	}
 	ChainFlag = cli.StringFlag{
 		Name:  "chain",
-		Usage: "Name of the testnet to join",
+		Usage: "Name of the testnet to join, or path to a custom chain spec file (.json or .toml)",
 		Value: params.MainnetChainName,
 	}
 	IdentityFlag = cli.StringFlag{
@@ -126,6 +140,26 @@ var (
 		Name:  "whitelist",
 		Usage: "Comma separated block number-to-hash mappings to enforce (<number>=<hash>)",
 	}
+	CheckpointFlag = cli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "Trusted block to sync a new database from, headers are verified down to genesis and the state is loaded from --checkpoint.state (<number>:<hash>:<state root>)",
+	}
+	CheckpointStateFlag = DirectoryFlag{
+		Name:  "checkpoint.state",
+		Usage: "State snapshot at the --checkpoint block (default = <datadir>/snapshots/state<number>)",
+	}
+	CheckpointStateDiffsFlag = cli.StringFlag{
+		Name:  "checkpoint.statediffs",
+		Usage: "Comma separated state diffs applied to --checkpoint.state in order of blocks, the last one ends at the --checkpoint block",
+	}
+	BeaconFlag = cli.BoolFlag{
+		Name:  "beacon",
+		Usage: "Chain is driven by a consensus client through the engine API of rpcdaemon (--engine.addr), blocks are not downloaded from peers",
+	}
+	MockBeaconPeriodFlag = cli.DurationFlag{
+		Name:  "beacon.mock.period",
+		Usage: "Drive the --beacon chain without consensus client: assemble, execute and finalize a block every period (requires --mine)",
+	}
 	// Ethash settings
 	EthashCachesInMemoryFlag = cli.IntFlag{
 		Name:  "ethash.cachesinmem",
@@ -246,6 +280,15 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerStratumAddrFlag = cli.StringFlag{
+		Name:  "miner.stratum.addr",
+		Usage: "TCP address of the stratum server for ethash miners and pool proxies (EthereumStratum/1.0.0 and eth-proxy), for example: 0.0.0.0:8008",
+	}
+	MinerStratumDifficultyFlag = cli.Uint64Flag{
+		Name:  "miner.stratum.difficulty",
+		Usage: "Pool difficulty of stratum shares",
+		Value: ethconfig.Defaults.Miner.StratumDifficulty,
+	}
 	VMEnableDebugFlag = cli.BoolFlag{
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
@@ -589,7 +632,9 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 		case params.SokolChainName:
 			urls = params.SokolBootnodes
 		default:
-			if cfg.BootstrapNodes != nil {
+			if isChainSpecFile(chain) {
+				urls = chainSpecFromFile(chain).Bootnodes
+			} else if cfg.BootstrapNodes != nil {
 				return // already set, don't apply defaults.
 			}
 		}
@@ -631,7 +676,9 @@ func setBootstrapNodesV5(ctx *cli.Context, cfg *p2p.Config) {
 		case params.SokolChainName:
 			urls = params.SokolBootnodes
 		default:
-			if cfg.BootstrapNodesV5 != nil {
+			if isChainSpecFile(chain) {
+				urls = chainSpecFromFile(chain).Bootnodes
+			} else if cfg.BootstrapNodesV5 != nil {
 				return // already set, don't apply defaults.
 			}
 		}
@@ -867,6 +914,7 @@ func DataDirForNetwork(datadir string, network string) string {
 	case params.SokolChainName:
 		return filepath.Join(datadir, "sokol")
 	default:
+		// custom chains from a spec file stay in the datadir: restart without --chain reads the spec from there
 		return datadir
 	}
 
@@ -1083,6 +1131,12 @@ func setMiner(ctx *cli.Context, cfg *params.MiningConfig) {
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerfiyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerStratumAddrFlag.Name) {
+		cfg.StratumAddr = ctx.GlobalString(MinerStratumAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumDifficultyFlag.Name) {
+		cfg.StratumDifficulty = ctx.GlobalUint64(MinerStratumDifficultyFlag.Name)
+	}
 }
 
 func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1108,6 +1162,48 @@ func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
 	}
 }
 
+func setBeacon(ctx *cli.Context, cfg *ethconfig.Config) {
+	cfg.Beacon.MockPeriod = ctx.GlobalDuration(MockBeaconPeriodFlag.Name)
+	cfg.Beacon.Enabled = ctx.GlobalBool(BeaconFlag.Name) || cfg.Beacon.MockPeriod != 0
+	if cfg.Beacon.MockPeriod != 0 && !cfg.Miner.Enabled {
+		Fatalf("Mock beacon assembles blocks, it requires --%s", MiningEnabledFlag.Name)
+	}
+}
+
+func setCheckpoint(ctx *cli.Context, datadir string, cfg *ethconfig.Config) {
+	checkpoint := ctx.GlobalString(CheckpointFlag.Name)
+	if checkpoint == "" {
+		return
+	}
+	parts := strings.Split(checkpoint, ":")
+	if len(parts) != 3 {
+		Fatalf("Invalid checkpoint %s, expected <number>:<hash>:<state root>", checkpoint)
+	}
+	number, err := strconv.ParseUint(parts[0], 0, 64)
+	if err != nil {
+		Fatalf("Invalid checkpoint block number %s: %v", parts[0], err)
+	}
+	if number == 0 {
+		Fatalf("Invalid checkpoint block number %s: genesis is synced without checkpoint", parts[0])
+	}
+	var hash, root common.Hash
+	if err = hash.UnmarshalText([]byte(parts[1])); err != nil {
+		Fatalf("Invalid checkpoint hash %s: %v", parts[1], err)
+	}
+	if err = root.UnmarshalText([]byte(parts[2])); err != nil {
+		Fatalf("Invalid checkpoint state root %s: %v", parts[2], err)
+	}
+	state := ctx.GlobalString(CheckpointStateFlag.Name)
+	if state == "" {
+		state = snapshotsync.SnapshotName(filepath.Join(datadir, "snapshots"), "state", number)
+	}
+	var diffs []string
+	if ctx.GlobalIsSet(CheckpointStateDiffsFlag.Name) {
+		diffs = SplitAndTrim(ctx.GlobalString(CheckpointStateDiffsFlag.Name))
+	}
+	cfg.Checkpoint = &ethconfig.Checkpoint{Number: number, Hash: hash, Root: root, State: state, StateDiffs: diffs}
+}
+
 // CheckExclusive verifies that only a single instance of the provided flags was
 // set by the user. Each flag might optionally be followed by a string type to
 // specialize it further.
@@ -1160,6 +1256,8 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *node.Config, cfg *ethconfig.Conf
 	setAuRa(ctx, &cfg.Aura, nodeConfig.DataDir)
 	setMiner(ctx, &cfg.Miner)
 	setWhitelist(ctx, cfg)
+	setCheckpoint(ctx, nodeConfig.DataDir, cfg)
+	setBeacon(ctx, cfg)
 
 	cfg.P2PEnabled = len(nodeConfig.P2P.SentryAddr) == 0
 
@@ -1178,6 +1276,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *node.Config, cfg *ethconfig.Conf
 	if ctx.GlobalIsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.GlobalFloat64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	cfg.EthstatsURL = ctx.GlobalString(EthStatsURLFlag.Name)
 	if ctx.GlobalIsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs = []string{}
 	} else if ctx.GlobalIsSet(DNSDiscoveryFlag.Name) {
@@ -1246,13 +1345,86 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *node.Config, cfg *ethconfig.Conf
 		log.Info("Using developer account", "address", developer)
 
 		// Create a new developer genesis block or reuse existing one
-		cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer)
+		period := uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name))
+		cfg.Miner.InstantSeal = ctx.GlobalBool(DeveloperInstantSealFlag.Name)
+		if cfg.Miner.InstantSeal && period != 0 {
+			Fatalf("Flag --%s requires --%s=0", DeveloperInstantSealFlag.Name, DeveloperPeriodFlag.Name)
+		}
+		if cfg.Miner.InstantSeal && cfg.Beacon.Enabled { // blocks of the --beacon chain are assembled on request of the consensus client
+			Fatalf("Flags --%s and --beacon can't be used together", DeveloperInstantSealFlag.Name)
+		}
+		if ctx.GlobalIsSet(DeveloperForkSourceFlag.Name) {
+			source := ctx.GlobalString(DeveloperForkSourceFlag.Name)
+			fork, header, err := core.OpenForkSource(source, ctx.GlobalUint64(DeveloperForkBlockFlag.Name))
+			if err != nil {
+				Fatalf("Could not open the source of developer chain: %v", err)
+			}
+			log.Info("Forking developer chain", "source", source, "block", fork.BlockNumber())
+			cfg.Fork = fork
+			cfg.Genesis = core.DeveloperForkGenesisBlock(period, developer, header)
+		} else {
+			cfg.Genesis = core.DeveloperGenesisBlock(period, developer)
+		}
 		if !ctx.GlobalIsSet(MinerGasPriceFlag.Name) {
 			cfg.Miner.GasPrice = big.NewInt(1)
 		}
 	default:
-		Fatalf("Chain name is not recognized: %s", chain)
+		if !isChainSpecFile(chain) {
+			Fatalf("Chain name is not recognized: %s", chain)
+		}
+		spec := chainSpecFromFile(chain)
+		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
+			cfg.NetworkID = spec.NetworkID
+		}
+		cfg.Genesis = spec.Genesis
+		cfg.ChainSpec = spec
+	}
+}
+
+// isChainSpecFile - --chain points to a custom chain spec file instead of naming a built-in chain
+func isChainSpecFile(chain string) bool {
+	ext := strings.ToLower(filepath.Ext(chain))
+	return ext == ".json" || ext == ".toml"
+}
+
+var chainSpecs sync.Map // path -> *core.ChainSpec, --chain is read by several setters
+
+// chainSpecFromFile loads the custom chain spec, exits on invalid file
+func chainSpecFromFile(path string) *core.ChainSpec {
+	if spec, ok := chainSpecs.Load(path); ok {
+		return spec.(*core.ChainSpec)
+	}
+	spec, err := core.LoadChainSpec(path)
+	if err != nil {
+		Fatalf("Could not load chain spec: %v", err)
 	}
+	chainSpecs.Store(path, spec)
+	return spec
+}
+
+// SetStoredChainSpec applies the networking settings of a custom chain stored in the database (see core.CommitChainSpec)
+// when the node is restarted without --chain, explicit flags take precedence
+func SetStoredChainSpec(ctx *cli.Context, spec *core.ChainSpec, nodeCfg *node.Config, ethCfg *ethconfig.Config) {
+	if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
+		ethCfg.NetworkID = spec.NetworkID
+	}
+	if !ctx.GlobalIsSet(DNSDiscoveryFlag.Name) && !ctx.GlobalIsSet(NoDiscoverFlag.Name) {
+		ethCfg.EthDiscoveryURLs = nil // defaults are of mainnet
+	}
+	if ctx.GlobalIsSet(BootnodesFlag.Name) {
+		return
+	}
+	bootnodes := make([]*enode.Node, 0, len(spec.Bootnodes))
+	for _, url := range spec.Bootnodes {
+		node, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			log.Error("Bootstrap URL invalid", "enode", url, "err", err)
+			continue
+		}
+		bootnodes = append(bootnodes, node)
+	}
+	nodeCfg.P2P.BootstrapNodes = bootnodes
+	nodeCfg.P2P.BootstrapNodesV5 = bootnodes
 }
 
 // SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
@@ -1311,6 +1483,10 @@ func MakeGenesis(ctx *cli.Context) *core.Genesis {
 		genesis = core.DefaultSokolGenesisBlock()
 	case params.DevChainName:
 		Fatalf("Developer chains are ephemeral")
+	default:
+		if isChainSpecFile(chain) {
+			genesis = chainSpecFromFile(chain).Genesis
+		}
 	}
 	return genesis
 }
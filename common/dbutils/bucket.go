@@ -140,6 +140,11 @@ var (
 	//key - contract code hash
 	//value - contract TEVM code
 	ContractTEVMCodeBucket = "TEVMCode"
+
+	//JumpDestAnalysisBucket - optional, filled by Execution stage
+	//key - contract code hash
+	//value - JUMPDEST analysis bitmap, little-endian words
+	JumpDestAnalysisBucket = "JumpDestAnalysis"
 )
 
 /*TrieOfAccountsBucket and TrieOfStorageBucket
@@ -249,6 +254,9 @@ const (
 	// headBlockKey tracks the latest know full block's hash.
 	HeadBlockKey = "LastBlock"
 
+	// FinalizedBlockKey tracks the hash of the block finalized by the consensus client, the chain is never unwound below it
+	FinalizedBlockKey = "LastFinalized"
+
 	// migrationName -> serialized SyncStageProgress and SyncStageUnwind buckets
 	// it stores stages progress to understand in which context was executed migration
 	// in case of bug-report developer can ask content of this bucket
@@ -257,7 +265,13 @@ const (
 	Sequence      = "Sequence" // tbl_name -> seq_u64
 	HeadHeaderKey = "LastHeader"
 
-	Epoch = "DevEpoch" // block_num_u64+block_hash->transition_proof
+	Epoch        = "DevEpoch"        // block_num_u64+block_hash->transition_proof
+	PendingEpoch = "DevPendingEpoch" // block_num_u64+block_hash->transition_proof
+
+	// ForkDeletedBucket - state of the source chain deleted by a dev chain forked from it (see state.ForkStateReader)
+	//key - address or address+incarnation+storage location (as in PlainStateBucket)
+	//value - empty
+	ForkDeletedBucket = "DevForkDeleted"
 )
 
 // Keys
@@ -280,6 +294,8 @@ var (
 	CurrentHeadersSnapshotBlock = []byte("CurrentHeadersSnapshotBlock")
 	CurrentBodiesSnapshotHash   = []byte("CurrentBodiesSnapshotHash")
 	CurrentBodiesSnapshotBlock  = []byte("CurrentBodiesSnapshotBlock")
+	// CurrentReceiptsSnapshotBlock - receipts of blocks up to this one are in receipts snapshot, execution doesn't write them
+	CurrentReceiptsSnapshotBlock = []byte("CurrentReceiptsSnapshotBlock")
 )
 
 // Buckets - list of all buckets. App will panic if some bucket is not in this list.
@@ -300,6 +316,7 @@ var Buckets = []string{
 	DatabaseInfoBucket,
 	IncarnationMapBucket,
 	ContractTEVMCodeBucket,
+	JumpDestAnalysisBucket,
 	CliqueSeparateBucket,
 	CliqueLastSnapshotBucket,
 	CliqueSnapshotBucket,
@@ -310,6 +327,7 @@ var Buckets = []string{
 	StorageChangeSetBucket,
 	Senders,
 	HeadBlockKey,
+	FinalizedBlockKey,
 	HeadHeaderKey,
 	Migrations,
 	LogTopicIndex,
@@ -330,6 +348,8 @@ var Buckets = []string{
 	HeadersBucket,
 	HeaderTDBucket,
 	Epoch,
+	PendingEpoch,
+	ForkDeletedBucket,
 }
 
 // DeprecatedBuckets - list of buckets which can be programmatically deleted - for example after migration
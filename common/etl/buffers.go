@@ -271,6 +271,20 @@ func getBufferByType(tp int, size datasize.ByteSize) Buffer {
 	}
 }
 
+// newBufferLike - empty buffer of the same type, size and comparator as `b`
+func newBufferLike(b Buffer) Buffer {
+	switch b := b.(type) {
+	case *sortableBuffer:
+		return &sortableBuffer{optimalSize: b.optimalSize, comparator: b.comparator}
+	case *appendSortableBuffer:
+		return &appendSortableBuffer{entries: make(map[string][]byte), optimalSize: b.optimalSize, comparator: b.comparator}
+	case *oldestEntrySortableBuffer:
+		return &oldestEntrySortableBuffer{entries: make(map[string][]byte), optimalSize: b.optimalSize, comparator: b.comparator}
+	default:
+		panic(fmt.Sprintf("unknown buffer type: %T ", b))
+	}
+}
+
 func getTypeByBuffer(b Buffer) int {
 	switch b.(type) {
 	case *sortableBuffer:
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/c2h5oh/datasize"
@@ -33,6 +34,26 @@ type Collector struct {
 	allFlushed      bool
 	autoClean       bool
 	bufType         int
+	compression     Compression
+	tmpdir          string
+	mergeThreshold  int
+
+	// background sorting, see CollectorOpts.SortWorkers
+	spare    chan Buffer // empty buffers, collection blocks on it when all workers are busy
+	flushing sync.WaitGroup
+	lock     sync.Mutex // protects dataProviders and flushErr while flushing in background
+	flushErr error
+}
+
+// CollectorOpts - tuning of Collector, zero value means: no compression, sorting in the collecting goroutine
+type CollectorOpts struct {
+	Compression Compression
+	// SortWorkers - amount of full buffers which are sorted and flushed to disk in background,
+	// while collection continues into spare buffer. Memory usage is bounded by (SortWorkers+1) buffers.
+	SortWorkers int
+	// MergeThreshold - if amount of flushed files exceeds it, files are merged in parallel
+	// into MergeThreshold files before loading - to keep heap small and amount of open files bounded
+	MergeThreshold int
 }
 
 // NewCollectorFromFiles creates collector from existing files (left over from previous unsuccessful loading)
@@ -67,13 +88,45 @@ func NewCriticalCollector(tmpdir string, sortableBuffer Buffer) *Collector {
 }
 
 func NewCollector(tmpdir string, sortableBuffer Buffer) *Collector {
-	c := &Collector{autoClean: true, bufType: getTypeByBuffer(sortableBuffer)}
+	return NewCompressedCollector(tmpdir, sortableBuffer, CompressNone)
+}
+
+// NewCompressedCollector compresses temporary files - trades CPU for disk space and IO
+func NewCompressedCollector(tmpdir string, sortableBuffer Buffer, compression Compression) *Collector {
+	return NewCollectorWithOpts(tmpdir, sortableBuffer, CollectorOpts{Compression: compression})
+}
+
+func NewCollectorWithOpts(tmpdir string, sortableBuffer Buffer, opts CollectorOpts) *Collector {
+	c := &Collector{
+		autoClean:      true,
+		bufType:        getTypeByBuffer(sortableBuffer),
+		compression:    opts.Compression,
+		tmpdir:         tmpdir,
+		mergeThreshold: opts.MergeThreshold,
+	}
+	if opts.SortWorkers > 0 {
+		c.spare = make(chan Buffer, opts.SortWorkers)
+		for i := 0; i < opts.SortWorkers; i++ {
+			c.spare <- newBufferLike(sortableBuffer)
+		}
+	}
 	encoder := codec.NewEncoder(nil, &cbor)
 
 	c.flushBuffer = func(currentKey []byte, canStoreInRam bool) error {
 		if sortableBuffer.Len() == 0 {
 			return nil
 		}
+		if c.spare != nil {
+			if !canStoreInRam {
+				c.flushInBackground(sortableBuffer)
+				sortableBuffer = <-c.spare
+				return c.backgroundErr()
+			}
+			// last buffer: it can stay in RAM only if nothing was flushed before
+			if err := c.waitFlushes(); err != nil {
+				return err
+			}
+		}
 		var provider dataProvider
 		var err error
 		sortableBuffer.Sort()
@@ -81,7 +134,7 @@ func NewCollector(tmpdir string, sortableBuffer Buffer) *Collector {
 			provider = KeepInRAM(sortableBuffer)
 			c.allFlushed = true
 		} else {
-			provider, err = FlushToDisk(encoder, currentKey, sortableBuffer, tmpdir)
+			provider, err = FlushToDisk(encoder, currentKey, sortableBuffer, tmpdir, c.compression)
 		}
 		if err != nil {
 			return err
@@ -104,6 +157,40 @@ func NewCollector(tmpdir string, sortableBuffer Buffer) *Collector {
 	return c
 }
 
+// flushInBackground - order of dataProviders is the order of flushes, because loading of
+// SortableOldestAppearedBuffer relies on it
+func (c *Collector) flushInBackground(b Buffer) {
+	c.lock.Lock()
+	idx := len(c.dataProviders)
+	c.dataProviders = append(c.dataProviders, nil)
+	c.lock.Unlock()
+
+	c.flushing.Add(1)
+	go func() {
+		defer c.flushing.Done()
+		b.Sort()
+		provider, err := FlushToDisk(codec.NewEncoder(nil, &cbor), nil, b, c.tmpdir, c.compression)
+		c.lock.Lock()
+		c.dataProviders[idx] = provider
+		if err != nil && c.flushErr == nil {
+			c.flushErr = err
+		}
+		c.lock.Unlock()
+		c.spare <- b
+	}()
+}
+
+func (c *Collector) backgroundErr() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.flushErr
+}
+
+func (c *Collector) waitFlushes() error {
+	c.flushing.Wait()
+	return c.backgroundErr()
+}
+
 func (c *Collector) Collect(k, v []byte) error {
 	return c.extractNextFunc(k, k, v)
 }
@@ -119,6 +206,16 @@ func (c *Collector) Load(logPrefix string, db ethdb.RwTx, toBucket string, loadF
 			return e
 		}
 	}
+	if err := c.waitFlushes(); err != nil {
+		return err
+	}
+	if c.mergeThreshold > 0 && len(c.dataProviders) > c.mergeThreshold {
+		merged, err := mergeProviders(logPrefix, c.dataProviders, c.mergeThreshold, c.bufType, args.Comparator, c.tmpdir, c.compression)
+		if err != nil {
+			return err
+		}
+		c.dataProviders = merged
+	}
 	if err := loadFilesIntoBucket(logPrefix, db, toBucket, c.bufType, c.dataProviders, loadFunc, args); err != nil {
 		return err
 	}
@@ -126,8 +223,12 @@ func (c *Collector) Load(logPrefix string, db ethdb.RwTx, toBucket string, loadF
 }
 
 func (c *Collector) Close(logPrefix string) {
+	c.flushing.Wait()
 	totalSize := uint64(0)
 	for _, p := range c.dataProviders {
+		if p == nil { // background flush failed
+			continue
+		}
 		totalSize += p.Dispose()
 	}
 	if totalSize > 0 {
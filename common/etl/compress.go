@@ -0,0 +1,91 @@
+package etl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression - algorithm of streaming compression of temporary files, chosen per Collector.
+// Reading doesn't depend on this setting: format of file is detected by its first bytes.
+type Compression uint8
+
+const (
+	CompressNone   Compression = iota
+	CompressSnappy             // fast, moderate ratio - good default for disk-bound stages
+	CompressZstd               // slower, better ratio - good when disk space is the limit
+)
+
+var (
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY") // stream identifier chunk of snappy framing format
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}   // zstd frame magic number, little-endian 0xFD2FB528
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressNone:
+		return "none"
+	case CompressSnappy:
+		return "snappy"
+	case CompressZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// ParseCompression - inverse of Compression.String, empty string means CompressNone
+func ParseCompression(s string) (Compression, error) {
+	switch s {
+	case "", "none":
+		return CompressNone, nil
+	case "snappy":
+		return CompressSnappy, nil
+	case "zstd":
+		return CompressZstd, nil
+	default:
+		return CompressNone, fmt.Errorf("unknown etl compression: %s", s)
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// compressWriter - Close must be called to flush the end of compressed stream, it doesn't close `w`
+func compressWriter(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressNone:
+		return nopWriteCloser{w}, nil
+	case CompressSnappy:
+		return snappy.NewBufferedWriter(w), nil
+	case CompressZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	default:
+		return nil, fmt.Errorf("unknown etl compression: %d", c)
+	}
+}
+
+// decompressReader - detects compression of the stream, returned func releases resources of decompressor
+func decompressReader(r *bufio.Reader) (io.Reader, func(), error) {
+	head, err := r.Peek(len(snappyMagic))
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, snappyMagic):
+		return snappy.NewReader(r), func() {}, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, err
+		}
+		return d, d.Close, nil
+	default:
+		return r, func() {}, nil
+	}
+}
@@ -18,8 +18,9 @@ type dataProvider interface {
 }
 
 type fileDataProvider struct {
-	file   *os.File
-	reader io.Reader
+	file            *os.File
+	reader          io.Reader
+	closeDecompress func()
 }
 
 type Encoder interface {
@@ -27,10 +28,35 @@ type Encoder interface {
 	Reset(writer io.Writer)
 }
 
-func FlushToDisk(encoder Encoder, currentKey []byte, b Buffer, tmpdir string) (dataProvider, error) {
+func FlushToDisk(encoder Encoder, currentKey []byte, b Buffer, tmpdir string, compression Compression) (dataProvider, error) {
 	if b.Len() == 0 {
 		return nil, nil
 	}
+	defer b.Reset()
+	bufferFile, err := writeTmpFile(encoder, tmpdir, compression, func(encoder Encoder) error {
+		for _, entry := range b.GetEntries() {
+			if err := writeToDisk(encoder, entry.key, entry.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	log.Info(
+		"Flushed buffer file",
+		"name", bufferFile.Name(),
+		"compression", compression,
+		"alloc", common.StorageSize(m.Alloc), "sys", common.StorageSize(m.Sys))
+	return &fileDataProvider{file: bufferFile}, nil
+}
+
+// writeTmpFile - creates new temporary file and fills it by `write`, file is removed if writing failed
+func writeTmpFile(encoder Encoder, tmpdir string, compression Compression, write func(encoder Encoder) error) (*os.File, error) {
 	// if we are going to create files in the system temp dir, we don't need any
 	// subfolders.
 	if tmpdir != "" {
@@ -43,30 +69,31 @@ func FlushToDisk(encoder Encoder, currentKey []byte, b Buffer, tmpdir string) (d
 	if err != nil {
 		return nil, err
 	}
-	defer bufferFile.Sync() //nolint:errcheck
-
-	w := bufio.NewWriterSize(bufferFile, BufIOSize)
-	defer w.Flush() //nolint:errcheck
-
-	defer func() {
-		b.Reset() // run it after buf.flush and file.sync
-		var m runtime.MemStats
-		runtime.ReadMemStats(&m)
-		log.Info(
-			"Flushed buffer file",
-			"name", bufferFile.Name(),
-			"alloc", common.StorageSize(m.Alloc), "sys", common.StorageSize(m.Sys))
-	}()
-
-	encoder.Reset(w)
-	for _, entry := range b.GetEntries() {
-		err = writeToDisk(encoder, entry.key, entry.value)
-		if err != nil {
-			return nil, fmt.Errorf("error writing entries to disk: %v", err)
-		}
+	if err = writeCompressed(bufferFile, encoder, compression, write); err != nil {
+		_ = bufferFile.Close()
+		_ = os.Remove(bufferFile.Name())
+		return nil, fmt.Errorf("error writing entries to disk: %w", err)
 	}
+	return bufferFile, nil
+}
 
-	return &fileDataProvider{bufferFile, nil}, nil
+func writeCompressed(f *os.File, encoder Encoder, compression Compression, write func(encoder Encoder) error) error {
+	w := bufio.NewWriterSize(f, BufIOSize)
+	cw, err := compressWriter(w, compression)
+	if err != nil {
+		return err
+	}
+	encoder.Reset(cw)
+	if err = write(encoder); err != nil {
+		return err
+	}
+	if err = cw.Close(); err != nil { // must be flushed before bufio.Writer
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
 }
 
 func (p *fileDataProvider) Next(decoder Decoder) ([]byte, []byte, error) {
@@ -75,13 +102,26 @@ func (p *fileDataProvider) Next(decoder Decoder) ([]byte, []byte, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		p.reader = bufio.NewReaderSize(p.file, BufIOSize)
+		r, closeDecompress, err := decompressReader(bufio.NewReaderSize(p.file, BufIOSize))
+		if err != nil {
+			return nil, nil, err
+		}
+		p.closeDecompress = closeDecompress
+		if br, ok := r.(*bufio.Reader); ok {
+			p.reader = br
+		} else {
+			p.reader = bufio.NewReaderSize(r, BufIOSize)
+		}
 	}
 	decoder.Reset(p.reader)
 	return readElementFromDisk(decoder)
 }
 
 func (p *fileDataProvider) Dispose() uint64 {
+	if p.closeDecompress != nil {
+		p.closeDecompress()
+		p.closeDecompress = nil
+	}
 	info, _ := os.Stat(p.file.Name())
 	_ = p.file.Close()
 	_ = os.Remove(p.file.Name())
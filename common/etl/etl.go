@@ -65,6 +65,9 @@ type TransformArgs struct {
 	FixedBits       int
 	BufferType      int
 	BufferSize      int
+	Compression     Compression // of temporary files
+	SortWorkers     int         // see CollectorOpts
+	MergeThreshold  int         // see CollectorOpts
 	Quit            <-chan struct{}
 
 	LogDetailsExtract AdditionalLogArguments
@@ -88,7 +91,11 @@ func Transform(
 		bufferSize = datasize.ByteSize(args.BufferSize)
 	}
 	buffer := getBufferByType(args.BufferType, bufferSize)
-	collector := NewCollector(tmpdir, buffer)
+	collector := NewCollectorWithOpts(tmpdir, buffer, CollectorOpts{
+		Compression:    args.Compression,
+		SortWorkers:    args.SortWorkers,
+		MergeThreshold: args.MergeThreshold,
+	})
 	defer collector.Close(logPrefix)
 
 	t := time.Now()
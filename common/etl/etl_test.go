@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
@@ -210,6 +211,112 @@ func TestTransformThroughFiles(t *testing.T) {
 	compareBuckets(t, tx, sourceBucket, destBucket, nil)
 }
 
+func TestTransformThroughCompressedFiles(t *testing.T) {
+	for _, compression := range []Compression{CompressSnappy, CompressZstd} {
+		compression := compression
+		t.Run(compression.String(), func(t *testing.T) {
+			_, tx := kv.NewTestTx(t)
+			sourceBucket := dbutils.Buckets[0]
+			destBucket := dbutils.Buckets[1]
+			generateTestData(t, tx, sourceBucket, 10)
+			err := Transform(
+				"logPrefix",
+				tx,
+				sourceBucket,
+				destBucket,
+				"", // temp dir
+				testExtractToMapFunc,
+				testLoadFromMapFunc,
+				TransformArgs{
+					BufferSize:  1,
+					Compression: compression,
+				},
+			)
+			assert.Nil(t, err)
+			compareBuckets(t, tx, sourceBucket, destBucket, nil)
+		})
+	}
+}
+
+func TestCollectorFromCompressedFiles(t *testing.T) {
+	// files of different compression can be mixed in one directory, format is detected on read
+	_, tx := kv.NewTestTx(t)
+	sourceBucket := dbutils.Buckets[0]
+	destBucket := dbutils.Buckets[1]
+	generateTestData(t, tx, sourceBucket, 9)
+	tmpdir := t.TempDir()
+	for i, compression := range []Compression{CompressNone, CompressSnappy, CompressZstd} {
+		collector := NewCompressedCollector(tmpdir, NewSortableBuffer(1), compression)
+		err := extractBucketIntoFiles("logPrefix", tx, sourceBucket, []byte(fmt.Sprintf("%10d", i*3)), []byte(fmt.Sprintf("%10d-key-%010d", i*3+2, i*3+2)), 0, collector, testExtractToMapFunc, nil, nil)
+		assert.NoError(t, err)
+	}
+	collector, err := NewCollectorFromFiles(tmpdir)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, len(collector.dataProviders))
+	err = collector.Load("logPrefix", tx, destBucket, testLoadFromMapFunc, TransformArgs{})
+	assert.NoError(t, err)
+	collector.Close("logPrefix")
+	compareBuckets(t, tx, sourceBucket, destBucket, nil)
+}
+
+func TestTransformParallelSortAndMerge(t *testing.T) {
+	for _, workers := range []int{0, 1, 4} {
+		for _, threshold := range []int{0, 1, 3} {
+			workers, threshold := workers, threshold
+			t.Run(fmt.Sprintf("workers=%d,merge=%d", workers, threshold), func(t *testing.T) {
+				_, tx := kv.NewTestTx(t)
+				sourceBucket := dbutils.Buckets[0]
+				destBucket := dbutils.Buckets[1]
+				generateTestData(t, tx, sourceBucket, 50)
+				err := Transform(
+					"logPrefix",
+					tx,
+					sourceBucket,
+					destBucket,
+					t.TempDir(),
+					testExtractToMapFunc,
+					testLoadFromMapFunc,
+					TransformArgs{
+						BufferSize:     300,
+						Compression:    CompressSnappy,
+						SortWorkers:    workers,
+						MergeThreshold: threshold,
+					},
+				)
+				assert.Nil(t, err)
+				compareBuckets(t, tx, sourceBucket, destBucket, nil)
+			})
+		}
+	}
+}
+
+func TestParallelOldestAppeared(t *testing.T) {
+	// the oldest value must win, even if newer values were flushed by faster worker and files were merged
+	_, tx := kv.NewTestTx(t)
+	destBucket := dbutils.Buckets[1]
+	tmpdir := t.TempDir()
+	collector := NewCollectorWithOpts(tmpdir, NewOldestEntryBuffer(100), CollectorOpts{SortWorkers: 4, MergeThreshold: 3})
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 20; i++ {
+			err := collector.Collect([]byte(fmt.Sprintf("%10d", i)), []byte(fmt.Sprintf("round-%d", round)))
+			assert.NoError(t, err)
+		}
+	}
+	err := collector.Load("logPrefix", tx, destBucket, IdentityLoadFunc, TransformArgs{})
+	assert.NoError(t, err)
+	count := 0
+	err = tx.ForEach(destBucket, nil, func(k, v []byte) error {
+		count++
+		assert.Equal(t, "round-0", string(v), string(k))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, count)
+	files, err := ioutil.ReadDir(tmpdir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(files))
+}
+
 func TestTransformDoubleOnExtract(t *testing.T) {
 	// test invariant when extractFunc multiplies the data 2x
 	_, tx := kv.NewTestTx(t)
@@ -250,7 +357,7 @@ func TestTransformDoubleOnLoad(t *testing.T) {
 	compareBucketsDouble(t, tx, sourceBucket, destBucket)
 }
 
-func generateTestData(t *testing.T, db ethdb.Putter, bucket string, count int) {
+func generateTestData(t testing.TB, db ethdb.Putter, bucket string, count int) {
 	for i := 0; i < count; i++ {
 		k := []byte(fmt.Sprintf("%10d-key-%010d", i, i))
 		v := []byte(fmt.Sprintf("val-%099d", i))
@@ -358,3 +465,39 @@ func compareBucketsDouble(t *testing.T, db ethdb.Tx, b1, b2 string) {
 	assert.NoError(t, err)
 	assert.Equal(t, b1Map, b2Map)
 }
+
+// BenchmarkTransformThroughFiles compares throughput and disk usage of compression algorithms of temporary files
+func BenchmarkTransformThroughFiles(b *testing.B) {
+	for _, compression := range []Compression{CompressNone, CompressSnappy, CompressZstd} {
+		compression := compression
+		b.Run(compression.String(), func(b *testing.B) {
+			_, tx := kv.NewTestTx(b)
+			defer tx.Rollback()
+			sourceBucket := dbutils.Buckets[0]
+			destBucket := dbutils.Buckets[1]
+			generateTestData(b, tx, sourceBucket, 100_000)
+			b.ResetTimer()
+			var diskSize uint64
+			for i := 0; i < b.N; i++ {
+				collector := NewCompressedCollector(b.TempDir(), NewSortableBuffer(1024*1024), compression)
+				if err := extractBucketIntoFiles("logPrefix", tx, sourceBucket, nil, nil, 0, collector, testExtractToMapFunc, nil, nil); err != nil {
+					b.Fatal(err)
+				}
+				diskSize = 0
+				for _, p := range collector.dataProviders {
+					if fp, ok := p.(*fileDataProvider); ok {
+						info, err := fp.file.Stat()
+						if err != nil {
+							b.Fatal(err)
+						}
+						diskSize += uint64(info.Size())
+					}
+				}
+				if err := collector.Load("logPrefix", tx, destBucket, testLoadFromMapFunc, TransformArgs{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(diskSize), "disk-bytes")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package etl
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ugorji/go/codec"
+	"golang.org/x/sync/errgroup"
+)
+
+// mergeProviders - merges sorted providers into `parts` sorted files. Each part is a continuous range of providers,
+// so order of parts keeps order of flushes (it's important for SortableOldestAppearedBuffer).
+// Parts are merged in parallel, merged providers are disposed.
+func mergeProviders(logPrefix string, providers []dataProvider, parts int, bufType int, comparator dbutils.CmpFunc, tmpdir string, compression Compression) ([]dataProvider, error) {
+	if parts <= 0 || len(providers) <= parts {
+		return providers, nil
+	}
+	t := time.Now()
+	result := make([]dataProvider, parts)
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var g errgroup.Group
+	for i := 0; i < parts; i++ {
+		i := i
+		from, to := i*len(providers)/parts, (i+1)*len(providers)/parts
+		if to-from == 1 {
+			result[i] = providers[from]
+			continue
+		}
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			merged, err := mergeIntoFile(providers[from:to], bufType, comparator, tmpdir, compression)
+			if err != nil {
+				return err
+			}
+			result[i] = merged
+			for _, p := range providers[from:to] {
+				p.Dispose()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		for i, p := range result { // providers which were not merged will be disposed by Collector
+			if p != nil && !containsProvider(providers, p) {
+				p.Dispose()
+				result[i] = nil
+			}
+		}
+		return nil, fmt.Errorf("%s: merging etl files: %w", logPrefix, err)
+	}
+	log.Info(fmt.Sprintf("[%s] ETL files merged", logPrefix), "files", len(providers), "into", parts, "took", time.Since(t))
+	return result, nil
+}
+
+func containsProvider(providers []dataProvider, p dataProvider) bool {
+	for _, provider := range providers {
+		if provider == p {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeIntoFile(providers []dataProvider, bufType int, comparator dbutils.CmpFunc, tmpdir string, compression Compression) (dataProvider, error) {
+	decoder := codec.NewDecoder(nil, &cbor)
+	h := &Heap{comparator: comparator}
+	heap.Init(h)
+	for i, provider := range providers {
+		key, value, err := provider.Next(decoder)
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading first element of %s: %w", provider, err)
+		}
+		heap.Push(h, HeapElem{key, i, value})
+	}
+
+	file, err := writeTmpFile(codec.NewEncoder(nil, &cbor), tmpdir, compression, func(encoder Encoder) error {
+		var prevK []byte
+		for h.Len() > 0 {
+			element := (heap.Pop(h)).(HeapElem)
+			// same as loading: only the oldest value of key is needed
+			if !(bufType == SortableOldestAppearedBuffer && bytes.Equal(prevK, element.Key)) {
+				if err := writeToDisk(encoder, element.Key, element.Value); err != nil {
+					return err
+				}
+			}
+			prevK = element.Key
+
+			var err error
+			if element.Key, element.Value, err = providers[element.TimeIdx].Next(decoder); err == nil {
+				heap.Push(h, element)
+			} else if err != io.EOF {
+				return fmt.Errorf("reading next element of %s: %w", providers[element.TimeIdx], err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fileDataProvider{file: file}, nil
+}
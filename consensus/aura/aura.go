@@ -35,6 +35,7 @@ import (
 	"github.com/ledgerwatch/erigon/consensus/clique"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
@@ -98,7 +99,7 @@ func (s *Step) doCalibrate() {
 
 // optCalibrate Calibrates the AuRa step number according to the current time.
 func (s *Step) optCalibrate() bool {
-	now := time.Now().Second()
+	now := time.Now().Unix()
 	var info StepDurationInfo
 	i := 0
 	for _, d := range s.durations {
@@ -121,6 +122,11 @@ func (s *Step) optCalibrate() bool {
 	return true
 }
 
+// increment - moves to the next step, used when calibration by time is disabled
+func (s *Step) increment() {
+	s.inner.Inc()
+}
+
 type PermissionedStep struct {
 	inner      *Step
 	canPropose *atomic.Bool
@@ -128,7 +134,6 @@ type PermissionedStep struct {
 
 type ReceivedStepHashes map[uint64]map[common.Address]common.Hash //BTreeMap<(u64, Address), H256>
 
-//nolint
 func (r ReceivedStepHashes) get(step uint64, author common.Address) (common.Hash, bool) {
 	res, ok := r[step]
 	if !ok {
@@ -138,7 +143,6 @@ func (r ReceivedStepHashes) get(step uint64, author common.Address) (common.Hash
 	return result, ok
 }
 
-//nolint
 func (r ReceivedStepHashes) insert(step uint64, author common.Address, blockHash common.Hash) {
 	res, ok := r[step]
 	if !ok {
@@ -148,7 +152,6 @@ func (r ReceivedStepHashes) insert(step uint64, author common.Address, blockHash
 	res[author] = blockHash
 }
 
-//nolint
 func (r ReceivedStepHashes) dropAncient(step uint64) {
 	for i := range r {
 		if i < step {
@@ -157,12 +160,16 @@ func (r ReceivedStepHashes) dropAncient(step uint64) {
 	}
 }
 
-//nolint
 type EpochManager struct {
+	mu                    sync.Mutex
 	epochTransitionHash   common.Hash // H256,
 	epochTransitionNumber uint64      // BlockNumber
-	finalityChecker       RollingFinality
+	finalityChecker       *RollingFinality
 	force                 bool
+
+	// the latest block finalized by the rolling finality of the imported blocks
+	finalizedNumber uint64
+	finalizedHash   common.Hash
 }
 
 func NewEpochManager() *EpochManager {
@@ -172,10 +179,12 @@ func NewEpochManager() *EpochManager {
 	}
 }
 
-// zoomValidators - Zooms to the epoch after the header with the given hash. Returns true if succeeded, false otherwise.
-// It's analog of zoom_to_after function in OE, but doesn't require external locking
-//nolint
-func (e *EpochManager) zoomToAfter(chain consensus.ChainHeaderReader, validators ValidatorSet, hash common.Hash) (RollingFinality, uint64, bool) {
+func (e *EpochManager) noteNewEpoch() { e.force = true }
+
+// zoomValidators - Zooms to the epoch after the header with the given hash. Returns true if succeeded, false if
+// the transition of the epoch is not known, error if the stored transition is invalid.
+// It's analog of zoom_to_after function in OE, caller must hold e.mu
+func (e *EpochManager) zoomToAfter(chain consensus.ChainHeaderReader, er consensus.EpochReader, validators ValidatorSet, hash common.Hash) (*RollingFinality, uint64, bool, error) {
 	var lastWasParent bool
 	if e.finalityChecker.lastPushed != nil {
 		lastWasParent = *e.finalityChecker.lastPushed == hash
@@ -184,7 +193,10 @@ func (e *EpochManager) zoomToAfter(chain consensus.ChainHeaderReader, validators
 	// early exit for current target == chain head, but only if the epochs are
 	// the same.
 	if lastWasParent && !e.force {
-		return e.finalityChecker, e.epochTransitionNumber, true
+		return e.finalityChecker, e.epochTransitionNumber, true, nil
+	}
+	if chain == nil || er == nil {
+		return e.finalityChecker, e.epochTransitionNumber, false, nil
 	}
 	e.force = false
 
@@ -192,29 +204,50 @@ func (e *EpochManager) zoomToAfter(chain consensus.ChainHeaderReader, validators
 	// forks it will only need to be called for the block directly after
 	// epoch transition, in which case it will be O(1) and require a single
 	// DB lookup.
-	lastTransition, ok := epochTransitionFor(chain, hash)
+	lastTransition, ok := epochTransitionFor(chain, er, hash)
 	if !ok {
-		return e.finalityChecker, e.epochTransitionNumber, false
+		log.Warn("[aura] No genesis transition found", "parent", hash)
+		return e.finalityChecker, e.epochTransitionNumber, false, nil
 	}
 	// extract other epoch set if it's not the same as the last.
 	if lastTransition.BlockHash != e.epochTransitionHash {
 		proof, err := destructure_proofs(lastTransition.ProofRlp)
 		if err != nil {
-			panic(err)
+			return nil, 0, false, fmt.Errorf("epoch transition at block %d: %w", lastTransition.BlockNumber, err)
 		}
 		first := proof.SignalNumber == 0
 		// use signal number so multi-set first calculation is correct.
-		list, _, err := validators.epochSet(first, proof.SignalNumber, proof.SetProof)
+		list, _, err := validators.epochSet(chain.Config(), first, proof.SignalNumber, proof.SetProof)
 		if err != nil {
-			panic(fmt.Errorf("proof produced by this engine; therefore it is valid; qed. %w", err))
+			return nil, 0, false, fmt.Errorf("validator set of epoch transition at block %d: %w", lastTransition.BlockNumber, err)
 		}
-		epochSet := list.validators
-		e.finalityChecker = NewRollingFinality(epochSet)
+		log.Trace("[aura] Updating finality checker with new validator set", "epoch", lastTransition.BlockNumber, "signal", proof.SignalNumber, "validators", list.validators)
+		e.finalityChecker = NewRollingFinality(list.validators)
 	}
 	e.epochTransitionHash = lastTransition.BlockHash
 	e.epochTransitionNumber = lastTransition.BlockNumber
-	return e.finalityChecker, e.epochTransitionNumber, true
+	return e.finalityChecker, e.epochTransitionNumber, true, nil
 }
+
+// zoomToParent - zooms to the epoch of the block with given parent hash and makes sure the finality checker
+// has the unfinalized ancestry of the parent, so the block can be pushed onto it. Caller must hold e.mu
+func (e *EpochManager) zoomToParent(chain consensus.ChainHeaderReader, er consensus.EpochReader, validators ValidatorSet, parentHash common.Hash) (*RollingFinality, error) {
+	finality, _, ok, err := e.zoomToAfter(chain, er, validators, parentHash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("unable to zoom to epoch of parent %x", parentHash)
+	}
+	if finality.lastPushed == nil || *finality.lastPushed != parentHash {
+		// build new finality checker from unfinalized ancestry of chain head, not including chain head itself yet.
+		if err := finality.buildAncestrySubchain(chain, parentHash, e.epochTransitionHash); err != nil {
+			return nil, fmt.Errorf("inconsistent validator set within epoch: %w", err)
+		}
+	}
+	return finality, nil
+}
+
 func destructure_proofs(b []byte) (EpochTransitionProof, error) {
 	res := &EpochTransitionProof{}
 	err := rlp.DecodeBytes(b, res)
@@ -229,8 +262,7 @@ func destructure_proofs(b []byte) (EpochTransitionProof, error) {
 /// This will give the epoch that any children of this parent belong to.
 ///
 /// The block corresponding the the parent hash must be stored already.
-//nolint
-func epochTransitionFor(chain consensus.ChainHeaderReader, parentHash common.Hash) (transition EpochTransition, ok bool) {
+func epochTransitionFor(chain consensus.ChainHeaderReader, e consensus.EpochReader, parentHash common.Hash) (transition EpochTransition, ok bool) {
 	// slow path: loop back block by block
 	for {
 		h := chain.GetHeaderByHash(parentHash)
@@ -239,7 +271,7 @@ func epochTransitionFor(chain consensus.ChainHeaderReader, parentHash common.Has
 		}
 
 		// look for transition in database.
-		transition, ok = epochTransition(h.Number.Uint64(), h.Hash())
+		transition, ok = epochTransition(e, h.Number.Uint64(), h.Hash())
 		if ok {
 			return transition, true
 		}
@@ -253,56 +285,52 @@ func epochTransitionFor(chain consensus.ChainHeaderReader, parentHash common.Has
 		if canonical == nil {
 			return transition, false
 		}
-		//nolint
 		if canonical.Hash() == parentHash {
-
-			return EpochTransition{
-				BlockNumber: 0,
-				BlockHash:   common.HexToHash("0x5b28c1bfd3a15230c9a46b399cd0f9a6920d432e85381cc6a140b06e8410112f"),
-				ProofRlp:    params.SokolGenesisEpochProof,
-			}, true
-			/* TODO:
-			   return self
-			       .epoch_transitions()
-			       .map(|(_, t)| t)
-			       .take_while(|t| t.block_number <= details.number)
-			       .last();
-			*/
+			return lastCanonicalEpochTransition(chain, e, h.Number.Uint64())
+		}
+		if h.Number.Uint64() == 0 {
+			return transition, false
 		}
 
-		parentHash = h.Hash()
+		parentHash = h.ParentHash
+	}
+}
+
+// lastCanonicalEpochTransition - the last transition of the canonical chain at or before the block number
+func lastCanonicalEpochTransition(chain consensus.ChainHeaderReader, e consensus.EpochReader, number uint64) (EpochTransition, bool) {
+	for {
+		blockNum, blockHash, proof, err := e.FindBeforeOrEqualNumber(number)
+		if err != nil {
+			log.Warn("[aura] Unable to read epoch transitions", "err", err)
+			return EpochTransition{}, false
+		}
+		if proof == nil {
+			return EpochTransition{}, false
+		}
+		// transitions of the forks are kept, skip them
+		if canonical := chain.GetHeaderByNumber(blockNum); canonical != nil && canonical.Hash() == blockHash {
+			return EpochTransition{BlockNumber: blockNum, BlockHash: blockHash, ProofRlp: proof}, true
+		}
+		if blockNum == 0 {
+			return EpochTransition{}, false
+		}
+		number = blockNum - 1
 	}
 }
 
 // epochTransition get a specific epoch transition by block number and provided block hash.
-//nolint
-func epochTransition(blockNum uint64, blockHash common.Hash) (transition EpochTransition, ok bool) {
-	if blockNum == 0 {
-		return EpochTransition{BlockNumber: 0, BlockHash: params.SokolGenesisHash, ProofRlp: params.SokolGenesisEpochProof}, true
+func epochTransition(e consensus.EpochReader, blockNum uint64, blockHash common.Hash) (transition EpochTransition, ok bool) {
+	proof, err := e.GetEpoch(blockHash, blockNum)
+	if err != nil {
+		log.Warn("[aura] Unable to read epoch transition", "block", blockNum, "err", err)
+		return transition, false
 	}
-	if blockNum > 672 {
-		return EpochTransition{BlockNumber: 672, BlockHash: common.HexToHash("0x6254cbb380ddbac9415773d668486a9cc69daf62a91022f653702a4e4db804e8"), ProofRlp: common.FromHex("0xf9071b8202a0b904cff904ccf9023da089e215eb0c3efcba1a9bc4f7d00245a5d918879480fc2f00c560f5defbe86e02a01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d4934794e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acaa0742fc98a1b575fb5f8d5cd475d0e53e35c2630993420ffc5b216e427bb8a54dfa0c8bbec82f1ace26614aa8379a3498fa4c2e5d6624167096f434e6955f5d67896a0ecf05cd0986446a335960ba00ccf2083e08b55103a20a874fb6215395e28dce4b901000000001000000010000000000000000000000000000100000000000002000000000000400000000040000000000000000000000000000000000000800000000020000200000000000000000000000000000002000000000000000000000000002000000000000000000000000000000100000000028000000000000000000000000000000000000000400400000000000000000000000000000000000000000000020000000000000008000000000012000000000200000000800000040000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000090fffffffffffffffffffffffffffffffe8202a0837a12008302ed2e845a3445bc96d5830108048650617269747986312e32322e31826c6984120a7459b84106cec1622008b07bc3fb6c0cb7781a69f4101fe99c59960b2b0aee6b2a8f34b17f07001e5a8d83c85e7584a48c90906b308a71681b6a500018f80868fc6dd98a01f90289f90286018302ed2eb9010000000010000000100000000000000000000000000001000000000000020000000000004000000000400000000000000000000000000000000000008000000000200002000000000000000000000000000000020000000000000000000000000020000000000000000000000000000001000000000280000000000000000000000000000000000000004004000000000000000000000000000000000000000000000200000000000000080000000000120000000002000000008000000400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000f9017bf8db948bf38d4764929064f2d4d3a56520a76ab3df415bf842a055252fa6eee4741b4e24a74a70e9c11fd2c2281df8d6ea13126ff845f7825c89a089e215eb0c3efcba1a9bc4f7d00245a5d918879480fc2f00c560f5defbe86e02b88000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000002000000000000000000000000e8ddc5c7a2d2f0d7a9798459c0104fdf5e987aca00000000000000000000000082e4e61e7f5139ff0a4157a5bc687ef42294c248f89c9488a34124bfffa27ef3e052c8dd2908e212643771f884a009b714df46e3a39ff284866b80612984e10c731561c157cd03aca91e436808a5a000000000000000000000000082e4e61e7f5139ff0a4157a5bc687ef42294c248a00000000000000000000000009a1f6adb5bd804b5d8bd21dd7aeb44edecbaa313a000000000000000000000000096604b744e9c5a72680d33e7faf9629e19c97eaa80b90243f90240f9023da089e215eb0c3efcba1a9bc4f7d00245a5d918879480fc2f00c560f5defbe86e02a01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d4934794e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acaa0742fc98a1b575fb5f8d5cd475d0e53e35c2630993420ffc5b216e427bb8a54dfa0c8bbec82f1ace26614aa8379a3498fa4c2e5d6624167096f434e6955f5d67896a0ecf05cd0986446a335960ba00ccf2083e08b55103a20a874fb6215395e28dce4b901000000001000000010000000000000000000000000000100000000000002000000000000400000000040000000000000000000000000000000000000800000000020000200000000000000000000000000000002000000000000000000000000002000000000000000000000000000000100000000028000000000000000000000000000000000000000400400000000000000000000000000000000000000000000020000000000000008000000000012000000000200000000800000040000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000090fffffffffffffffffffffffffffffffe8202a0837a12008302ed2e845a3445bc96d5830108048650617269747986312e32322e31826c6984120a7459b84106cec1622008b07bc3fb6c0cb7781a69f4101fe99c59960b2b0aee6b2a8f34b17f07001e5a8d83c85e7584a48c90906b308a71681b6a500018f80868fc6dd98a01")}, true
+	if proof == nil {
+		return transition, false
 	}
-	return EpochTransition{}, false
-	/*
-		pub fn epoch_transition(&self, block_num: u64, block_hash: H256) -> Option<EpochTransition> {
-		   trace!(target: "blockchain", "Loading epoch transition at block {}, {}",
-		    block_num, block_hash);
-
-		   self.db
-		       .key_value()
-		       .read(db::COL_EXTRA, &block_num)
-		       .and_then(|transitions: EpochTransitions| {
-		           transitions
-		               .candidates
-		               .into_iter()
-		               .find(|c| c.block_hash == block_hash)
-		       })
-		}
-	*/
+	return EpochTransition{BlockNumber: blockNum, BlockHash: blockHash, ProofRlp: proof}, true
 }
 
-//nolint
 type unAssembledHeader struct {
 	h common.Hash // H256
 	n uint64      // BlockNumber
@@ -311,24 +339,100 @@ type unAssembledHeader struct {
 
 // RollingFinality checker for authority round consensus.
 // Stores a chain of unfinalized hashes that can be pushed onto.
-//nolint
 type RollingFinality struct {
-	headers    []unAssembledHeader //nolint
+	headers    []unAssembledHeader // oldest first
 	signers    *SimpleList
 	signCount  map[common.Address]uint
 	lastPushed *common.Hash // Option<H256>,
 }
 
 /// Create a blank finality checker under the given validator set.
-func NewRollingFinality(signers []common.Address) RollingFinality {
-	return RollingFinality{
-		signers: NewSimpleList(signers),
-		//headers: VecDeque::new(),
-		//sign_count: HashMap::new(),
-		//last_pushed: None
+func NewRollingFinality(signers []common.Address) *RollingFinality {
+	return &RollingFinality{
+		signers:   NewSimpleList(signers),
+		signCount: map[common.Address]uint{},
 	}
 }
 
+func (f *RollingFinality) clear() {
+	f.headers = nil
+	f.signCount = map[common.Address]uint{}
+	f.lastPushed = nil
+}
+
+func (f *RollingFinality) checkSigners(signers []common.Address) error {
+	for _, s := range signers {
+		if !f.signers.contains(s) {
+			return fmt.Errorf("unknown validator: %x", s)
+		}
+	}
+	return nil
+}
+
+// buildAncestrySubchain - rebuilds the checker from the unfinalized ancestry of the block with given hash
+// (not including the epoch transition block), walking back until the first finalized block.
+func (f *RollingFinality) buildAncestrySubchain(chain consensus.ChainHeaderReader, hash common.Hash, epochTransitionHash common.Hash) error {
+	f.clear()
+	head := chain.GetHeaderByHash(hash)
+	if head == nil {
+		return fmt.Errorf("header %x not found", hash)
+	}
+	f.lastPushed = &hash
+	for h := head; h != nil && h.Hash() != epochTransitionHash; h = chain.GetHeader(h.ParentHash, h.Number.Uint64()-1) {
+		signers := []common.Address{h.Coinbase}
+		if err := f.checkSigners(signers); err != nil {
+			return err
+		}
+		// break when we've got our first finalized block.
+		newSigners := 0
+		for _, s := range signers {
+			if _, ok := f.signCount[s]; !ok {
+				newSigners++
+			}
+		}
+		if (len(f.signCount)+newSigners)*2 > len(f.signers.validators) {
+			break
+		}
+		for _, s := range signers {
+			f.signCount[s]++
+		}
+		f.headers = append([]unAssembledHeader{{h: h.Hash(), n: h.Number.Uint64(), a: signers}}, f.headers...)
+		if h.Number.Uint64() == 0 {
+			break
+		}
+	}
+	log.Trace("[aura] Rolling finality state", "unfinalized", len(f.headers))
+	return nil
+}
+
+// push - pushes a hash onto the rolling finality checker (implying `subchain_head` == head.parent)
+//
+// Fails if `signer` isn't a member of the active validator set.
+// Returns a list of all newly finalized headers.
+func (f *RollingFinality) push(head common.Hash, num uint64, signers []common.Address) (newlyFinalized []unAssembledHeader, err error) {
+	if err = f.checkSigners(signers); err != nil {
+		return nil, err
+	}
+	for _, s := range signers {
+		f.signCount[s]++
+	}
+	f.headers = append(f.headers, unAssembledHeader{h: head, n: num, a: signers})
+
+	for len(f.signCount)*2 > len(f.signers.validators) {
+		finalized := f.headers[0]
+		f.headers = f.headers[1:]
+		newlyFinalized = append(newlyFinalized, finalized)
+		for _, s := range finalized.a {
+			f.signCount[s]--
+			if f.signCount[s] == 0 {
+				delete(f.signCount, s)
+			}
+		}
+	}
+	f.lastPushed = &head
+	return newlyFinalized, nil
+}
+
 // AuRa
 //nolint
 type AuRa struct {
@@ -336,6 +440,10 @@ type AuRa struct {
 	exitCh chan struct{}
 	lock   sync.RWMutex // Protects the signer fields
 
+	signer common.Address  // Ethereum address of the signing key
+	signFn clique.SignerFn // Signer function to authorize hashes with
+	client EngineClient    // Access to the node: calls of contracts, reports and, if supported, empty steps broadcast
+
 	step PermissionedStep
 	// History of step hashes recently received from peers.
 	receivedStepHashes ReceivedStepHashes
@@ -483,6 +591,7 @@ func NewAuRa(config *params.AuRaConfig, db ethdb.RwKV, ourSigningAddress common.
 		OurSigningAddress:  ourSigningAddress,
 		cfg:                auraParams,
 		receivedStepHashes: ReceivedStepHashes{},
+		EmptyStepsSet:      &EmptyStepSet{},
 		EpochManager:       NewEpochManager(),
 	}
 	_ = config
@@ -526,181 +635,140 @@ func (c *AuRa) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Hea
 	return nil
 }
 
-//nolint
+// hasReceivedStepHashes - whether other block of the author was received for the step
 func (c *AuRa) hasReceivedStepHashes(step uint64, author common.Address, newHash common.Hash) bool {
-	/*
-		self
-			       .received_step_hashes
-			       .read()
-			       .get(&received_step_key)
-			       .map_or(false, |h| *h != new_hash)
-	*/
-	return false
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	h, ok := c.receivedStepHashes.get(step, author)
+	return ok && h != newHash
 }
 
-//nolint
 func (c *AuRa) insertReceivedStepHashes(step uint64, author common.Address, newHash common.Hash) {
-	/*
-	   	    self.received_step_hashes
-	                      .write()
-	                      .insert(received_step_key, new_hash);
-	*/
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.receivedStepHashes.insert(step, author, newHash)
 }
 
-/// Phase 3 verification. Check block information against parent. Returns either a null `Ok` or a general error detailing the problem with import.
-//nolint
-func (c *AuRa) VerifyFamily(chain consensus.ChainHeaderReader, header *types.Header) error {
-	return nil
+// dropAncientStepHashes - removes hash records of the steps before the given one
+func (c *AuRa) dropAncientStepHashes(step uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.receivedStepHashes.dropAncient(step)
+}
+
+// VerifyFamily - phase 3 verification. Check block information against parent: the step is after the parent's one,
+// empty steps of the seal and the score. Misbehaviour of the validators is reported only by the engine of a
+// validator (with signing address), like report_skipped of OE.
+func (c *AuRa) VerifyFamily(chain consensus.ChainHeaderReader, header *types.Header, e consensus.EpochReader) error {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
 	step, err := headerStep(header)
 	if err != nil {
 		return err
 	}
-	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
 	parentStep, err := headerStep(parent)
 	if err != nil {
 		return err
 	}
-	validators, setNumber, err := c.epochSet(chain, header)
+	validators, setNumber, err := c.epochSet(chain, e, header)
 	if err != nil {
 		return err
 	}
+	report := c.OurSigningAddress != (common.Address{})
 
 	// Ensure header is from the step after parent.
 	if step == parentStep ||
-		(header.Number.Uint64() >= c.cfg.ValidateStepTransition && step <= parentStep) {
-		log.Debug("[engine] Multiple blocks proposed for step", "num", parentStep)
-		_ = setNumber
-		/*
-			self.validators.report_malicious(
-				header.author(),
-				set_number,
-				header.number(),
-				Default::default(),
-			);
-			Err(EngineError::DoubleVote(*header.author()))?;
-		*/
+		(number >= c.cfg.ValidateStepTransition && step <= parentStep) {
+		log.Debug("[aura] Multiple blocks proposed for step", "num", parentStep)
+		if report {
+			c.cfg.Validators.reportMalicious(header.Coinbase, setNumber, number, nil)
+		}
 		return fmt.Errorf("double vote: %x", header.Coinbase)
 	}
 
 	// Report malice if the validator produced other sibling blocks in the same step.
-	if !c.hasReceivedStepHashes(step, header.Coinbase, header.Hash()) {
-		/*
-		   trace!(target: "engine", "Validator {} produced sibling blocks in the same step", header.author());
-		   self.validators.report_malicious(
-		       header.author(),
-		       set_number,
-		       header.number(),
-		       Default::default(),
-		   );
-		*/
+	if c.hasReceivedStepHashes(step, header.Coinbase, header.Hash()) {
+		log.Trace("[aura] Validator produced sibling blocks in the same step", "validator", header.Coinbase)
+		if report {
+			c.cfg.Validators.reportMalicious(header.Coinbase, setNumber, number, nil)
+		}
 	} else {
 		c.insertReceivedStepHashes(step, header.Coinbase, header.Hash())
 	}
 
 	// Remove hash records older than two full rounds of steps (picked as a reasonable trade-off between
 	// memory consumption and fault-tolerance).
-	cnt, err := count(validators, parent.Hash())
-	if err != nil {
-		return err
-	}
-	siblingMaliceDetectionPeriod := 2 * cnt
-	oldestStep := uint64(0) //  let oldest_step = parent_step.saturating_sub(sibling_malice_detection_period);
-	if parentStep > siblingMaliceDetectionPeriod {
-		oldestStep = parentStep - siblingMaliceDetectionPeriod
-	}
-	//nolint
-	if oldestStep > 0 {
-		/*
-		   let mut rsh = self.received_step_hashes.write();
-		   let new_rsh = rsh.split_off(&(oldest_step, Address::zero()));
-		   *rsh = new_rsh;
-		*/
+	if cnt, err := count(validators, parent.Hash()); err != nil {
+		log.Debug("[aura] Unable to count validators", "block", number, "err", err)
+	} else if siblingMaliceDetectionPeriod := 2 * cnt; parentStep > siblingMaliceDetectionPeriod {
+		c.dropAncientStepHashes(parentStep - siblingMaliceDetectionPeriod)
 	}
 
+	// If empty step messages are enabled we will validate the messages in the seal, missing messages are not
+	// reported as there's no way to tell whether the empty step message was never sent or simply not included.
 	emptyStepLen := uint64(0)
-	//self.report_skipped(header, step, parent_step, &*validators, set_number);
+	if number >= c.cfg.EmptyStepsTransition {
+		emptySteps, err := c.verifyEmptySteps(header, step, parentStep, validators)
+		if err != nil {
+			log.Trace("[aura] Reporting benign misbehaviour (cause: invalid empty steps)", "block", number, "set", setNumber)
+			if report {
+				c.cfg.Validators.reportBenign(header.Coinbase, setNumber, number)
+			}
+			return err
+		}
+		emptyStepLen = uint64(len(emptySteps))
+	} else if report {
+		c.reportSkipped(header, step, parentStep, validators, setNumber, c.OurSigningAddress)
+	}
 
-	/*
-	   // If empty step messages are enabled we will validate the messages in the seal, missing messages are not
-	   // reported as there's no way to tell whether the empty step message was never sent or simply not included.
-	   let empty_steps_len = if header.number() >= self.empty_steps_transition {
-	       let validate_empty_steps = || -> Result<usize, Error> {
-	           let strict_empty_steps = header.number() >= self.strict_empty_steps_transition;
-	           let empty_steps = header_empty_steps(header)?;
-	           let empty_steps_len = empty_steps.len();
-	           let mut prev_empty_step = 0;
-
-	           for empty_step in empty_steps {
-	               if empty_step.step <= parent_step || empty_step.step >= step {
-	                   Err(EngineError::InsufficientProof(format!(
-	                       "empty step proof for invalid step: {:?}",
-	                       empty_step.step
-	                   )))?;
-	               }
-
-	               if empty_step.parent_hash != *header.parent_hash() {
-	                   Err(EngineError::InsufficientProof(format!(
-	                       "empty step proof for invalid parent hash: {:?}",
-	                       empty_step.parent_hash
-	                   )))?;
-	               }
-
-	               if !empty_step.verify(&*validators).unwrap_or(false) {
-	                   Err(EngineError::InsufficientProof(format!(
-	                       "invalid empty step proof: {:?}",
-	                       empty_step
-	                   )))?;
-	               }
-
-	               if strict_empty_steps {
-	                   if empty_step.step <= prev_empty_step {
-	                       Err(EngineError::InsufficientProof(format!(
-	                           "{} empty step: {:?}",
-	                           if empty_step.step == prev_empty_step {
-	                               "duplicate"
-	                           } else {
-	                               "unordered"
-	                           },
-	                           empty_step
-	                       )))?;
-	                   }
-
-	                   prev_empty_step = empty_step.step;
-	               }
-	           }
-
-	           Ok(empty_steps_len)
-	       };
-
-	       match validate_empty_steps() {
-	           Ok(len) => len,
-	           Err(err) => {
-	               trace!(
-	                   target: "engine",
-	                   "Reporting benign misbehaviour (cause: invalid empty steps) \
-	                   at block #{}, epoch set number {}. Own address: {}",
-	                   header.number(), set_number, self.address().unwrap_or_default()
-	               );
-	               self.validators
-	                   .report_benign(header.author(), set_number, header.number());
-	               return Err(err);
-	           }
-	       }
-	   } else {
-	       self.report_skipped(header, step, parent_step, &*validators, set_number);
-
-	       0
-	   };
-	*/
-	if header.Number.Uint64() >= c.cfg.ValidateScoreTransition {
+	if number >= c.cfg.ValidateScoreTransition {
 		expectedDifficulty := calculateScore(parentStep, step, emptyStepLen)
 		if header.Difficulty.Cmp(expectedDifficulty.ToBig()) != 0 {
-			return fmt.Errorf("invlid difficulty: expect=%s, found=%s\n", expectedDifficulty, header.Difficulty)
+			return fmt.Errorf("invalid difficulty: expect=%s, found=%s", expectedDifficulty, header.Difficulty)
 		}
 	}
 	return nil
 }
 
+// verifyEmptySteps - checks the empty steps of the seal: they are between the steps of the parent and the header,
+// signed by the proposers of their steps, and with strict empty steps ordered without duplicates
+func (c *AuRa) verifyEmptySteps(header *types.Header, step, parentStep uint64, validators ValidatorSet) ([]EmptyStep, error) {
+	strict := header.Number.Uint64() >= c.cfg.StrictEmptyStepsTransition
+	emptySteps, err := headerEmptySteps(header)
+	if err != nil {
+		return nil, err
+	}
+	prevEmptyStep := uint64(0)
+	for i := range emptySteps {
+		emptyStep := &emptySteps[i]
+		if emptyStep.step <= parentStep || emptyStep.step >= step {
+			return nil, fmt.Errorf("insufficient proof: empty step proof for invalid step: %d", emptyStep.step)
+		}
+		if emptyStep.parentHash != header.ParentHash {
+			return nil, fmt.Errorf("insufficient proof: empty step proof for invalid parent hash: %x", emptyStep.parentHash)
+		}
+		if ok, err := emptyStep.verify(validators); err != nil || !ok {
+			return nil, fmt.Errorf("insufficient proof: invalid empty step proof: step %d", emptyStep.step)
+		}
+		if strict {
+			if emptyStep.step == prevEmptyStep {
+				return nil, fmt.Errorf("insufficient proof: duplicate empty step: %d", emptyStep.step)
+			}
+			if emptyStep.step < prevEmptyStep {
+				return nil, fmt.Errorf("insufficient proof: unordered empty step: %d", emptyStep.step)
+			}
+			prevEmptyStep = emptyStep.step
+		}
+	}
+	return emptySteps, nil
+}
+
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
 // method returns a quit channel to abort the operations and a results channel to
 // retrieve the async verifications (the order is that of the input slice).
@@ -730,137 +798,200 @@ func (c *AuRa) VerifySeal(chain consensus.ChainHeaderReader, header *types.Heade
 }
 
 // Prepare implements consensus.Engine, preparing all the consensus fields of the
-// header for running the transactions on top.
-func (c *AuRa) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
-	return nil
-	/// If the block isn't a checkpoint, cast a random vote (good enough for now)
-	//header.Coinbase = common.Address{}
-	//header.Nonce = types.BlockNonce{}
-	//
-	//number := header.Number.Uint64()
-	/// Assemble the voting snapshot to check which votes make sense
-	//snap, err := c.Snapshot(chain, number-1, header.ParentHash, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//if number%c.config.Epoch != 0 {
-	//	c.lock.RLock()
-	//
-	//	// Gather all the proposals that make sense voting on
-	//	addresses := make([]common.Address, 0, len(c.proposals))
-	//	for address, authorize := range c.proposals {
-	//		if snap.validVote(address, authorize) {
-	//			addresses = append(addresses, address)
-	//		}
-	//	}
-	//	// If there's pending proposals, cast a vote on them
-	//	if len(addresses) > 0 {
-	//		header.Coinbase = addresses[rand.Intn(len(addresses))]
-	//		if c.proposals[header.Coinbase] {
-	//			copy(header.Nonce[:], NonceAuthVote)
-	//		} else {
-	//			copy(header.Nonce[:], nonceDropVote)
-	//		}
-	//	}
-	//	c.lock.RUnlock()
-	//}
-	/// Set the correct difficulty
-	//header.Difficulty = calcDifficulty(snap, c.signer)
-	//
-	/// Ensure the extra data has all its components
-	//if len(header.Extra) < ExtraVanity {
-	//	header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, ExtraVanity-len(header.Extra))...)
-	//}
-	//header.Extra = header.Extra[:ExtraVanity]
-	//
-	//if number%c.config.Epoch == 0 {
-	//	for _, signer := range snap.GetSigners() {
-	//		header.Extra = append(header.Extra, signer[:]...)
-	//	}
-	//}
-	//header.Extra = append(header.Extra, make([]byte, ExtraSeal)...)
-	//
-	/// Mix digest is reserved for now, set to empty
-	//header.MixDigest = common.Hash{}
-	//
-	/// Ensure the timestamp has the correct delay
-	//parent := chain.GetHeader(header.ParentHash, number-1)
-	//if parent == nil {
-	//	return consensus.ErrUnknownAncestor
-	//}
-	//header.Time = parent.Time + c.config.Period
-	//
-	//now := uint64(time.Now().Unix())
-	//if header.Time < now {
-	//	header.Time = now
-	//}
-	//
-	//return nil
-}
+// header for running the transactions on top: the step, the empty steps collected since
+// the parent and the score (difficulty). The signature is filled by Seal.
+func (c *AuRa) Prepare(chain consensus.ChainHeaderReader, header *types.Header, e consensus.EpochReader) error {
+	number := header.Number.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	parentStep, err := headerStep(parent)
+	if err != nil {
+		return err
+	}
+	// the step is switched by Step
+	step := c.step.inner.inner.Load()
 
-func (c *AuRa) Initialize(cc *params.ChainConfig, e consensus.EpochReader, header *types.Header, txs []types.Transaction, uncles []*types.Header, syscall consensus.SystemCall) {
-	//TODO: hardcoded boolean!!!
-	// 	let is_epoch_begin = chain.epoch_transition(parent.number(), *header.parent_hash()).is_some();
+	var emptyStepsRlp []byte
+	var emptyStepsLen uint64
+	if number >= c.cfg.EmptyStepsTransition {
+		emptySteps := c.emptySteps(parentStep, step, header.ParentHash)
+		if emptyStepsRlp, err = sealedEmptyStepsRlp(emptySteps); err != nil {
+			return err
+		}
+		emptyStepsLen = uint64(len(emptySteps))
+	}
+	header.Seal, err = encodeSeal(step, make([]byte, crypto.SignatureLength), emptyStepsRlp)
+	if err != nil {
+		return err
+	}
+	header.WithSeal = true
+	header.Difficulty = calculateScore(parentStep, step, emptyStepsLen).ToBig()
 
-	if header.Number.Uint64() == 1 {
-		_ = e.PutEpoch(header.ParentHash, 0, []byte{1}) //TODO: block 0 hardcoded - need fix it inside validators
+	// the genesis epoch is stored by Initialize of the first block, its set is taken from the spec by epochSet
+	if c.cfg.ImmediateTransitions || e == nil || number == 1 {
+		return nil
+	}
+	// zoom to the epoch of the parent in advance, the validator set of it is used by Seal
+	c.EpochManager.mu.Lock()
+	defer c.EpochManager.mu.Unlock()
+	_, err = c.EpochManager.zoomToParent(chain, e, c.cfg.Validators, header.ParentHash)
+	return err
+}
+
+func (c *AuRa) Initialize(cc *params.ChainConfig, e consensus.EpochReader, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, syscall consensus.SystemCall) {
+	if e == nil {
+		return
+	}
+	blockNum := header.Number.Uint64()
+	if blockNum == 1 {
+		// genesis is the first epoch transition, its proof is calculated on the state of genesis
+		proof, err := e.GetEpoch(header.ParentHash, 0)
+		if err != nil {
+			log.Warn("aura initialize block: read genesis epoch", "err", err)
+			return
+		}
+		if proof == nil {
+			if proof, err = c.GenesisEpochData(cc, header.ParentHash, state); err != nil {
+				log.Error("aura initialize block: genesis epoch data", "err", err)
+				return
+			}
+			if err = e.PutEpoch(header.ParentHash, 0, proof); err != nil {
+				log.Error("aura initialize block: write genesis epoch", "err", err)
+				return
+			}
+		}
+	}
+	if c.cfg.ImmediateTransitions {
+		return
 	}
 
-	epoch, err := e.GetEpoch(header.ParentHash, header.Number.Uint64()-1)
+	epoch, err := e.GetEpoch(header.ParentHash, blockNum-1)
 	if err != nil {
 		log.Warn("aura initialize block: on epoch begin", "err", err)
 		return
 	}
-
 	isEpochBegin := epoch != nil
-	//var isEpochBegin bool
-	//sokolTransitions := []uint64{
-	//	1, 673, 194808, 200642,
-	//}
-	//for _, t := range sokolTransitions {
-	//	if header.Number.Uint64() == t {
-	//		isEpochBegin = true
-	//		break
-	//	}
-	//}
 	if !isEpochBegin {
 		return
 	}
-	err = c.cfg.Validators.onEpochBegin(isEpochBegin, header, syscall)
+	err = c.cfg.Validators.onEpochBegin(false, header, syscall)
 	if err != nil {
 		log.Warn("aura initialize block: on epoch begin", "err", err)
 		return
 	}
 }
 
-func (c *AuRa) Finalize(cc *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, syscall consensus.SystemCall) {
+func (c *AuRa) Finalize(cc *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall) error {
 	// accumulateRewards retrieves rewards for a block and applies them to the coinbase accounts for miner and uncle miners
 	beneficiaries, _, rewards, err := AccumulateRewards(cc, c, header, uncles, syscall)
 	if err != nil {
-		log.Error("accumulateRewards", "err", err)
-		return
+		return fmt.Errorf("accumulateRewards: %w", err)
 	}
 	for i := range beneficiaries {
-		//fmt.Printf("beneficiary: n=%d, %x,%d\n", header.Number.Uint64(), beneficiaries[i], rewards[i])
 		state.AddBalance(beneficiaries[i], rewards[i])
 	}
 
+	if c.OurSigningAddress != (common.Address{}) {
+		if err = c.cfg.Validators.onCloseBlock(header, c.OurSigningAddress); err != nil {
+			log.Warn("aura finalize block: on close block", "err", err)
+		}
+	}
+
 	// t_nb 9.13 check epoch end. Related only to AuRa and it seems light engine
-	//c.check_epoch_end(&header, &finalized, &chain, client)
-	//c.cfg.Validators.isEpochEnd()
-	_, ok := c.cfg.Validators.signalEpochEnd(header.Number.Uint64() == 0, header, r)
-	if ok {
-		//fmt.Printf("put echo: %d\n", header.Number.Uint64())
-		_ = e.PutEpoch(header.Hash(), header.Number.Uint64(), []byte{1}) //TODO: put correct proof
+	if e == nil || chain == nil {
+		return nil
+	}
+	if err = c.checkEpochEnd(cc, header, state, r, e, chain); err != nil {
+		return fmt.Errorf("aura finalize block %d: check epoch end: %w", header.Number.Uint64(), err)
 	}
+	return nil
+}
+
+// checkEpochEnd - stores the proof of the transition signalled by the block as pending, and the epoch transition
+// when the block finalizes some pending signal. Analog of check_epoch_end_signal and check_epoch_end of OE.
+func (c *AuRa) checkEpochEnd(cc *params.ChainConfig, header *types.Header, ibs *state.IntraBlockState, r types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader) error {
+	// epochs only matter if we want to support light clients.
+	if c.cfg.ImmediateTransitions {
+		return nil
+	}
+	prover := proverAt(cc, ibs, header.Number.Uint64())
+	pendingProof, err := c.cfg.Validators.signalEpochEnd(header.Number.Uint64() == 0, header, r, prover)
+	if err != nil {
+		return err
+	}
+	if pendingProof != nil {
+		if err = e.PutPendingEpoch(header.Hash(), header.Number.Uint64(), pendingProof); err != nil {
+			return err
+		}
+	}
+
+	c.EpochManager.mu.Lock()
+	defer c.EpochManager.mu.Unlock()
+	finality, err := c.EpochManager.zoomToParent(chain, e, c.cfg.Validators, header.ParentHash)
+	if err != nil {
+		return err
+	}
+	finalized, err := finality.push(header.Hash(), header.Number.Uint64(), []common.Address{header.Coinbase})
+	if err != nil {
+		return err
+	}
+	if len(finalized) > 0 {
+		last := finalized[len(finalized)-1]
+		c.EpochManager.finalizedNumber, c.EpochManager.finalizedHash = last.n, last.h
+	}
+	for _, f := range finalized {
+		signalProof, err := e.GetPendingEpoch(f.h, f.n)
+		if err != nil {
+			return err
+		}
+		if signalProof == nil {
+			continue
+		}
+		// walk the chain from current head to finalized block
+		finalityProof, err := finalityProof(chain, header, f.h, f.n)
+		if err != nil {
+			return err
+		}
+		c.EpochManager.noteNewEpoch()
+		log.Info("[aura] Applying validator set change signalled at block", "signal", f.n, "block", header.Number.Uint64())
+		// We turn off can_propose here because upon validator set change there can
+		// be two valid proposers for a single step: one from the old set and
+		// one from the new.
+		//
+		// This way, upon encountering an epoch change, the proposer from the
+		// new set will be forced to wait until the next step to avoid sealing a
+		// block that breaks the invariant that the parent's step < the block's step.
+		c.step.canPropose.Store(false)
+		return e.PutEpoch(header.Hash(), header.Number.Uint64(), combineProofs(f.n, signalProof, finalityProof))
+	}
+	return nil
 }
 
-//func (c *AuRa) check_epoch_end(cc *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, syscall consensus.SystemCall) {
-//}
+// finalityProof - rlp list of headers from the head back to the finalized one, inclusive
+func finalityProof(chain consensus.ChainHeaderReader, head *types.Header, finalizedHash common.Hash, finalizedNumber uint64) ([]byte, error) {
+	var headers []*types.Header
+	for h := head; ; {
+		headers = append(headers, h)
+		if h.Number.Uint64() <= finalizedNumber {
+			if h.Hash() != finalizedHash {
+				return nil, fmt.Errorf("finalized block %d,%x is not an ancestor of %d", finalizedNumber, finalizedHash, head.Number.Uint64())
+			}
+			break
+		}
+		if h = chain.GetHeader(h.ParentHash, h.Number.Uint64()-1); h == nil {
+			return nil, fmt.Errorf("missing ancestor of %d", head.Number.Uint64())
+		}
+	}
+	return rlp.EncodeToBytes(headers)
+}
 
 // FinalizeAndAssemble implements consensus.Engine
-func (c *AuRa) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, syscall consensus.SystemCall) (*types.Block, error) {
-	c.Finalize(chainConfig, header, state, txs, uncles, r, e, syscall)
+func (c *AuRa) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall) (*types.Block, error) {
+	if err := c.Finalize(chainConfig, header, state, txs, uncles, r, e, chain, syscall); err != nil {
+		return nil, err
+	}
 
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, uncles, r), nil
@@ -872,104 +1003,106 @@ func (c *AuRa) Authorize(signer common.Address, signFn clique.SignerFn) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	//c.signer = signer
-	//c.signFn = signFn
+	c.signer = signer
+	c.signFn = signFn
+	if c.OurSigningAddress == (common.Address{}) {
+		c.OurSigningAddress = signer
+	}
+}
+
+// RegisterClient - gives the validator sets access to the node: contract calls and sending of reports,
+// and the engine the way to broadcast its empty step messages
+func (c *AuRa) RegisterClient(client EngineClient) {
+	c.lock.Lock()
+	c.client = client
+	c.lock.Unlock()
+	c.cfg.Validators.registerClient(client)
+}
+
+// Step - moves the engine to the step of the current time (or to the next step if the spec has fixed startStep)
+// and allows proposing in it. Returns true if the step has changed. Analog of TransitionHandler of OE,
+// it's expected to be called at least once per step duration.
+func (c *AuRa) Step() bool {
+	before := c.step.inner.inner.Load()
+	if c.step.inner.calibrate {
+		if !c.step.inner.optCalibrate() {
+			log.Warn("[aura] Unable to calibrate step", "step", before)
+			return false
+		}
+	} else {
+		c.step.inner.increment()
+	}
+	current := c.step.inner.inner.Load()
+	if current == before {
+		return false
+	}
+	c.step.canPropose.Store(true)
+	log.Trace("[aura] New step", "step", current)
+	return true
 }
 
-func (c *AuRa) GenesisEpochData(header *types.Header, caller Call) ([]byte, error) {
-	proof, err := c.cfg.Validators.genesisEpochData(header, caller)
+// FinalizedBlock - the latest block finalized by the rolling finality of the validators, zero if nothing is
+// finalized since the start of the engine
+func (c *AuRa) FinalizedBlock() (uint64, common.Hash) {
+	c.EpochManager.mu.Lock()
+	defer c.EpochManager.mu.Unlock()
+	return c.EpochManager.finalizedNumber, c.EpochManager.finalizedHash
+}
+
+// GenesisEpochData - proof of the genesis epoch transition, the validator set of contract is proven on the given state of genesis
+func (c *AuRa) GenesisEpochData(cc *params.ChainConfig, genesisHash common.Hash, ibs *state.IntraBlockState) ([]byte, error) {
+	proof, err := c.cfg.Validators.genesisEpochData(genesisHash, proverAt(cc, ibs, 0))
 	if err != nil {
 		return nil, err
 	}
 	return combineProofs(0, proof, []byte{}), nil
 }
 func combineProofs(signalNumber uint64, setProof []byte, finalityProof []byte) []byte {
-	//if signalNumber == 672 {
-	return common.FromHex("0xf91a8c80b91a87f91a84f9020da00000000000000000000000000000000000000000000000000000000000000000a01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347940000000000000000000000000000000000000000a0fad4af258fd11939fae0c6c6eec9d340b1caac0b0196fd9a1bc3f489c5bf00b3a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421b9010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000830200008083663be080808080b8410000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000f91871b853f851808080a07bb75cabebdcbd1dbb4331054636d0c6d7a2b08483b9e04df057395a7434c9e080808080808080a0e61e567237b49c44d8f906ceea49027260b4010c10a547b38d8b131b9d3b6f848080808080b86bf869a02080c7b7ae81a58eb98d9c78de4a1fd7fd9535fc953ed2be602daaa41767312ab846f8448080a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421a0c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470b86bf869a033aa5d69545785694b808840be50c182dad2ec3636dfccbe6572fb69828742c0b846f8440101a0663ce0d171e545a26aa67e4ca66f72ba96bb48287dbcc03beea282867f80d44ba01f0e7726926cb43c03a0abf48197dba78522ec8ba1b158e2aa30da7d2a2c6f9ea3e2a02052222313e28459528d920b65115c16c04f3efc82aaedc97be59f3f377c0d3f01b914c26060604052600436106100fc576000357c0100000000000000000000000000000000000000000000000000000000900463ffffffff16806303aca79214610101578063108552691461016457806340a141ff1461019d57806340c9cdeb146101d65780634110a489146101ff57806345199e0a1461025757806349285b58146102c15780634d238c8e14610316578063752862111461034f578063900eb5a8146103645780639a573786146103c7578063a26a47d21461041c578063ae4b1b5b14610449578063b3f05b971461049e578063b7ab4db5146104cb578063d3e848f114610535578063fa81b2001461058a578063facd743b146105df575b600080fd5b341561010c57600080fd5b6101226004808035906020019091905050610630565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b341561016f57600080fd5b61019b600480803573ffffffffffffffffffffffffffffffffffffffff1690602001909190505061066f565b005b34156101a857600080fd5b6101d4600480803573ffffffffffffffffffffffffffffffffffffffff16906020019091905050610807565b005b34156101e157600080fd5b6101e9610bb7565b6040518082815260200191505060405180910390f35b341561020a57600080fd5b610236600480803573ffffffffffffffffffffffffffffffffffffffff16906020019091905050610bbd565b60405180831515151581526020018281526020019250505060405180910390f35b341561026257600080fd5b61026a610bee565b6040518080602001828103825283818151815260200191508051906020019060200280838360005b838110156102ad578082015181840152602081019050610292565b505050509050019250505060405180910390f35b34156102cc57600080fd5b6102d4610c82565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b341561032157600080fd5b61034d600480803573ffffffffffffffffffffffffffffffffffffffff16906020019091905050610d32565b005b341561035a57600080fd5b610362610fcc565b005b341561036f57600080fd5b61038560048080359060200190919050506110fc565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b34156103d257600080fd5b6103da61113b565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b341561042757600080fd5b61042f6111eb565b604051808215151515815260200191505060405180910390f35b341561045457600080fd5b61045c6111fe565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b34156104a957600080fd5b6104b1611224565b604051808215151515815260200191505060405180910390f35b34156104d657600080fd5b6104de611237565b6040518080602001828103825283818151815260200191508051906020019060200280838360005b83811015610521578082015181840152602081019050610506565b505050509050019250505060405180910390f35b341561054057600080fd5b6105486112cb565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b341561059557600080fd5b61059d6112f1565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b34156105ea57600080fd5b610616600480803573ffffffffffffffffffffffffffffffffffffffff16906020019091905050611317565b604051808215151515815260200191505060405180910390f35b60078181548110151561063f57fe5b90600052602060002090016000915054906101000a900473ffffffffffffffffffffffffffffffffffffffff1681565b600460029054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff161415156106cb57600080fd5b600460019054906101000a900460ff161515156106e757600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff161415151561072357600080fd5b80600a60006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055506001600460016101000a81548160ff0219169083151502179055507f600bcf04a13e752d1e3670a5a9f1c21177ca2a93c6f5391d4f1298d098097c22600a60009054906101000a900473ffffffffffffffffffffffffffffffffffffffff16604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390a150565b600080600061081461113b565b73ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff1614151561084d57600080fd5b83600960008273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200190815260200160002060000160009054906101000a900460ff1615156108a957600080fd5b600960008673ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff168152602001908152602001600020600101549350600160078054905003925060078381548110151561090857fe5b906000526020600020900160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1691508160078581548110151561094657fe5b906000526020600020900160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff16021790555083600960008473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff168152602001908152602001600020600101819055506007838154811015156109e557fe5b906000526020600020900160006101000a81549073ffffffffffffffffffffffffffffffffffffffff02191690556000600780549050111515610a2757600080fd5b6007805480919060019003610a3c9190611370565b506000600960008773ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff168152602001908152602001600020600101819055506000600960008773ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200190815260200160002060000160006101000a81548160ff0219169083151502179055506000600460006101000a81548160ff0219169083151502179055506001430340600019167f55252fa6eee4741b4e24a74a70e9c11fd2c2281df8d6ea13126ff845f7825c89600760405180806020018281038252838181548152602001915080548015610ba257602002820191906000526020600020905b8160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019060010190808311610b58575b50509250505060405180910390a25050505050565b60085481565b60096020528060005260406000206000915090508060000160009054906101000a900460ff16908060010154905082565b610bf661139c565b6007805480602002602001604051908101604052809291908181526020018280548015610c7857602002820191906000526020600020905b8160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019060010190808311610c2e575b5050505050905090565b6000600a60009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff166349285b586000604051602001526040518163ffffffff167c0100000000000000000000000000000000000000000000000000000000028152600401602060405180830381600087803b1515610d1257600080fd5b6102c65a03f11515610d2357600080fd5b50505060405180519050905090565b610d3a61113b565b73ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff16141515610d7357600080fd5b80600960008273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200190815260200160002060000160009054906101000a900460ff16151515610dd057600080fd5b600073ffffffffffffffffffffffffffffffffffffffff168273ffffffffffffffffffffffffffffffffffffffff1614151515610e0c57600080fd5b6040805190810160405280600115158152602001600780549050815250600960008473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200190815260200160002060008201518160000160006101000a81548160ff0219169083151502179055506020820151816001015590505060078054806001018281610ea991906113b0565b9160005260206000209001600084909190916101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550506000600460006101000a81548160ff0219169083151502179055506001430340600019167f55252fa6eee4741b4e24a74a70e9c11fd2c2281df8d6ea13126ff845f7825c89600760405180806020018281038252838181548152602001915080548015610fba57602002820191906000526020600020905b8160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019060010190808311610f70575b50509250505060405180910390a25050565b600560009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff161480156110365750600460009054906101000a900460ff16155b151561104157600080fd5b6001600460006101000a81548160ff0219169083151502179055506007600690805461106e9291906113dc565b506006805490506008819055507f8564cd629b15f47dc310d45bcbfc9bcf5420b0d51bf0659a16c67f91d27632536110a4611237565b6040518080602001828103825283818151815260200191508051906020019060200280838360005b838110156110e75780820151818401526020810190506110cc565b505050509050019250505060405180910390a1565b60068181548110151561110b57fe5b90600052602060002090016000915054906101000a900473ffffffffffffffffffffffffffffffffffffffff1681565b6000600a60009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16639a5737866000604051602001526040518163ffffffff167c0100000000000000000000000000000000000000000000000000000000028152600401602060405180830381600087803b15156111cb57600080fd5b6102c65a03f115156111dc57600080fd5b50505060405180519050905090565b600460019054906101000a900460ff1681565b600a60009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1681565b600460009054906101000a900460ff1681565b61123f61139c565b60068054806020026020016040519081016040528092919081815260200182805480156112c157602002820191906000526020600020905b8160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019060010190808311611277575b5050505050905090565b600560009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1681565b600460029054906101000a900473ffffffffffffffffffffffffffffffffffffffff1681565b6000600960008373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200190815260200160002060000160009054906101000a900460ff169050919050565b81548183558181151161139757818360005260206000209182019101611396919061142e565b5b505050565b602060405190810160405280600081525090565b8154818355818115116113d7578183600052602060002091820191016113d6919061142e565b5b505050565b82805482825590600052602060002090810192821561141d5760005260206000209182015b8281111561141c578254825591600101919060010190611401565b5b50905061142a9190611453565b5090565b61145091905b8082111561144c576000816000905550600101611434565b5090565b90565b61149391905b8082111561148f57600081816101000a81549073ffffffffffffffffffffffffffffffffffffffff021916905550600101611459565b5090565b905600a165627a7a7230582036ea35935c8246b68074adece2eab70c40e69a0193c08a6277ce06e5b25188510029b8f3f8f1a08023c0d95fc2364e0bf7593f5ff32e1db8ef9f4b41c0bd474eae62d1af896e99808080a0b47b4f0b3e73b5edc8f9a9da1cbcfed562eb06bf54619b6aefeadebf5b3604c280a0da6ec08940a924cb08c947dd56cdb40076b29a6f0ea4dba4e2d02d9a9a72431b80a030cc4138c9e74b6cf79d624b4b5612c0fd888e91f55316cfee7d1694e1a90c0b80a0c5d54b915b56a888eee4e6eeb3141e778f9b674d1d322962eed900f02c29990aa017256b36ef47f907c6b1378a2636942ce894c17075e56fc054d4283f6846659e808080a03340bbaeafcda3a8672eb83099231dbbfab8dae02a1e8ec2f7180538fac207e080b853f851808080a0a87d9bb950836582673aa0eecc0ff64aac607870637a2dd2012b8b1b31981f698080a08da6d5c36a404670c553a2c9052df7cd604f04e3863c4c7b9e0027bfd54206d680808080808080808080b838f7a03868bdfa8727775661e4ccf117824a175a33f8703d728c04488fbfffcafda9f99594e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acab8d3f8d1a0dc277c93a9f9dcee99aac9b8ba3cfa4c51821998522469c37715644e8fbac0bfa0ab8cdb808c8303bb61fb48e276217be9770fa83ecf3f90f2234d558885f5abf1808080a0fe137c3a474fbde41d89a59dd76da4c55bf696b86d3af64a55632f76cf30786780808080a06301b39b2ea8a44df8b0356120db64b788e71f52e1d7a6309d0d2e5b86fee7cb80a0da5d8b08dea0c5a4799c0f44d8a24d7cdf209f9b7a5588c1ecafb5361f6b9f07a01b7779e149cadf24d4ffb77ca7e11314b8db7097e4d70b2a173493153ca2e5a080808080")
-	//} else if signalNumber == 194807 {
-	//	return common.FromHex("0xf9097c8302f8f6b904f0f904edf9023ea0f2ddffeefdda4c62e6dd5fe1d489c2afa5b60cef25fbea75d71a97e6237a7b2ea01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d4934794e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acaa065eb3b2f364e19dd070fcbbe25177ea0e0394be1d7f3846de432fab7f514f412a0c67d3e89a146214538d0a11d9fcbf32c8fdd2aec1b08c0d5c980cf9ce90f4b12a070c8149e15fee19ac37cc4c0fcd91b3fa299b2954f4bda0ea1850ea052f862e4b901000000000000000010000020000000000000000000000100000000000002000000000000001000000000000000000000002000000000000000000000800000000000000000200000000000000100000000000002000000804000000000000000002000000000000000000000000000000100000000008000000000000000000000000000000000000000000400000000000000000000000000000000000000000008000000000000002008000000000012000000000008000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000000000000000000000200000000000000090fffffffffffffffffffffffffffffffe8302f8f6837a12008302ef15845a43183696d5830108048650617269747986312e32322e31826c6984120d6b3eb8415efd749e75311427f77a482d57c059aa26aaf5adfeb1dc0c49054ef3b50bdc232edf6d89964d98dfa8b26698de7dee7458381b263f4156122ba17309bd2b710301f902a9f902a6018302ef15b9010000000000000000100000200000000000000000000001000000000000020000000000000010000000000000000000000020000000000000000000008000000000000000002000000000000001000000000000020000008040000000000000000020000000000000000000000000000001000000000080000000000000000000000000000000000000000004000000000000000000000000000000000000000000080000000000000020080000000000120000000000080000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000f9019bf8fb948bf38d4764929064f2d4d3a56520a76ab3df415bf842a055252fa6eee4741b4e24a74a70e9c11fd2c2281df8d6ea13126ff845f7825c89a0f2ddffeefdda4c62e6dd5fe1d489c2afa5b60cef25fbea75d71a97e6237a7b2eb8a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000003000000000000000000000000e8ddc5c7a2d2f0d7a9798459c0104fdf5e987aca00000000000000000000000082e4e61e7f5139ff0a4157a5bc687ef42294c24800000000000000000000000023468db7f4b8ff4924b0709ab7ae0971b3bc8d28f89c9488a34124bfffa27ef3e052c8dd2908e212643771f884a009b714df46e3a39ff284866b80612984e10c731561c157cd03aca91e436808a5a000000000000000000000000023468db7f4b8ff4924b0709ab7ae0971b3bc8d28a00000000000000000000000007b4352a6a703bcf581f30c3259ef0078dfed8d90a0000000000000000000000000743f62ea80bebd2151c190bb611634cfacef12d480b90482f9047ff9023ea0f2ddffeefdda4c62e6dd5fe1d489c2afa5b60cef25fbea75d71a97e6237a7b2ea01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d4934794e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acaa065eb3b2f364e19dd070fcbbe25177ea0e0394be1d7f3846de432fab7f514f412a0c67d3e89a146214538d0a11d9fcbf32c8fdd2aec1b08c0d5c980cf9ce90f4b12a070c8149e15fee19ac37cc4c0fcd91b3fa299b2954f4bda0ea1850ea052f862e4b901000000000000000010000020000000000000000000000100000000000002000000000000001000000000000000000000002000000000000000000000800000000000000000200000000000000100000000000002000000804000000000000000002000000000000000000000000000000100000000008000000000000000000000000000000000000000000400000000000000000000000000000000000000000008000000000000002008000000000012000000000008000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000000000000000000000200000000000000090fffffffffffffffffffffffffffffffe8302f8f6837a12008302ef15845a43183696d5830108048650617269747986312e32322e31826c6984120d6b3eb8415efd749e75311427f77a482d57c059aa26aaf5adfeb1dc0c49054ef3b50bdc232edf6d89964d98dfa8b26698de7dee7458381b263f4156122ba17309bd2b710301f9023ba0ffbe26248da7c29ab616e860abdae2d946995b2f49a53eda3cf491a37e926a2ea01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d493479482e4e61e7f5139ff0a4157a5bc687ef42294c248a02e8491780355b3ad277d61b844f8d3bb5150e09be443fc740da17ebaaa58fc39a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421b901000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000090fffffffffffffffffffffffffffffffe8302f8f7837a120080845a43183a96d5830108048650617269747986312e32322e31826c6984120d6b3fb841d558d52af10da51c559706d4c5d79bec19fe1f9d473a8da0ccde3ab006d1dbe13ad1f69c27b59203ddb31d0f9533dbfb71692609040ca254588b64f20da0a88700")
-	//	last_transition: 194807,87cf344d370c79a20e154b97d4e7fc9b287b91eb823f79955e00b1f3aa9f9d1b,f9097c8302f8f6b904f0f904edf9023ea0f2ddffeefdda4c62e6dd5fe1d489c2afa5b60cef25fbea75d71a97e6237a7b2ea01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d4934794e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acaa065eb3b2f364e19dd070fcbbe25177ea0e0394be1d7f3846de432fab7f514f412a0c67d3e89a146214538d0a11d9fcbf32c8fdd2aec1b08c0d5c980cf9ce90f4b12a070c8149e15fee19ac37cc4c0fcd91b3fa299b2954f4bda0ea1850ea052f862e4b901000000000000000010000020000000000000000000000100000000000002000000000000001000000000000000000000002000000000000000000000800000000000000000200000000000000100000000000002000000804000000000000000002000000000000000000000000000000100000000008000000000000000000000000000000000000000000400000000000000000000000000000000000000000008000000000000002008000000000012000000000008000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000000000000000000000200000000000000090fffffffffffffffffffffffffffffffe8302f8f6837a12008302ef15845a43183696d5830108048650617269747986312e32322e31826c6984120d6b3eb8415efd749e75311427f77a482d57c059aa26aaf5adfeb1dc0c49054ef3b50bdc232edf6d89964d98dfa8b26698de7dee7458381b263f4156122ba17309bd2b710301f902a9f902a6018302ef15b9010000000000000000100000200000000000000000000001000000000000020000000000000010000000000000000000000020000000000000000000008000000000000000002000000000000001000000000000020000008040000000000000000020000000000000000000000000000001000000000080000000000000000000000000000000000000000004000000000000000000000000000000000000000000080000000000000020080000000000120000000000080000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000f9019bf8fb948bf38d4764929064f2d4d3a56520a76ab3df415bf842a055252fa6eee4741b4e24a74a70e9c11fd2c2281df8d6ea13126ff845f7825c89a0f2ddffeefdda4c62e6dd5fe1d489c2afa5b60cef25fbea75d71a97e6237a7b2eb8a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000003000000000000000000000000e8ddc5c7a2d2f0d7a9798459c0104fdf5e987aca00000000000000000000000082e4e61e7f5139ff0a4157a5bc687ef42294c24800000000000000000000000023468db7f4b8ff4924b0709ab7ae0971b3bc8d28f89c9488a34124bfffa27ef3e052c8dd2908e212643771f884a009b714df46e3a39ff284866b80612984e10c731561c157cd03aca91e436808a5a000000000000000000000000023468db7f4b8ff4924b0709ab7ae0971b3bc8d28a00000000000000000000000007b4352a6a703bcf581f30c3259ef0078dfed8d90a0000000000000000000000000743f62ea80bebd2151c190bb611634cfacef12d480b90482f9047ff9023ea0f2ddffeefdda4c62e6dd5fe1d489c2afa5b60cef25fbea75d71a97e6237a7b2ea01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d4934794e8ddc5c7a2d2f0d7a9798459c0104fdf5e987acaa065eb3b2f364e19dd070fcbbe25177ea0e0394be1d7f3846de432fab7f514f412a0c67d3e89a146214538d0a11d9fcbf32c8fdd2aec1b08c0d5c980cf9ce90f4b12a070c8149e15fee19ac37cc4c0fcd91b3fa299b2954f4bda0ea1850ea052f862e4b901000000000000000010000020000000000000000000000100000000000002000000000000001000000000000000000000002000000000000000000000800000000000000000200000000000000100000000000002000000804000000000000000002000000000000000000000000000000100000000008000000000000000000000000000000000000000000400000000000000000000000000000000000000000008000000000000002008000000000012000000000008000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000000000000000000000200000000000000090fffffffffffffffffffffffffffffffe8302f8f6837a12008302ef15845a43183696d5830108048650617269747986312e32322e31826c6984120d6b3eb8415efd749e75311427f77a482d57c059aa26aaf5adfeb1dc0c49054ef3b50bdc232edf6d89964d98dfa8b26698de7dee7458381b263f4156122ba17309bd2b710301f9023ba0ffbe26248da7c29ab616e860abdae2d946995b2f49a53eda3cf491a37e926a2ea01dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d493479482e4e61e7f5139ff0a4157a5bc687ef42294c248a02e8491780355b3ad277d61b844f8d3bb5150e09be443fc740da17ebaaa58fc39a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421b901000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000090fffffffffffffffffffffffffffffffe8302f8f7837a120080845a43183a96d5830108048650617269747986312e32322e31826c6984120d6b3fb841d558d52af10da51c559706d4c5d79bec19fe1f9d473a8da0ccde3ab006d1dbe13ad1f69c27b59203ddb31d0f9533dbfb71692609040ca254588b64f20da0a88700
-	//}
-	//return nil
-	/*
-	   let mut stream = RlpStream::new_list(3);
-	   stream
-	       .append(&signal_number)
-	       .append(&set_proof)
-	       .append(&finality_proof);
-	   stream.out()
-	*/
+	res, err := rlp.EncodeToBytes(EpochTransitionProof{SignalNumber: signalNumber, SetProof: setProof, FinalityProof: finalityProof})
+	if err != nil {
+		panic(err) // encoding of uint and byte strings can't fail
+	}
+	return res
 }
 
+// Seal implements consensus.Engine, signing the block if the engine's signer is the proposer of the current step.
+// If empty steps are enabled, there are no transactions to include and the client can broadcast consensus
+// messages, the proposer broadcasts a signed empty step message instead of sealing the block, until
+// maximumEmptySteps of them are accumulated.
 func (c *AuRa) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+	number := header.Number.Uint64()
+	// Sealing the genesis block is not supported
+	if number == 0 {
+		return fmt.Errorf("sealing of the genesis block is not supported")
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+
+	if number >= c.cfg.EmptyStepsTransition && len(block.Transactions()) == 0 && c.broadcaster() != nil {
+		emptySteps, err := headerEmptySteps(header)
+		if err != nil {
+			return err
+		}
+		if uint64(len(emptySteps)) < c.cfg.MaximumEmptySteps {
+			c.proposeEmptyStep(chain, header, parent)
+			return nil
+		}
+	}
+
+	seal := c.GenerateSeal(chain, header, parent)
+	if seal == nil {
+		return nil
+	}
+	header.Seal = seal
+	select {
+	case results <- block.WithSeal(header):
+	default:
+		log.Warn("[aura] Sealing result is not read by miner", "number", number, "sealhash", c.SealHash(header))
+	}
 	return nil
-	//header := block.Header()
-	//
-	/// Sealing the genesis block is not supported
-	//number := header.Number.Uint64()
-	//if number == 0 {
-	//	return errUnknownBlock
-	//}
-	/// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
-	//if c.config.Period == 0 && len(block.Transactions()) == 0 {
-	//	log.Info("Sealing paused, waiting for transactions")
-	//	return nil
-	//}
-	/// Don't hold the signer fields for the entire sealing procedure
-	//c.lock.RLock()
-	//signer, signFn := c.signer, c.signFn
-	//c.lock.RUnlock()
-	//
-	/// Bail out if we're unauthorized to sign a block
-	//snap, err := c.Snapshot(chain, number-1, header.ParentHash, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//if _, authorized := snap.Signers[signer]; !authorized {
-	//	return ErrUnauthorizedSigner
-	//}
-	/// If we're amongst the recent signers, wait for the next block
-	//for seen, recent := range snap.Recents {
-	//	if recent == signer {
-	//		// Signer is among RecentsRLP, only wait if the current block doesn't shift it out
-	//		if limit := uint64(len(snap.Signers)/2 + 1); number < limit || seen > number-limit {
-	//			log.Info("Signed recently, must wait for others")
-	//			return nil
-	//		}
-	//	}
-	//}
-	/// Sweet, the protocol permits us to sign the block, wait for our time
-	//delay := time.Unix(int64(header.Time), 0).Sub(time.Now()) // nolint: gosimple
-	//if header.Difficulty.Cmp(diffNoTurn) == 0 {
-	//	// It's not our turn explicitly to sign, delay it a bit
-	//	wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
-	//	delay += time.Duration(rand.Int63n(int64(wiggle)))
-	//
-	//	log.Trace("Out-of-turn signing requested", "wiggle", common.PrettyDuration(wiggle))
-	//}
-	/// Sign all the things!
-	//sighash, err := signFn(signer, accounts.MimetypeClique, CliqueRLP(header))
-	//if err != nil {
-	//	return err
-	//}
-	//copy(header.Extra[len(header.Extra)-ExtraSeal:], sighash)
-	/// Wait until sealing is terminated or delay timeout.
-	//log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
-	//go func() {
-	//	select {
-	//	case <-stop:
-	//		return
-	//	case <-time.After(delay):
-	//	}
-	//
-	//	select {
-	//	case results <- block.WithSeal(header):
-	//	default:
-	//		log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
-	//	}
-	//}()
-	//
-	//return nil
 }
 
 func stepProposer(validators ValidatorSet, blockHash common.Hash, step uint64) (common.Address, error) {
@@ -980,116 +1113,277 @@ func stepProposer(validators ValidatorSet, blockHash common.Hash, step uint64) (
 	return validators.getWithCaller(blockHash, uint(step), c)
 }
 
-// GenerateSeal - Attempt to seal the block internally.
-//
-// This operation is synchronous and may (quite reasonably) not be available, in which case
-// `Seal::None` will be returned.
-func (c *AuRa) GenerateSeal(chain consensus.ChainHeaderReader, current, parent *types.Header) []rlp.RawValue {
+// proposerStep - checks that the engine's signer is the proposer of the current step for the header prepared on
+// top of the parent. Returns the steps, the validator set and the signer when it is.
+func (c *AuRa) proposerStep(chain consensus.ChainHeaderReader, current, parent *types.Header) (step, parentStep uint64, validators ValidatorSet, setNumber uint64, signer common.Address, ok bool) {
 	// first check to avoid generating signature most of the time
 	// (but there's still a race to the `compare_exchange`)
 	if !c.step.canPropose.Load() {
-		log.Trace("[engine] Aborting seal generation. Can't propose.")
-		return nil
+		log.Trace("[aura] Aborting seal generation. Can't propose.")
+		return
 	}
-	parentStep, err := headerStep(parent)
-	if err != nil {
-		panic(err)
+	c.lock.RLock()
+	signer, signFn := c.signer, c.signFn
+	c.lock.RUnlock()
+	if signFn == nil {
+		return
 	}
-	step := c.step.inner.inner.Load()
+
+	var err error
+	if parentStep, err = headerStep(parent); err != nil {
+		log.Warn("[aura] Unable to read step of parent", "err", err)
+		return
+	}
+	step = c.step.inner.inner.Load()
 
 	// filter messages from old and future steps and different parents
-	expectedDiff := calculateScore(parentStep, step, 0)
+	emptySteps, err := headerEmptySteps(current)
+	if err != nil {
+		log.Warn("[aura] Unable to read empty steps", "err", err)
+		return
+	}
+	expectedDiff := calculateScore(parentStep, step, uint64(len(emptySteps)))
 	if current.Difficulty.Cmp(expectedDiff.ToBig()) != 0 {
-		log.Debug(fmt.Sprintf("[engine] Aborting seal generation. The step or empty_steps have changed in the meantime. %d != %d", current.Difficulty, expectedDiff))
-		return nil
+		log.Debug(fmt.Sprintf("[aura] Aborting seal generation. The step or empty_steps have changed in the meantime. %d != %d", current.Difficulty, expectedDiff))
+		return
 	}
 
 	if parentStep > step {
-		log.Warn(fmt.Sprintf("[engine] Aborting seal generation for invalid step: %d > %d", parentStep, step))
-		return nil
+		log.Warn(fmt.Sprintf("[aura] Aborting seal generation for invalid step: %d > %d", parentStep, step))
+		return
 	}
 
-	validators, setNumber, err := c.epochSet(chain, current)
+	validators, setNumber, err = c.epochSet(chain, nil, current)
 	if err != nil {
-		log.Warn("[engine] Unable to generate seal", "err", err)
-		return nil
+		log.Warn("[aura] Unable to generate seal", "err", err)
+		return
 	}
 
 	stepProposerAddr, err := stepProposer(validators, current.ParentHash, step)
 	if err != nil {
-		log.Warn("[engine] Unable to get stepProposer", "err", err)
-		return nil
+		log.Warn("[aura] Unable to get stepProposer", "err", err)
+		return
 	}
-	if stepProposerAddr != current.Coinbase {
-		return nil
+	if stepProposerAddr != signer || stepProposerAddr != current.Coinbase {
+		log.Trace("[aura] Not a proposer of the step", "step", step, "proposer", stepProposerAddr)
+		return
 	}
 
 	// this is guarded against by `can_propose` unless the block was signed
 	// on the same step (implies same key) and on a different node.
 	if parentStep == step {
 		log.Warn("Attempted to seal block on the same step as parent. Is this authority sealing with more than one node?")
+		return
+	}
+	return step, parentStep, validators, setNumber, signer, true
+}
+
+// GenerateSeal - Attempt to seal the block internally.
+//
+// This operation is synchronous and may (quite reasonably) not be available, in which case
+// `Seal::None` will be returned.
+func (c *AuRa) GenerateSeal(chain consensus.ChainHeaderReader, current, parent *types.Header) []rlp.RawValue {
+	step, parentStep, validators, setNumber, signer, ok := c.proposerStep(chain, current, parent)
+	if !ok {
+		return nil
+	}
+	var emptyStepsRlp []byte
+	if current.Number.Uint64() >= c.cfg.EmptyStepsTransition {
+		emptyStepsRlp = headerEmptyStepsRaw(current)
+	}
+	signature, err := c.sign(signer, sealHash(current, emptyStepsRlp))
+	if err != nil {
+		log.Warn("[aura] generate_seal: FAIL: Accounts secret key unavailable.", "err", err)
 		return nil
 	}
 
-	_ = setNumber
-	/*
-		signature, err := c.sign(current.bareHash())
-			if err != nil {
-				log.Warn("[engine] generate_seal: FAIL: Accounts secret key unavailable.", "err", err)
-				return nil
-			}
-	*/
+	// only issue the seal if we were the first to reach the compare_exchange.
+	if !c.step.canPropose.CAS(true, false) {
+		return nil
+	}
+	// we can drop all accumulated empty step messages that are
+	// older than the parent step since we're including them in
+	// the seal
+	c.EmptyStepsSet.prune(parentStep)
 
-	/*
-		  // only issue the seal if we were the first to reach the compare_exchange.
-		  if self
-			  .step
-			  .can_propose
-			  .compare_exchange(true, false, AtomicOrdering::SeqCst, AtomicOrdering::SeqCst)
-			  .is_ok()
-		  {
-			  // we can drop all accumulated empty step messages that are
-			  // older than the parent step since we're including them in
-			  // the seal
-			  self.clear_empty_steps(parent_step);
-
-			  // report any skipped primaries between the parent block and
-			  // the block we're sealing, unless we have empty steps enabled
-			  if header.number() < self.empty_steps_transition {
-				  self.report_skipped(header, step, parent_step, &*validators, set_number);
-			  }
-
-			  let mut fields =
-				  vec![encode(&step), encode(&(H520::from(signature).as_bytes()))];
-
-			  if let Some(empty_steps_rlp) = empty_steps_rlp {
-				  fields.push(empty_steps_rlp);
-			  }
-
-			  return Seal::Regular(fields);
-		  }
-	*/
+	// report any skipped primaries between the parent block and
+	// the block we're sealing, unless we have empty steps enabled
+	if current.Number.Uint64() < c.cfg.EmptyStepsTransition {
+		c.reportSkipped(current, step, parentStep, validators, setNumber, signer)
+	}
+
+	seal, err := encodeSeal(step, signature, emptyStepsRlp)
+	if err != nil {
+		log.Warn("[aura] Unable to encode seal", "err", err)
+		return nil
+	}
+	return seal
+}
+
+// reportSkipped - reports benign misbehaviour of the proposers of the steps between the parent block and
+// the block we're sealing
+func (c *AuRa) reportSkipped(header *types.Header, currentStep, parentStep uint64, validators ValidatorSet, setNumber uint64, us common.Address) {
+	// we're building on top of the genesis block so don't do any reporting
+	if header.Number.Uint64() == 1 || currentStep <= parentStep+1 {
+		return
+	}
+	reported := map[common.Address]struct{}{}
+	for step := parentStep + 1; step < currentStep; step++ {
+		skippedPrimary, err := stepProposer(validators, header.ParentHash, step)
+		if err != nil {
+			log.Warn("[aura] Unable to get proposer of skipped step", "step", step, "err", err)
+			return
+		}
+		// Do not report this signer.
+		if skippedPrimary == us {
+			continue
+		}
+		// Stop reporting once validators start repeating.
+		if _, ok := reported[skippedPrimary]; ok {
+			break
+		}
+		reported[skippedPrimary] = struct{}{}
+		log.Trace("[aura] Reporting benign misbehaviour (cause: skipped step)", "step", step, "validator", skippedPrimary)
+		validators.reportBenign(skippedPrimary, setNumber, header.Number.Uint64())
+	}
+}
+
+// proposeEmptyStep - signs and broadcasts the empty step message for the current step, if the engine's signer
+// is the proposer of it
+func (c *AuRa) proposeEmptyStep(chain consensus.ChainHeaderReader, current, parent *types.Header) {
+	step, _, _, _, signer, ok := c.proposerStep(chain, current, parent)
+	if !ok {
+		return
+	}
+	if !c.step.canPropose.CAS(true, false) {
+		return
+	}
+	message, err := EmptyStepRlp(step, parent.Hash())
+	if err != nil {
+		log.Warn("[aura] Unable to encode empty step", "err", err)
+		return
+	}
+	signature, err := c.sign(signer, crypto.Keccak256Hash(message))
+	if err != nil {
+		log.Warn("[aura] generate_empty_step: FAIL: Accounts secret key unavailable.", "err", err)
+		return
+	}
+	emptyStep := &EmptyStep{signature: signature, step: step, parentHash: parent.Hash()}
+	c.EmptyStepsSet.insert(emptyStep)
+
+	msg, err := EmptyStepFullRlp(signature, message)
+	if err != nil {
+		log.Warn("[aura] Unable to encode empty step", "err", err)
+		return
+	}
+	if broadcaster := c.broadcaster(); broadcaster != nil {
+		broadcaster.BroadcastConsensusMessage(msg)
+	}
+	log.Debug("[aura] Broadcasting empty step message", "step", step, "parent", parent.Hash())
+}
+
+// broadcaster - the registered client, if it can deliver consensus messages to the other validators
+func (c *AuRa) broadcaster() ConsensusMessageBroadcaster {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	broadcaster, _ := c.client.(ConsensusMessageBroadcaster)
+	return broadcaster
+}
+
+// HandleMessage - handles the consensus message (empty step) received from other validator: the message is
+// accumulated to be included into the seal of the next block. Analog of handle_message of OE.
+func (c *AuRa) HandleMessage(msg []byte) error {
+	emptyStep, err := decodeEmptyStep(msg)
+	if err != nil {
+		return err
+	}
+	if emptyStep.step > c.step.inner.inner.Load()+1 {
+		return fmt.Errorf("empty step from the future: %d", emptyStep.step)
+	}
+	ok, err := emptyStep.verify(c.cfg.Validators)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("invalid signature of empty step %d", emptyStep.step)
+	}
+	c.EmptyStepsSet.insert(emptyStep)
 	return nil
 }
 
+func (c *AuRa) sign(signer common.Address, hash common.Hash) ([]byte, error) {
+	c.lock.RLock()
+	signFn := c.signFn
+	c.lock.RUnlock()
+	return signFn(signer, accounts.MimetypeAuRa, hash[:])
+}
+
+// sealHash - the hash signed by the block proposer: the hash of the header without seal or, if empty steps are
+// included, the hash of it concatenated with the empty steps
+func sealHash(header *types.Header, emptyStepsRlp []byte) common.Hash {
+	bareHash := bareHeaderHash(header)
+	if emptyStepsRlp == nil {
+		return bareHash
+	}
+	return crypto.Keccak256Hash(bareHash[:], emptyStepsRlp)
+}
+
+// bareHeaderHash - hash of the header without seal fields
+func bareHeaderHash(header *types.Header) common.Hash {
+	bare := types.CopyHeader(header)
+	bare.Seal, bare.WithSeal = nil, true
+	return bare.Hash()
+}
+
+// encodeSeal - seal fields of the header: step, signature and, if not nil, empty steps
+func encodeSeal(step uint64, signature []byte, emptyStepsRlp []byte) ([]rlp.RawValue, error) {
+	stepRlp, err := rlp.EncodeToBytes(step)
+	if err != nil {
+		return nil, err
+	}
+	signatureRlp, err := rlp.EncodeToBytes(signature)
+	if err != nil {
+		return nil, err
+	}
+	seal := []rlp.RawValue{stepRlp, signatureRlp}
+	if emptyStepsRlp != nil {
+		seal = append(seal, emptyStepsRlp)
+	}
+	return seal, nil
+}
+
 // epochSet fetch correct validator set for epoch at header, taking into account
 // finality of previous transitions.
-func (c *AuRa) epochSet(chain consensus.ChainHeaderReader, h *types.Header) (ValidatorSet, uint64, error) {
-	if c.cfg.ImmediateTransitions {
-		return c.cfg.Validators, h.Number.Uint64(), nil
+// If EpochReader is nil, only the already zoomed epoch can be used.
+func (c *AuRa) epochSet(chain consensus.ChainHeaderReader, e consensus.EpochReader, h *types.Header) (ValidatorSet, uint64, error) {
+	// the first block belongs to the genesis epoch, its set is the one of the spec
+	if c.cfg.ImmediateTransitions || h.Number.Uint64() == 1 {
+		setNumber := h.Number.Uint64()
+		if !c.cfg.ImmediateTransitions {
+			setNumber = 0
+		}
+		if multi, ok := c.cfg.Validators.(*Multi); ok {
+			_, set := multi.correctSetByNumber(h.Number.Uint64() - 1)
+			return set, setNumber, nil
+		}
+		return c.cfg.Validators, setNumber, nil
 	}
 
-	finalityChecker, epochTransitionNumber, ok := c.EpochManager.zoomToAfter(chain, c.cfg.Validators, h.ParentHash)
+	c.EpochManager.mu.Lock()
+	defer c.EpochManager.mu.Unlock()
+	finalityChecker, epochTransitionNumber, ok, err := c.EpochManager.zoomToAfter(chain, e, c.cfg.Validators, h.ParentHash)
+	if err != nil {
+		return nil, 0, err
+	}
 	if !ok {
 		return nil, 0, fmt.Errorf("unable to zoomToAfter to epoch")
 	}
 	return finalityChecker.signers, epochTransitionNumber, nil
 }
 
-//nolint
 func headerStep(current *types.Header) (val uint64, err error) {
 	if len(current.Seal) < 1 {
-		panic("was either checked with verify_block_basic or is genesis; has 2 fields; qed (Make sure the spec file has a correct genesis seal)")
+		return 0, fmt.Errorf("block %d has no step in the seal (make sure the spec file has a correct genesis seal)", current.Number.Uint64())
 	}
 	err = rlp.Decode(bytes.NewReader(current.Seal[0]), &val)
 	if err != nil {
@@ -1131,8 +1425,9 @@ func calculateScore(parentStep, currentStep, currentEmptySteps uint64) *uint256.
 	return res
 }
 
+// SealHash returns the hash of a block prior to it being sealed: the hash of the header without seal fields.
 func (c *AuRa) SealHash(header *types.Header) common.Hash {
-	return clique.SealHash(header)
+	return bareHeaderHash(header)
 }
 
 // Close implements consensus.Engine. It's a noop for clique as there are no background threads.
@@ -1154,18 +1449,16 @@ func (c *AuRa) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 	}
 }
 
-//nolint
+// emptySteps - accumulated empty step messages with fromStep < step < toStep on top of the given parent, sorted
 func (c *AuRa) emptySteps(fromStep, toStep uint64, parentHash common.Hash) []EmptyStep {
-	from := EmptyStep{step: fromStep + 1, parentHash: parentHash}
-	to := EmptyStep{step: toStep}
 	res := []EmptyStep{}
-	if to.LessOrEqual(&from) {
+	if toStep <= fromStep+1 {
 		return res
 	}
 
 	c.EmptyStepsSet.Sort()
 	c.EmptyStepsSet.ForEach(func(i int, step *EmptyStep) {
-		if step.Less(&from) || (&to).Less(step) {
+		if step.step <= fromStep || step.step >= toStep {
 			return
 		}
 		if step.parentHash != parentHash {
@@ -1180,6 +1473,21 @@ func (c *AuRa) emptySteps(fromStep, toStep uint64, parentHash common.Hash) []Emp
 // of the static blockReward plus a reward for each included uncle (if any). Individual
 // uncle rewards are also returned in an array.
 func AccumulateRewards(_ *params.ChainConfig, aura *AuRa, header *types.Header, _ []*types.Header, syscall consensus.SystemCall) (beneficiaries []common.Address, rewardKind []aurainterfaces.RewardKind, rewards []*uint256.Int, err error) {
+	// the authors of the empty steps included in the seal are rewarded as well
+	if header.Number.Uint64() >= aura.cfg.EmptyStepsTransition {
+		emptySteps, err := headerEmptySteps(header)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for i := range emptySteps {
+			author, err := emptySteps[i].author()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			beneficiaries = append(beneficiaries, author)
+			rewardKind = append(rewardKind, aurainterfaces.RewardEmptyStep)
+		}
+	}
 	beneficiaries = append(beneficiaries, header.Coinbase)
 	rewardKind = append(rewardKind, aurainterfaces.RewardAuthor)
 
@@ -1193,8 +1501,11 @@ func AccumulateRewards(_ *params.ChainConfig, aura *AuRa, header *types.Header,
 		rewardContractAddress = c
 	}
 	if foundContract {
-		beneficiaries, rewards = callBlockRewardAbi(rewardContractAddress.address, syscall, beneficiaries, rewardKind)
-		rewardKind = rewardKind[:len(beneficiaries)]
+		beneficiaries, rewards, err = callBlockRewardAbi(rewardContractAddress.address, syscall, beneficiaries, rewardKind)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		rewardKind = make([]aurainterfaces.RewardKind, len(beneficiaries))
 		for i := 0; i < len(rewardKind); i++ {
 			rewardKind[i] = aurainterfaces.RewardExternal
 		}
@@ -1225,33 +1536,53 @@ func AccumulateRewards(_ *params.ChainConfig, aura *AuRa, header *types.Header,
 	return
 }
 
-func callBlockRewardAbi(contractAddr common.Address, syscall consensus.SystemCall, beneficiaries []common.Address, rewardKind []aurainterfaces.RewardKind) ([]common.Address, []*uint256.Int) {
+// callBlockRewardAbi - calls "reward" of the block reward contract, which returns the receivers of the rewards
+// and the amounts, the lengths of them must match
+func callBlockRewardAbi(contractAddr common.Address, syscall consensus.SystemCall, beneficiaries []common.Address, rewardKind []aurainterfaces.RewardKind) ([]common.Address, []*uint256.Int, error) {
 	castedKind := make([]uint16, len(rewardKind))
 	for i := range rewardKind {
 		castedKind[i] = uint16(rewardKind[i])
 	}
-	packed, err := blockRewardAbi().Pack("reward", beneficiaries, castedKind)
+	packed, err := blockRewardAbi.Pack("reward", beneficiaries, castedKind)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 	out, err := syscall(contractAddr, packed)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 	if len(out) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
-	res, err := blockRewardAbi().Unpack("reward", out)
+	res, err := blockRewardAbi.Unpack("reward", out)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
+	}
+	receivers, ok := res[0].([]common.Address)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected receivers of block reward: %T", res[0])
+	}
+	amounts, ok := res[1].([]*big.Int)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected rewards of block reward: %T", res[1])
+	}
+	if len(receivers) != len(amounts) {
+		return nil, nil, fmt.Errorf("invalid block reward: %d receivers, %d rewards", len(receivers), len(amounts))
 	}
-	_ = res[0]
-	_ = res[1]
-	return nil, nil
+	rewards := make([]*uint256.Int, len(amounts))
+	for i := range amounts {
+		var overflow bool
+		if rewards[i], overflow = uint256.FromBig(amounts[i]); overflow {
+			return nil, nil, fmt.Errorf("block reward overflow: %d", amounts[i])
+		}
+	}
+	return receivers, rewards, nil
 }
 
-func blockRewardAbi() abi.ABI {
-	a, err := abi.JSON(bytes.NewReader(contracts.BlockReward))
+var blockRewardAbi = mustParseAbi(contracts.BlockReward)
+
+func mustParseAbi(abiJson []byte) abi.ABI {
+	a, err := abi.JSON(bytes.NewReader(abiJson))
 	if err != nil {
 		panic(err)
 	}
@@ -1261,18 +1592,19 @@ func blockRewardAbi() abi.ABI {
 // An empty step message that is included in a seal, the only difference is that it doesn't include
 // the `parent_hash` in order to save space. The included signature is of the original empty step
 // message, which can be reconstructed by using the parent hash of the block in which this sealed
-// empty message is inc    luded.
-//nolint
+// empty message is included.
 type SealedEmptyStep struct {
-	signature []byte // H520
-	step      uint64
+	Signature []byte // H520
+	Step      uint64
 }
 
-/*
-// extracts the empty steps from the header seal. should only be called when there are 3 fields in the seal
-// (i.e. header.number() >= self.empty_steps_transition).
+// extracts the empty steps from the header seal. Headers with less than 3 fields in the seal
+// (i.e. header.number() < self.empty_steps_transition) have no empty steps.
 func headerEmptySteps(header *types.Header) ([]EmptyStep, error) {
 	s := headerEmptyStepsRaw(header)
+	if s == nil {
+		return nil, nil
+	}
 	sealedSteps := []SealedEmptyStep{}
 	err := rlp.DecodeBytes(s, &sealedSteps)
 	if err != nil {
@@ -1287,21 +1619,28 @@ func headerEmptySteps(header *types.Header) ([]EmptyStep, error) {
 
 func newEmptyStepFromSealed(step SealedEmptyStep, parentHash common.Hash) EmptyStep {
 	return EmptyStep{
-		signature:  step.signature,
-		step:       step.step,
+		signature:  step.Signature,
+		step:       step.Step,
 		parentHash: parentHash,
 	}
 }
 
-// extracts the raw empty steps vec from the header seal. should only be called when there are 3 fields in the seal
-// (i.e. header.number() >= self.empty_steps_transition)
+// extracts the raw empty steps vec from the header seal, nil if there are less than 3 fields in the seal
 func headerEmptyStepsRaw(header *types.Header) []byte {
 	if len(header.Seal) < 3 {
-		panic("was checked with verify_block_basic; has 3 fields; qed")
+		return nil
 	}
 	return header.Seal[2]
 }
-*/
+
+// sealedEmptyStepsRlp - the third field of the seal: rlp list of the empty steps without parent hash
+func sealedEmptyStepsRlp(emptySteps []EmptyStep) ([]byte, error) {
+	sealed := make([]SealedEmptyStep, len(emptySteps))
+	for i := range emptySteps {
+		sealed[i] = SealedEmptyStep{Signature: emptySteps[i].signature, Step: emptySteps[i].step}
+	}
+	return rlp.EncodeToBytes(sealed)
+}
 
 // A message broadcast by authorities when it's their turn to seal a block but there are no
 // transactions. Other authorities accumulate these messages and later include them in the seal as
@@ -1325,48 +1664,31 @@ type EmptyStep struct {
 }
 
 func (s *EmptyStep) Less(other *EmptyStep) bool {
-	if s.step < other.step {
-		return true
+	if s.step != other.step {
+		return s.step < other.step
 	}
-	if bytes.Compare(s.parentHash[:], other.parentHash[:]) < 0 {
-		return true
+	if c := bytes.Compare(s.parentHash[:], other.parentHash[:]); c != 0 {
+		return c < 0
 	}
-	if bytes.Compare(s.signature, other.signature) < 0 {
-		return true
-	}
-	return false
+	return bytes.Compare(s.signature, other.signature) < 0
 }
 func (s *EmptyStep) LessOrEqual(other *EmptyStep) bool {
-	if s.step <= other.step {
-		return true
-	}
-	if bytes.Compare(s.parentHash[:], other.parentHash[:]) <= 0 {
-		return true
-	}
-	if bytes.Compare(s.signature, other.signature) <= 0 {
-		return true
-	}
-	return false
+	return !other.Less(s)
 }
 
 // Returns `true` if the message has a valid signature by the expected proposer in the message's step.
-func (s *EmptyStep) verify(validators ValidatorSet) (bool, error) { //nolint
-	//sRlp, err := EmptyStepRlp(s.step, s.parentHash)
-	//if err != nil {
-	//	return false, err
-	//}
-	//message := crypto.Keccak256(sRlp)
-
-	/*
-		let correct_proposer = step_proposer(validators, &self.parent_hash, self.step);
-
-		publickey::verify_address(&correct_proposer, &self.signature.into(), &message)
-		.map_err(|e| e.into())
-	*/
-	return true, nil
+func (s *EmptyStep) verify(validators ValidatorSet) (bool, error) {
+	author, err := s.author()
+	if err != nil {
+		return false, err
+	}
+	correctProposer, err := stepProposer(validators, s.parentHash, s.step)
+	if err != nil {
+		return false, err
+	}
+	return author == correctProposer, nil
 }
 
-//nolint
 func (s *EmptyStep) author() (common.Address, error) {
 	sRlp, err := EmptyStepRlp(s.step, s.parentHash)
 	if err != nil {
@@ -1407,19 +1729,52 @@ func (s *EmptyStepSet) ForEach(f func(int, *EmptyStep)) {
 	}
 }
 
-func EmptyStepFullRlp(signature []byte, emptyStepRlp []byte) ([]byte, error) {
-	type A struct {
-		s []byte
-		r []byte
+// insert - adds the message to the set, duplicates are ignored
+func (s *EmptyStepSet) insert(step *EmptyStep) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, el := range s.list {
+		if !el.Less(step) && !step.Less(el) {
+			return
+		}
 	}
+	s.list = append(s.list, step)
+}
 
-	return rlp.EncodeToBytes(A{s: signature, r: emptyStepRlp})
+// prune - drops the messages of the steps up to the given one (inclusive)
+func (s *EmptyStepSet) prune(step uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	list := s.list[:0]
+	for _, el := range s.list {
+		if el.step > step {
+			list = append(list, el)
+		}
+	}
+	s.list = list
 }
 
+// EmptyStepFullRlp - the empty step message as it's broadcast: rlp list of the signature and the signed message
+func EmptyStepFullRlp(signature []byte, emptyStepRlp []byte) ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{signature, rlp.RawValue(emptyStepRlp)})
+}
+
+// EmptyStepRlp - the signed part of the empty step message: rlp list of the step and the parent hash
 func EmptyStepRlp(step uint64, parentHash common.Hash) ([]byte, error) {
-	type A struct {
-		s uint64
-		h common.Hash
+	return rlp.EncodeToBytes([]interface{}{step, parentHash})
+}
+
+// decodeEmptyStep - decodes the broadcast empty step message, see EmptyStepFullRlp
+func decodeEmptyStep(msg []byte) (*EmptyStep, error) {
+	var full struct {
+		Signature []byte
+		Message   struct {
+			Step       uint64
+			ParentHash common.Hash
+		}
+	}
+	if err := rlp.DecodeBytes(msg, &full); err != nil {
+		return nil, fmt.Errorf("invalid empty step message: %w", err)
 	}
-	return rlp.EncodeToBytes(A{s: step, h: parentHash})
+	return &EmptyStep{signature: full.Signature, step: full.Message.Step, parentHash: full.Message.ParentHash}, nil
 }
@@ -2,21 +2,36 @@ package aura_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/consensus/aura"
 	"github.com/ledgerwatch/erigon/consensus/aura/test"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/stages"
 	"github.com/stretchr/testify/require"
 )
 
+func TestMain(m *testing.M) {
+	// AuRa headers carry the step and the signature in the seal fields
+	os.Setenv("HEADERS_SEAL", "true")
+	os.Exit(m.Run())
+}
+
 /*
  #[test]
     fn block_reward_contract() {
@@ -187,3 +202,222 @@ func TestRewardContract(t *testing.T) {
 	              )
 	*/
 }
+
+// listClient - client of the list validator set, which can't broadcast consensus messages
+type listClient struct{}
+
+func (listClient) CallAtBlockHash(common.Hash, common.Address, []byte) ([]byte, error) {
+	return nil, fmt.Errorf("no contracts in the list validator set")
+}
+func (listClient) CallAtLatestBlock(common.Address, []byte) ([]byte, error) {
+	return nil, fmt.Errorf("no contracts in the list validator set")
+}
+func (listClient) Transact(common.Address, []byte) error { return nil }
+
+// validatorClient - delivers the empty step messages of one validator to the engines of the others
+type validatorClient struct {
+	listClient
+	others []*aura.AuRa
+}
+
+func (c *validatorClient) BroadcastConsensusMessage(msg []byte) {
+	for _, engine := range c.others {
+		if err := engine.HandleMessage(msg); err != nil {
+			panic(err)
+		}
+	}
+}
+
+type validatorNode struct {
+	key    *ecdsa.PrivateKey
+	engine *aura.AuRa
+	m      *stages.MockSentry
+}
+
+// newValidatorNetwork - in-process network of validators of the list set, each one with own chain and engine
+func newValidatorNetwork(t *testing.T, n int, extraParams map[string]interface{}) []*validatorNode {
+	keys := make([]*ecdsa.PrivateKey, n)
+	addrs := make([]common.Address, n)
+	alloc := core.GenesisAlloc{}
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i], addrs[i] = key, crypto.PubkeyToAddress(key.PublicKey)
+		alloc[addrs[i]] = core.GenesisAccount{Balance: big.NewInt(1_000_000_000)}
+	}
+	engineParams := map[string]interface{}{
+		"stepDuration":         1,
+		"startStep":            0, // steps are switched by the test
+		"validators":           map[string]interface{}{"list": addrs},
+		"immediateTransitions": false,
+	}
+	for k, v := range extraParams {
+		engineParams[k] = v
+	}
+	engineParamsJson, err := json.Marshal(engineParams)
+	require.NoError(t, err)
+
+	sealRlp, err := rlp.EncodeToBytes([]interface{}{uint64(0), make([]byte, crypto.SignatureLength)})
+	require.NoError(t, err)
+	gspec := &core.Genesis{
+		Config: &params.ChainConfig{
+			ChainID:             big.NewInt(1337),
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			Aura:                &params.AuRaConfig{},
+		},
+		SealRlp:    sealRlp,
+		GasLimit:   0x222222,
+		Difficulty: big.NewInt(0x20000),
+		Alloc:      alloc,
+	}
+
+	nodes := make([]*validatorNode, n)
+	for i := range nodes {
+		engine, err := aura.NewAuRa(gspec.Config.Aura, kv.NewTestKV(t), addrs[i], engineParamsJson)
+		require.NoError(t, err)
+		key := keys[i]
+		engine.Authorize(addrs[i], func(_ common.Address, _ string, message []byte) ([]byte, error) {
+			return crypto.Sign(message, key)
+		})
+		nodes[i] = &validatorNode{key: key, engine: engine, m: stages.MockWithEverything(t, gspec, key, ethdb.DefaultStorageMode, engine)}
+	}
+	for i := range nodes {
+		client := &validatorClient{}
+		for j := range nodes {
+			if j != i {
+				client.others = append(client.others, nodes[j].engine)
+			}
+		}
+		nodes[i].engine.RegisterClient(client)
+	}
+	return nodes
+}
+
+// mine - runs the mining stages of the node, returns the sealed block or nil if the node didn't seal one
+func (v *validatorNode) mine(t *testing.T) *types.Block {
+	require.NoError(t, stages.MiningStep(v.m.Ctx, v.m.DB, v.m.MiningSync))
+	<-v.m.PendingBlocks
+	select {
+	case b := <-v.m.MinedBlocks:
+		return b
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func insertToAll(t *testing.T, nodes []*validatorNode, b *types.Block) {
+	for _, v := range nodes {
+		require.NoError(t, v.m.InsertChain(&core.ChainPack{Length: 1, Headers: []*types.Header{b.Header()}, Blocks: []*types.Block{b}, TopBlock: b}))
+	}
+}
+
+func sealSigner(t *testing.T, engine *aura.AuRa, header *types.Header) common.Address {
+	var sig []byte
+	require.NoError(t, rlp.DecodeBytes(header.Seal[1], &sig))
+	hash := engine.SealHash(header)
+	if len(header.Seal) > 2 { // signature covers the empty steps as well
+		hash = crypto.Keccak256Hash(hash[:], header.Seal[2])
+	}
+	pub, err := crypto.SigToPub(hash[:], sig)
+	require.NoError(t, err)
+	return crypto.PubkeyToAddress(*pub)
+}
+
+func TestSealingAndFinality(t *testing.T) {
+	nodes := newValidatorNetwork(t, 3, nil)
+
+	for number := uint64(1); number <= 6; number++ {
+		for _, v := range nodes {
+			require.True(t, v.engine.Step())
+		}
+		// the step equals to the block number, validators propose in round robin
+		proposer := nodes[number%3]
+		other := nodes[(number+1)%3]
+		require.Nil(t, other.mine(t), "validator sealed a block out of its step")
+
+		b := proposer.mine(t)
+		require.NotNil(t, b, "proposer of step %d didn't seal a block", number)
+		require.Equal(t, number, b.NumberU64())
+		require.Equal(t, proposer.m.Address, b.Coinbase())
+		require.Equal(t, proposer.m.Address, sealSigner(t, proposer.engine, b.Header()))
+		// no second block in the same step
+		require.Nil(t, proposer.mine(t))
+
+		insertToAll(t, nodes, b)
+		// signatures of 2 out of 3 validators finalize the parent
+		for _, v := range nodes {
+			finalized, _ := v.engine.FinalizedBlock()
+			require.Equal(t, number-1, finalized)
+		}
+	}
+}
+
+func TestSealingEmptySteps(t *testing.T) {
+	nodes := newValidatorNetwork(t, 3, map[string]interface{}{
+		"emptyStepsTransition": "1",
+		"maximumEmptySteps":    "2",
+		"blockReward":          "10",
+	})
+	for _, v := range nodes {
+		require.True(t, v.engine.Step())
+	}
+	// there are no transactions: proposers of the steps 1 and 2 broadcast empty steps instead of blocks
+	require.Nil(t, nodes[1].mine(t))
+	for _, v := range nodes {
+		require.True(t, v.engine.Step())
+	}
+	require.Nil(t, nodes[2].mine(t))
+	for _, v := range nodes {
+		require.True(t, v.engine.Step())
+	}
+	// the limit of empty steps is reached, the block is sealed with them
+	b := nodes[0].mine(t)
+	require.NotNil(t, b)
+	require.Equal(t, 3, len(b.Header().Seal))
+	var emptySteps []aura.SealedEmptyStep
+	require.NoError(t, rlp.DecodeBytes(b.Header().Seal[2], &emptySteps))
+	require.Equal(t, 2, len(emptySteps))
+	require.Equal(t, uint64(1), emptySteps[0].Step)
+	require.Equal(t, uint64(2), emptySteps[1].Step)
+	require.Equal(t, nodes[0].m.Address, sealSigner(t, nodes[0].engine, b.Header()))
+
+	insertToAll(t, nodes, b)
+	// authors of empty steps are rewarded as well as the block author
+	for _, v := range nodes {
+		err := v.m.DB.View(context.Background(), func(tx ethdb.Tx) error {
+			for _, validator := range nodes {
+				var acc accounts.Account
+				ok, err := rawdb.ReadAccount(tx, validator.m.Address, &acc)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, uint64(1_000_000_010), acc.Balance.Uint64())
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestSealingWithoutEmptyStepsBroadcast(t *testing.T) {
+	nodes := newValidatorNetwork(t, 3, map[string]interface{}{
+		"emptyStepsTransition": "1",
+		"maximumEmptySteps":    "2",
+	})
+	for _, v := range nodes {
+		v.engine.RegisterClient(listClient{})
+		require.True(t, v.engine.Step())
+	}
+	// empty steps can't be delivered to the other validators, so the block is sealed without transactions
+	b := nodes[1].mine(t)
+	require.NotNil(t, b)
+	require.Equal(t, 0, len(b.Transactions()))
+	require.Equal(t, nodes[1].m.Address, sealSigner(t, nodes[1].engine, b.Header()))
+	insertToAll(t, nodes, b)
+}
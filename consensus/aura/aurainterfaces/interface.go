@@ -10,14 +10,6 @@ type BlockRewardABI interface {
 	Reward(benefactors []common.Address, kind []RewardKind) ([]common.Address, []*uint256.Int, error)
 }
 
-type abiDecoder func([]byte, interface{}) error
-
-// see openethereum/crates/ethcore/res/contracts/validator_set.json
-type ValidatorSetABI interface {
-	GetValidators() ([]byte, abiDecoder)
-	ShouldValidatorReport(ourAddr, maliciousValidatorAddress common.Address, blockNum uint64) ([]byte, abiDecoder)
-}
-
 // RewardKind - The kind of block reward.
 // Depending on the consensus engine the allocated block reward might have
 // different semantics which could lead e.g. to different reward values.
@@ -22,7 +22,7 @@ import (
 
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon/common"
-	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/common/math"
 	"github.com/ledgerwatch/erigon/common/u256"
 )
 
@@ -52,14 +52,10 @@ func newValidatorSetFromJson(j *ValidatorSetJson, posdaoTransition *uint64) Vali
 		return &SimpleList{validators: j.List}
 	}
 	if j.SafeContract != nil {
-		return &ValidatorSafeContract{contractAddress: *j.SafeContract, posdaoTransition: posdaoTransition}
+		return NewValidatorSafeContract(*j.SafeContract, posdaoTransition, nil)
 	}
 	if j.Contract != nil {
-		return &ValidatorContract{
-			contractAddress:  *j.SafeContract,
-			validators:       ValidatorSafeContract{contractAddress: *j.SafeContract, posdaoTransition: posdaoTransition},
-			posdaoTransition: posdaoTransition,
-		}
+		return NewValidatorContract(*j.Contract, posdaoTransition, nil)
 	}
 	if j.Multi != nil {
 		l := map[uint64]ValidatorSet{}
@@ -79,11 +75,11 @@ type JsonSpec struct {
 
 	// Starting step. Determined automatically if not specified.
 	// To be used for testing only.
-	StartStep               *uint64         `json:"startStep"`
-	ValidateScoreTransition *uint64         `json:"validateScoreTransition"` // Block at which score validation should start.
-	ValidateStepTransition  *uint64         `json:"validateStepTransition"`  // Block from which monotonic steps start.
-	ImmediateTransitions    *bool           `json:"immediateTransitions"`    // Whether transitions should be immediate.
-	BlockReward             *hexutil.Uint64 `json:"blockReward"`             // Reward per block in wei.
+	StartStep               *uint64              `json:"startStep"`
+	ValidateScoreTransition *uint64              `json:"validateScoreTransition"` // Block at which score validation should start.
+	ValidateStepTransition  *uint64              `json:"validateStepTransition"`  // Block from which monotonic steps start.
+	ImmediateTransitions    *bool                `json:"immediateTransitions"`    // Whether transitions should be immediate.
+	BlockReward             *math.HexOrDecimal64 `json:"blockReward"`             // Reward per block in wei.
 	// Block at which the block reward contract should start being used. This option allows one to
 	// add a single block reward contract transition and is compatible with the multiple address
 	// option `block_reward_contract_transitions` below.
@@ -108,6 +104,10 @@ type JsonSpec struct {
 	MaximumUncleCountTransition *uint64 `json:"maximumUncleCountTransition"`
 	// Maximum number of accepted uncles.
 	MaximumUncleCount *uint `json:"maximumUncleCount"`
+	// Block at which empty step messages should start.
+	EmptyStepsTransition *math.HexOrDecimal64 `json:"emptyStepsTransition"`
+	// Maximum number of accepted empty steps.
+	MaximumEmptySteps *math.HexOrDecimal64 `json:"maximumEmptySteps"`
 	// Strict validation of empty steps transition block.
 	StrictEmptyStepsTransition *uint `json:"strictEmptyStepsTransition"`
 	// The random number contract's address, or a map of contract transitions.
@@ -177,6 +177,10 @@ type AuthorityRoundParams struct {
 	MaximumUncleCountTransition uint64
 	// Number of accepted uncles.
 	MaximumUncleCount uint
+	// Empty step messages transition block.
+	EmptyStepsTransition uint64
+	// Maximum number of accepted empty steps.
+	MaximumEmptySteps uint64
 	// Transition block to strict empty steps validation.
 	StrictEmptyStepsTransition uint64
 	// If set, enables random number contract integration. It maps the transition block to the contract address.
@@ -259,6 +263,17 @@ func FromJson(jsonParams JsonSpec) (AuthorityRoundParams, error) {
 	if jsonParams.MaximumUncleCountTransition != nil {
 		params.MaximumUncleCountTransition = *jsonParams.MaximumUncleCountTransition
 	}
+	params.EmptyStepsTransition = math.MaxUint64
+	if jsonParams.EmptyStepsTransition != nil {
+		params.EmptyStepsTransition = uint64(*jsonParams.EmptyStepsTransition)
+		if params.EmptyStepsTransition < 1 {
+			params.EmptyStepsTransition = 1
+		}
+	}
+	params.MaximumEmptySteps = math.MaxUint64
+	if jsonParams.MaximumEmptySteps != nil {
+		params.MaximumEmptySteps = uint64(*jsonParams.MaximumEmptySteps)
+	}
 
 	if jsonParams.BlockReward == nil {
 		params.BlockReward = append(params.BlockReward, BlockReward{blockNum: 0, amount: u256.Num0})
@@ -6,3 +6,9 @@ import (
 
 //go:embed block_reward.json
 var BlockReward []byte
+
+//go:embed validator_set.json
+var ValidatorSet []byte
+
+//go:embed validator_report.json
+var ValidatorReport []byte
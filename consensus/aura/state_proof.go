@@ -0,0 +1,237 @@
+package aura
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// StateProof - state read by a call of a contract at the block, enough to repeat the call without the database.
+// Analog of the state items of the proofs of OE, which are trie nodes of the accessed accounts and storage.
+// The trie of the block is not built yet when the proof is made during execution, so the items are plain values.
+type StateProof struct {
+	BlockNumber uint64
+	Accounts    []ProofAccount
+	Storage     []ProofStorage
+}
+
+type ProofAccount struct {
+	Address     common.Address
+	Exists      bool
+	Nonce       uint64
+	Balance     *big.Int
+	Incarnation uint64
+	Code        []byte
+}
+
+type ProofStorage struct {
+	Address common.Address
+	Key     common.Hash
+	Value   []byte
+}
+
+// stateProver - call of the contract on the state of the block, returning the proof of the state read by the call
+type stateProver func(contract common.Address, data []byte) ([]byte, *StateProof, error)
+
+// proverAt - prover of calls on a copy of the state of the block
+func proverAt(cc *params.ChainConfig, ibs *state.IntraBlockState, blockNum uint64) stateProver {
+	return func(contract common.Address, data []byte) ([]byte, *StateProof, error) {
+		r := &proofRecorder{ibs: ibs.Copy(), accounts: map[common.Address]*ProofAccount{}, storage: map[common.Address]map[common.Hash][]byte{}}
+		st := state.New(r)
+		ret, err := proofCall(cc, st, blockNum, contract, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ret, r.proof(blockNum), nil
+	}
+}
+
+// call - repeats the call on the state of the proof, fails if the call reads state which is not in the proof
+func (p *StateProof) call(cc *params.ChainConfig, contract common.Address, data []byte) ([]byte, error) {
+	r := &proofReader{accounts: map[common.Address]*ProofAccount{}, storage: map[common.Address]map[common.Hash][]byte{}}
+	for i := range p.Accounts {
+		r.accounts[p.Accounts[i].Address] = &p.Accounts[i]
+	}
+	for _, s := range p.Storage {
+		if _, ok := r.storage[s.Address]; !ok {
+			r.storage[s.Address] = map[common.Hash][]byte{}
+		}
+		r.storage[s.Address][s.Key] = s.Value
+	}
+	return proofCall(cc, state.New(r), p.BlockNumber, contract, data)
+}
+
+// proofCall - static call of the contract with block context, which depends only on the number of the block,
+// so the prover and the checker of the proof execute the same code
+func proofCall(cc *params.ChainConfig, ibs *state.IntraBlockState, blockNum uint64, contract common.Address, data []byte) ([]byte, error) {
+	const providedGas = 50_000_000
+	blockCtx := vm.BlockContext{
+		CanTransfer: func(db vm.IntraBlockState, addr common.Address, amount *uint256.Int) bool {
+			return !db.GetBalance(addr).Lt(amount)
+		},
+		Transfer:    func(db vm.IntraBlockState, sender, recipient common.Address, amount *uint256.Int, bailout bool) {},
+		GetHash:     func(n uint64) common.Hash { return common.Hash{} },
+		CheckTEVM:   func(common.Hash) (bool, error) { return false, nil },
+		BlockNumber: blockNum,
+		Difficulty:  new(big.Int),
+		GasLimit:    providedGas,
+	}
+	evm := vm.NewEVM(blockCtx, vm.TxContext{}, ibs, cc, vm.Config{})
+	ret, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), contract, data, providedGas)
+	if err != nil {
+		return nil, err
+	}
+	if err = ibs.Error(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// proofRecorder - reader of the state of the block, which records what is read
+type proofRecorder struct {
+	ibs      *state.IntraBlockState
+	accounts map[common.Address]*ProofAccount
+	storage  map[common.Address]map[common.Hash][]byte
+}
+
+func (r *proofRecorder) account(address common.Address) *ProofAccount {
+	if a, ok := r.accounts[address]; ok {
+		return a
+	}
+	a := &ProofAccount{Address: address, Exists: r.ibs.Exist(address), Balance: new(big.Int)}
+	if a.Exists {
+		a.Nonce = r.ibs.GetNonce(address)
+		a.Balance = r.ibs.GetBalance(address).ToBig()
+		a.Incarnation = r.ibs.GetIncarnation(address)
+		a.Code = r.ibs.GetCode(address)
+	}
+	r.accounts[address] = a
+	return a
+}
+
+func (r *proofRecorder) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	return proofAccountData(r.account(address)), nil
+}
+
+func (r *proofRecorder) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	var v uint256.Int
+	r.ibs.GetState(address, key, &v)
+	if _, ok := r.storage[address]; !ok {
+		r.storage[address] = map[common.Hash][]byte{}
+	}
+	r.storage[address][*key] = v.Bytes()
+	return v.Bytes(), nil
+}
+
+func (r *proofRecorder) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	return r.account(address).Code, nil
+}
+
+func (r *proofRecorder) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	return len(r.account(address).Code), nil
+}
+
+func (r *proofRecorder) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return r.account(address).Incarnation, nil
+}
+
+// proof - recorded state, sorted to make the encoding of the proof deterministic
+func (r *proofRecorder) proof(blockNum uint64) *StateProof {
+	p := &StateProof{BlockNumber: blockNum}
+	for _, a := range r.accounts {
+		p.Accounts = append(p.Accounts, *a)
+	}
+	sort.Slice(p.Accounts, func(i, j int) bool {
+		return bytes.Compare(p.Accounts[i].Address[:], p.Accounts[j].Address[:]) < 0
+	})
+	for address, slots := range r.storage {
+		for key, value := range slots {
+			p.Storage = append(p.Storage, ProofStorage{Address: address, Key: key, Value: value})
+		}
+	}
+	sort.Slice(p.Storage, func(i, j int) bool {
+		if c := bytes.Compare(p.Storage[i].Address[:], p.Storage[j].Address[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(p.Storage[i].Key[:], p.Storage[j].Key[:]) < 0
+	})
+	return p
+}
+
+// proofReader - reader of the state of the proof
+type proofReader struct {
+	accounts map[common.Address]*ProofAccount
+	storage  map[common.Address]map[common.Hash][]byte
+}
+
+func (r *proofReader) account(address common.Address) (*ProofAccount, error) {
+	a, ok := r.accounts[address]
+	if !ok {
+		return nil, fmt.Errorf("insufficient proof: no account %x", address)
+	}
+	return a, nil
+}
+
+func (r *proofReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	a, err := r.account(address)
+	if err != nil {
+		return nil, err
+	}
+	return proofAccountData(a), nil
+}
+
+func (r *proofReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	v, ok := r.storage[address][*key]
+	if !ok {
+		return nil, fmt.Errorf("insufficient proof: no storage %x of account %x", *key, address)
+	}
+	return v, nil
+}
+
+func (r *proofReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	a, err := r.account(address)
+	if err != nil {
+		return nil, err
+	}
+	return a.Code, nil
+}
+
+func (r *proofReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	a, err := r.account(address)
+	if err != nil {
+		return 0, err
+	}
+	return len(a.Code), nil
+}
+
+func (r *proofReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	a, err := r.account(address)
+	if err != nil {
+		return 0, err
+	}
+	return a.Incarnation, nil
+}
+
+func proofAccountData(a *ProofAccount) *accounts.Account {
+	if !a.Exists {
+		return nil
+	}
+	acc := accounts.NewAccount()
+	acc.Initialised = true
+	acc.Nonce = a.Nonce
+	acc.Balance.SetFromBig(a.Balance)
+	acc.Incarnation = a.Incarnation
+	if len(a.Code) > 0 {
+		acc.CodeHash = crypto.Keccak256Hash(a.Code)
+	}
+	return &acc
+}
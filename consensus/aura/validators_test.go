@@ -0,0 +1,530 @@
+package aura
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	validatorA  = common.HexToAddress("0x7d577a597b2742b498cb5cf0c26cdcd726d39e6e")
+	validatorB  = common.HexToAddress("0x82a978b3f5962a5b0957d9ee9eef472ee55b42f1")
+	validatorC  = common.HexToAddress("0x0000000000000000000000000000000000000c0c")
+	setContract = common.HexToAddress("0x0000000000000000000000000000000000005e75")
+)
+
+type testChain struct {
+	byHash   map[common.Hash]*types.Header
+	byNumber map[uint64]*types.Header
+}
+
+func newTestChain() *testChain {
+	return &testChain{byHash: map[common.Hash]*types.Header{}, byNumber: map[uint64]*types.Header{}}
+}
+
+func (c *testChain) insert(h *types.Header) {
+	c.byHash[h.Hash()] = h
+	c.byNumber[h.Number.Uint64()] = h
+}
+func (c *testChain) Config() *params.ChainConfig { return params.TestChainConfig }
+func (c *testChain) CurrentHeader() *types.Header {
+	return c.byNumber[uint64(len(c.byNumber)-1)]
+}
+func (c *testChain) GetHeader(hash common.Hash, number uint64) *types.Header { return c.byHash[hash] }
+func (c *testChain) GetHeaderByNumber(number uint64) *types.Header           { return c.byNumber[number] }
+func (c *testChain) GetHeaderByHash(hash common.Hash) *types.Header          { return c.byHash[hash] }
+
+type epochKey struct {
+	num  uint64
+	hash common.Hash
+}
+
+type testEpochs struct {
+	epochs  map[epochKey][]byte
+	pending map[epochKey][]byte
+}
+
+func newTestEpochs() *testEpochs {
+	return &testEpochs{epochs: map[epochKey][]byte{}, pending: map[epochKey][]byte{}}
+}
+
+func (e *testEpochs) GetEpoch(blockHash common.Hash, blockN uint64) ([]byte, error) {
+	return e.epochs[epochKey{blockN, blockHash}], nil
+}
+func (e *testEpochs) PutEpoch(blockHash common.Hash, blockN uint64, proof []byte) error {
+	e.epochs[epochKey{blockN, blockHash}] = proof
+	return nil
+}
+func (e *testEpochs) GetPendingEpoch(blockHash common.Hash, blockN uint64) ([]byte, error) {
+	return e.pending[epochKey{blockN, blockHash}], nil
+}
+func (e *testEpochs) PutPendingEpoch(blockHash common.Hash, blockN uint64, proof []byte) error {
+	e.pending[epochKey{blockN, blockHash}] = proof
+	return nil
+}
+func (e *testEpochs) FindBeforeOrEqualNumber(number uint64) (uint64, common.Hash, []byte, error) {
+	var found *epochKey
+	for k := range e.epochs {
+		k := k
+		if k.num <= number && (found == nil || k.num > found.num) {
+			found = &k
+		}
+	}
+	if found == nil {
+		return 0, common.Hash{}, nil, nil
+	}
+	return found.num, found.hash, e.epochs[*found], nil
+}
+
+type testClient struct {
+	shouldReport map[common.Address]bool
+	sent         []common.Address
+}
+
+func (c *testClient) CallAtBlockHash(blockHash common.Hash, contract common.Address, data []byte) ([]byte, error) {
+	return c.CallAtLatestBlock(contract, data)
+}
+func (c *testClient) CallAtLatestBlock(contract common.Address, data []byte) ([]byte, error) {
+	setAbi := validatorSetAbi
+	method, err := setAbi.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(c.shouldReport[args[1].(common.Address)])
+}
+func (c *testClient) Transact(contract common.Address, data []byte) error {
+	reportAbi := validatorReportAbi
+	method, err := reportAbi.MethodById(data)
+	if err != nil {
+		return err
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return err
+	}
+	c.sent = append(c.sent, args[0].(common.Address))
+	return nil
+}
+
+// getValidatorsCaller - caller of the contract, which returns the given validators on getValidators
+func getValidatorsCaller(validators ...common.Address) func(common.Address, []byte) ([]byte, error) {
+	return func(contract common.Address, data []byte) ([]byte, error) {
+		if contract != setContract {
+			return nil, fmt.Errorf("unexpected call of %x", contract)
+		}
+		return validatorSetAbi.Methods["getValidators"].Outputs.Pack(validators)
+	}
+}
+
+// validatorsCode - code of the contract, which returns the given validators on any call
+func validatorsCode(t *testing.T, validators ...common.Address) []byte {
+	out, err := validatorSetAbi.Methods["getValidators"].Outputs.Pack(validators)
+	require.NoError(t, err)
+	const codeLen = 15
+	n := len(out)
+	code := []byte{
+		byte(vm.PUSH2), byte(n >> 8), byte(n), byte(vm.PUSH2), 0, codeLen, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH2), byte(n >> 8), byte(n), byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	return append(code, out...)
+}
+
+// stateWithValidators - state with the validator set contract at setContract, which returns the given validators
+func stateWithValidators(t *testing.T, validators ...common.Address) *state.IntraBlockState {
+	db := kv.NewTestKV(t)
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(tx.Rollback)
+	ibs := state.New(state.NewPlainStateReader(tx))
+	ibs.SetCode(setContract, validatorsCode(t, validators...))
+	return ibs
+}
+
+func initiateChangeReceipts(t *testing.T, contract common.Address, parentHash common.Hash, newSet ...common.Address) types.Receipts {
+	data, err := validatorSetAbi.Events["InitiateChange"].Inputs.NonIndexed().Pack(newSet)
+	require.NoError(t, err)
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000},
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 50000, Logs: []*types.Log{{
+			Address: contract,
+			Topics:  []common.Hash{EVENT_NAME_HASH, parentHash},
+			Data:    data,
+		}}},
+	}
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+	return receipts
+}
+
+func TestMultiSelectsSetByParent(t *testing.T) {
+	first, second := NewSimpleList([]common.Address{validatorA}), NewSimpleList([]common.Address{validatorB, validatorC})
+	multi := NewMulti(map[uint64]ValidatorSet{0: first, 10: second})
+	chain := newTestChain()
+	parent := map[uint64]*types.Header{}
+	for _, n := range []uint64{0, 8, 9, 10} {
+		parent[n] = &types.Header{Number: big.NewInt(int64(n)), Difficulty: big.NewInt(1)}
+		chain.insert(parent[n])
+	}
+	multi.parent = chain.GetHeaderByHash
+
+	_, err := multi.getWithCaller(common.Hash{1}, 0, nil)
+	require.Error(t, err)
+
+	// set of block 10 is used for the children of block 9
+	for n, expected := range map[uint64][]common.Address{0: {validatorA}, 8: {validatorA}, 9: {validatorB, validatorC}, 10: {validatorB, validatorC}} {
+		for nonce, v := range expected {
+			addr, err := multi.getWithCaller(parent[n].Hash(), uint(nonce), nil)
+			require.NoError(t, err)
+			require.Equal(t, v, addr)
+		}
+		cnt, err := multi.countWithCaller(parent[n].Hash(), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(len(expected)), cnt)
+	}
+
+	setBlock, set := multi.correctSetByNumber(9)
+	require.Equal(t, uint64(10), setBlock)
+	require.Equal(t, second, set)
+	isFirst, _ := multi.get(10)
+	require.True(t, isFirst)
+	isFirst, _ = multi.get(9)
+	require.False(t, isFirst)
+}
+
+func TestFirstProofRoundTrip(t *testing.T) {
+	contract := NewValidatorSafeContract(setContract, nil, nil)
+	genesisHash := common.Hash{0xaa}
+	ibs := stateWithValidators(t, validatorA, validatorB)
+	proof, err := contract.genesisEpochData(genesisHash, proverAt(params.TestChainConfig, ibs, 0))
+	require.NoError(t, err)
+
+	// the set is taken from the state of the proof, not from the database
+	ibs.SetCode(setContract, validatorsCode(t, validatorC))
+	list, hash, err := contract.epochSet(params.TestChainConfig, true, 0, proof)
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{validatorA, validatorB}, list.validators)
+	require.Equal(t, genesisHash, hash)
+
+	// proof of other contract is rejected
+	_, _, err = NewValidatorSafeContract(validatorC, nil, nil).epochSet(params.TestChainConfig, true, 0, proof)
+	require.Error(t, err)
+
+	// proof without the code of the contract is insufficient
+	decoded, err := decodeFirstValidatorSetProof(proof)
+	require.NoError(t, err)
+	require.NotEmpty(t, decoded.State.Accounts)
+	for i := range decoded.State.Accounts {
+		decoded.State.Accounts[i].Code = nil
+	}
+	_, err = decoded.State.call(params.TestChainConfig, setContract, nil)
+	require.NoError(t, err)
+	noCode, err := rlp.EncodeToBytes(decoded)
+	require.NoError(t, err)
+	_, _, err = contract.epochSet(params.TestChainConfig, true, 0, noCode)
+	require.Error(t, err)
+	decoded.State.Accounts = nil
+	_, err = decoded.State.call(params.TestChainConfig, setContract, nil)
+	require.Error(t, err)
+}
+
+func TestSignalEpochEndRoundTrip(t *testing.T) {
+	contract := NewValidatorSafeContract(setContract, nil, nil)
+	parentHash := common.Hash{0x01}
+	receipts := initiateChangeReceipts(t, setContract, parentHash, validatorB, validatorC)
+	header := &types.Header{Number: big.NewInt(5), ParentHash: parentHash, Difficulty: big.NewInt(1), ReceiptHash: types.DeriveSha(receipts)}
+
+	proof, err := contract.signalEpochEnd(false, header, receipts, nil)
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+
+	list, hash, err := contract.epochSet(params.TestChainConfig, false, 5, proof)
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{validatorB, validatorC}, list.validators)
+	require.Equal(t, header.Hash(), hash)
+
+	// event of other parent or other contract doesn't signal
+	noSignal, err := contract.signalEpochEnd(false, &types.Header{Number: big.NewInt(5), ParentHash: common.Hash{0x02}}, receipts, nil)
+	require.NoError(t, err)
+	require.Nil(t, noSignal)
+	noSignal, err = NewValidatorSafeContract(validatorC, nil, nil).signalEpochEnd(false, header, receipts, nil)
+	require.NoError(t, err)
+	require.Nil(t, noSignal)
+
+	// receipts must match the header
+	header.ReceiptHash = common.Hash{0x03}
+	badProof, err := encodeValidatorSetProof(ValidatorSetProof{Header: header, Receipts: receipts})
+	require.NoError(t, err)
+	_, _, err = contract.epochSet(params.TestChainConfig, false, 5, badProof)
+	require.Error(t, err)
+}
+
+func TestRollingFinality(t *testing.T) {
+	single := NewRollingFinality([]common.Address{validatorA})
+	finalized, err := single.push(common.Hash{1}, 1, []common.Address{validatorA})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(finalized))
+	require.Equal(t, common.Hash{1}, finalized[0].h)
+	_, err = single.push(common.Hash{2}, 2, []common.Address{validatorB})
+	require.Error(t, err)
+
+	// with 3 validators a block is finalized when 2 of them have signed on top of it
+	three := NewRollingFinality([]common.Address{validatorA, validatorB, validatorC})
+	finalized, err = three.push(common.Hash{1}, 1, []common.Address{validatorA})
+	require.NoError(t, err)
+	require.Empty(t, finalized)
+	finalized, err = three.push(common.Hash{2}, 2, []common.Address{validatorA})
+	require.NoError(t, err)
+	require.Empty(t, finalized)
+	finalized, err = three.push(common.Hash{3}, 3, []common.Address{validatorB})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{{1}, {2}}, []common.Hash{finalized[0].h, finalized[1].h})
+	require.Equal(t, common.Hash{3}, *three.lastPushed)
+}
+
+func TestReportQueueAndPosdao(t *testing.T) {
+	posdao := uint64(100)
+	client := &testClient{shouldReport: map[common.Address]bool{validatorA: true}}
+	set := NewValidatorContract(setContract, &posdao, nil)
+	set.registerClient(client)
+
+	// before POSDAO reports are sent, but not queued
+	set.reportMalicious(validatorB, 0, 50, nil)
+	require.Equal(t, []common.Address{validatorB}, client.sent)
+	require.Equal(t, 0, set.validators.reportQueue.len())
+	set.reportBenign(validatorC, 0, 51)
+	require.Equal(t, []common.Address{validatorB, validatorC}, client.sent)
+
+	client.sent = nil
+	set.reportMalicious(validatorA, 0, 100, nil)
+	set.reportMalicious(validatorB, 0, 101, nil)
+	require.Equal(t, 2, set.validators.reportQueue.len())
+
+	// queued reports are not resent before POSDAO transition
+	require.NoError(t, set.onCloseBlock(&types.Header{Number: big.NewInt(99)}, validatorC))
+	require.Equal(t, 2, set.validators.reportQueue.len())
+
+	// reports which the contract doesn't need anymore are dropped, others are resent
+	client.sent = nil
+	require.NoError(t, set.onCloseBlock(&types.Header{Number: big.NewInt(102)}, validatorC))
+	require.Equal(t, 1, set.validators.reportQueue.len())
+	require.Equal(t, []common.Address{validatorA}, client.sent)
+
+	// resent at most once per REPORTS_SKIP_BLOCKS
+	require.NoError(t, set.onCloseBlock(&types.Header{Number: big.NewInt(103)}, validatorC))
+	require.Equal(t, []common.Address{validatorA}, client.sent)
+	require.NoError(t, set.onCloseBlock(&types.Header{Number: big.NewInt(104)}, validatorC))
+	require.Equal(t, []common.Address{validatorA, validatorA}, client.sent)
+
+	var queue ReportQueue
+	for i := uint64(0); i < 15; i++ {
+		queue.push(validatorA, i, nil)
+	}
+	queue.truncate()
+	require.Equal(t, 10, queue.len())
+	var first uint64 = 100
+	queue.forEach(func(item *ReportQueueItem) {
+		if item.blockNum < first {
+			first = item.blockNum
+		}
+	})
+	require.Equal(t, uint64(0), first) // oldest reports are kept
+}
+
+func TestEpochTransitionsOfContract(t *testing.T) {
+	checkContractTransitions(t, []byte(`{"stepDuration": 5, "validators": {"safeContract": "0x0000000000000000000000000000000000005e75"}}`))
+}
+
+// checkContractTransitions - the engine of the params with the validator set contract at setContract
+// follows the sets signalled by the contract
+func checkContractTransitions(t *testing.T, engineParams []byte) {
+	engine, err := NewAuRa(nil, nil, common.Address{}, engineParams)
+	require.NoError(t, err)
+
+	ibs := stateWithValidators(t, validatorA)
+
+	chain, epochs := newTestChain(), newTestEpochs()
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1)}
+	chain.insert(genesis)
+
+	newBlock := func(parent *types.Header, coinbase common.Address, receipts types.Receipts) *types.Header {
+		h := &types.Header{Number: new(big.Int).Add(parent.Number, big.NewInt(1)), ParentHash: parent.Hash(), Coinbase: coinbase, Difficulty: big.NewInt(1), ReceiptHash: types.DeriveSha(receipts)}
+		chain.insert(h)
+		engine.Initialize(params.TestChainConfig, epochs, h, ibs, nil, nil, getValidatorsCaller(validatorA))
+		require.NoError(t, engine.Finalize(params.TestChainConfig, h, ibs, nil, nil, receipts, epochs, chain, nil))
+		return h
+	}
+
+	b1 := newBlock(genesis, validatorA, nil)
+	require.NotNil(t, epochs.epochs[epochKey{0, genesis.Hash()}])
+	set, setNumber, err := engine.epochSet(chain, epochs, &types.Header{Number: big.NewInt(2), ParentHash: b1.Hash()})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), setNumber)
+	require.Equal(t, []common.Address{validatorA}, set.(*SimpleList).validators)
+
+	// the only validator finalizes the signal immediately
+	b2 := newBlock(b1, validatorA, initiateChangeReceipts(t, setContract, b1.Hash(), validatorB, validatorC))
+	require.NotNil(t, epochs.pending[epochKey{2, b2.Hash()}])
+	proof := epochs.epochs[epochKey{2, b2.Hash()}]
+	require.NotNil(t, proof)
+	transition, err := destructure_proofs(proof)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), transition.SignalNumber)
+	require.False(t, engine.step.canPropose.Load())
+
+	set, setNumber, err = engine.epochSet(chain, epochs, &types.Header{Number: big.NewInt(3), ParentHash: b2.Hash()})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), setNumber)
+	require.Equal(t, []common.Address{validatorB, validatorC}, set.(*SimpleList).validators)
+
+	// with 2 validators the signal is finalized when both have built on top of it
+	b3 := newBlock(b2, validatorB, nil)
+	b4 := newBlock(b3, validatorB, initiateChangeReceipts(t, setContract, b3.Hash(), validatorA))
+	require.NotNil(t, epochs.pending[epochKey{4, b4.Hash()}])
+	require.Nil(t, epochs.epochs[epochKey{4, b4.Hash()}])
+	b5 := newBlock(b4, validatorC, nil)
+	require.NotNil(t, epochs.epochs[epochKey{5, b5.Hash()}])
+
+	// the engine restores the epoch from the database
+	restarted, err := NewAuRa(nil, nil, common.Address{}, engineParams)
+	require.NoError(t, err)
+	set, setNumber, err = restarted.epochSet(chain, epochs, &types.Header{Number: big.NewInt(6), ParentHash: b5.Hash()})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), setNumber)
+	require.Equal(t, []common.Address{validatorA}, set.(*SimpleList).validators)
+}
+
+func TestOpenEthereumSpecs(t *testing.T) {
+	files, err := filepath.Glob("oe-test/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		f := f
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			data, err := os.ReadFile(f)
+			require.NoError(t, err)
+			var spec struct {
+				Engine struct {
+					AuthorityRound struct {
+						Params json.RawMessage `json:"params"`
+					} `json:"authorityRound"`
+				} `json:"engine"`
+			}
+			require.NoError(t, json.Unmarshal(data, &spec))
+			var jsonSpec JsonSpec
+			require.NoError(t, json.Unmarshal(spec.Engine.AuthorityRound.Params, &jsonSpec))
+			engine, err := NewAuRa(nil, nil, common.Address{}, spec.Engine.AuthorityRound.Params)
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), engine.step.inner.inner.Load())
+			// empty steps are enabled from the first block by the specs that have them
+			if bytes.Contains(data, []byte(`"emptyStepsTransition"`)) {
+				require.Equal(t, uint64(1), engine.cfg.EmptyStepsTransition)
+			} else {
+				require.Equal(t, uint64(math.MaxUint64), engine.cfg.EmptyStepsTransition)
+			}
+			if bytes.Contains(data, []byte(`"maximumEmptySteps"`)) {
+				require.Equal(t, uint64(2), engine.cfg.MaximumEmptySteps)
+			}
+
+			switch set := engine.cfg.Validators.(type) {
+			case *SimpleList:
+				checkListProposers(t, engine)
+			case *Multi:
+				checkMultiTransitions(t, engine, jsonSpec.Validators.Multi)
+			case *ValidatorSafeContract:
+				checkContractTransitions(t, spec.Engine.AuthorityRound.Params)
+			case *ValidatorContract:
+				checkContractTransitions(t, spec.Engine.AuthorityRound.Params)
+				require.NotNil(t, jsonSpec.PosdaoTransition)
+				checkPosdaoTransition(t, set, *jsonSpec.PosdaoTransition)
+			default:
+				t.Fatalf("unexpected validator set %T", set)
+			}
+		})
+	}
+}
+
+// checkListProposers - with immediate transitions proposer of the step is taken from the configured list
+func checkListProposers(t *testing.T, engine *AuRa) {
+	require.True(t, engine.cfg.ImmediateTransitions)
+	set, _, err := engine.epochSet(nil, nil, &types.Header{Number: big.NewInt(1)})
+	require.NoError(t, err)
+	list := set.(*SimpleList).validators
+	for step := uint64(0); step < 4; step++ {
+		proposer, err := stepProposer(set, common.Hash{}, step)
+		require.NoError(t, err)
+		require.Equal(t, list[step%uint64(len(list))], proposer)
+	}
+}
+
+// checkMultiTransitions - each block is validated by the set of the last transition at or before it
+func checkMultiTransitions(t *testing.T, engine *AuRa, multi map[uint64]*ValidatorSetJson) {
+	require.True(t, engine.cfg.ImmediateTransitions)
+	var last uint64
+	for n := range multi {
+		if n > last {
+			last = n
+		}
+	}
+	for n := uint64(1); n <= last+1; n++ {
+		var expected *ValidatorSetJson
+		var from uint64
+		for transition, s := range multi {
+			if transition <= n && (expected == nil || transition >= from) {
+				expected, from = s, transition
+			}
+		}
+		set, setNumber, err := engine.epochSet(nil, nil, &types.Header{Number: new(big.Int).SetUint64(n)})
+		require.NoError(t, err)
+		require.Equal(t, n, setNumber)
+		switch {
+		case expected.List != nil:
+			require.Equal(t, expected.List, set.(*SimpleList).validators, "block %d", n)
+		case expected.SafeContract != nil:
+			require.Equal(t, *expected.SafeContract, set.(*ValidatorSafeContract).contractAddress, "block %d", n)
+			proposer, err := set.getWithCaller(common.Hash{}, 0, getValidatorsCaller(validatorB))
+			require.NoError(t, err)
+			require.Equal(t, validatorB, proposer)
+		default:
+			t.Fatalf("unexpected validator set of block %d", n)
+		}
+	}
+}
+
+// checkPosdaoTransition - malicious reports are queued for resending only from the POSDAO transition
+func checkPosdaoTransition(t *testing.T, set *ValidatorContract, posdaoTransition uint64) {
+	require.Equal(t, posdaoTransition, *set.posdaoTransition)
+	client := &testClient{shouldReport: map[common.Address]bool{validatorA: true}}
+	set.registerClient(client)
+
+	set.reportMalicious(validatorA, 0, posdaoTransition-1, nil)
+	require.Equal(t, []common.Address{validatorA}, client.sent)
+	require.Equal(t, 0, set.validators.reportQueue.len())
+	require.NoError(t, set.onCloseBlock(&types.Header{Number: new(big.Int).SetUint64(posdaoTransition - 1)}, validatorC))
+	require.Equal(t, []common.Address{validatorA}, client.sent)
+
+	set.reportMalicious(validatorA, 0, posdaoTransition, nil)
+	require.Equal(t, 1, set.validators.reportQueue.len())
+	client.sent = nil
+	require.NoError(t, set.onCloseBlock(&types.Header{Number: new(big.Int).SetUint64(posdaoTransition + 2)}, validatorC))
+	require.Equal(t, []common.Address{validatorA}, client.sent)
+}
@@ -16,100 +16,30 @@
 
 package clique
 
-/*
 import (
+	"encoding/json"
 	"fmt"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core/types"
-	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
 )
-// API is a user facing RPC API to allow controlling the signer and voting
-// mechanisms of the proof-of-authority scheme.
-type API struct {
-	chain  consensus.ChainHeaderReader
-	clique *Clique
-}
-
-// GetSnapshot retrieves the state snapshot at a given block.
-func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
-	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
-	}
-	// Ensure we have an actually valid block and return its snapshot
-	if header == nil {
-		return nil, errUnknownBlock
-	}
-
-	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return snap, err
-}
-
-// GetSnapshotAtHash retrieves the state snapshot at a given block.
-func (api *API) GetSnapshotAtHash(hash common.Hash) (*Snapshot, error) {
-	header := api.chain.GetHeaderByHash(hash)
-	if header == nil {
-		return nil, errUnknownBlock
-	}
-
-	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return snap, err
-}
 
-// GetSigners retrieves the list of authorized signers at the specified block.
-func (api *API) GetSigners(number *rpc.BlockNumber) ([]common.Address, error) {
-	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
-	}
-	// Ensure we have an actually valid block and return the signers from its snapshot
-	if header == nil {
-		return nil, errUnknownBlock
-	}
-
-	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
-	if err != nil {
-		return nil, err
-	}
-	return snap.signers(), nil
-}
-
-// GetSignersAtHash retrieves the list of authorized signers at the specified block.
-func (api *API) GetSignersAtHash(hash common.Hash) ([]common.Address, error) {
-	header := api.chain.GetHeaderByHash(hash)
-	if header == nil {
-		return nil, errUnknownBlock
-	}
-	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
-	if err != nil {
-		return nil, err
-	}
-	return snap.signers(), nil
-}
+// The user facing clique_ RPC API is served by rpcdaemon (see cmd/rpcdaemon/commands/clique_api.go):
+// snapshots are read there from the checkpoint snapshots stored by the engine of the node (see StoredSnapshot),
+// while the proposals live in the engine of the node and are reached through the remotedbserver.CliqueServer.
 
 // Proposals returns the current proposals the node tries to uphold and vote on.
-func (api *API) Proposals() map[common.Address]bool {
-	api.clique.lock.RLock()
-	defer api.clique.lock.RUnlock()
+func (c *Clique) Proposals() map[common.Address]bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 
 	proposals := make(map[common.Address]bool)
-	for address, auth := range api.clique.proposals {
+	for address, auth := range c.proposals {
 		proposals[address] = auth
 	}
 	return proposals
@@ -117,45 +47,61 @@ func (api *API) Proposals() map[common.Address]bool {
 
 // Propose injects a new authorization proposal that the signer will attempt to
 // push through.
-func (api *API) Propose(address common.Address, auth bool) {
-	api.clique.lock.Lock()
-	defer api.clique.lock.Unlock()
+func (c *Clique) Propose(address common.Address, auth bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	api.clique.proposals[address] = auth
+	c.proposals[address] = auth
 }
 
 // Discard drops a currently running proposal, stopping the signer from casting
 // further votes (either for or against).
-func (api *API) Discard(address common.Address) {
-	api.clique.lock.Lock()
-	defer api.clique.lock.Unlock()
+func (c *Clique) Discard(address common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	delete(api.clique.proposals, address)
+	delete(c.proposals, address)
 }
 
-type status struct {
+// Status is the signing activity over the last blocks of the chain.
+type Status struct {
 	InturnPercent float64                `json:"inturnPercent"`
 	SigningStatus map[common.Address]int `json:"sealerActivity"`
 	NumBlocks     uint64                 `json:"numBlocks"`
 }
 
-// Status returns the status of the last N blocks,
+// statusBlocks is the number of recent blocks Status looks at.
+const statusBlocks = uint64(64)
+
+// Status returns the status of the last N blocks before header,
 // - the number of active signers,
 // - the number of signers,
 // - the percentage of in-turn blocks
-func (api *API) Status() (*status, error) {
-	var (
-		numBlocks = uint64(64)
-		header    = api.chain.CurrentHeader()
-		diff      = uint64(0)
-		optimals  = 0
-	)
-	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+func (c *Clique) Status(chain consensus.ChainHeaderReader, header *types.Header) (*Status, error) {
+	snap, err := c.Snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
 	if err != nil {
 		return nil, err
 	}
+	return status(chain, header, snap, c.signatures)
+}
+
+// StoredStatus is Status over the snapshot returned by StoredSnapshot
+func StoredStatus(config *params.CliqueConfig, tx ethdb.Tx, chain consensus.ChainHeaderReader, header *types.Header) (*Status, error) {
+	snap, err := StoredSnapshot(config, tx, chain, header.Number.Uint64(), header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	sigcache, _ := lru.NewARC(int(statusBlocks))
+	return status(chain, header, snap, sigcache)
+}
+
+func status(chain consensus.ChainHeaderReader, header *types.Header, snap *Snapshot, sigcache *lru.ARCCache) (*Status, error) {
+	var (
+		numBlocks = statusBlocks
+		optimals  = 0
+	)
 	var (
-		signers = snap.signers()
+		signers = snap.GetSigners()
 		end     = header.Number.Uint64()
 		start   = end - numBlocks
 	)
@@ -168,24 +114,68 @@ func (api *API) Status() (*status, error) {
 		signStatus[s] = 0
 	}
 	for n := start; n < end; n++ {
-		h := api.chain.GetHeaderByNumber(n)
+		h := chain.GetHeaderByNumber(n)
 		if h == nil {
 			return nil, fmt.Errorf("missing block %d", n)
 		}
-		if h.Difficulty.Cmp(diffInTurn) == 0 {
+		if h.Difficulty.Cmp(DiffInTurn) == 0 {
 			optimals++
 		}
-		diff += h.Difficulty.Uint64()
-		sealer, err := api.clique.Author(h)
+		sealer, err := ecrecover(h, sigcache)
 		if err != nil {
 			return nil, err
 		}
 		signStatus[sealer]++
 	}
-	return &status{
-		InturnPercent: float64(100*optimals) / float64(numBlocks),
+	var inturnPercent float64
+	if numBlocks > 0 {
+		inturnPercent = float64(100*optimals) / float64(numBlocks)
+	}
+	return &Status{
+		InturnPercent: inturnPercent,
 		SigningStatus: signStatus,
 		NumBlocks:     numBlocks,
 	}, nil
 }
-*/
+
+// StoredSnapshot returns the voting snapshot at the block, built from the closest checkpoint snapshot stored by
+// the engine in the CliqueSeparate bucket and the headers after it. Unlike Clique.Snapshot it doesn't write to
+// the database, so it can be served from the database of the node opened read-only.
+func StoredSnapshot(config *params.CliqueConfig, tx ethdb.Tx, chain consensus.ChainHeaderReader, number uint64, hash common.Hash) (*Snapshot, error) {
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		blob, err := tx.GetOne(dbutils.CliqueSeparateBucket, SnapshotFullKey(number, hash))
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) > 0 {
+			snap = new(Snapshot)
+			if err := json.Unmarshal(blob, snap); err != nil {
+				return nil, fmt.Errorf("invalid stored snapshot %d %x: %w", number, hash, err)
+			}
+			snap.config = config
+			break
+		}
+		header := chain.GetHeader(hash, number)
+		if header == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		if number == 0 {
+			snap = newSnapshot(config, number, hash, checkpointSigners(header))
+			break
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	if len(headers) == 0 {
+		return snap, nil
+	}
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	sigcache, _ := lru.NewARC(len(headers))
+	return snap.apply(sigcache, headers...)
+}
@@ -21,10 +21,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goccy/go-json"
@@ -182,9 +184,12 @@ type Clique struct {
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
-	signer common.Address // Ethereum address of the signing key
-	signFn SignerFn       // Signer function to authorize hashes with
-	lock   sync.RWMutex   // Protects the signer fields
+	signer      common.Address // Ethereum address of the signing key
+	signFn      SignerFn       // Signer function to authorize hashes with
+	instantSeal bool           // Seal blocks without transactions too, see InstantSeal
+	lock        sync.RWMutex   // Protects the signer fields
+
+	timeOffset int64 // Seconds the clock of the developer chain is moved forward by, see IncreaseTime
 
 	// The fields below are for testing only
 	FakeDiff bool // Skip difficulty verifications
@@ -296,7 +301,7 @@ func (c *Clique) VerifySeal(chain consensus.ChainHeaderReader, header *types.Hea
 
 // Prepare implements consensus.Engine, preparing all the consensus fields of the
 // header for running the transactions on top.
-func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header, e consensus.EpochReader) error {
 
 	// If the block isn't a checkpoint, cast a random vote (good enough for now)
 	header.Coinbase = common.Address{}
@@ -355,7 +360,7 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 	}
 	header.Time = parent.Time + c.config.Period
 
-	now := uint64(time.Now().Unix())
+	now := uint64(c.now().Unix())
 	if header.Time < now {
 		header.Time = now
 	}
@@ -363,19 +368,20 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 	return nil
 }
 
-func (c *Clique) Initialize(_ *params.ChainConfig, e consensus.EpochReader, header *types.Header, txs []types.Transaction, uncles []*types.Header, syscall consensus.SystemCall) {
+func (c *Clique) Initialize(_ *params.ChainConfig, e consensus.EpochReader, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, syscall consensus.SystemCall) {
 }
 
 // Finalize implements consensus.Engine, ensuring no uncles are set, nor block
 // rewards given.
-func (c *Clique) Finalize(_ *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, syscall consensus.SystemCall) {
+func (c *Clique) Finalize(_ *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall) error {
 	// No block rewards in PoA, so the state remains as is and uncles are dropped
 	header.UncleHash = types.CalcUncleHash(nil)
+	return nil
 }
 
 // FinalizeAndAssemble implements consensus.Engine, ensuring no uncles are set,
 // nor block rewards given, and returns the final block.
-func (c *Clique) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, receipts types.Receipts, e consensus.EpochReader, syscall consensus.SystemCall) (*types.Block, error) {
+func (c *Clique) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, receipts types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall) (*types.Block, error) {
 	// No block rewards in PoA, so the state remains as is and uncles are dropped
 	header.UncleHash = types.CalcUncleHash(nil)
 
@@ -393,6 +399,29 @@ func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
 	c.signFn = signFn
 }
 
+// InstantSeal switches the 0-period engine of a developer chain to sealing every block it is given,
+// with or without transactions: the miner decides when blocks are made (as soon as a transaction
+// enters the pool or on demand), not the engine.
+func (c *Clique) InstantSeal() error {
+	if c.config.Period != 0 {
+		return fmt.Errorf("instant sealing needs 0-period clique, period is %d", c.config.Period)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.instantSeal = true
+	return nil
+}
+
+// IncreaseTime moves the clock of the engine forward, so that the following blocks are sealed
+// (and accepted) with later timestamps. Returns the total offset in seconds.
+func (c *Clique) IncreaseTime(seconds uint64) uint64 {
+	return uint64(atomic.AddInt64(&c.timeOffset, int64(seconds)))
+}
+
+func (c *Clique) now() time.Time {
+	return time.Now().Add(time.Duration(atomic.LoadInt64(&c.timeOffset)) * time.Second)
+}
+
 // Seal implements consensus.Engine, attempting to create a sealed block using
 // the local signing credentials.
 func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
@@ -404,15 +433,16 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 	if number == 0 {
 		return errUnknownBlock
 	}
+	// Don't hold the signer fields for the entire sealing procedure
+	c.lock.RLock()
+	signer, signFn, instantSeal := c.signer, c.signFn, c.instantSeal
+	c.lock.RUnlock()
+
 	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
-	if c.config.Period == 0 && len(block.Transactions()) == 0 {
+	if c.config.Period == 0 && len(block.Transactions()) == 0 && !instantSeal {
 		log.Info("Sealing paused, waiting for transactions")
 		return nil
 	}
-	// Don't hold the signer fields for the entire sealing procedure
-	c.lock.RLock()
-	signer, signFn := c.signer, c.signFn
-	c.lock.RUnlock()
 
 	// Bail out if we're unauthorized to sign a block
 	snap, err := c.Snapshot(chain, number-1, header.ParentHash, nil)
@@ -433,7 +463,7 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 		}
 	}
 	// Sweet, the protocol permits us to sign the block, wait for our time
-	delay := time.Unix(int64(header.Time), 0).Sub(time.Now()) // nolint: gosimple
+	delay := time.Unix(int64(header.Time), 0).Sub(c.now()) // nolint: gosimple
 	if header.Difficulty.Cmp(diffNoTurn) == 0 {
 		// It's not our turn explicitly to sign, delay it a bit
 		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
@@ -496,6 +526,11 @@ func (c *Clique) SealHash(header *types.Header) common.Hash {
 	return SealHash(header)
 }
 
+// SnapshotDB returns the database the engine stores its checkpoint snapshots in.
+func (c *Clique) SnapshotDB() ethdb.RoKV {
+	return c.db
+}
+
 // Close implements consensus.Engine. It's a noop for clique as there are no background threads.
 func (c *Clique) Close() error {
 	common.SafeClose(c.exitCh)
@@ -606,6 +641,6 @@ func (c *Clique) snapshots(latest uint64, total int) ([]*Snapshot, error) {
 	return res, nil
 }
 
-func (c *Clique) VerifyFamily(chain consensus.ChainHeaderReader, header *types.Header) error {
+func (c *Clique) VerifyFamily(chain consensus.ChainHeaderReader, header *types.Header, e consensus.EpochReader) error {
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
 	"github.com/ledgerwatch/erigon/ethdb/kv"
 
 	"github.com/ledgerwatch/erigon/common"
@@ -30,8 +31,10 @@ import (
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/protocols/eth"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/stages"
 )
 
@@ -136,3 +139,62 @@ func TestReimportMirroredState(t *testing.T) {
 	}
 
 }
+
+// Blocks sealed by the clique signer are inserted into the own chain and announced to the peers, the same way as
+// blocks of other engines
+func TestPropagateMinedBlock(t *testing.T) {
+	var (
+		cliqueDB = kv.NewTestKV(t)
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		config   = *params.AllCliqueProtocolChanges
+	)
+	// with zero period clique doesn't seal blocks without transactions
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	engine := clique.New(&config, params.CliqueSnapshot, cliqueDB)
+	engine.Authorize(addr, func(_ common.Address, _ string, message []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(message), key)
+	})
+	genspec := &core.Genesis{
+		ExtraData: make([]byte, clique.ExtraVanity+common.AddressLength+clique.ExtraSeal),
+		Alloc: map[common.Address]core.GenesisAccount{
+			addr: {Balance: big.NewInt(1)},
+		},
+		Config: &config,
+	}
+	copy(genspec.ExtraData[clique.ExtraVanity:], addr[:])
+	m := stages.MockWithEverything(t, genspec, key, ethdb.DefaultStorageMode, engine)
+
+	if err := stages.MiningStep(m.Ctx, m.DB, m.MiningSync); err != nil {
+		t.Fatal(err)
+	}
+	<-m.PendingBlocks
+	b := <-m.MinedBlocks
+	if err := m.PropagateMinedBlock(b); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := m.SentMessage(0)
+	if msg.Id != sentry.MessageId_NEW_BLOCK_66 {
+		t.Fatalf("mined block is not announced, sent %s", msg.Id)
+	}
+	var packet eth.NewBlockPacket
+	if err := rlp.DecodeBytes(msg.Data, &packet); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DB.View(context.Background(), func(tx ethdb.Tx) error {
+		if hash, err := rawdb.ReadCanonicalHash(tx, 1); err != nil || hash != b.Hash() {
+			t.Fatalf("mined block is not inserted: %x, %v", hash, err)
+		}
+		td, err := rawdb.ReadTd(tx, b.Hash(), 1)
+		if err != nil {
+			return err
+		}
+		if packet.Block.Hash() != b.Hash() || packet.TD.Cmp(td) != 0 {
+			t.Fatalf("announced block %x with td %d, want %x with td %d", packet.Block.Hash(), packet.TD, b.Hash(), td)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
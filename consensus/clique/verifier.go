@@ -3,7 +3,6 @@ package clique
 import (
 	"bytes"
 	"fmt"
-	"time"
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/consensus"
@@ -23,7 +22,7 @@ func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.H
 	}
 	number := header.Number.Uint64()
 
-	now := time.Now()
+	now := c.now()
 	nowUnix := now.Unix()
 
 	// Don't waste time checking blocks from the future
@@ -182,11 +181,7 @@ func (c *Clique) Snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 			if checkpoint != nil {
 				hash := checkpoint.Hash()
 
-				signers := make([]common.Address, (len(checkpoint.Extra)-ExtraVanity-ExtraSeal)/common.AddressLength)
-				for i := 0; i < len(signers); i++ {
-					copy(signers[i][:], checkpoint.Extra[ExtraVanity+i*common.AddressLength:])
-				}
-				snap = newSnapshot(c.config, number, hash, signers)
+				snap = newSnapshot(c.config, number, hash, checkpointSigners(checkpoint))
 				if err := snap.store(c.db); err != nil {
 					return nil, err
 				}
@@ -233,6 +228,15 @@ func (c *Clique) Snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 	return snap, err
 }
 
+// checkpointSigners returns the list of signers in the extra-data of the checkpoint header
+func checkpointSigners(checkpoint *types.Header) []common.Address {
+	signers := make([]common.Address, (len(checkpoint.Extra)-ExtraVanity-ExtraSeal)/common.AddressLength)
+	for i := 0; i < len(signers); i++ {
+		copy(signers[i][:], checkpoint.Extra[ExtraVanity+i*common.AddressLength:])
+	}
+	return signers
+}
+
 // verifySeal checks whether the signature contained in the header satisfies the
 // consensus protocol requirements. The method accepts an optional list of parent
 // headers that aren't yet part of the local blockchain to generate the snapshots
@@ -62,6 +62,11 @@ type ChainReader interface {
 type EpochReader interface {
 	GetEpoch(blockHash common.Hash, blockN uint64) (transitionProof []byte, err error)
 	PutEpoch(blockHash common.Hash, blockN uint64, transitionProof []byte) (err error)
+	// GetPendingEpoch - proof of the transition signalled at the block, which is not finalized yet
+	GetPendingEpoch(blockHash common.Hash, blockN uint64) (transitionProof []byte, err error)
+	PutPendingEpoch(blockHash common.Hash, blockN uint64, transitionProof []byte) (err error)
+	// FindBeforeOrEqualNumber - the last transition at or before the block number, zero blockNum and nil proof if not found
+	FindBeforeOrEqualNumber(number uint64) (blockNum uint64, blockHash common.Hash, transitionProof []byte, err error)
 }
 
 type SystemCall func(contract common.Address, data []byte) ([]byte, error)
@@ -91,17 +96,17 @@ type Engine interface {
 
 	// Prepare initializes the consensus fields of a block header according to the
 	// rules of a particular engine. The changes are executed inline.
-	Prepare(chain ChainHeaderReader, header *types.Header) error
+	Prepare(chain ChainHeaderReader, header *types.Header, e EpochReader) error
 
 	// Initialize runs any pre-transaction state modifications (e.g. epoch start)
-	Initialize(config *params.ChainConfig, e EpochReader, header *types.Header, txs []types.Transaction, uncles []*types.Header, syscall SystemCall)
+	Initialize(config *params.ChainConfig, e EpochReader, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, syscall SystemCall)
 
 	// Finalize runs any post-transaction state modifications (e.g. block rewards)
 	// but does not assemble the block.
 	//
 	// Note: The block header and state database might be updated to reflect any
 	// consensus rules that happen at finalization (e.g. block rewards).
-	Finalize(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e EpochReader, syscall SystemCall)
+	Finalize(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction, uncles []*types.Header, r types.Receipts, e EpochReader, chain ChainHeaderReader, syscall SystemCall) error
 
 	// FinalizeAndAssemble runs any post-transaction state modifications (e.g. block
 	// rewards) and assembles the final block.
@@ -109,7 +114,7 @@ type Engine interface {
 	// Note: The block header and state database might be updated to reflect any
 	// consensus rules that happen at finalization (e.g. block rewards).
 	FinalizeAndAssemble(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs []types.Transaction,
-		uncles []*types.Header, receipts types.Receipts, e EpochReader, syscall SystemCall) (*types.Block, error)
+		uncles []*types.Header, receipts types.Receipts, e EpochReader, chain ChainHeaderReader, syscall SystemCall) (*types.Block, error)
 
 	// Seal generates a new sealing request for the given input block and pushes
 	// the result into the given channel.
@@ -128,7 +133,7 @@ type Engine interface {
 	GenerateSeal(chain ChainHeaderReader, currnt, parent *types.Header) []rlp.RawValue
 
 	// VerifyFamily only used by Aura now - later may be merged into VerifyHeaders
-	VerifyFamily(chain ChainHeaderReader, header *types.Header) error
+	VerifyFamily(chain ChainHeaderReader, header *types.Header, e EpochReader) error
 
 	// APIs returns the RPC APIs this consensus engine provides.
 	APIs(chain ChainHeaderReader) []rpc.API
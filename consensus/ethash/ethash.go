@@ -553,6 +553,10 @@ func (ethash *Ethash) dataset(block uint64, async bool) *dataset {
 // Note the returned hashrate includes local hashrate, but also includes the total
 // hashrate of all remote miner.
 func (ethash *Ethash) Hashrate() float64 {
+	// The fake engines don't search for nonces, hence don't measure the hashrate.
+	if ethash.hashrate == nil {
+		return 0
+	}
 	// Short circuit if we are run the ethash in normal/test mode.
 	if (ethash.config.PowMode != ModeNormal && ethash.config.PowMode != ModeTest) || ethash.remote == nil {
 		return ethash.hashrate.Rate1()
@@ -89,6 +89,8 @@ type remoteSealer struct {
 	submitWorkCh chan *mineResult // Channel used for remote sealer to submit their mining result
 	fetchRateCh  chan chan uint64 // Channel used to gather submitted hash rate for local or remote sealer.
 	submitRateCh chan *hashrate   // Channel used for remote sealer to submit their mining hashrate
+	subscribeCh  chan chan [4]string
+	workSubs     []chan [4]string // Stratum servers notified of new work packages
 	requestExit  chan struct{}
 	exitCh       chan struct{}
 }
@@ -138,6 +140,7 @@ func startRemoteSealer(ethash *Ethash, urls []string, noverify bool) *remoteSeal
 		submitWorkCh: make(chan *mineResult),
 		fetchRateCh:  make(chan chan uint64),
 		submitRateCh: make(chan *hashrate),
+		subscribeCh:  make(chan chan [4]string),
 		requestExit:  make(chan struct{}),
 		exitCh:       make(chan struct{}),
 	}
@@ -181,6 +184,13 @@ func (s *remoteSealer) loop() {
 				result.errc <- errInvalidSealResult
 			}
 
+		case sub := <-s.subscribeCh:
+			// Subscribe to new work packages, starting with the current one.
+			s.workSubs = append(s.workSubs, sub)
+			if s.currentBlock != nil {
+				replaceWork(sub, s.currentWork)
+			}
+
 		case result := <-s.submitRateCh:
 			// Trace remote sealer's hash rate by submitted value.
 			s.rates[result.id] = hashrate{rate: result.rate, ping: time.Now()}
@@ -250,12 +260,31 @@ func (s *remoteSealer) notifyWork() {
 		blob, _ = json.Marshal(work)
 	}
 
+	for _, sub := range s.workSubs {
+		replaceWork(sub, work)
+	}
+
 	s.reqWG.Add(len(s.notifyURLs))
 	for _, url := range s.notifyURLs {
 		go s.sendNotification(s.notifyCtx, url, blob, work)
 	}
 }
 
+// replaceWork sends the work package to the subscriber without blocking, replacing
+// the package it has not received yet. Subscription channels have capacity 1 and
+// the sealer loop is their only sender.
+func replaceWork(sub chan [4]string, work [4]string) {
+	select {
+	case sub <- work:
+	default:
+		select {
+		case <-sub:
+		default:
+		}
+		sub <- work
+	}
+}
+
 func (s *remoteSealer) sendNotification(ctx context.Context, url string, json []byte, work [4]string) {
 	defer s.reqWG.Done()
 
@@ -0,0 +1,634 @@
+package ethash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/log"
+)
+
+const (
+	stratumReadTimeout  = 10 * time.Minute // miners send shares and hashrate much more often
+	stratumWriteTimeout = 10 * time.Second
+	stratumMaxLine      = 4096
+	stratumRateWindow   = 10 * time.Minute // effective hashrate of a worker is estimated from the shares of this window
+	stratumReportPeriod = time.Minute
+)
+
+// stratumDiff1 - ethash difficulty of the share of difficulty 1 in EthereumStratum/1.0.0 (mining.set_difficulty)
+var stratumDiff1 = new(big.Int).Lsh(big.NewInt(1), 32)
+
+var (
+	errStratumUnknownJob     = errors.New("job not found")
+	errStratumDuplicateShare = errors.New("duplicate share")
+	errStratumLowDifficulty  = errors.New("low difficulty share")
+	errStratumUnauthorized   = errors.New("unauthorized worker")
+	errStratumNotSubscribed  = errors.New("not subscribed")
+)
+
+// stratumErrorCode - error codes of EthereumStratum/1.0.0, eth-proxy miners get them too
+func stratumErrorCode(err error) int {
+	switch err {
+	case errStratumUnknownJob:
+		return 21
+	case errStratumDuplicateShare:
+		return 22
+	case errStratumLowDifficulty:
+		return 23
+	case errStratumUnauthorized:
+		return 24
+	case errStratumNotSubscribed:
+		return 25
+	default:
+		return 20
+	}
+}
+
+type stratumProtocol int
+
+const (
+	stratumUnknown         stratumProtocol = iota
+	stratumEthereumStratum                 // EthereumStratum/1.0.0: jobs are pushed with mining.notify, nonces are prefixed by the extranonce of the session
+	stratumEthProxy                        // eth-proxy: eth_getWork and eth_submitWork over TCP, new work is pushed as eth_getWork results
+)
+
+// stratumJob - work package of the remote sealer
+type stratumJob struct {
+	sealHash common.Hash
+	seedHash common.Hash
+	number   uint64
+	target   *big.Int            // boundary of the block, 2^256/difficulty
+	nonces   map[uint64]struct{} // nonces of the submitted shares
+}
+
+// StratumWorker - hashrate and shares of a worker, named by the login of the miner
+type StratumWorker struct {
+	Name          string
+	ReportedRate  uint64 // hashes per second reported by the miner
+	EffectiveRate uint64 // hashes per second estimated from the accepted shares
+	Accepted      uint64
+	Rejected      uint64
+	Blocks        uint64 // shares which were blocks accepted by the sealer
+	LastSeen      time.Time
+}
+
+type stratumWorker struct {
+	StratumWorker
+	since  time.Time
+	shares []stratumShare // accepted shares within stratumRateWindow
+}
+
+type stratumShare struct {
+	at         time.Time
+	difficulty float64
+}
+
+type stratumSession struct {
+	conn      net.Conn
+	writeLock sync.Mutex
+
+	// guarded by StratumServer.lock
+	protocol   stratumProtocol
+	extranonce string // hex prefix of the nonces of the EthereumStratum/1.0.0 session
+	worker     string
+}
+
+type stratumRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []interface{}   `json:"params"`
+	Worker string          `json:"worker"` // eth-proxy miners send the name of the rig here
+}
+
+type stratumResponse struct {
+	ID      json.RawMessage `json:"id"`
+	Version string          `json:"jsonrpc,omitempty"`
+	Result  interface{}     `json:"result"`
+	Error   interface{}     `json:"error"`
+}
+
+type stratumNotification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// StratumServer - TCP stratum endpoint of the remote sealer for mining pool proxies and miners. It speaks
+// EthereumStratum/1.0.0 and eth-proxy, hands out the work packages the mining stage seals, checks shares against
+// the pool difficulty and submits the shares which solve the block through the submitWork path of the remote sealer.
+type StratumServer struct {
+	ethash     *Ethash
+	api        *API
+	listener   net.Listener
+	difficulty *big.Int // pool difficulty of shares
+	target     *big.Int // boundary of shares, 2^256/difficulty
+	workCh     chan [4]string
+
+	lock       sync.Mutex
+	current    *stratumJob
+	jobs       map[common.Hash]*stratumJob
+	sessions   map[*stratumSession]struct{}
+	workers    map[string]*stratumWorker
+	extranonce uint16
+	sessionID  uint32
+
+	quit      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// StartStratumServer listens on addr for miners, shares must be solved with the given ethash difficulty
+func StartStratumServer(ethash *Ethash, addr string, difficulty uint64) (*StratumServer, error) {
+	if ethash.remote == nil {
+		return nil, errors.New("not supported")
+	}
+	if difficulty == 0 {
+		return nil, errors.New("pool difficulty of stratum shares must be positive")
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &StratumServer{
+		ethash:     ethash,
+		api:        &API{ethash},
+		listener:   listener,
+		difficulty: new(big.Int).SetUint64(difficulty),
+		target:     new(big.Int).Div(two256, new(big.Int).SetUint64(difficulty)),
+		workCh:     make(chan [4]string, 1),
+		jobs:       make(map[common.Hash]*stratumJob),
+		sessions:   make(map[*stratumSession]struct{}),
+		workers:    make(map[string]*stratumWorker),
+		quit:       make(chan struct{}),
+	}
+	select {
+	case ethash.remote.subscribeCh <- s.workCh:
+	case <-ethash.remote.exitCh:
+		listener.Close()
+		return nil, errEthashStopped
+	}
+	s.wg.Add(2)
+	go s.loop()
+	go s.acceptLoop()
+	log.Info("Stratum server started", "addr", listener.Addr(), "difficulty", difficulty)
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *StratumServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops accepting miners and disconnects them
+func (s *StratumServer) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		s.listener.Close()
+		s.lock.Lock()
+		for session := range s.sessions {
+			session.conn.Close()
+		}
+		s.lock.Unlock()
+		s.wg.Wait()
+	})
+	return nil
+}
+
+// Workers returns the hashrate and shares of the workers, sorted by name
+func (s *StratumServer) Workers() []StratumWorker {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	workers := make([]StratumWorker, 0, len(s.workers))
+	for _, w := range s.workers {
+		for len(w.shares) > 0 && now.Sub(w.shares[0].at) > stratumRateWindow {
+			w.shares = w.shares[1:]
+		}
+		var sum float64
+		for _, share := range w.shares {
+			sum += share.difficulty
+		}
+		elapsed := stratumRateWindow
+		if now.Sub(w.since) < elapsed {
+			elapsed = now.Sub(w.since)
+		}
+		w.EffectiveRate = 0
+		if elapsed > 0 {
+			w.EffectiveRate = uint64(sum / elapsed.Seconds())
+		}
+		workers = append(workers, w.StratumWorker)
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].Name < workers[j].Name })
+	return workers
+}
+
+func (s *StratumServer) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(stratumReportPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case work := <-s.workCh:
+			s.newWork(work)
+		case <-ticker.C:
+			s.report()
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// newWork makes a job of the work package of the remote sealer and pushes it to the miners
+func (s *StratumServer) newWork(work [4]string) {
+	number, err := hexutil.DecodeUint64(work[3])
+	if err != nil {
+		log.Warn("Stratum: invalid work package", "number", work[3], "err", err)
+		return
+	}
+	job := &stratumJob{
+		sealHash: common.HexToHash(work[0]),
+		seedHash: common.HexToHash(work[1]),
+		number:   number,
+		target:   new(big.Int).SetBytes(common.FromHex(work[2])),
+		nonces:   make(map[uint64]struct{}),
+	}
+	s.lock.Lock()
+	if s.current != nil && s.current.sealHash == job.sealHash {
+		s.lock.Unlock()
+		return
+	}
+	s.current = job
+	s.jobs[job.sealHash] = job
+	for hash, old := range s.jobs {
+		if old.number+staleThreshold <= number {
+			delete(s.jobs, hash)
+		}
+	}
+	var sessions []*stratumSession
+	var protocols []stratumProtocol
+	for session := range s.sessions {
+		if session.worker != "" {
+			sessions = append(sessions, session)
+			protocols = append(protocols, session.protocol)
+		}
+	}
+	s.lock.Unlock()
+
+	for i, session := range sessions {
+		if err := s.notify(session, protocols[i], job); err != nil {
+			log.Debug("Stratum: failed to notify miner", "remote", session.conn.RemoteAddr(), "err", err)
+			session.conn.Close()
+		}
+	}
+}
+
+func (s *StratumServer) notify(session *stratumSession, protocol stratumProtocol, job *stratumJob) error {
+	if protocol == stratumEthereumStratum {
+		return s.write(session, &stratumNotification{
+			Method: "mining.notify",
+			Params: []interface{}{stratumHex(job.sealHash), stratumHex(job.seedHash), stratumHex(job.sealHash), true},
+		})
+	}
+	return s.write(session, &stratumResponse{ID: json.RawMessage("0"), Version: "2.0", Result: s.workPackage(job)})
+}
+
+// workPackage - eth-proxy work: seal hash, seed hash, boundary of shares and block number
+func (s *StratumServer) workPackage(job *stratumJob) [4]string {
+	return [4]string{
+		job.sealHash.Hex(),
+		job.seedHash.Hex(),
+		common.BytesToHash(s.shareTarget(job).Bytes()).Hex(),
+		hexutil.EncodeUint64(job.number),
+	}
+}
+
+// shareTarget - boundary of shares of the job, blocks easier than the pool difficulty are shares too
+func (s *StratumServer) shareTarget(job *stratumJob) *big.Int {
+	if job.target.Cmp(s.target) > 0 {
+		return job.target
+	}
+	return s.target
+}
+
+func (s *StratumServer) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Temporary() { //nolint:staticcheck
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			log.Warn("Stratum server stopped accepting miners", "err", err)
+			return
+		}
+		session := &stratumSession{conn: conn}
+		s.lock.Lock()
+		s.sessions[session] = struct{}{}
+		s.lock.Unlock()
+		s.wg.Add(1)
+		go s.handle(session)
+	}
+}
+
+func (s *StratumServer) handle(session *stratumSession) {
+	defer s.wg.Done()
+	defer func() {
+		session.conn.Close()
+		s.lock.Lock()
+		delete(s.sessions, session)
+		s.lock.Unlock()
+	}()
+	log.Debug("Stratum: miner connected", "remote", session.conn.RemoteAddr())
+	scanner := bufio.NewScanner(session.conn)
+	scanner.Buffer(make([]byte, stratumMaxLine), stratumMaxLine)
+	for {
+		if err := session.conn.SetReadDeadline(time.Now().Add(stratumReadTimeout)); err != nil {
+			return
+		}
+		if !scanner.Scan() {
+			log.Debug("Stratum: miner disconnected", "remote", session.conn.RemoteAddr(), "err", scanner.Err())
+			return
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req stratumRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			log.Debug("Stratum: malformed request", "remote", session.conn.RemoteAddr(), "err", err)
+			return
+		}
+		if err := s.handleRequest(session, &req); err != nil {
+			log.Debug("Stratum: failed to reply", "remote", session.conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+}
+
+// handleRequest replies to the request of the miner, only errors of the connection are returned
+func (s *StratumServer) handleRequest(session *stratumSession, req *stratumRequest) error {
+	s.lock.Lock()
+	protocol, worker, extranonce := session.protocol, session.worker, session.extranonce
+	s.lock.Unlock()
+
+	switch req.Method {
+	// EthereumStratum/1.0.0
+	case "mining.subscribe":
+		s.lock.Lock()
+		s.extranonce++
+		s.sessionID++
+		session.protocol = stratumEthereumStratum
+		session.extranonce = fmt.Sprintf("%04x", s.extranonce)
+		extranonce, id := session.extranonce, fmt.Sprintf("%08x", s.sessionID)
+		s.lock.Unlock()
+		return s.reply(session, stratumEthereumStratum, req, []interface{}{[]string{"mining.notify", id, "EthereumStratum/1.0.0"}, extranonce}, nil)
+	case "mining.extranonce.subscribe":
+		return s.reply(session, protocol, req, true, nil)
+	case "mining.authorize":
+		if protocol != stratumEthereumStratum {
+			return s.reply(session, protocol, req, nil, errStratumNotSubscribed)
+		}
+		name := stringParam(req.Params, 0)
+		if name == "" {
+			return s.reply(session, protocol, req, nil, errStratumUnauthorized)
+		}
+		s.lock.Lock()
+		session.worker = name
+		s.worker(name)
+		job := s.current
+		s.lock.Unlock()
+		if err := s.reply(session, protocol, req, true, nil); err != nil {
+			return err
+		}
+		difficulty, _ := new(big.Float).Quo(new(big.Float).SetInt(s.difficulty), new(big.Float).SetInt(stratumDiff1)).Float64()
+		if err := s.write(session, &stratumNotification{Method: "mining.set_difficulty", Params: []interface{}{difficulty}}); err != nil {
+			return err
+		}
+		if job != nil {
+			return s.notify(session, protocol, job)
+		}
+		return nil
+	case "mining.submit":
+		if protocol != stratumEthereumStratum || worker == "" {
+			return s.reply(session, protocol, req, nil, errStratumUnauthorized)
+		}
+		jobID, suffix := stringParam(req.Params, 1), stringParam(req.Params, 2)
+		nonce, err := strconv.ParseUint(extranonce+strings.TrimPrefix(suffix, "0x"), 16, 64)
+		if err != nil || len(extranonce)+len(strings.TrimPrefix(suffix, "0x")) != 16 {
+			return s.reply(session, protocol, req, nil, fmt.Errorf("invalid nonce %q", suffix))
+		}
+		if len(common.FromHex(jobID)) != common.HashLength {
+			return s.reply(session, protocol, req, nil, errStratumUnknownJob)
+		}
+		err = s.submitShare(worker, common.HexToHash(jobID), nonce, nil)
+		return s.reply(session, protocol, req, err == nil, err)
+
+	// eth-proxy
+	case "eth_submitLogin":
+		name := stringParam(req.Params, 0)
+		if name == "" {
+			return s.reply(session, stratumEthProxy, req, nil, errStratumUnauthorized)
+		}
+		if req.Worker != "" {
+			name += "." + req.Worker
+		}
+		s.lock.Lock()
+		session.protocol = stratumEthProxy
+		session.worker = name
+		s.worker(name)
+		s.lock.Unlock()
+		return s.reply(session, stratumEthProxy, req, true, nil)
+	case "eth_getWork":
+		if protocol != stratumEthProxy || worker == "" {
+			return s.reply(session, protocol, req, nil, errStratumUnauthorized)
+		}
+		s.lock.Lock()
+		job := s.current
+		s.lock.Unlock()
+		if job == nil {
+			return s.reply(session, protocol, req, nil, errNoMiningWork)
+		}
+		return s.reply(session, protocol, req, s.workPackage(job), nil)
+	case "eth_submitWork":
+		if protocol != stratumEthProxy || worker == "" {
+			return s.reply(session, protocol, req, nil, errStratumUnauthorized)
+		}
+		nonce := common.FromHex(stringParam(req.Params, 0))
+		if len(nonce) != 8 {
+			return s.reply(session, protocol, req, nil, fmt.Errorf("invalid nonce %q", stringParam(req.Params, 0)))
+		}
+		mixDigest := common.HexToHash(stringParam(req.Params, 2))
+		err := s.submitShare(worker, common.HexToHash(stringParam(req.Params, 1)), binary.BigEndian.Uint64(nonce), &mixDigest)
+		return s.reply(session, protocol, req, err == nil, err)
+
+	// both protocols
+	case "eth_submitHashrate":
+		if worker == "" {
+			return s.reply(session, protocol, req, nil, errStratumUnauthorized)
+		}
+		rate := new(big.Int).SetBytes(common.FromHex(stringParam(req.Params, 0)))
+		if !rate.IsUint64() {
+			return s.reply(session, protocol, req, nil, fmt.Errorf("invalid hashrate %q", stringParam(req.Params, 0)))
+		}
+		s.lock.Lock()
+		w := s.worker(worker)
+		w.ReportedRate = rate.Uint64()
+		s.lock.Unlock()
+		ok := s.api.SubmitHashRate(hexutil.Uint64(rate.Uint64()), common.HexToHash(stringParam(req.Params, 1)))
+		return s.reply(session, protocol, req, ok, nil)
+	default:
+		return s.reply(session, protocol, req, nil, fmt.Errorf("method %q not found", req.Method))
+	}
+}
+
+// submitShare checks the share against the pool difficulty and submits it to the remote sealer if it solves the block.
+// mixDigest is nil for EthereumStratum/1.0.0 miners, which don't send it.
+func (s *StratumServer) submitShare(name string, sealHash common.Hash, nonce uint64, mixDigest *common.Hash) error {
+	s.lock.Lock()
+	job := s.jobs[sealHash]
+	if job == nil {
+		s.worker(name).Rejected++
+		s.lock.Unlock()
+		return errStratumUnknownJob
+	}
+	if _, ok := job.nonces[nonce]; ok {
+		s.worker(name).Rejected++
+		s.lock.Unlock()
+		return errStratumDuplicateShare
+	}
+	s.lock.Unlock()
+
+	digest, result := s.ethash.hashLight(job.number, sealHash.Bytes(), nonce)
+	err := errStratumLowDifficulty
+	target := s.shareTarget(job)
+	if mixDigest != nil && !bytes.Equal(mixDigest[:], digest) {
+		err = errInvalidMixDigest
+	} else if new(big.Int).SetBytes(result).Cmp(target) <= 0 {
+		err = nil
+	}
+	if err == nil {
+		// only valid shares are remembered, the same share may have been submitted meanwhile
+		s.lock.Lock()
+		if _, ok := job.nonces[nonce]; ok {
+			err = errStratumDuplicateShare
+		}
+		job.nonces[nonce] = struct{}{}
+		s.lock.Unlock()
+	}
+	var block bool
+	if err == nil && new(big.Int).SetBytes(result).Cmp(job.target) <= 0 {
+		if block = s.api.SubmitWork(types.EncodeNonce(nonce), sealHash, common.BytesToHash(digest)); block {
+			log.Info("Stratum: block found", "worker", name, "number", job.number, "sealhash", sealHash)
+		} else {
+			log.Warn("Stratum: block rejected by the sealer", "worker", name, "number", job.number, "sealhash", sealHash)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	w := s.worker(name)
+	if err != nil {
+		w.Rejected++
+		return err
+	}
+	w.Accepted++
+	if block {
+		w.Blocks++
+	}
+	difficulty, _ := new(big.Float).SetInt(new(big.Int).Div(two256, target)).Float64()
+	w.shares = append(w.shares, stratumShare{at: time.Now(), difficulty: difficulty})
+	return nil
+}
+
+// worker returns the stats of the worker, s.lock must be held
+func (s *StratumServer) worker(name string) *stratumWorker {
+	now := time.Now()
+	w, ok := s.workers[name]
+	if !ok {
+		w = &stratumWorker{StratumWorker: StratumWorker{Name: name}, since: now}
+		s.workers[name] = w
+	}
+	w.LastSeen = now
+	return w
+}
+
+// report logs the hashrate of the active workers and forgets the inactive ones
+func (s *StratumServer) report() {
+	for _, w := range s.Workers() {
+		if time.Since(w.LastSeen) > stratumRateWindow {
+			s.lock.Lock()
+			delete(s.workers, w.Name)
+			s.lock.Unlock()
+			continue
+		}
+		log.Info("Stratum worker", "name", w.Name, "reported", w.ReportedRate, "effective", w.EffectiveRate,
+			"accepted", w.Accepted, "rejected", w.Rejected, "blocks", w.Blocks)
+	}
+}
+
+func (s *StratumServer) reply(session *stratumSession, protocol stratumProtocol, req *stratumRequest, result interface{}, err error) error {
+	res := &stratumResponse{ID: req.ID, Result: result}
+	if len(res.ID) == 0 {
+		res.ID = json.RawMessage("null")
+	}
+	if err != nil {
+		res.Result = nil
+		if protocol == stratumEthProxy {
+			res.Error = map[string]interface{}{"code": stratumErrorCode(err), "message": err.Error()}
+		} else {
+			res.Error = []interface{}{stratumErrorCode(err), err.Error(), nil}
+		}
+	}
+	if protocol == stratumEthProxy {
+		res.Version = "2.0"
+	}
+	return s.write(session, res)
+}
+
+func (s *StratumServer) write(session *stratumSession, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	session.writeLock.Lock()
+	defer session.writeLock.Unlock()
+	if err = session.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout)); err != nil {
+		return err
+	}
+	_, err = session.conn.Write(append(data, '\n'))
+	return err
+}
+
+func stringParam(params []interface{}, i int) string {
+	if i >= len(params) {
+		return ""
+	}
+	str, _ := params[i].(string)
+	return str
+}
+
+// stratumHex - hash without 0x prefix, as EthereumStratum/1.0.0 sends it
+func stratumHex(hash common.Hash) string {
+	return strings.TrimPrefix(hash.Hex(), "0x")
+}
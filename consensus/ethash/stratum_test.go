@@ -0,0 +1,228 @@
+package ethash
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+)
+
+type stratumTestClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialStratum(t *testing.T, server *StratumServer) *stratumTestClient {
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &stratumTestClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *stratumTestClient) send(req string) {
+	if _, err := c.conn.Write([]byte(req + "\n")); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *stratumTestClient) read() map[string]interface{} {
+	if err := c.conn.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		c.t.Fatal(err)
+	}
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		c.t.Fatalf("failed to read stratum message: %v", err)
+	}
+	var msg map[string]interface{}
+	if err = json.Unmarshal(line, &msg); err != nil {
+		c.t.Fatalf("malformed stratum message %s: %v", line, err)
+	}
+	return msg
+}
+
+// findNonce searches for the nonce with the given prefix, whose PoW value is within the bounds
+func findNonce(t *testing.T, ethash *Ethash, header *types.Header, prefix uint64, min, max *big.Int) uint64 {
+	sealHash := ethash.SealHash(header).Bytes()
+	for nonce := prefix; nonce < prefix+1000000; nonce++ {
+		_, result := ethash.hashLight(header.Number.Uint64(), sealHash, nonce)
+		value := new(big.Int).SetBytes(result)
+		if (min == nil || value.Cmp(min) > 0) && value.Cmp(max) <= 0 {
+			return nonce
+		}
+	}
+	t.Fatal("nonce not found")
+	return 0
+}
+
+func startStratumTest(t *testing.T, difficulty uint64) (*Ethash, *StratumServer) {
+	ethash := NewTester(nil, false)
+	t.Cleanup(func() { ethash.Close() })
+	server, err := StartStratumServer(ethash, "127.0.0.1:0", difficulty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return ethash, server
+}
+
+func TestStratumEthereumStratum(t *testing.T) {
+	ethash, server := startStratumTest(t, 10)
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	results := make(chan *types.Block, 1)
+	if err := ethash.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	c := dialStratum(t, server)
+	c.send(`{"id":1,"method":"mining.submit","params":["miner","00","0000"]}`)
+	if res := c.read(); res["error"] == nil {
+		t.Errorf("share accepted before authorization: %v", res)
+	}
+	c.send(`{"id":2,"method":"mining.subscribe","params":["ethminer/0.19.0","EthereumStratum/1.0.0"]}`)
+	res := c.read()
+	extranonce := res["result"].([]interface{})[1].(string)
+	if len(extranonce) != 4 {
+		t.Fatalf("extranonce %q", extranonce)
+	}
+	c.send(`{"id":3,"method":"mining.authorize","params":["miner","x"]}`)
+	if res = c.read(); res["result"] != true {
+		t.Fatalf("authorization failed: %v", res)
+	}
+	msg := c.read()
+	if msg["method"] != "mining.set_difficulty" || msg["params"].([]interface{})[0].(float64) != 10/float64(1<<32) {
+		t.Errorf("unexpected difficulty message %v", msg)
+	}
+	msg = c.read()
+	if msg["method"] != "mining.notify" {
+		t.Fatalf("unexpected job message %v", msg)
+	}
+	params := msg["params"].([]interface{})
+	jobID := params[0].(string)
+	if want := stratumHex(ethash.SealHash(header)); params[2] != want || jobID != want {
+		t.Errorf("job header hash mismatch: have %v, want %s", params[2], want)
+	}
+	if want := stratumHex(common.BytesToHash(SeedHash(1))); params[1] != want {
+		t.Errorf("job seed hash mismatch: have %v, want %s", params[1], want)
+	}
+
+	prefix, _ := strconv.ParseUint(extranonce, 16, 64)
+	prefix <<= 48
+	submit := func(id int, nonce uint64) map[string]interface{} {
+		c.send(`{"id":` + strconv.Itoa(id) + `,"method":"mining.submit","params":["miner","` + jobID + `","` + fmt.Sprintf("%016x", nonce)[4:] + `"]}`)
+		return c.read()
+	}
+	shareTarget := new(big.Int).Div(two256, big.NewInt(10))
+	blockTarget := new(big.Int).Div(two256, big.NewInt(100))
+
+	low := findNonce(t, ethash, header, prefix, shareTarget, two256)
+	if res = submit(4, low); res["error"].([]interface{})[0].(float64) != 23 {
+		t.Errorf("low difficulty share: %v", res)
+	}
+	share := findNonce(t, ethash, header, prefix, blockTarget, shareTarget)
+	if res = submit(5, share); res["result"] != true {
+		t.Errorf("share rejected: %v", res)
+	}
+	if res = submit(6, share); res["error"].([]interface{})[0].(float64) != 22 {
+		t.Errorf("duplicate share: %v", res)
+	}
+	select {
+	case block := <-results:
+		t.Fatalf("share sealed block %d", block.NumberU64())
+	default:
+	}
+
+	solution := findNonce(t, ethash, header, prefix, nil, blockTarget)
+	if res = submit(7, solution); res["result"] != true {
+		t.Errorf("block solution rejected: %v", res)
+	}
+	select {
+	case block := <-results:
+		if block.Nonce() != solution {
+			t.Errorf("sealed block nonce mismatch: have %d, want %d", block.Nonce(), solution)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("block solution not submitted")
+	}
+
+	workers := server.Workers()
+	if len(workers) != 1 || workers[0].Name != "miner" || workers[0].Accepted != 2 || workers[0].Rejected != 2 || workers[0].Blocks != 1 || workers[0].EffectiveRate == 0 {
+		t.Errorf("unexpected workers %+v", workers)
+	}
+}
+
+func TestStratumEthProxy(t *testing.T) {
+	ethash, server := startStratumTest(t, 10)
+
+	c := dialStratum(t, server)
+	c.send(`{"id":1,"jsonrpc":"2.0","method":"eth_submitLogin","params":["0xwallet","x"],"worker":"rig1"}`)
+	if res := c.read(); res["result"] != true {
+		t.Fatalf("login failed: %v", res)
+	}
+	c.send(`{"id":2,"jsonrpc":"2.0","method":"eth_getWork","params":[]}`)
+	if res := c.read(); res["error"] == nil {
+		t.Errorf("work before the mining stage: %v", res)
+	}
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	results := make(chan *types.Block, 1)
+	if err := ethash.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatal(err)
+	}
+	shareTarget := common.BytesToHash(new(big.Int).Div(two256, big.NewInt(10)).Bytes()).Hex()
+	push := c.read()
+	if push["id"].(float64) != 0 {
+		t.Fatalf("unexpected push %v", push)
+	}
+	work := push["result"].([]interface{})
+	if work[0] != ethash.SealHash(header).Hex() || work[2] != shareTarget {
+		t.Errorf("unexpected work %v", work)
+	}
+	c.send(`{"id":3,"jsonrpc":"2.0","method":"eth_getWork","params":[]}`)
+	if res := c.read(); res["result"].([]interface{})[0] != work[0] {
+		t.Errorf("unexpected work %v", res)
+	}
+
+	blockTarget := new(big.Int).Div(two256, big.NewInt(100))
+	solution := findNonce(t, ethash, header, 0, nil, blockTarget)
+	digest, _ := ethash.hashLight(1, ethash.SealHash(header).Bytes(), solution)
+	nonce := types.EncodeNonce(solution)
+	c.send(`{"id":4,"jsonrpc":"2.0","method":"eth_submitWork","params":["` + hexutil.Encode(nonce[:]) + `","` + work[0].(string) + `","` + common.Hash{1}.Hex() + `"]}`)
+	if res := c.read(); res["error"] == nil {
+		t.Errorf("share with wrong mix digest accepted: %v", res)
+	}
+	c.send(`{"id":5,"jsonrpc":"2.0","method":"eth_submitWork","params":["` + hexutil.Encode(nonce[:]) + `","` + work[0].(string) + `","` + common.BytesToHash(digest).Hex() + `"]}`)
+	if res := c.read(); res["result"] != true {
+		t.Errorf("block solution rejected: %v", res)
+	}
+	select {
+	case block := <-results:
+		if block.MixDigest() != common.BytesToHash(digest) {
+			t.Errorf("sealed block mix digest mismatch")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("block solution not submitted")
+	}
+
+	c.send(`{"id":6,"jsonrpc":"2.0","method":"eth_submitHashrate","params":["0x0000000000000000000000000000000000000000000000000000000000100000","` + common.Hash{7}.Hex() + `"]}`)
+	if res := c.read(); res["result"] != true {
+		t.Errorf("hashrate rejected: %v", res)
+	}
+	if rate := ethash.Hashrate(); rate != 0x100000 {
+		t.Errorf("node hashrate mismatch: have %f, want %d", rate, 0x100000)
+	}
+	workers := server.Workers()
+	if len(workers) != 1 || workers[0].Name != "0xwallet.rig1" || workers[0].ReportedRate != 0x100000 || workers[0].Blocks != 1 || workers[0].Rejected != 1 {
+		t.Errorf("unexpected workers %+v", workers)
+	}
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/pelletier/go-toml"
+)
+
+// ChainSpec is a custom chain loaded from a file instead of the hard-coded chains of params:
+// the genesis block with its chain config (fork blocks and consensus engine), plus the networking
+// settings which params keeps next to the built-in chains.
+//
+// The file is a genesis JSON (or TOML with the same field names and value encodings)
+// with the extra top-level fields "name", "networkId" and "bootnodes".
+type ChainSpec struct {
+	Name      string   `json:"name,omitempty"`
+	NetworkID uint64   `json:"networkId,omitempty"`
+	Bootnodes []string `json:"bootnodes,omitempty"`
+
+	Genesis *Genesis `json:"-"`
+}
+
+// LoadChainSpec reads the chain spec from a .json or .toml file
+func LoadChainSpec(path string) (*ChainSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("chain spec %s: %w", path, err)
+		}
+		if data, err = json.Marshal(tree.ToMap()); err != nil {
+			return nil, fmt.Errorf("chain spec %s: %w", path, err)
+		}
+	}
+	spec, err := ParseChainSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("chain spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// ParseChainSpec decodes and validates the JSON chain spec, filling the defaults:
+// network id is the chain id and chains without consensus engine section run ethash.
+func ParseChainSpec(data []byte) (*ChainSpec, error) {
+	spec := &ChainSpec{Genesis: new(Genesis)}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, spec.Genesis); err != nil {
+		return nil, err
+	}
+
+	config := spec.Genesis.Config
+	if config == nil {
+		return nil, ErrGenesisNoConfig
+	}
+	if config.ChainID == nil {
+		return nil, errors.New("chainId is not set in the chain config")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return nil, err
+	}
+	var engines int
+	if config.Ethash != nil {
+		engines++
+	}
+	if config.Clique != nil {
+		engines++
+		extra := spec.Genesis.ExtraData
+		if len(extra) < 32+crypto.SignatureLength || (len(extra)-32-crypto.SignatureLength)%common.AddressLength != 0 {
+			return nil, errors.New("clique genesis extraData must be 32 bytes vanity, the signers and 65 zero bytes")
+		}
+	}
+	if config.Aura != nil {
+		engines++
+		if len(config.Aura.Params) == 0 {
+			return nil, errors.New("aura chain config requires the authority round engine params")
+		}
+	}
+	switch engines {
+	case 0:
+		config.Ethash = new(params.EthashConfig)
+	case 1:
+	default:
+		return nil, errors.New("more than one consensus engine in the chain config")
+	}
+
+	if config.ChainName == "" {
+		config.ChainName = spec.Name
+	}
+	if spec.NetworkID == 0 {
+		spec.NetworkID = config.ChainID.Uint64()
+	}
+	return spec, nil
+}
+
+// CommitChainSpec is CommitGenesisBlock for custom chains, it also stores the networking settings
+// of the spec in the Config bucket, so the node can be restarted without the spec file.
+func CommitChainSpec(db ethdb.RwKV, spec *ChainSpec, history bool) (*params.ChainConfig, *types.Block, error) {
+	tx, err := db.BeginRw(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	c, b, err := WriteGenesisBlock(tx, spec.Genesis, history)
+	if err != nil {
+		return c, b, err
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return c, b, err
+	}
+	if err = rawdb.WriteChainSpec(tx, b.Hash(), data); err != nil {
+		return c, b, err
+	}
+	if err = tx.Commit(); err != nil {
+		return c, b, err
+	}
+	return c, b, nil
+}
+
+// ReadChainSpec returns the stored networking settings of a custom chain (without genesis),
+// nil if the database is empty or holds one of the built-in chains
+func ReadChainSpec(tx ethdb.Tx) (*ChainSpec, error) {
+	genesisHash, err := rawdb.ReadCanonicalHash(tx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if genesisHash == (common.Hash{}) {
+		return nil, nil
+	}
+	data, err := rawdb.ReadChainSpec(tx, genesisHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	spec := &ChainSpec{}
+	if err = json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("invalid chain spec JSON: %x, %w", genesisHash, err)
+	}
+	return spec, nil
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+const cliqueSpecJSON = `{
+	"name": "private",
+	"bootnodes": ["enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"],
+	"config": {
+		"chainId": 4242,
+		"homesteadBlock": 0,
+		"eip150Block": 0,
+		"eip155Block": 0,
+		"eip158Block": 0,
+		"byzantiumBlock": 0,
+		"constantinopleBlock": 0,
+		"petersburgBlock": 0,
+		"istanbulBlock": 10,
+		"clique": {"period": 5, "epoch": 30000}
+	},
+	"gasLimit": "0x47b760",
+	"difficulty": "0x1",
+	"extraData": "0x00000000000000000000000000000000000000000000000000000000000000007df9a875a174b3bc565e6424a0050ebc1b2d1d820000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	"alloc": {"0x7df9a875a174b3bc565e6424a0050ebc1b2d1d82": {"balance": "0x100"}}
+}`
+
+const ethashSpecTOML = `
+name = "tomlnet"
+networkId = 77777
+gasLimit = "0x1000000"
+difficulty = "0x20000"
+
+[config]
+chainId = 1234
+homesteadBlock = 0
+eip150Block = 0
+eip155Block = 0
+eip158Block = 0
+byzantiumBlock = 5
+
+[alloc."0x0000000000000000000000000000000000000001"]
+balance = "0x1"
+`
+
+func TestChainSpecJSON(t *testing.T) {
+	spec, err := ParseChainSpec([]byte(cliqueSpecJSON))
+	require.NoError(t, err)
+	require.Equal(t, "private", spec.Name)
+	require.Equal(t, uint64(4242), spec.NetworkID) // defaults to chain id
+	require.Len(t, spec.Bootnodes, 1)
+	require.Equal(t, "private", spec.Genesis.Config.ChainName)
+	require.Equal(t, uint64(5), spec.Genesis.Config.Clique.Period)
+	require.Nil(t, spec.Genesis.Config.Ethash)
+	require.Equal(t, big.NewInt(10), spec.Genesis.Config.IstanbulBlock)
+	require.Equal(t, big.NewInt(0x100), spec.Genesis.Alloc[common.HexToAddress("0x7df9a875a174b3bc565e6424a0050ebc1b2d1d82")].Balance)
+}
+
+func TestChainSpecTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.toml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(ethashSpecTOML), 0600))
+	spec, err := LoadChainSpec(path)
+	require.NoError(t, err)
+	require.Equal(t, uint64(77777), spec.NetworkID)
+	require.Equal(t, big.NewInt(1234), spec.Genesis.Config.ChainID)
+	require.Equal(t, big.NewInt(5), spec.Genesis.Config.ByzantiumBlock)
+	require.NotNil(t, spec.Genesis.Config.Ethash) // no engine section - ethash
+	require.Equal(t, uint64(0x1000000), spec.Genesis.GasLimit)
+}
+
+func TestChainSpecInvalid(t *testing.T) {
+	for name, spec := range map[string]string{
+		"no config":   `{"gasLimit": "0x1", "difficulty": "0x1", "alloc": {}}`,
+		"no chain id": `{"config": {"homesteadBlock": 0}, "gasLimit": "0x1", "difficulty": "0x1", "alloc": {}}`,
+		"two engines": `{"config": {"chainId": 1, "ethash": {}, "clique": {"period": 1, "epoch": 1}}, "extraData": "0x` + strings.Repeat("00", 32+65) + `", "gasLimit": "0x1", "difficulty": "0x1", "alloc": {}}`,
+		"no signers":  `{"config": {"chainId": 1, "clique": {"period": 1, "epoch": 1}}, "gasLimit": "0x1", "difficulty": "0x1", "alloc": {}}`,
+		"aura params": `{"config": {"chainId": 1, "aura": {}}, "gasLimit": "0x1", "difficulty": "0x1", "alloc": {}}`,
+		"fork order":  `{"config": {"chainId": 1, "homesteadBlock": 5, "byzantiumBlock": 1}, "gasLimit": "0x1", "difficulty": "0x1", "alloc": {}}`,
+	} {
+		_, err := ParseChainSpec([]byte(spec))
+		require.Error(t, err, name)
+	}
+}
+
+func TestCommitChainSpec(t *testing.T) {
+	db := kv.NewTestKV(t)
+	spec, err := ParseChainSpec([]byte(cliqueSpecJSON))
+	require.NoError(t, err)
+
+	config, genesis, err := CommitChainSpec(db, spec, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4242), config.ChainID.Uint64())
+
+	// restart without the spec file: genesis, chain config and networking settings come from the db
+	config, block, err := CommitGenesisBlock(db, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Hash(), block.Hash())
+	require.Equal(t, uint64(5), config.Clique.Period)
+	require.NoError(t, db.View(context.Background(), func(tx ethdb.Tx) error {
+		stored, err := ReadChainSpec(tx)
+		require.NoError(t, err)
+		require.Equal(t, "private", stored.Name)
+		require.Equal(t, uint64(4242), stored.NetworkID)
+		require.Equal(t, spec.Bootnodes, stored.Bootnodes)
+		return nil
+	}))
+
+	// built-in chains have no stored spec
+	db2 := kv.NewTestKV(t)
+	_, _, err = CommitGenesisBlock(db2, DefaultRinkebyGenesisBlock(), false)
+	require.NoError(t, err)
+	require.NoError(t, db2.View(context.Background(), func(tx ethdb.Tx) error {
+		stored, err := ReadChainSpec(tx)
+		require.NoError(t, err)
+		require.Nil(t, stored)
+		return nil
+	}))
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+)
+
+// buckets a fork source is read from: the state with its history and the canonical headers
+var forkChaindataBuckets = []string{
+	dbutils.PlainStateBucket,
+	dbutils.PlainContractCodeBucket,
+	dbutils.CodeBucket,
+	dbutils.IncarnationMapBucket,
+	dbutils.AccountChangeSetBucket,
+	dbutils.StorageChangeSetBucket,
+	dbutils.AccountsHistoryBucket,
+	dbutils.StorageHistoryBucket,
+	dbutils.HeaderCanonicalBucket,
+	dbutils.HeadersBucket,
+	dbutils.SyncStageProgress,
+	dbutils.DatabaseInfoBucket,
+}
+
+// buckets of the state snapshots (see snapshotsync)
+var forkSnapshotBuckets = []string{
+	dbutils.PlainStateBucket,
+	dbutils.PlainContractCodeBucket,
+	dbutils.CodeBucket,
+}
+
+func openForkSourceDB(path string, buckets []string) (ethdb.RwKV, error) {
+	return kv.NewMDBX().Path(path).Readonly().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		cfg := make(dbutils.BucketsCfg, len(buckets))
+		for _, name := range buckets {
+			cfg[name] = defaultBuckets[name]
+		}
+		return cfg
+	}).Open()
+}
+
+// OpenForkSource opens chaindata of another node or a state snapshot read-only, as the source of a forked dev chain.
+// Chaindata is forked at blockNr (0 - at its latest executed block), it must keep the history to fork
+// from the past blocks. Plain state of a snapshot has no block number, blockNr of the snapshot is required.
+// Returns the header of the fork block, nil for snapshots.
+func OpenForkSource(path string, blockNr uint64) (*state.ForkSource, *types.Header, error) {
+	// read-only database can't be opened with buckets it doesn't have, snapshot buckets are in both kinds
+	db, err := openForkSourceDB(path, forkSnapshotBuckets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fork source %s: %w", path, err)
+	}
+	var existing []string
+	if err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		existing, err = tx.(ethdb.BucketMigrator).ExistingBuckets()
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("fork source %s: %w", path, err)
+	}
+	var isChaindata bool
+	for _, name := range existing {
+		if name == dbutils.HeaderCanonicalBucket {
+			isChaindata = true
+		}
+	}
+	if !isChaindata {
+		if blockNr == 0 {
+			db.Close()
+			return nil, nil, errors.New("block number of the state snapshot is required to fork from it")
+		}
+		return state.NewForkSource(db, blockNr, true, true), nil, nil
+	}
+	db.Close()
+	if db, err = openForkSourceDB(path, forkChaindataBuckets); err != nil {
+		return nil, nil, fmt.Errorf("fork source %s: %w", path, err)
+	}
+
+	var header *types.Header
+	var latest bool
+	if err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		executed, err := stages.GetStageProgress(tx, stages.Execution)
+		if err != nil {
+			return err
+		}
+		if blockNr == 0 {
+			blockNr = executed
+		}
+		if blockNr > executed {
+			return fmt.Errorf("block %d is not executed yet, source is at %d", blockNr, executed)
+		}
+		latest = blockNr == executed
+		if !latest {
+			sm, err := ethdb.GetStorageModeFromDB(tx)
+			if err != nil {
+				return err
+			}
+			if !sm.History {
+				return fmt.Errorf("source keeps no history, can only fork at its latest block %d", executed)
+			}
+		}
+		if header = rawdb.ReadHeaderByNumber(tx, blockNr); header == nil {
+			return fmt.Errorf("header %d not found", blockNr)
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("fork source %s: %w", path, err)
+	}
+	return state.NewForkSource(db, blockNr, latest, false), header, nil
+}
+
+// DeveloperForkGenesisBlock returns the 'geth --dev' genesis block of a chain forked from the state of another chain
+// after the given header (nil for state snapshots). The state is not copied: only the faucet is allocated
+// by the genesis, everything else is read from the source (see state.ForkStateReader).
+func DeveloperForkGenesisBlock(period uint64, faucet common.Address, source *types.Header) *Genesis {
+	g := DeveloperGenesisBlock(period, faucet)
+	g.Alloc = GenesisAlloc{faucet: g.Alloc[faucet]} // precompiles are in the source state
+	if source != nil {
+		g.ParentHash = source.Hash()
+		g.Timestamp = source.Time
+		if source.GasLimit > g.GasLimit {
+			g.GasLimit = source.GasLimit
+		}
+	}
+	return g
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeveloperFork(t *testing.T) {
+	rich, faucet := common.HexToAddress("0x1000"), common.HexToAddress("0x2000")
+
+	// chaindata of the source
+	path := t.TempDir()
+	sourceDB := kv.NewMDBX().Path(path).MustOpen()
+	_, sourceGenesis, err := CommitGenesisBlock(sourceDB, &Genesis{
+		Config:     params.TestChainConfig,
+		GasLimit:   30_000_000,
+		Difficulty: big.NewInt(1),
+		Timestamp:  1000,
+		Alloc:      GenesisAlloc{rich: {Balance: big.NewInt(1_000_000)}},
+	}, false)
+	require.NoError(t, err)
+	sourceDB.Close()
+
+	_, _, err = OpenForkSource(path, 5)
+	require.Error(t, err, "block is not executed by the source")
+
+	fork, header, err := OpenForkSource(path, 0)
+	require.NoError(t, err)
+	defer fork.Close()
+	require.Equal(t, uint64(0), fork.BlockNumber())
+	require.Equal(t, sourceGenesis.Hash(), header.Hash())
+
+	genesis := DeveloperForkGenesisBlock(0, faucet, header)
+	require.Equal(t, sourceGenesis.Hash(), genesis.ParentHash)
+	require.Equal(t, uint64(1000), genesis.Timestamp)
+	require.Equal(t, uint64(30_000_000), genesis.GasLimit)
+	require.Len(t, genesis.Alloc, 1)
+
+	db := kv.NewTestKV(t)
+	_, _, err = CommitGenesisBlock(db, genesis, false)
+	require.NoError(t, err)
+	require.NoError(t, db.View(context.Background(), func(tx ethdb.Tx) error {
+		ibs := state.New(fork.Reader(state.NewPlainStateReader(tx), tx))
+		require.Equal(t, uint64(1_000_000), ibs.GetBalance(rich).Uint64())
+		require.False(t, ibs.GetBalance(faucet).IsZero())
+		return nil
+	}))
+}
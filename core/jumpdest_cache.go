@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb"
+)
+
+var _ vm.JumpDestCache = &DBJumpDestCache{}
+
+// DBJumpDestCache - JUMPDEST analysis cache layered over JumpDestAnalysisBucket: misses of the in-memory cache
+// are read from the bucket, new results are written to it if putter is set (Execution stage).
+// Read-only instance (putter is nil) is used by rpcdaemon.
+type DBJumpDestCache struct {
+	mem    vm.JumpDestCache
+	db     ethdb.KVGetter
+	putter ethdb.Putter
+	err    error
+}
+
+func NewDBJumpDestCache(mem vm.JumpDestCache, db ethdb.KVGetter, putter ethdb.Putter) *DBJumpDestCache {
+	return &DBJumpDestCache{mem: mem, db: db, putter: putter}
+}
+
+func (c *DBJumpDestCache) Get(codeHash common.Hash) ([]uint64, bool) {
+	if analysis, ok := c.mem.Get(codeHash); ok {
+		return analysis, true
+	}
+	v, err := c.db.GetOne(dbutils.JumpDestAnalysisBucket, codeHash[:])
+	if err != nil {
+		c.setErr(fmt.Errorf("reading JUMPDEST analysis of %x: %w", codeHash, err))
+		return nil, false
+	}
+	if len(v) == 0 {
+		return nil, false
+	}
+	analysis, err := vm.DecodeJumpDests(v)
+	if err != nil {
+		c.setErr(fmt.Errorf("decoding JUMPDEST analysis of %x: %w", codeHash, err))
+		return nil, false
+	}
+	c.mem.Add(codeHash, analysis)
+	return analysis, true
+}
+
+func (c *DBJumpDestCache) Add(codeHash common.Hash, analysis []uint64) {
+	c.mem.Add(codeHash, analysis)
+	if c.putter == nil {
+		return
+	}
+	if err := c.putter.Put(dbutils.JumpDestAnalysisBucket, common.CopyBytes(codeHash[:]), vm.EncodeJumpDests(analysis)); err != nil {
+		c.setErr(fmt.Errorf("writing JUMPDEST analysis of %x: %w", codeHash, err))
+	}
+}
+
+// Err - the first error of reading or writing db. Execution doesn't depend on it - analysis missing
+// because of error is done again, but writer must not commit incomplete data.
+func (c *DBJumpDestCache) Err() error {
+	return c.err
+}
+
+func (c *DBJumpDestCache) setErr(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
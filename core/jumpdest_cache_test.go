@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBJumpDestCache(t *testing.T) {
+	_, tx := kv.NewTestTx(t)
+	hash := common.HexToHash("0x01")
+	analysis := []uint64{0x6, 0}
+
+	writer := NewDBJumpDestCache(vm.NewJumpDestLRU(16), tx, tx)
+	_, ok := writer.Get(hash)
+	require.False(t, ok)
+	writer.Add(hash, analysis)
+	require.NoError(t, writer.Err())
+	v, err := tx.GetOne(dbutils.JumpDestAnalysisBucket, hash[:])
+	require.NoError(t, err)
+	require.Equal(t, vm.EncodeJumpDests(analysis), v)
+
+	// another process - empty in-memory cache, analysis is read from db
+	mem := vm.NewJumpDestLRU(16)
+	reader := NewDBJumpDestCache(mem, tx, nil)
+	got, ok := reader.Get(hash)
+	require.True(t, ok)
+	require.Equal(t, analysis, got)
+	got, ok = mem.Get(hash)
+	require.True(t, ok)
+	require.Equal(t, analysis, got)
+
+	// read-only cache doesn't write
+	other := common.HexToHash("0x02")
+	reader.Add(other, analysis)
+	has, err := tx.Has(dbutils.JumpDestAnalysisBucket, other[:])
+	require.NoError(t, err)
+	require.False(t, has)
+	require.NoError(t, reader.Err())
+}
@@ -130,6 +130,23 @@ func WriteHeadBlockHash(db ethdb.Putter, hash common.Hash) {
 	}
 }
 
+// ReadFinalizedBlockHash retrieves the hash of the block finalized by the consensus client.
+func ReadFinalizedBlockHash(db ethdb.KVGetter) (common.Hash, error) {
+	data, err := db.GetOne(dbutils.FinalizedBlockKey, []byte(dbutils.FinalizedBlockKey))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed ReadFinalizedBlockHash: %w", err)
+	}
+	return common.BytesToHash(data), nil
+}
+
+// WriteFinalizedBlockHash stores the hash of the block finalized by the consensus client.
+func WriteFinalizedBlockHash(db ethdb.Putter, hash common.Hash) error {
+	if err := db.Put(dbutils.FinalizedBlockKey, []byte(dbutils.FinalizedBlockKey), hash.Bytes()); err != nil {
+		return fmt.Errorf("failed to store finalized block hash: %w", err)
+	}
+	return nil
+}
+
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db ethdb.KVGetter, hash common.Hash, number uint64) rlp.RawValue {
 	data, err := db.GetOne(dbutils.HeadersBucket, dbutils.HeaderKey(number, hash))
@@ -871,3 +888,43 @@ func WriteEpoch(tx ethdb.RwTx, blockNum uint64, blockHash common.Hash, transitio
 	copy(k[8:], blockHash[:])
 	return tx.Put(dbutils.Epoch, k, transitionProof)
 }
+
+// FindEpochBeforeOrEqualNumber - the last epoch transition at or before the block number
+func FindEpochBeforeOrEqualNumber(tx ethdb.Tx, n uint64) (blockNum uint64, blockHash common.Hash, transitionProof []byte, err error) {
+	c, err := tx.Cursor(dbutils.Epoch)
+	if err != nil {
+		return 0, common.Hash{}, nil, err
+	}
+	defer c.Close()
+	seek := dbutils.EncodeBlockNumber(n + 1)
+	k, v, err := c.Seek(seek)
+	if err != nil {
+		return 0, common.Hash{}, nil, err
+	}
+	if k == nil {
+		k, v, err = c.Last()
+	} else {
+		k, v, err = c.Prev()
+	}
+	if err != nil {
+		return 0, common.Hash{}, nil, err
+	}
+	if k == nil {
+		return 0, common.Hash{}, nil, nil
+	}
+	return binary.BigEndian.Uint64(k), common.BytesToHash(k[8:]), v, nil
+}
+
+func ReadPendingEpoch(tx ethdb.Tx, blockNum uint64, blockHash common.Hash) (transitionProof []byte, err error) {
+	k := make([]byte, 8+32)
+	binary.BigEndian.PutUint64(k, blockNum)
+	copy(k[8:], blockHash[:])
+	return tx.GetOne(dbutils.PendingEpoch, k)
+}
+
+func WritePendingEpoch(tx ethdb.RwTx, blockNum uint64, blockHash common.Hash, transitionProof []byte) (err error) {
+	k := make([]byte, 8+32)
+	binary.BigEndian.PutUint64(k, blockNum)
+	copy(k[8:], blockHash[:])
+	return tx.Put(dbutils.PendingEpoch, k, transitionProof)
+}
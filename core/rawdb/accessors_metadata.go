@@ -58,6 +58,23 @@ func WriteChainConfig(db ethdb.Putter, hash common.Hash, cfg *params.ChainConfig
 	return nil
 }
 
+// chainSpecPrefix + genesis hash - networking settings of a custom chain, next to its chain config
+var chainSpecPrefix = []byte("chainSpec-")
+
+// ReadChainSpec retrieves the networking settings (JSON) of a custom chain based on the given genesis hash.
+// Built-in chains have none.
+func ReadChainSpec(db ethdb.KVGetter, hash common.Hash) ([]byte, error) {
+	return db.GetOne(dbutils.ConfigPrefix, append(common.CopyBytes(chainSpecPrefix), hash[:]...))
+}
+
+// WriteChainSpec writes the networking settings (JSON) of a custom chain to the database.
+func WriteChainSpec(db ethdb.Putter, hash common.Hash, spec []byte) error {
+	if err := db.Put(dbutils.ConfigPrefix, append(common.CopyBytes(chainSpecPrefix), hash[:]...), spec); err != nil {
+		return fmt.Errorf("failed to store chain spec: %w", err)
+	}
+	return nil
+}
+
 // DeleteChainConfig retrieves the consensus settings based on the given genesis hash.
 func DeleteChainConfig(db ethdb.Deleter, hash common.Hash) error {
 	return db.Delete(dbutils.ConfigPrefix, hash[:], nil)
@@ -0,0 +1,200 @@
+package state
+
+import (
+	"context"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/ethdb"
+)
+
+var _ StateReader = (*ForkStateReader)(nil)
+var _ StateReader = (*ForkSource)(nil)
+var _ WriterWithChangeSets = (*ForkStateWriter)(nil)
+
+// ForkStateReader reads the state of a dev chain forked from the state of another chain.
+// Local database holds only the state touched by the blocks of the fork, reads of everything else
+// fall through to the source. Items of the source deleted by the fork are marked in ForkDeletedBucket
+// of the local database by ForkStateWriter, so that absence in the local state is not mistaken for untouched.
+type ForkStateReader struct {
+	local  StateReader
+	db     ethdb.KVGetter
+	source StateReader
+}
+
+// NewForkStateReader layers local reader (latest or historical state of the fork) over the source reader,
+// db is the local database with ForkDeletedBucket
+func NewForkStateReader(local StateReader, db ethdb.KVGetter, source StateReader) *ForkStateReader {
+	return &ForkStateReader{local: local, db: db, source: source}
+}
+
+func (r *ForkStateReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	a, err := r.local.ReadAccountData(address)
+	if err != nil || a != nil {
+		return a, err
+	}
+	if deleted, err := r.db.Has(dbutils.ForkDeletedBucket, address[:]); err != nil || deleted {
+		return nil, err
+	}
+	return r.source.ReadAccountData(address)
+}
+
+func (r *ForkStateReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	v, err := r.local.ReadAccountStorage(address, incarnation, key)
+	if err != nil || len(v) > 0 {
+		return v, err
+	}
+	compositeKey := dbutils.PlainGenerateCompositeStorageKey(address[:], incarnation, key[:])
+	if deleted, err := r.db.Has(dbutils.ForkDeletedBucket, compositeKey); err != nil || deleted {
+		return nil, err
+	}
+	return r.source.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *ForkStateReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	code, err := r.local.ReadAccountCode(address, incarnation, codeHash)
+	if err != nil || len(code) > 0 {
+		return code, err
+	}
+	return r.source.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (r *ForkStateReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	code, err := r.ReadAccountCode(address, incarnation, codeHash)
+	return len(code), err
+}
+
+func (r *ForkStateReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	incarnation, err := r.local.ReadAccountIncarnation(address)
+	if err != nil || incarnation > 0 {
+		return incarnation, err
+	}
+	return r.source.ReadAccountIncarnation(address)
+}
+
+// ForkStateWriter marks deletions of the source state in ForkDeletedBucket, see ForkStateReader.
+// Deletion marks are never removed: anything written to the local state later takes precedence over them,
+// unwind of the fork included, because the changesets keep the source values read through ForkStateReader.
+type ForkStateWriter struct {
+	WriterWithChangeSets
+	db ethdb.Putter
+}
+
+// NewForkStateWriter wraps the writer of the local state, db is the local database with ForkDeletedBucket
+func NewForkStateWriter(w WriterWithChangeSets, db ethdb.Putter) *ForkStateWriter {
+	return &ForkStateWriter{WriterWithChangeSets: w, db: db}
+}
+
+func (w *ForkStateWriter) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	if err := w.WriterWithChangeSets.UpdateAccountData(address, original, account); err != nil {
+		return err
+	}
+	// changesets omit code hashes, unwind and history readers restore them from PlainContractCodeBucket,
+	// which only has the contracts created by the fork
+	if account.Incarnation > 0 && !account.IsEmptyCodeHash() {
+		return w.db.Put(dbutils.PlainContractCodeBucket, dbutils.PlainGenerateStoragePrefix(address[:], account.Incarnation), account.CodeHash[:])
+	}
+	return nil
+}
+
+func (w *ForkStateWriter) DeleteAccount(address common.Address, original *accounts.Account) error {
+	if err := w.WriterWithChangeSets.DeleteAccount(address, original); err != nil {
+		return err
+	}
+	return w.db.Put(dbutils.ForkDeletedBucket, common.CopyBytes(address[:]), []byte{})
+}
+
+func (w *ForkStateWriter) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	if err := w.WriterWithChangeSets.WriteAccountStorage(address, incarnation, key, original, value); err != nil {
+		return err
+	}
+	if !value.IsZero() {
+		return nil
+	}
+	return w.db.Put(dbutils.ForkDeletedBucket, dbutils.PlainGenerateCompositeStorageKey(address[:], incarnation, key[:]), []byte{})
+}
+
+// ChangeSetWriter of the wrapped writer, if it has one
+func (w *ForkStateWriter) ChangeSetWriter() *ChangeSetWriter {
+	if csw, ok := w.WriterWithChangeSets.(interface{ ChangeSetWriter() *ChangeSetWriter }); ok {
+		return csw.ChangeSetWriter()
+	}
+	return nil
+}
+
+// ForkSource is the read-only database a dev chain is forked from - chaindata of another node or a state snapshot.
+// It reads the state at the fork block, every read in its own transaction, so it can be shared by
+// the stages, the tx pool and RPC.
+type ForkSource struct {
+	db        ethdb.RoKV
+	blockNr   uint64
+	latest    bool // plain state of the source is at the fork block, otherwise it is read from the history
+	stateOnly bool // state snapshot: plain state and code only
+}
+
+func NewForkSource(db ethdb.RoKV, blockNr uint64, latest, stateOnly bool) *ForkSource {
+	return &ForkSource{db: db, blockNr: blockNr, latest: latest || stateOnly, stateOnly: stateOnly}
+}
+
+// BlockNumber of the source the fork starts from
+func (s *ForkSource) BlockNumber() uint64 { return s.blockNr }
+
+func (s *ForkSource) Close() { s.db.Close() }
+
+// Reader layers the given reader of the local state over the source
+func (s *ForkSource) Reader(local StateReader, db ethdb.KVGetter) *ForkStateReader {
+	return NewForkStateReader(local, db, s)
+}
+
+func (s *ForkSource) view(f func(r StateReader) error) error {
+	return s.db.View(context.Background(), func(tx ethdb.Tx) error {
+		if s.latest {
+			return f(NewPlainStateReader(tx))
+		}
+		return f(NewPlainKvState(tx, s.blockNr))
+	})
+}
+
+func (s *ForkSource) ReadAccountData(address common.Address) (a *accounts.Account, err error) {
+	err = s.view(func(r StateReader) error {
+		a, err = r.ReadAccountData(address)
+		return err
+	})
+	return a, err
+}
+
+func (s *ForkSource) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) (v []byte, err error) {
+	err = s.view(func(r StateReader) error {
+		v, err = r.ReadAccountStorage(address, incarnation, key)
+		v = common.CopyBytes(v) // outlives the transaction
+		return err
+	})
+	return v, err
+}
+
+func (s *ForkSource) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) (code []byte, err error) {
+	err = s.view(func(r StateReader) error {
+		code, err = r.ReadAccountCode(address, incarnation, codeHash)
+		code = common.CopyBytes(code)
+		return err
+	})
+	return code, err
+}
+
+func (s *ForkSource) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	code, err := s.ReadAccountCode(address, incarnation, codeHash)
+	return len(code), err
+}
+
+func (s *ForkSource) ReadAccountIncarnation(address common.Address) (incarnation uint64, err error) {
+	if s.stateOnly { // snapshots don't keep incarnations of deleted contracts
+		return 0, nil
+	}
+	err = s.view(func(r StateReader) error {
+		incarnation, err = r.ReadAccountIncarnation(address)
+		return err
+	})
+	return incarnation, err
+}
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkStateReader(t *testing.T) {
+	eoa, contract := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	slot1, slot2 := common.HexToHash("0x01"), common.HexToHash("0x02")
+	code := []byte{0x60, 0x00, 0x60, 0x00}
+
+	// source chain
+	sourceDB := kv.NewTestKV(t)
+	require.NoError(t, sourceDB.Update(context.Background(), func(tx ethdb.RwTx) error {
+		ibs := New(NewPlainStateReader(tx))
+		ibs.AddBalance(eoa, uint256.NewInt(10))
+		ibs.CreateAccount(contract, true)
+		ibs.SetCode(contract, code)
+		ibs.SetState(contract, &slot1, *uint256.NewInt(5))
+		ibs.SetState(contract, &slot2, *uint256.NewInt(7))
+		return ibs.CommitBlock(params.Rules{}, NewPlainStateWriter(tx, tx, 1))
+	}))
+	fork := NewForkSource(sourceDB, 1, true, false)
+
+	localDB := kv.NewTestKV(t)
+	tx, err := localDB.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// untouched state comes from the source
+	ibs := New(fork.Reader(NewPlainStateReader(tx), tx))
+	require.Equal(t, uint64(10), ibs.GetBalance(eoa).Uint64())
+	require.Equal(t, code, ibs.GetCode(contract))
+	var v uint256.Int
+	ibs.GetState(contract, &slot1, &v)
+	require.Equal(t, uint64(5), v.Uint64())
+
+	// block 1 of the fork
+	ibs.AddBalance(eoa, uint256.NewInt(10))
+	ibs.SetState(contract, &slot1, *uint256.NewInt(0))
+	ibs.SetState(contract, &slot2, *uint256.NewInt(9))
+	require.NoError(t, ibs.CommitBlock(params.Rules{}, NewForkStateWriter(NewPlainStateWriter(tx, tx, 1), tx)))
+
+	ibs = New(fork.Reader(NewPlainStateReader(tx), tx))
+	require.Equal(t, uint64(20), ibs.GetBalance(eoa).Uint64())
+	require.Equal(t, code, ibs.GetCode(contract))
+	ibs.GetState(contract, &slot1, &v)
+	require.True(t, v.IsZero(), "deleted slot must not fall through to the source")
+	ibs.GetState(contract, &slot2, &v)
+	require.Equal(t, uint64(9), v.Uint64())
+
+	// block 2 of the fork
+	ibs.Suicide(contract)
+	require.NoError(t, ibs.CommitBlock(params.Rules{}, NewForkStateWriter(NewPlainStateWriter(tx, tx, 2), tx)))
+
+	ibs = New(fork.Reader(NewPlainStateReader(tx), tx))
+	require.False(t, ibs.Exist(contract), "deleted account must not fall through to the source")
+	require.Equal(t, uint64(20), ibs.GetBalance(eoa).Uint64())
+
+	// the source is not modified
+	ibs = New(fork)
+	require.Equal(t, uint64(10), ibs.GetBalance(eoa).Uint64())
+	ibs.GetState(contract, &slot1, &v)
+	require.Equal(t, uint64(5), v.Uint64())
+}
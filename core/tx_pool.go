@@ -216,6 +216,7 @@ type TxPool struct {
 	config      TxPoolConfig
 	chainconfig *params.ChainConfig
 	chaindb     ethdb.Database
+	fork        *state.ForkSource // dev chain forked from the state of another one
 	gasPrice    *uint256.Int
 	txFeed      event.Feed
 	scope       event.SubscriptionScope
@@ -398,7 +399,11 @@ func (pool *TxPool) loop() {
 func (pool *TxPool) resetHead(blockGasLimit uint64, blockNumber uint64) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
-	pool.currentState = state.New(state.NewPlainStateReader(pool.chaindb))
+	var stateReader state.StateReader = state.NewPlainStateReader(pool.chaindb)
+	if pool.fork != nil {
+		stateReader = pool.fork.Reader(stateReader, pool.chaindb)
+	}
+	pool.currentState = state.New(stateReader)
 	pool.pendingNonces = newTxNoncer(pool.currentState)
 	pool.currentMaxGas = blockGasLimit
 
@@ -439,6 +444,12 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SetForkSource makes the pool validate transactions against the state of the dev chain forked from another one,
+// must be called before Start
+func (pool *TxPool) SetForkSource(fork *state.ForkSource) {
+	pool.fork = fork
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *uint256.Int {
 	pool.mu.RLock()
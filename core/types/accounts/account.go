@@ -28,6 +28,7 @@ const (
 	MimetypeDataWithValidator = "data/validator"
 	MimetypeTypedData         = "data/typed"
 	MimetypeClique            = "application/x-clique-header"
+	MimetypeAuRa              = "application/x-aura-header"
 	MimetypeTextPlain         = "text/plain"
 )
 
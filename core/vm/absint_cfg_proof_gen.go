@@ -634,6 +634,11 @@ func pushNewEdges(workList []edge, edges []edge) []edge {
 }
 
 func GenCfg(code []byte, anlyCounterLimit int, maxStackLen int, maxStackCount int, metrics *CfgMetrics) (cfg *Cfg, err error) {
+	return genCfg(nil, code, anlyCounterLimit, maxStackLen, maxStackCount, metrics)
+}
+
+// genCfg - same as GenCfg, stops with incomplete result when quit is closed
+func genCfg(quit <-chan struct{}, code []byte, anlyCounterLimit int, maxStackLen int, maxStackCount int, metrics *CfgMetrics) (cfg *Cfg, err error) {
 	program := toProgram(code)
 	cfg = &Cfg{Metrics: metrics}
 	cfg.BadJumps = make(map[int]bool)
@@ -662,6 +667,11 @@ func GenCfg(code []byte, anlyCounterLimit int, maxStackLen int, maxStackCount in
 			cfg.Metrics.AnlyCounterLimit = true
 			return cfg, errors.New("reached analysis counter limit")
 		}
+		select {
+		case <-quit:
+			return cfg, errors.New("analysis interrupted")
+		default:
+		}
 
 		var e edge
 		e, workList = workList[0], workList[1:]
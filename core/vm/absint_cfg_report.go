@@ -0,0 +1,186 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/emicklei/dot"
+)
+
+// CfgLimits - limits of abstract interpretation, analysis stops with incomplete result when they are reached
+type CfgLimits struct {
+	AnlyCounter   int // number of processed edges, 0 - unlimited
+	MaxStackLen   int
+	MaxStackCount int // number of abstract stacks in one state
+}
+
+var DefaultCfgLimits = CfgLimits{AnlyCounter: 1 << 20, MaxStackLen: 1024, MaxStackCount: 1 << 17}
+
+// CfgReport - control flow graph of contract code built by abstract interpretation, in the form for reviewers:
+// basic blocks with successors, resolved jump edges, jumps analysis couldn't resolve and jump sites which
+// take destination from the stack rather than from preceding PUSH.
+type CfgReport struct {
+	CodeSize int  `json:"codeSize"`
+	Complete bool `json:"complete"` // all jumps are resolved, so edges and reachability are exact
+	// Reason why analysis is not complete
+	Reason          string      `json:"reason,omitempty"`
+	Blocks          []*CfgBlock `json:"blocks"`
+	Jumps           []CfgEdge   `json:"jumps"`           // resolved edges of JUMP and JUMPI
+	UnresolvedJumps []int       `json:"unresolvedJumps"` // pcs of jumps with destinations unknown to analysis
+	DynamicJumps    []int       `json:"dynamicJumps"`    // pcs of jumps not preceded by PUSH
+	// Unreachable - code ranges not reachable from the entry, including data appended to code (e.g. metadata).
+	// Filled only for complete analysis.
+	Unreachable []CfgRange `json:"unreachable"`
+}
+
+type CfgBlock struct {
+	Entry      int      `json:"entry"`
+	Exit       int      `json:"exit"`
+	Reachable  bool     `json:"reachable"`
+	Successors []int    `json:"successors,omitempty"` // entries of the next blocks
+	Code       []string `json:"code"`
+}
+
+type CfgEdge struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+type CfgRange struct {
+	From int `json:"from"`
+	To   int `json:"to"` // inclusive
+}
+
+// AnalyseCfg - builds control flow graph of code. Error is returned only if analysis failed completely,
+// result of incomplete analysis is returned with Complete=false, also when ctx is done before analysis finishes.
+func AnalyseCfg(ctx context.Context, code []byte, limits CfgLimits) (report *CfgReport, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			report, err = nil, fmt.Errorf("cfg analysis panic: %v", r)
+		}
+	}()
+	cfg, cfgErr := genCfg(ctx.Done(), code, limits.AnlyCounter, limits.MaxStackLen, limits.MaxStackCount, &CfgMetrics{})
+	if cfg == nil || cfg.Program == nil {
+		return nil, cfgErr
+	}
+	report = &CfgReport{CodeSize: len(code), Complete: cfgErr == nil && cfg.Metrics.Valid}
+	if !report.Complete {
+		report.Reason = cfg.Metrics.GetBadJumpReason()
+		if cfgErr != nil {
+			report.Reason = cfgErr.Error()
+		}
+	}
+	program := cfg.Program
+
+	// basic blocks: start at entry, JUMPDEST and after control flow instructions
+	blocks := map[int]*CfgBlock{}
+	var cur *CfgBlock
+	var prev *Astmt
+	for pc, stmt := range program.Stmts {
+		if stmt.inferredAsData {
+			continue
+		}
+		if cur == nil || stmt.opcode == JUMPDEST {
+			cur = &CfgBlock{Entry: pc}
+			cur.Reachable = pc == 0 || len(cfg.PrevEdgeMap[pc]) > 0
+			blocks[pc] = cur
+			report.Blocks = append(report.Blocks, cur)
+		}
+		if stmt.opcode.IsPush() {
+			cur.Code = append(cur.Code, fmt.Sprintf("%d %v %s", pc, stmt.opcode, stmt.value.Hex()))
+		} else {
+			cur.Code = append(cur.Code, fmt.Sprintf("%d %v", pc, stmt.opcode))
+		}
+		cur.Exit = pc
+		if stmt.opcode == JUMP || stmt.opcode == JUMPI {
+			if prev == nil || !prev.opcode.IsPush() {
+				report.DynamicJumps = append(report.DynamicJumps, pc)
+			}
+		}
+		if stmt.ends || stmt.opcode == JUMP || stmt.opcode == JUMPI {
+			cur = nil
+		}
+		prev = stmt
+	}
+
+	for pc1, pc0s := range cfg.PrevEdgeMap {
+		for pc0 := range pc0s {
+			op := program.Stmts[pc0].opcode
+			if op == JUMP || (op == JUMPI && pc1 != pc0+1) {
+				report.Jumps = append(report.Jumps, CfgEdge{From: pc0, To: pc1})
+			}
+		}
+	}
+	sort.Slice(report.Jumps, func(i, j int) bool {
+		if report.Jumps[i].From != report.Jumps[j].From {
+			return report.Jumps[i].From < report.Jumps[j].From
+		}
+		return report.Jumps[i].To < report.Jumps[j].To
+	})
+	for _, block := range report.Blocks {
+		for pc1, pc0s := range cfg.PrevEdgeMap {
+			if pc0s[block.Exit] && blocks[pc1] != nil {
+				block.Successors = append(block.Successors, pc1)
+			}
+		}
+		sort.Ints(block.Successors)
+	}
+	for pc := range cfg.BadJumps {
+		report.UnresolvedJumps = append(report.UnresolvedJumps, pc)
+	}
+	sort.Ints(report.UnresolvedJumps)
+
+	if report.Complete {
+		// blocks are sorted by entry, data between them belongs to the previous block
+		for i, block := range report.Blocks {
+			if block.Reachable {
+				continue
+			}
+			to := len(code) - 1
+			if i+1 < len(report.Blocks) {
+				to = report.Blocks[i+1].Entry - 1
+			}
+			if n := len(report.Unreachable); n > 0 && report.Unreachable[n-1].To+1 == block.Entry {
+				report.Unreachable[n-1].To = to
+			} else {
+				report.Unreachable = append(report.Unreachable, CfgRange{From: block.Entry, To: to})
+			}
+		}
+	}
+	return report, nil
+}
+
+// Dot - graph of blocks in Graphviz format. Unreachable blocks are grey, blocks ending with unresolved jump are red,
+// jump edges are solid, fall through edges are dashed.
+func (r *CfgReport) Dot() string {
+	unresolved := map[int]bool{}
+	for _, pc := range r.UnresolvedJumps {
+		unresolved[pc] = true
+	}
+	g := dot.NewGraph(dot.Directed)
+	nodes := map[int]dot.Node{}
+	for _, block := range r.Blocks {
+		n := g.Node(fmt.Sprintf("%d-%d", block.Entry, block.Exit)).Box()
+		switch {
+		case unresolved[block.Exit]:
+			n.Attr("color", "red")
+		case !block.Reachable:
+			n.Attr("color", "grey").Attr("fontcolor", "grey")
+		}
+		nodes[block.Entry] = n
+	}
+	jumps := map[CfgEdge]bool{}
+	for _, e := range r.Jumps {
+		jumps[e] = true
+	}
+	for _, block := range r.Blocks {
+		for _, succ := range block.Successors {
+			e := g.Edge(nodes[block.Entry], nodes[succ])
+			if !jumps[CfgEdge{From: block.Exit, To: succ}] {
+				e.Attr("style", "dashed")
+			}
+		}
+	}
+	return g.String()
+}
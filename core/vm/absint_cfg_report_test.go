@@ -0,0 +1,46 @@
+package vm
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseCfg(t *testing.T) {
+	// pragma solidity 0.5.0; contract staticloop00 { function execute(uint a0) pure external returns(uint256) {...} }
+	code, err := hex.DecodeString("608060405260043610603f576000357c0100000000000000000000000000000000000000000000000000000000900463ffffffff168063fe0d94c1146044575b600080fd5b348015604f57600080fd5b50607960048036036020811015606457600080fd5b8101908080359060200190929190505050608f565b6040518082815260200191505060405180910390f35b600080829050600a8310151560a357600080fd5b60008090505b600381101560c2578082019150808060010191505060a9565b508091505091905056fea165627a7a72305820e9eae4d836605e8f28df860b8f590e6cd933ddcbf111d99767c764aa99f093900029")
+	require.NoError(t, err)
+	report, err := AnalyseCfg(context.Background(), code, DefaultCfgLimits)
+	require.NoError(t, err)
+	require.True(t, report.Complete, report.Reason)
+	require.Empty(t, report.UnresolvedJumps)
+	require.Equal(t, 0, report.Blocks[0].Entry)
+	require.Contains(t, report.Jumps, CfgEdge{From: 11, To: 63})
+	// return from internal function takes destination from the stack
+	require.Contains(t, report.DynamicJumps, 203)
+	require.Contains(t, report.Jumps, CfgEdge{From: 203, To: 121})
+	// metadata after the last instruction
+	require.NotEmpty(t, report.Unreachable)
+	require.Equal(t, len(code)-1, report.Unreachable[len(report.Unreachable)-1].To)
+	require.True(t, strings.HasPrefix(report.Dot(), "digraph"))
+
+	// destination depends on calldata
+	code = []byte{byte(PUSH1), 0, byte(CALLDATALOAD), byte(JUMP), byte(JUMPDEST), byte(STOP)}
+	report, err = AnalyseCfg(context.Background(), code, DefaultCfgLimits)
+	require.NoError(t, err)
+	require.False(t, report.Complete)
+	require.Equal(t, []int{3}, report.UnresolvedJumps)
+	require.Equal(t, []int{3}, report.DynamicJumps)
+	require.Empty(t, report.Unreachable)
+
+	// cancelled analysis returns incomplete result
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = AnalyseCfg(ctx, code, DefaultCfgLimits)
+	require.NoError(t, err)
+	require.False(t, report.Complete)
+	require.Equal(t, "analysis interrupted", report.Reason)
+}
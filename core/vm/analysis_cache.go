@@ -0,0 +1,67 @@
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/ledgerwatch/erigon/common"
+)
+
+const DefaultJumpDestCacheSize = 16384
+
+// JumpDestCache - results of JUMPDEST analysis, shared between transactions and blocks.
+// Keys are code hashes, so entries never become stale.
+type JumpDestCache interface {
+	Get(codeHash common.Hash) ([]uint64, bool)
+	Add(codeHash common.Hash, analysis []uint64)
+}
+
+// SharedJumpDests - in-memory cache of the process, used if Config.JumpDestCache is not set
+var SharedJumpDests JumpDestCache = NewJumpDestLRU(DefaultJumpDestCacheSize)
+
+// JumpDestLRU - in-memory JumpDestCache with limited number of entries
+type JumpDestLRU struct {
+	cache *lru.Cache
+}
+
+func NewJumpDestLRU(size int) *JumpDestLRU {
+	cache, err := lru.New(size)
+	if err != nil {
+		panic(err)
+	}
+	return &JumpDestLRU{cache: cache}
+}
+
+func (c *JumpDestLRU) Get(codeHash common.Hash) ([]uint64, bool) {
+	v, ok := c.cache.Get(codeHash)
+	if !ok {
+		return nil, false
+	}
+	return v.([]uint64), true
+}
+
+func (c *JumpDestLRU) Add(codeHash common.Hash, analysis []uint64) {
+	c.cache.Add(codeHash, analysis)
+}
+
+// EncodeJumpDests - serialises analysis bitmap as little-endian words
+func EncodeJumpDests(analysis []uint64) []byte {
+	buf := make([]byte, 8*len(analysis))
+	for i, w := range analysis {
+		binary.LittleEndian.PutUint64(buf[8*i:], w)
+	}
+	return buf
+}
+
+// DecodeJumpDests - parses bitmap serialised by EncodeJumpDests
+func DecodeJumpDests(buf []byte) ([]uint64, error) {
+	if len(buf)%8 != 0 {
+		return nil, errors.New("invalid length of JUMPDEST analysis")
+	}
+	analysis := make([]uint64, len(buf)/8)
+	for i := range analysis {
+		analysis[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	return analysis, nil
+}
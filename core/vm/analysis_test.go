@@ -100,3 +100,50 @@ func BenchmarkJumpDest(b *testing.B) {
 		b.StopTimer()
 	}
 }
+
+type countingJumpDestCache struct {
+	*JumpDestLRU
+	adds int
+}
+
+func (c *countingJumpDestCache) Add(codeHash common.Hash, analysis []uint64) {
+	c.adds++
+	c.JumpDestLRU.Add(codeHash, analysis)
+}
+
+func TestJumpDestCache(t *testing.T) {
+	code := []byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}
+	codeHash := crypto.Keccak256Hash(code)
+	cache := &countingJumpDestCache{JumpDestLRU: NewJumpDestLRU(2)}
+
+	for i := 0; i < 3; i++ {
+		// new transaction every time, analysis is taken from the shared cache
+		contract := NewContract(AccountRef(common.Address{}), AccountRef(common.Address{}), new(uint256.Int), 0, false, false)
+		contract.jumpDestCache = cache
+		contract.SetCallCode(nil, codeHash, code)
+		if valid, _ := contract.validJumpdest(uint256.NewInt(1)); valid {
+			t.Errorf("push data is a valid jump destination")
+		}
+		if valid, _ := contract.validJumpdest(uint256.NewInt(2)); !valid {
+			t.Errorf("JUMPDEST is not a valid jump destination")
+		}
+	}
+	if cache.adds != 1 {
+		t.Errorf("expected analysis to be done once, done %d times", cache.adds)
+	}
+
+	analysis, ok := cache.Get(codeHash)
+	if !ok {
+		t.Fatal("analysis not cached")
+	}
+	decoded, err := DecodeJumpDests(EncodeJumpDests(analysis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(analysis) || decoded[0] != analysis[0] {
+		t.Errorf("decoded %x, expected %x", decoded, analysis)
+	}
+	if _, err = DecodeJumpDests([]byte{1, 2, 3}); err == nil {
+		t.Errorf("expected error for invalid length")
+	}
+}
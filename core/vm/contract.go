@@ -50,6 +50,7 @@ type Contract struct {
 	self          ContractRef
 	jumpdests     map[common.Hash][]uint64 // Aggregated result of JUMPDEST analysis.
 	analysis      []uint64                 // Locally cached result of JUMPDEST analysis
+	jumpDestCache JumpDestCache            // Results of JUMPDEST analysis shared between transactions
 	skipAnalysis  bool
 	vmType        VmType
 
@@ -72,6 +73,7 @@ func NewContract(caller ContractRef, object ContractRef, value *uint256.Int, gas
 	} else {
 		c.jumpdests = make(map[common.Hash][]uint64)
 	}
+	c.jumpDestCache = SharedJumpDests
 
 	// Gas should be a pointer so it can safely be reduced through the run
 	// This pointer will be off the state transition
@@ -122,9 +124,9 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
+			// Do the analysis (or take it from the shared cache) and save in parent context
 			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			analysis = c.sharedAnalysis()
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
@@ -143,6 +145,20 @@ func (c *Contract) isCode(udest uint64) bool {
 	return isCodeFromAnalysis(c.analysis, udest)
 }
 
+// sharedAnalysis returns JUMPDEST analysis of the code from the shared cache,
+// analysis is done and added to the cache on miss
+func (c *Contract) sharedAnalysis() []uint64 {
+	if c.jumpDestCache == nil {
+		return codeBitmap(c.Code)
+	}
+	if analysis, ok := c.jumpDestCache.Get(c.CodeHash); ok {
+		return analysis
+	}
+	analysis := codeBitmap(c.Code)
+	c.jumpDestCache.Add(c.CodeHash, analysis)
+	return analysis
+}
+
 // AsDelegate sets the contract to be a delegate call and returns the current
 // contract (for chaining calls)
 func (c *Contract) AsDelegate() *Contract {
@@ -0,0 +1,286 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/vm/stack"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// GasProfiler - tracer which aggregates gas used by opcodes of executed contracts: per (contract, opcode, pc),
+// per call depth and per storage slot. It can be used for many transactions, results are accumulated.
+//
+// Gas of an opcode is the difference of remaining gas before it and before the next opcode of the same frame,
+// minus gas used by the frames it called. So the gas of the failed opcode includes all burnt gas,
+// gas of RETURN from constructor includes code deposit and gas of precompile call is attributed to PRECOMPILE
+// "opcode" of the precompile address. Intrinsic gas of transactions is not included.
+type GasProfiler struct {
+	frames  []*gasProfilerFrame
+	gas     uint64
+	opcodes map[gasProfilerOpKey]*GasProfileOpcode
+	depths  map[int]*GasProfileDepth
+	storage map[gasProfilerSlotKey]*GasProfileSlot
+	stacks  map[string]uint64
+}
+
+// GasProfile - result of profiling, sorted by gas
+type GasProfile struct {
+	Gas     uint64              `json:"gas"`
+	Opcodes []*GasProfileOpcode `json:"opcodes"`
+	Depths  []*GasProfileDepth  `json:"depths"`
+	Storage []*GasProfileSlot   `json:"storage"`
+	// Folded - stacks in the folded format of flamegraph tools: "contract;contract;OPCODE@pc gas" per line
+	Folded string `json:"folded"`
+}
+
+type GasProfileOpcode struct {
+	Contract common.Address `json:"contract"` // address of the executed code
+	Op       string         `json:"op"`
+	Pc       uint64         `json:"pc"`
+	Count    uint64         `json:"count"`
+	Gas      uint64         `json:"gas"`
+}
+
+type GasProfileDepth struct {
+	Depth int    `json:"depth"` // depth of transaction call is 1
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+type GasProfileSlot struct {
+	Contract common.Address `json:"contract"` // owner of the storage
+	Slot     common.Hash    `json:"slot"`
+	Op       string         `json:"op"`
+	Access   string         `json:"access,omitempty"` // "warm" or "cold" under EIP-2929
+	Count    uint64         `json:"count"`
+	Gas      uint64         `json:"gas"`
+}
+
+type gasProfilerOpKey struct {
+	contract common.Address
+	op       string
+	pc       uint64
+}
+
+type gasProfilerSlotKey struct {
+	contract common.Address
+	slot     common.Hash
+	op       string
+	access   string
+}
+
+type gasProfilerFrame struct {
+	address    common.Address
+	path       string // folded stack of the frame
+	precompile bool
+	startGas   uint64
+	childUsed  uint64 // gas used by frames called from the pending opcode
+	pending    *gasProfilerStep
+}
+
+type gasProfilerStep struct {
+	op      OpCode
+	pc      uint64
+	gas     uint64 // remaining gas before the opcode
+	storage *gasProfilerSlotKey
+}
+
+func NewGasProfiler() *GasProfiler {
+	return &GasProfiler{
+		opcodes: map[gasProfilerOpKey]*GasProfileOpcode{},
+		depths:  map[int]*GasProfileDepth{},
+		storage: map[gasProfilerSlotKey]*GasProfileSlot{},
+		stacks:  map[string]uint64{},
+	}
+}
+
+func (p *GasProfiler) CaptureStart(depth int, from common.Address, to common.Address, precompile bool, create bool, callType CallType, input []byte, gas uint64, value *big.Int, codeHash common.Hash) error {
+	path := to.Hex()
+	if n := len(p.frames); n > 0 {
+		path = p.frames[n-1].path + ";" + path
+	}
+	p.frames = append(p.frames, &gasProfilerFrame{address: to, path: path, precompile: precompile, startGas: gas})
+	return nil
+}
+
+func (p *GasProfiler) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, st *stack.Stack, rData []byte, contract *Contract, depth int, err error) error {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	frame := p.frames[len(p.frames)-1]
+	p.finishStep(frame, gas)
+	step := &gasProfilerStep{op: op, pc: pc, gas: gas}
+	if (op == SLOAD || op == SSTORE) && st.Len() > 0 {
+		step.storage = &gasProfilerSlotKey{contract: contract.Address(), slot: common.Hash(st.Back(0).Bytes32()), op: op.String()}
+		if env.ChainRules.IsBerlin {
+			step.storage.access = "warm"
+			if coldStorageAccess(op, cost) {
+				step.storage.access = "cold"
+			}
+		}
+	}
+	frame.pending = step
+	return nil
+}
+
+// coldStorageAccess - cost of the first access to the slot under EIP-2929 includes ColdSloadCost,
+// the slot is already in the access list when opcode is traced
+func coldStorageAccess(op OpCode, cost uint64) bool {
+	if op == SLOAD {
+		return cost == params.ColdSloadCostEIP2929
+	}
+	if cost < params.ColdSloadCostEIP2929 {
+		return false
+	}
+	switch cost - params.ColdSloadCostEIP2929 {
+	case params.WarmStorageReadCostEIP2929, params.SstoreResetGasEIP2200 - params.ColdSloadCostEIP2929, params.SstoreSetGasEIP2200:
+		return true
+	}
+	return false
+}
+
+func (p *GasProfiler) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *stack.Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+func (p *GasProfiler) CaptureEnd(depth int, output []byte, gasUsed uint64, t time.Duration, err error) error {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	frame := p.frames[len(p.frames)-1]
+	if frame.precompile {
+		p.record(frame, "PRECOMPILE", 0, gasUsed, nil)
+	} else if gasUsed <= frame.startGas {
+		p.finishStep(frame, frame.startGas-gasUsed)
+	}
+	p.frames = p.frames[:len(p.frames)-1]
+	if n := len(p.frames); n > 0 {
+		p.frames[n-1].childUsed += gasUsed
+	}
+	return nil
+}
+
+func (p *GasProfiler) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {}
+
+func (p *GasProfiler) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (p *GasProfiler) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// finishStep - attributes gas to the pending opcode of the frame, gas is remaining gas after it
+func (p *GasProfiler) finishStep(frame *gasProfilerFrame, gas uint64) {
+	step := frame.pending
+	if step == nil {
+		return
+	}
+	var used uint64
+	if step.gas >= gas+frame.childUsed {
+		used = step.gas - gas - frame.childUsed
+	}
+	frame.pending, frame.childUsed = nil, 0
+	p.record(frame, step.op.String(), step.pc, used, step.storage)
+}
+
+func (p *GasProfiler) record(frame *gasProfilerFrame, op string, pc uint64, gas uint64, slot *gasProfilerSlotKey) {
+	p.gas += gas
+
+	key := gasProfilerOpKey{contract: frame.address, op: op, pc: pc}
+	opcode, ok := p.opcodes[key]
+	if !ok {
+		opcode = &GasProfileOpcode{Contract: frame.address, Op: op, Pc: pc}
+		p.opcodes[key] = opcode
+	}
+	opcode.Count++
+	opcode.Gas += gas
+
+	depth, ok := p.depths[len(p.frames)]
+	if !ok {
+		depth = &GasProfileDepth{Depth: len(p.frames)}
+		p.depths[len(p.frames)] = depth
+	}
+	depth.Count++
+	depth.Gas += gas
+
+	if slot != nil {
+		s, ok := p.storage[*slot]
+		if !ok {
+			s = &GasProfileSlot{Contract: slot.contract, Slot: slot.slot, Op: slot.op, Access: slot.access}
+			p.storage[*slot] = s
+		}
+		s.Count++
+		s.Gas += gas
+	}
+
+	p.stacks[fmt.Sprintf("%s;%s@%d", frame.path, op, pc)] += gas
+}
+
+// Folded - profile in the folded stacks format, input of flamegraph.pl, speedscope and similar tools
+func (p *GasProfiler) Folded() string {
+	lines := make([]string, 0, len(p.stacks))
+	for path, gas := range p.stacks {
+		if gas > 0 {
+			lines = append(lines, fmt.Sprintf("%s %d\n", path, gas))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
+
+func (p *GasProfiler) Profile() *GasProfile {
+	profile := &GasProfile{
+		Gas:     p.gas,
+		Opcodes: make([]*GasProfileOpcode, 0, len(p.opcodes)),
+		Depths:  make([]*GasProfileDepth, 0, len(p.depths)),
+		Storage: make([]*GasProfileSlot, 0, len(p.storage)),
+		Folded:  p.Folded(),
+	}
+	for _, opcode := range p.opcodes {
+		profile.Opcodes = append(profile.Opcodes, opcode)
+	}
+	sort.Slice(profile.Opcodes, func(i, j int) bool {
+		a, b := profile.Opcodes[i], profile.Opcodes[j]
+		if a.Gas != b.Gas {
+			return a.Gas > b.Gas
+		}
+		if c := bytes.Compare(a.Contract[:], b.Contract[:]); c != 0 {
+			return c < 0
+		}
+		if a.Pc != b.Pc {
+			return a.Pc < b.Pc
+		}
+		return a.Op < b.Op
+	})
+	for _, depth := range p.depths {
+		profile.Depths = append(profile.Depths, depth)
+	}
+	sort.Slice(profile.Depths, func(i, j int) bool { return profile.Depths[i].Depth < profile.Depths[j].Depth })
+	for _, slot := range p.storage {
+		profile.Storage = append(profile.Storage, slot)
+	}
+	sort.Slice(profile.Storage, func(i, j int) bool {
+		a, b := profile.Storage[i], profile.Storage[j]
+		if a.Gas != b.Gas {
+			return a.Gas > b.Gas
+		}
+		if c := bytes.Compare(a.Contract[:], b.Contract[:]); c != 0 {
+			return c < 0
+		}
+		if c := bytes.Compare(a.Slot[:], b.Slot[:]); c != 0 {
+			return c < 0
+		}
+		if a.Op != b.Op {
+			return a.Op < b.Op
+		}
+		return a.Access < b.Access
+	})
+	return profile
+}
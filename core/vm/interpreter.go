@@ -38,6 +38,8 @@ type Config struct {
 	ReadOnly      bool   // Do no perform any block finalisation
 	EnableTEMV    bool   // true if execution with TEVM enable flag
 
+	JumpDestCache JumpDestCache // Shared cache of JUMPDEST analysis, SharedJumpDests if not set
+
 	ExtraEips []int // Additional EIPS that are to be enabled
 }
 
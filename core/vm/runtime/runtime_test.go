@@ -20,17 +20,21 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon/accounts/abi"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/asm"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/ethdb/kv"
 	"github.com/ledgerwatch/erigon/params"
 )
@@ -594,3 +598,196 @@ func TestEip2929Cases(t *testing.T) {
 			"account (cheap)", code)
 	}
 }
+
+func TestTEVM(t *testing.T) {
+	callee := common.HexToAddress("0x0b")
+	programs := map[string][]byte{
+		"loop": {
+			// for i := 0; i < 100; i++ {}, then store and return i
+			byte(vm.PUSH1), 0,
+			byte(vm.JUMPDEST),
+			byte(vm.PUSH1), 1, byte(vm.ADD),
+			byte(vm.DUP1), byte(vm.PUSH1), 100, byte(vm.GT),
+			byte(vm.PUSH1), 2, byte(vm.JUMPI),
+			byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+			byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		},
+		"dynamic jump": {
+			byte(vm.PUSH1), 6, byte(vm.DUP1), byte(vm.SWAP1), byte(vm.POP), byte(vm.JUMP),
+			byte(vm.JUMPDEST), byte(vm.PC), byte(vm.GAS), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 0, byte(vm.MSTORE8), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		},
+		"invalid static jump":  {byte(vm.PUSH1), 3, byte(vm.JUMP), byte(vm.PUSH1), byte(vm.JUMPDEST)},
+		"invalid dynamic jump": {byte(vm.PUSH1), 4, byte(vm.DUP1), byte(vm.JUMP), byte(vm.PUSH1), byte(vm.JUMPDEST)},
+		"jumpi not taken":      {byte(vm.PUSH1), 0, byte(vm.PUSH1), 0xff, byte(vm.JUMPI), byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)},
+		"truncated push":       {byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH32), 1, 2},
+		"undefined opcode":     {byte(vm.PUSH1), 1, byte(vm.PUSH1), 2, 0x0c},
+		"stack underflow":      {byte(vm.PUSH1), 1, byte(vm.SUB)},
+		"stack overflow":       {byte(vm.JUMPDEST), byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.JUMP)},
+		"revert":               {byte(vm.PUSH1), 7, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.REVERT)},
+		"fused ops": {
+			byte(vm.PUSH1), 10, byte(vm.PUSH1), 3, byte(vm.SUB), byte(vm.PUSH1), 5, byte(vm.LT),
+			byte(vm.PUSH1), 9, byte(vm.MUL), byte(vm.PUSH1), 0xf0, byte(vm.XOR), byte(vm.PUSH1), 0xf9, byte(vm.EQ),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		},
+		"call": {
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), byte(callee[19]), byte(vm.GAS), byte(vm.CALL),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		},
+	}
+	calleeCode := []byte{
+		byte(vm.GAS), byte(vm.PUSH1), 1, byte(vm.SSTORE),
+		byte(vm.GAS), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+
+	type result struct {
+		ret     []byte
+		gasLeft uint64
+		failed  bool
+		storage [2]uint256.Int
+		logs    []vm.StructLog
+	}
+	run := func(code []byte, gas uint64, tevm bool, debug bool) result {
+		db := kv.NewTestDB(t)
+		ibs := state.New(state.NewDbStateReader(db))
+		address := common.HexToAddress("0x0a")
+		ibs.SetCode(address, code)
+		ibs.SetCode(callee, calleeCode)
+		cfg := &Config{State: ibs, GasLimit: gas, CheckTEVM: func(common.Hash) (bool, error) { return tevm, nil }}
+		var tracer *vm.StructLogger
+		if debug {
+			tracer = vm.NewStructLogger(&vm.LogConfig{DisableMemory: true, DisableStack: true, DisableStorage: true})
+			cfg.EVMConfig = vm.Config{Debug: true, Tracer: tracer}
+		}
+		var res result
+		var err error
+		res.ret, res.gasLeft, err = Call(address, nil, cfg)
+		res.failed = err != nil
+		for i := range res.storage {
+			key := common.Hash{31: byte(i)}
+			ibs.GetState(address, &key, &res.storage[i])
+		}
+		if tracer != nil {
+			res.logs = tracer.StructLogs()
+		}
+		return res
+	}
+
+	for name, code := range programs {
+		// gas limits which cut execution in the middle of blocks
+		for _, gas := range []uint64{100000, 30000, 21000, 5000, 100, 40, 10, 3} {
+			for _, debug := range []bool{false, true} {
+				expected, got := run(code, gas, false, debug), run(code, gas, true, debug)
+				if !reflect.DeepEqual(expected, got) {
+					t.Errorf("%s, gas %d, debug %t: EVM result %+v, TEVM result %+v", name, gas, debug, expected, got)
+				}
+			}
+		}
+	}
+}
+
+func TestGasProfiler(t *testing.T) {
+	address, callee, identity := common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.HexToAddress("0x04")
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP), // 0: cold
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP), // 4: warm
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 1, byte(vm.SSTORE), // 8: cold, set
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), byte(callee[19]), byte(vm.GAS), byte(vm.CALL), byte(vm.POP), // 13: CALL at 26
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH1), byte(identity[19]),
+		byte(vm.GAS), byte(vm.STATICCALL), byte(vm.POP), // 28: STATICCALL at 39
+		byte(vm.STOP),
+	}
+	calleeCode := []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)}
+
+	db := kv.NewTestDB(t)
+	ibs := state.New(state.NewDbStateReader(db))
+	ibs.SetCode(address, code)
+	ibs.SetCode(callee, calleeCode)
+	profiler := vm.NewGasProfiler()
+	cfg := &Config{State: ibs, GasLimit: 1000000, EVMConfig: vm.Config{Debug: true, Tracer: profiler}}
+	_, gasLeft, err := Call(address, nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := profiler.Profile()
+	if profile.Gas != cfg.GasLimit-gasLeft {
+		t.Errorf("profiled gas %d, used gas %d", profile.Gas, cfg.GasLimit-gasLeft)
+	}
+
+	opcodes := map[string]uint64{}
+	for _, op := range profile.Opcodes {
+		opcodes[fmt.Sprintf("%x %s@%d", op.Contract[19:], op.Op, op.Pc)] = op.Gas
+	}
+	for name, gas := range map[string]uint64{
+		"0a SLOAD@2":       params.ColdSloadCostEIP2929,
+		"0a SLOAD@6":       params.WarmStorageReadCostEIP2929,
+		"0a CALL@26":       params.ColdAccountAccessCostEIP2929,                // without gas of the callee
+		"0a STATICCALL@39": params.WarmStorageReadCostEIP2929 + 3,              // precompile is warm, one word of memory
+		"04 PRECOMPILE@0":  params.IdentityBaseGas + params.IdentityPerWordGas, // one word
+		"0b SSTORE@4":      params.ColdSloadCostEIP2929 + params.SstoreSetGasEIP2200,
+		"0b PUSH1@0":       3,
+	} {
+		if opcodes[name] != gas {
+			t.Errorf("%s: expected gas %d, got %d", name, gas, opcodes[name])
+		}
+	}
+
+	depths := map[int]uint64{}
+	for _, d := range profile.Depths {
+		depths[d.Depth] = d.Gas
+	}
+	if depths[2] != 3+3+params.ColdSloadCostEIP2929+params.SstoreSetGasEIP2200+params.IdentityBaseGas+params.IdentityPerWordGas {
+		t.Errorf("unexpected gas of depth 2: %d", depths[2])
+	}
+
+	slots := map[string]uint64{}
+	for _, s := range profile.Storage {
+		slots[fmt.Sprintf("%x %x %s %s", s.Contract[19:], s.Slot[31:], s.Op, s.Access)] = s.Count
+	}
+	expectedSlots := map[string]uint64{"0a 00 SLOAD cold": 1, "0a 00 SLOAD warm": 1, "0a 01 SSTORE cold": 1, "0b 00 SSTORE cold": 1}
+	if !reflect.DeepEqual(slots, expectedSlots) {
+		t.Errorf("expected slots %v, got %v", expectedSlots, slots)
+	}
+
+	line := fmt.Sprintf("%s;%s;SSTORE@4 %d\n", address.Hex(), callee.Hex(), params.ColdSloadCostEIP2929+params.SstoreSetGasEIP2200)
+	if !strings.Contains(profile.Folded, line) {
+		t.Errorf("folded stacks don't contain %q:\n%s", line, profile.Folded)
+	}
+}
+
+func TestTEVMStoredCode(t *testing.T) {
+	_, tx := kv.NewTestTx(t)
+	address := common.HexToAddress("0x0a")
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}
+	stored := []byte{byte(vm.PUSH1), 2, byte(vm.PUSH1), 0, byte(vm.SSTORE)}
+	tevmCode, err := vm.Transpile(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the program stored by Translation stage is executed, not a translation of contract code
+	if err = tx.Put(dbutils.ContractTEVMCodeBucket, crypto.Keccak256(code), tevmCode); err != nil {
+		t.Fatal(err)
+	}
+	ibs := state.New(state.NewPlainStateReader(tx))
+	ibs.SetCode(address, code)
+	cfg := &Config{State: ibs, GasLimit: 100000, CheckTEVM: vm.GetCheckTEVM(tx), EVMConfig: vm.Config{EnableTEMV: true}}
+	if _, _, err = Call(address, nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	var value uint256.Int
+	ibs.GetState(address, &common.Hash{}, &value)
+	if value.Uint64() != 2 {
+		t.Errorf("storage value %d, expected 2 of the stored program", value.Uint64())
+	}
+
+	// contracts without stored code are executed by EVM, creation with TEVM enabled doesn't need tracer
+	if ok, err := cfg.CheckTEVM(common.Hash{1}); err != nil || ok {
+		t.Errorf("contract without TEVM code: %t, %v", ok, err)
+	}
+	if _, _, _, err = Create(code, cfg, 0); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,275 @@
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/holiman/uint256"
+)
+
+// TEVM code is EVM bytecode translated to pre-decoded instructions:
+//  - push data is decoded once, instead of on every execution
+//  - PUSHn followed by JUMP/JUMPI or by a binary operation is fused into one instruction
+//  - targets of static jumps are resolved to instruction indexes, and validated, at translation time
+//  - code is split into blocks, static gas of the whole block is charged once on entering it
+//
+// Translation doesn't depend on the fork: static gas of blocks is calculated by interpreter for it's jump table.
+
+const tevmVersion = 1
+
+var ErrInvalidTEVMCode = errors.New("invalid TEVM code")
+
+type tevmKind uint8
+
+const (
+	tevmOp        tevmKind = iota // EVM opcode, executed by the jump table
+	tevmPush                      // PUSHn with decoded argument
+	tevmPushOp                    // PUSHn followed by binary operation, argument is the first operand
+	tevmPushJump                  // PUSHn JUMP with resolved target
+	tevmPushJumpi                 // PUSHn JUMPI with resolved target
+	tevmKindsCount
+)
+
+type tevmInstr struct {
+	kind       tevmKind
+	op         OpCode // opcode, PUSHn of push instruction, or the second opcode of fused instruction
+	pushOp     OpCode // PUSHn of push and fused instructions
+	blockStart bool   // instruction starts static gas block
+	pc         uint64 // pc of (the first) EVM opcode
+	target     int    // instruction index of static jump, -1 if destination is not a valid JUMPDEST
+	arg        uint256.Int
+}
+
+// opPc - pc of the second opcode of fused instruction
+func (ins *tevmInstr) opPc() uint64 {
+	return ins.pc + uint64(ins.pushOp-PUSH1) + 2
+}
+
+// tevmProgram - decoded TEVM code
+type tevmProgram struct {
+	instrs    []tevmInstr
+	jumpdests map[uint64]int // pc of JUMPDEST -> instruction index
+
+	lock     sync.Mutex
+	blockGas map[*JumpTable][]uint64 // static gas of blocks, by index of block start
+}
+
+// fusedPushOps - binary operations which can take PUSH argument directly, defined in all forks
+var fusedPushOps = map[OpCode]bool{
+	ADD: true, MUL: true, SUB: true, LT: true, GT: true, EQ: true, AND: true, OR: true, XOR: true,
+}
+
+// endsTEVMBlock - instructions after these opcodes start new static gas block. Besides control flow,
+// these are opcodes which observe remaining gas: static gas of the following instructions must not be charged before them.
+func endsTEVMBlock(op OpCode) bool {
+	switch op {
+	case STOP, JUMP, JUMPI, RETURN, REVERT, SELFDESTRUCT,
+		GAS, SSTORE, CREATE, CREATE2, CALL, CALLCODE, DELEGATECALL, STATICCALL:
+		return true
+	}
+	return false
+}
+
+// Transpile - translates EVM bytecode to TEVM code
+func Transpile(code []byte) ([]byte, error) {
+	return encodeTEVM(translate(code)), nil
+}
+
+func translate(code []byte) *tevmProgram {
+	// decode opcodes and push data
+	var raw []tevmInstr
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		op := OpCode(code[pc])
+		ins := tevmInstr{kind: tevmOp, op: op, pc: pc}
+		if op.IsPush() {
+			size := uint64(op - PUSH1 + 1)
+			data := make([]byte, size) // missing bytes at the end of code are zeroes
+			if pc+1 < uint64(len(code)) {
+				copy(data, code[pc+1:])
+			}
+			ins.kind, ins.pushOp = tevmPush, op
+			ins.arg.SetBytes(data)
+			pc += size
+		}
+		raw = append(raw, ins)
+	}
+
+	// fuse PUSHn with the next opcode. Next opcode is never JUMPDEST, so it can't be a jump target.
+	p := &tevmProgram{instrs: make([]tevmInstr, 0, len(raw)), jumpdests: map[uint64]int{}}
+	for i := 0; i < len(raw); i++ {
+		ins := raw[i]
+		if ins.kind == tevmPush && i+1 < len(raw) && raw[i+1].kind == tevmOp {
+			switch next := raw[i+1].op; {
+			case next == JUMP:
+				ins.kind, ins.op = tevmPushJump, next
+			case next == JUMPI:
+				ins.kind, ins.op = tevmPushJumpi, next
+			case fusedPushOps[next]:
+				ins.kind, ins.op = tevmPushOp, next
+			}
+			if ins.kind != tevmPush {
+				i++
+			}
+		}
+		if ins.kind == tevmOp && ins.op == JUMPDEST {
+			p.jumpdests[ins.pc] = len(p.instrs)
+		}
+		p.instrs = append(p.instrs, ins)
+	}
+
+	// resolve static jumps and split to blocks
+	for i := range p.instrs {
+		ins := &p.instrs[i]
+		if ins.kind == tevmPushJump || ins.kind == tevmPushJumpi {
+			ins.target = -1
+			if dest, overflow := ins.arg.Uint64WithOverflow(); !overflow {
+				if target, ok := p.jumpdests[dest]; ok {
+					ins.target = target
+				}
+			}
+		}
+		ins.blockStart = i == 0 || (ins.kind == tevmOp && ins.op == JUMPDEST) || endsTEVMBlock(p.instrs[i-1].op)
+	}
+	return p
+}
+
+// encodeTEVM - serialises program, format is:
+//
+//	version byte, uvarint number of instructions, and for every instruction:
+//	kind byte, op byte, flags byte, uvarint pc, [pushOp byte, argument bytes], [varint target]
+func encodeTEVM(p *tevmProgram) []byte {
+	buf := []byte{tevmVersion}
+	buf = appendUvarint(buf, uint64(len(p.instrs)))
+	for i := range p.instrs {
+		ins := &p.instrs[i]
+		var flags byte
+		if ins.blockStart {
+			flags = 1
+		}
+		buf = append(buf, byte(ins.kind), byte(ins.op), flags)
+		buf = appendUvarint(buf, ins.pc)
+		if ins.kind == tevmOp {
+			continue
+		}
+		buf = append(buf, byte(ins.pushOp))
+		arg := ins.arg.Bytes32()
+		buf = append(buf, arg[32-int(ins.pushOp-PUSH1+1):]...)
+		if ins.kind == tevmPushJump || ins.kind == tevmPushJumpi {
+			var b [binary.MaxVarintLen64]byte
+			buf = append(buf, b[:binary.PutVarint(b[:], int64(ins.target))]...)
+		}
+	}
+	return buf
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+// decodeTEVM - parses code produced by Transpile
+func decodeTEVM(code []byte) (*tevmProgram, error) {
+	if len(code) == 0 || code[0] != tevmVersion {
+		return nil, fmt.Errorf("%w: unsupported version", ErrInvalidTEVMCode)
+	}
+	code = code[1:]
+	readUvarint := func() (uint64, bool) {
+		v, n := binary.Uvarint(code)
+		if n <= 0 {
+			return 0, false
+		}
+		code = code[n:]
+		return v, true
+	}
+	count, ok := readUvarint()
+	if !ok || count > uint64(len(code)) {
+		return nil, fmt.Errorf("%w: instructions count", ErrInvalidTEVMCode)
+	}
+	p := &tevmProgram{instrs: make([]tevmInstr, count), jumpdests: map[uint64]int{}}
+	for i := range p.instrs {
+		ins := &p.instrs[i]
+		if len(code) < 3 {
+			return nil, fmt.Errorf("%w: instruction %d", ErrInvalidTEVMCode, i)
+		}
+		ins.kind, ins.op, ins.blockStart = tevmKind(code[0]), OpCode(code[1]), code[2]&1 != 0
+		code = code[3:]
+		if ins.kind >= tevmKindsCount {
+			return nil, fmt.Errorf("%w: instruction %d kind %d", ErrInvalidTEVMCode, i, ins.kind)
+		}
+		if ins.pc, ok = readUvarint(); !ok {
+			return nil, fmt.Errorf("%w: pc of instruction %d", ErrInvalidTEVMCode, i)
+		}
+		if ins.kind == tevmOp {
+			if ins.op == JUMPDEST {
+				p.jumpdests[ins.pc] = i
+			}
+			continue
+		}
+		if len(code) < 1 || !OpCode(code[0]).IsPush() || len(code) < 1+int(code[0]-byte(PUSH1))+1 {
+			return nil, fmt.Errorf("%w: push of instruction %d", ErrInvalidTEVMCode, i)
+		}
+		ins.pushOp = OpCode(code[0])
+		size := int(ins.pushOp-PUSH1) + 1
+		ins.arg.SetBytes(code[1 : 1+size])
+		code = code[1+size:]
+		if ins.kind == tevmPushJump || ins.kind == tevmPushJumpi {
+			target, n := binary.Varint(code)
+			if n <= 0 || target < -1 || target >= int64(count) {
+				return nil, fmt.Errorf("%w: jump target of instruction %d", ErrInvalidTEVMCode, i)
+			}
+			ins.target = int(target)
+			code = code[n:]
+		}
+	}
+	if len(code) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrInvalidTEVMCode)
+	}
+	for i := range p.instrs {
+		ins := &p.instrs[i]
+		if (ins.kind == tevmPushJump || ins.kind == tevmPushJumpi) && ins.target >= 0 {
+			if t := &p.instrs[ins.target]; t.kind != tevmOp || t.op != JUMPDEST {
+				return nil, fmt.Errorf("%w: jump target of instruction %d is not JUMPDEST", ErrInvalidTEVMCode, i)
+			}
+		}
+	}
+	return p, nil
+}
+
+// staticGas - static gas of blocks for jump table, by index of block start.
+// Summing stops on opcodes undefined in jump table, execution fails on them anyway.
+func (p *tevmProgram) staticGas(jt *JumpTable) []uint64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if gas, ok := p.blockGas[jt]; ok {
+		return gas
+	}
+	gas := make([]uint64, len(p.instrs))
+	block, undefined := 0, false
+	for i := range p.instrs {
+		ins := &p.instrs[i]
+		if ins.blockStart {
+			block, undefined = i, false
+		}
+		if undefined {
+			continue
+		}
+		if ins.kind != tevmOp {
+			gas[block] += jt[ins.pushOp].constantGas
+			if ins.kind == tevmPush {
+				continue
+			}
+		}
+		if jt[ins.op] == nil {
+			undefined = true
+			continue
+		}
+		gas[block] += jt[ins.op].constantGas
+	}
+	if p.blockGas == nil {
+		p.blockGas = map[*JumpTable][]uint64{}
+	}
+	p.blockGas[jt] = gas
+	return gas
+}
@@ -1,6 +1,69 @@
 package vm
 
-// todo: TBD actual TEVM interpreter
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/core/vm/stack"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+const tevmCacheSize = 4096
+
+// tevmCache - programs decoded from TEVM code stored by Translation stage, by code hash. It's in front of the db:
+// CheckTEVM reads and decodes stored code only on a miss.
+var tevmCache, _ = lru.New(tevmCacheSize)
+
+// GetCheckTEVM - returns CheckTEVM of BlockContext: true if the contract has TEVM code stored by Translation stage.
+// The stored program is loaded to tevmCache for the interpreter.
+func GetCheckTEVM(db ethdb.KVGetter) func(codeHash common.Hash) (bool, error) {
+	checked := map[common.Hash]bool{}
+
+	return func(codeHash common.Hash) (bool, error) {
+		// init code of contract creation
+		if codeHash == (common.Hash{}) {
+			return true, nil
+		}
+
+		if ok, cached := checked[codeHash]; cached {
+			return ok, nil
+		}
+
+		ok, err := loadTEVMProgram(db, codeHash)
+		if err != nil {
+			return false, err
+		}
+		checked[codeHash] = ok
+		return ok, nil
+	}
+}
+
+// loadTEVMProgram - puts the program stored by Translation stage to tevmCache, false if the code isn't translated
+func loadTEVMProgram(db ethdb.KVGetter, codeHash common.Hash) (bool, error) {
+	if tevmCache.Contains(codeHash) {
+		return true, nil
+	}
+	code, err := db.GetOne(dbutils.ContractTEVMCodeBucket, codeHash.Bytes())
+	if err != nil && !errors.Is(err, ethdb.ErrKeyNotFound) {
+		return false, fmt.Errorf("can't read TEVM code of contract %q: %w", codeHash.String(), err)
+	}
+	if len(code) == 0 {
+		return false, nil
+	}
+	p, err := decodeTEVM(code)
+	if err != nil {
+		return false, fmt.Errorf("TEVM code of contract %q: %w", codeHash.String(), err)
+	}
+	tevmCache.Add(codeHash, p)
+	return true, nil
+}
 
 // TEVMInterpreter represents an TEVM interpreter
 type TEVMInterpreter struct {
@@ -18,3 +81,347 @@ const (
 func NewTEVMInterpreter(evm *EVM, cfg Config) *TEVMInterpreter {
 	return &TEVMInterpreter{NewEVMInterpreter(evm, cfg)}
 }
+
+// tevmProgramOf - program loaded by CheckTEVM. Init code isn't stored, and the program may be evicted from cache
+// since the check - translation is deterministic, so then the interpreter translates contract code itself.
+func tevmProgramOf(contract *Contract) *tevmProgram {
+	if contract.CodeHash != (common.Hash{}) {
+		if p, ok := tevmCache.Get(contract.CodeHash); ok {
+			return p.(*tevmProgram)
+		}
+	}
+	return translate(contract.Code)
+}
+
+// tevmTrace - state of the current step for Tracer
+type tevmTrace struct {
+	op      OpCode
+	pc      uint64
+	gas     uint64
+	cost    uint64
+	logged  bool
+	enabled bool
+}
+
+// start - new step, cost of the previous one is kept until the new one is known, same as in EVMInterpreter
+func (t *tevmTrace) start(op OpCode, pc uint64, gas uint64) {
+	t.op, t.pc, t.gas, t.logged = op, pc, gas, false
+}
+
+// Run executes TEVM code of the contract. Results are the same as of EVMInterpreter: static gas is charged by blocks,
+// but blocks end on opcodes observing remaining gas, so the difference is only in the moment of out of gas failure.
+// With Debug enabled instructions are executed one by one, with gas charged for every opcode, so tracers see
+// the same steps as with EVMInterpreter.
+func (in *TEVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (ret []byte, err error) {
+	// Increment the call depth which is restricted to 1024
+	in.evm.depth++
+	defer func() { in.evm.depth-- }()
+
+	// Make sure the readOnly is only set if we aren't in readOnly yet.
+	// This makes also sure that the readOnly flag isn't removed for child calls.
+	if readOnly && !in.readOnly {
+		in.readOnly = true
+		defer func() { in.readOnly = false }()
+	}
+
+	// Reset the previous call's return data. It's unimportant to preserve the old buffer
+	// as every returning call will return new data anyway.
+	in.returnData = nil
+
+	// Don't bother with the execution if there's no code.
+	if len(contract.Code) == 0 {
+		return nil, nil
+	}
+
+	var (
+		p           = tevmProgramOf(contract)
+		mem         = NewMemory()
+		locStack    = stack.New()
+		callContext = &callCtx{
+			memory:   mem,
+			stack:    locStack,
+			contract: contract,
+		}
+		trace = &tevmTrace{enabled: in.cfg.Debug}
+	)
+	defer func() {
+		stack.ReturnNormalStack(locStack)
+	}()
+	contract.Input = input
+
+	if trace.enabled {
+		defer func() {
+			if err != nil {
+				if !trace.logged {
+					in.cfg.Tracer.CaptureState(in.evm, trace.pc, trace.op, trace.gas, trace.cost, mem, locStack, in.returnData, contract, in.evm.depth, err) //nolint:errcheck
+				} else {
+					_ = in.cfg.Tracer.CaptureFault(in.evm, trace.pc, trace.op, trace.gas, trace.cost, mem, locStack, contract, in.evm.depth, err)
+				}
+			}
+		}()
+		return in.runSteps(p, callContext, trace)
+	}
+	return in.runBlocks(p, callContext, trace)
+}
+
+// runBlocks - charges static gas once per block and executes fused instructions
+func (in *TEVMInterpreter) runBlocks(p *tevmProgram, callContext *callCtx, trace *tevmTrace) ([]byte, error) {
+	var (
+		contract = callContext.contract
+		locStack = callContext.stack
+		blockGas = p.staticGas(in.jt)
+		i        int
+	)
+	for steps := 1; ; steps++ {
+		if steps%1000 == 0 && atomic.LoadInt32(&in.evm.abort) != 0 {
+			return nil, nil
+		}
+		// running past the end of code is STOP
+		if i >= len(p.instrs) {
+			return nil, nil
+		}
+		ins := &p.instrs[i]
+		if ins.blockStart && !contract.UseGas(blockGas[i]) {
+			return nil, ErrOutOfGas
+		}
+		if ins.kind == tevmOp {
+			next, res, halt, err := in.execute(p, i, ins.op, ins.pc, callContext, false, trace)
+			if err != nil || halt {
+				return res, err
+			}
+			i = next
+			continue
+		}
+
+		// push and fused instructions, stack limits are the same as for separate opcodes
+		sLen := locStack.Len()
+		if sLen >= int(params.StackLimit) {
+			return nil, &ErrStackOverflow{stackLen: sLen, limit: int(params.StackLimit) - 1}
+		}
+		switch ins.kind {
+		case tevmPush:
+			locStack.Push(&ins.arg)
+			i++
+		case tevmPushJump:
+			if ins.target < 0 {
+				return nil, ErrInvalidJump
+			}
+			i = ins.target
+		case tevmPushJumpi:
+			if sLen < 1 {
+				return nil, &ErrStackUnderflow{stackLen: sLen + 1, required: 2}
+			}
+			cond := locStack.Pop()
+			if cond.IsZero() {
+				i++
+				continue
+			}
+			if ins.target < 0 {
+				return nil, ErrInvalidJump
+			}
+			i = ins.target
+		case tevmPushOp:
+			if sLen < 1 {
+				return nil, &ErrStackUnderflow{stackLen: sLen + 1, required: 2}
+			}
+			pushBinaryOp(ins.op, &ins.arg, locStack.Peek())
+			i++
+		}
+	}
+}
+
+// runSteps - executes EVM opcodes one by one, for tracing
+func (in *TEVMInterpreter) runSteps(p *tevmProgram, callContext *callCtx, trace *tevmTrace) ([]byte, error) {
+	var (
+		contract = callContext.contract
+		locStack = callContext.stack
+		i        int
+	)
+	for steps := 1; ; steps++ {
+		if steps%1000 == 0 && atomic.LoadInt32(&in.evm.abort) != 0 {
+			return nil, nil
+		}
+		// running past the end of code is STOP, traced as in EVMInterpreter
+		if i >= len(p.instrs) {
+			_, res, _, err := in.execute(p, i, STOP, uint64(len(contract.Code)), callContext, true, trace)
+			return res, err
+		}
+		ins := &p.instrs[i]
+		op, pc := ins.op, ins.pc
+		if ins.kind != tevmOp {
+			trace.start(ins.pushOp, ins.pc, contract.Gas)
+			operation := in.jt[ins.pushOp]
+			if sLen := locStack.Len(); sLen > operation.maxStack {
+				return nil, &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
+			}
+			trace.cost = operation.constantGas
+			if !contract.UseGas(operation.constantGas) {
+				return nil, ErrOutOfGas
+			}
+			in.cfg.Tracer.CaptureState(in.evm, ins.pc, ins.pushOp, trace.gas, trace.cost, callContext.memory, locStack, in.returnData, contract, in.evm.depth, nil) //nolint:errcheck
+			trace.logged = true
+			locStack.Push(&ins.arg)
+			if ins.kind == tevmPush {
+				i++
+				continue
+			}
+			pc = ins.opPc()
+		}
+		next, res, halt, err := in.execute(p, i, op, pc, callContext, true, trace)
+		if err != nil || halt {
+			return res, err
+		}
+		i = next
+	}
+}
+
+// execute - executes EVM opcode of instruction i by jump table, returns index of the next instruction.
+// Static gas is charged only if chargeStatic is set, otherwise it's charged by block.
+func (in *TEVMInterpreter) execute(p *tevmProgram, i int, op OpCode, pc uint64, callContext *callCtx, chargeStatic bool, trace *tevmTrace) (next int, res []byte, halt bool, err error) {
+	var (
+		contract = callContext.contract
+		locStack = callContext.stack
+		mem      = callContext.memory
+	)
+	if trace.enabled {
+		trace.start(op, pc, contract.Gas)
+	}
+	operation := in.jt[op]
+	if operation == nil {
+		return 0, nil, true, &ErrInvalidOpCode{opcode: op}
+	}
+	// Validate stack
+	if sLen := locStack.Len(); sLen < operation.minStack {
+		return 0, nil, true, &ErrStackUnderflow{stackLen: sLen, required: operation.minStack}
+	} else if sLen > operation.maxStack {
+		return 0, nil, true, &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
+	}
+	// If the operation is valid, enforce and write restrictions
+	if in.readOnly && in.evm.ChainRules.IsByzantium {
+		if operation.writes || (op == CALL && locStack.Back(2).Sign() != 0) {
+			return 0, nil, true, ErrWriteProtection
+		}
+	}
+	var cost uint64
+	if chargeStatic {
+		cost = operation.constantGas
+		trace.cost = cost
+		if !contract.UseGas(operation.constantGas) {
+			return 0, nil, true, ErrOutOfGas
+		}
+	}
+
+	var memorySize uint64
+	if operation.memorySize != nil {
+		memSize, overflow := operation.memorySize(locStack)
+		if overflow {
+			return 0, nil, true, ErrGasUintOverflow
+		}
+		if memorySize, overflow = math.SafeMul(toWordSize(memSize), 32); overflow {
+			return 0, nil, true, ErrGasUintOverflow
+		}
+	}
+	if operation.dynamicGas != nil {
+		var dynamicCost uint64
+		dynamicCost, err = operation.dynamicGas(in.evm, contract, locStack, mem, memorySize)
+		cost += dynamicCost
+		trace.cost = cost
+		if err != nil || !contract.UseGas(dynamicCost) {
+			return 0, nil, true, ErrOutOfGas
+		}
+	}
+	if memorySize > 0 {
+		mem.Resize(memorySize)
+	}
+
+	if trace.enabled {
+		trace.cost = cost
+		in.cfg.Tracer.CaptureState(in.evm, pc, op, trace.gas, cost, mem, locStack, in.returnData, contract, in.evm.depth, nil) //nolint:errcheck
+		trace.logged = true
+	}
+
+	switch op {
+	case JUMP:
+		pos := locStack.Pop()
+		target, ok := p.jumpTarget(&pos)
+		if !ok {
+			return 0, nil, true, ErrInvalidJump
+		}
+		return target, nil, false, nil
+	case JUMPI:
+		pos, cond := locStack.Pop(), locStack.Pop()
+		if cond.IsZero() {
+			return i + 1, nil, false, nil
+		}
+		target, ok := p.jumpTarget(&pos)
+		if !ok {
+			return 0, nil, true, ErrInvalidJump
+		}
+		return target, nil, false, nil
+	case PC:
+		locStack.Push(new(uint256.Int).SetUint64(pc))
+		return i + 1, nil, false, nil
+	}
+
+	res, err = operation.execute(&pc, in.EVMInterpreter, callContext)
+	// if the operation clears the return data (e.g. it has returning data)
+	// set the last return to the result of the operation.
+	if operation.returns {
+		in.returnData = common.CopyBytes(res)
+	}
+
+	switch {
+	case err != nil:
+		return 0, nil, true, err
+	case operation.reverts:
+		return 0, res, true, ErrExecutionReverted
+	case operation.halts:
+		return 0, res, true, nil
+	}
+	return i + 1, nil, false, nil
+}
+
+func (p *tevmProgram) jumpTarget(dest *uint256.Int) (int, bool) {
+	udest, overflow := dest.Uint64WithOverflow()
+	if overflow {
+		return 0, false
+	}
+	target, ok := p.jumpdests[udest]
+	return target, ok
+}
+
+// pushBinaryOp - executes binary operation with pushed value x as the first operand, result replaces y
+func pushBinaryOp(op OpCode, x, y *uint256.Int) {
+	switch op {
+	case ADD:
+		y.Add(x, y)
+	case MUL:
+		y.Mul(x, y)
+	case SUB:
+		y.Sub(x, y)
+	case LT:
+		if x.Lt(y) {
+			y.SetOne()
+		} else {
+			y.Clear()
+		}
+	case GT:
+		if x.Gt(y) {
+			y.SetOne()
+		} else {
+			y.Clear()
+		}
+	case EQ:
+		if x.Eq(y) {
+			y.SetOne()
+		} else {
+			y.Clear()
+		}
+	case AND:
+		y.And(x, y)
+	case OR:
+		y.Or(x, y)
+	case XOR:
+		y.Xor(x, y)
+	}
+}
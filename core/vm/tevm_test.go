@@ -0,0 +1,87 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTranspile(t *testing.T) {
+	code := []byte{
+		byte(PUSH1), 6, byte(JUMP), // 0: fused static jump
+		byte(PUSH1), 1, byte(ADD), // 3: fused op
+		byte(JUMPDEST),                  // 6
+		byte(PUSH2), 0, 20, byte(JUMPI), // 7: fused jump to invalid destination
+		byte(GAS),      // 11: ends block
+		byte(PUSH1), 1, // 12: plain push
+		byte(SSTORE),    // 14
+		byte(PUSH32), 1, // 15: truncated push
+	}
+	p := translate(code)
+	expected := []struct {
+		kind       tevmKind
+		op         OpCode
+		pc         uint64
+		target     int
+		arg        uint64
+		blockStart bool
+	}{
+		{tevmPushJump, JUMP, 0, 2, 6, true},
+		{tevmPushOp, ADD, 3, 0, 1, true},
+		{tevmOp, JUMPDEST, 6, 0, 0, true},
+		{tevmPushJumpi, JUMPI, 7, -1, 20, false},
+		{tevmOp, GAS, 11, 0, 0, true},
+		{tevmPush, PUSH1, 12, 0, 1, true},
+		{tevmOp, SSTORE, 14, 0, 0, false},
+		{tevmPush, PUSH32, 15, 0, 0, true},
+	}
+	if len(p.instrs) != len(expected) {
+		t.Fatalf("expected %d instructions, got %d", len(expected), len(p.instrs))
+	}
+	for i, exp := range expected {
+		ins := p.instrs[i]
+		if ins.kind != exp.kind || ins.op != exp.op || ins.pc != exp.pc || ins.blockStart != exp.blockStart {
+			t.Errorf("instruction %d: expected %+v, got %+v", i, exp, ins)
+		}
+		if (ins.kind == tevmPushJump || ins.kind == tevmPushJumpi) && ins.target != exp.target {
+			t.Errorf("instruction %d: expected target %d, got %d", i, exp.target, ins.target)
+		}
+		if ins.kind != tevmOp && ins.kind != tevmPush && ins.arg.Uint64() != exp.arg {
+			t.Errorf("instruction %d: expected argument %d, got %d", i, exp.arg, ins.arg.Uint64())
+		}
+	}
+	if arg := p.instrs[7].arg.Bytes32(); arg[0] != 1 || arg[1] != 0 {
+		t.Errorf("missing bytes of truncated push are not zeroes: %x", arg)
+	}
+
+	// static gas of blocks: PUSH1 JUMP, PUSH1 ADD, JUMPDEST PUSH2 JUMPI, GAS, PUSH1 SSTORE (dynamic), PUSH32
+	jt := newIstanbulInstructionSet()
+	gas := p.staticGas(&jt)
+	for i, exp := range map[int]uint64{0: 11, 1: 6, 2: 14, 4: 2, 5: 3, 7: 3} {
+		if gas[i] != exp {
+			t.Errorf("block %d: expected static gas %d, got %d", i, exp, gas[i])
+		}
+	}
+
+	// encoding round trip
+	encoded, err := Transpile(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeTEVM(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.instrs) != len(p.instrs) || len(decoded.jumpdests) != len(p.jumpdests) {
+		t.Fatalf("decoded program differs")
+	}
+	for i := range p.instrs {
+		if decoded.instrs[i] != p.instrs[i] {
+			t.Errorf("instruction %d: decoded %+v, expected %+v", i, decoded.instrs[i], p.instrs[i])
+		}
+	}
+	for _, invalid := range [][]byte{nil, {0}, encoded[:len(encoded)-1], append(encoded, 0)} {
+		if _, err = decodeTEVM(invalid); !errors.Is(err, ErrInvalidTEVMCode) {
+			t.Errorf("expected ErrInvalidTEVMCode for %x, got %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/cmd/sentry/download"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// auraEngineClient - implementation of aura.EngineClient: calls of validator set contracts on the state of
+// the node and sending of reports of misbehaving validators, signed by the mining key, through the tx pool.
+// It doesn't implement aura.ConsensusMessageBroadcaster: with remote sentries the node can't deliver consensus
+// messages, so it doesn't propose empty steps and seals blocks without transactions instead. Empty steps of other
+// validators in the seals of received blocks are still verified and rewarded.
+type auraEngineClient struct {
+	db          ethdb.RoKV
+	chainConfig *params.ChainConfig
+	engine      consensus.Engine
+	txPool      *core.TxPool
+	key         *ecdsa.PrivateKey
+}
+
+// reportGas - gas limit of report transactions, they are free (zero gas price) service transactions
+const reportGas = 1_000_000
+
+func (c *auraEngineClient) CallAtBlockHash(blockHash common.Hash, contract common.Address, data []byte) ([]byte, error) {
+	tx, err := c.db.BeginRo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	number := rawdb.ReadHeaderNumber(tx, blockHash)
+	if number == nil {
+		return nil, fmt.Errorf("block %x not found", blockHash)
+	}
+	return c.call(tx, rawdb.ReadHeader(tx, blockHash, *number), contract, data)
+}
+
+func (c *auraEngineClient) CallAtLatestBlock(contract common.Address, data []byte) ([]byte, error) {
+	tx, err := c.db.BeginRo(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return c.call(tx, rawdb.ReadCurrentHeader(tx), contract, data)
+}
+
+func (c *auraEngineClient) call(tx ethdb.Tx, header *types.Header, contract common.Address, data []byte) ([]byte, error) {
+	if header == nil {
+		return nil, fmt.Errorf("header not found")
+	}
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		return rawdb.ReadHeader(tx, hash, number)
+	}
+	ibs := state.New(state.NewPlainKvState(tx, header.Number.Uint64()))
+	blockCtx := core.NewEVMBlockContext(header, getHeader, c.engine, nil, nil /* checkTEVM */)
+	evm := vm.NewEVM(blockCtx, vm.TxContext{}, ibs, c.chainConfig, vm.Config{})
+	ret, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), contract, data, header.GasLimit)
+	return ret, err
+}
+
+func (c *auraEngineClient) Transact(contract common.Address, data []byte) error {
+	from := crypto.PubkeyToAddress(c.key.PublicKey)
+	txn := types.NewTransaction(c.txPool.Nonce(from), contract, uint256.NewInt(0), reportGas, uint256.NewInt(0), data)
+	signed, err := types.SignTx(txn, *types.LatestSignerForChainID(c.chainConfig.ChainID), c.key)
+	if err != nil {
+		return err
+	}
+	return c.txPool.AddLocal(signed)
+}
+
+// auraBroadcastingClient - auraEngineClient which delivers empty steps to the other validators through the sentries
+// running inside of the node, see download.SentryServerImpl.EnableConsensusMessages
+type auraBroadcastingClient struct {
+	*auraEngineClient
+	sentries []*download.SentryServerImpl
+}
+
+func (c *auraBroadcastingClient) BroadcastConsensusMessage(msg []byte) {
+	sent := 0
+	for _, sentry := range c.sentries {
+		sent += sentry.BroadcastConsensusMessage(msg)
+	}
+	log.Trace("[aura] Consensus message sent", "peers", sent)
+}
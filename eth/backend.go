@@ -40,6 +40,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/common/etl"
 	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/consensus/aura"
 	"github.com/ledgerwatch/erigon/consensus/clique"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
 	"github.com/ledgerwatch/erigon/core"
@@ -55,6 +56,7 @@ import (
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/ethstats"
 	"github.com/ledgerwatch/erigon/log"
 	"github.com/ledgerwatch/erigon/node"
 	"github.com/ledgerwatch/erigon/p2p"
@@ -101,6 +103,9 @@ type Ethereum struct {
 	miningSealingQuit chan struct{}
 	pendingBlocks     chan *types.Block
 	minedBlocks       chan *types.Block
+	devMiner          *devMiner                // instant sealing of the developer chain, nil otherwise
+	executionEngine   *stages2.ExecutionEngine // chain driven by a consensus client (--beacon), nil otherwise
+	stratum           *ethash.StratumServer    // stratum endpoint of ethash miners (--miner.stratum.addr), nil otherwise
 
 	// downloader fields
 	downloadCtx     context.Context
@@ -154,6 +159,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		if err != nil {
 			return nil, err
 		}
+		if config.Snapshot.Source != "" {
+			torrentClient.SetSource(snapshotsync.NewMirrorSource(config.Snapshot.Dir, config.Snapshot.Source))
+		}
 		if len(peerID) == 0 {
 			log.Info("Generate new bittorent peerID", "id", common.Bytes2Hex(torrentClient.PeerID()))
 			if err = chainKv.Update(context.Background(), func(tx ethdb.RwTx) error {
@@ -171,9 +179,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		if err != nil {
 			return nil, err
 		}
+		if config.Snapshot.Mode.State {
+			if err = snapshotsync.StateDiffSeeding(torrentClient, config.Snapshot.Dir); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	chainConfig, genesis, genesisErr := core.CommitGenesisBlock(chainKv, config.Genesis, config.StorageMode.History)
+	var chainConfig *params.ChainConfig
+	var genesis *types.Block
+	var genesisErr error
+	if config.ChainSpec != nil {
+		chainConfig, genesis, genesisErr = core.CommitChainSpec(chainKv, config.ChainSpec, config.StorageMode.History)
+	} else {
+		chainConfig, genesis, genesisErr = core.CommitGenesisBlock(chainKv, config.Genesis, config.StorageMode.History)
+	}
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
 	}
@@ -245,6 +265,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 
 	backend.txPool = core.NewTxPool(config.TxPool, chainConfig, chainKv)
+	if config.Fork != nil {
+		backend.txPool.SetForkSource(config.Fork)
+	}
 
 	// setting notifier to support streaming events to rpc daemon
 	var mg *snapshotsync.SnapshotMigrator
@@ -265,6 +288,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	backend.pendingBlocks = make(chan *types.Block, 1)
 	backend.minedBlocks = make(chan *types.Block, 1)
 
+	backend.downloadCtx, backend.downloadCancel = context.WithCancel(context.Background())
 	miner := stagedsync.NewMiningState(&config.Miner)
 	backend.pendingBlocks = miner.PendingResultCh
 	backend.minedBlocks = miner.MiningResultCh
@@ -272,8 +296,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	mining := stagedsync.New(
 		stagedsync.MiningStages(backend.downloadCtx,
 			stagedsync.StageMiningCreateBlockCfg(backend.chainKV, miner, *backend.chainConfig, backend.engine, backend.txPool, tmpdir),
-			stagedsync.StageMiningExecCfg(backend.chainKV, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir),
-			stagedsync.StageHashStateCfg(backend.chainKV, tmpdir),
+			stagedsync.StageMiningExecCfg(backend.chainKV, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, config.Fork, tmpdir),
+			stagedsync.StageHashStateCfg(backend.chainKV, tmpdir, config.EtlCompression),
 			stagedsync.StageTrieCfg(backend.chainKV, false, true, tmpdir),
 			stagedsync.StageMiningFinishCfg(backend.chainKV, *backend.chainConfig, backend.engine, miner, backend.miningSealingQuit),
 		), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder)
@@ -281,74 +305,42 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	var ethashApi *ethash.API
 	if casted, ok := backend.engine.(*ethash.Ethash); ok {
 		ethashApi = casted.APIs(nil)[1].Service.(*ethash.API)
-	}
-
-	kvRPC := remotedbserver.NewKvServer(backend.chainKV)
-	ethBackendRPC := remotedbserver.NewEthBackendServer(backend, backend.notifications.Events)
-	txPoolRPC := remotedbserver.NewTxPoolServer(context.Background(), backend.txPool)
-	miningRPC := remotedbserver.NewMiningServer(context.Background(), backend, ethashApi)
-
-	if stack.Config().PrivateApiAddr != "" {
-
-		if stack.Config().TLSConnection {
-			// load peer cert/key, ca cert
-			var creds credentials.TransportCredentials
-
-			if stack.Config().TLSCACert != "" {
-				var peerCert tls.Certificate
-				var caCert []byte
-				peerCert, err = tls.LoadX509KeyPair(stack.Config().TLSCertFile, stack.Config().TLSKeyFile)
-				if err != nil {
-					log.Error("load peer cert/key error:%v", err)
-					return nil, err
-				}
-				caCert, err = ioutil.ReadFile(stack.Config().TLSCACert)
-				if err != nil {
-					log.Error("read ca cert file error:%v", err)
-					return nil, err
-				}
-				caCertPool := x509.NewCertPool()
-				caCertPool.AppendCertsFromPEM(caCert)
-				creds = credentials.NewTLS(&tls.Config{
-					Certificates: []tls.Certificate{peerCert},
-					ClientCAs:    caCertPool,
-					ClientAuth:   tls.RequireAndVerifyClientCert,
-					MinVersion:   tls.VersionTLS12,
-				})
-			} else {
-				creds, err = credentials.NewServerTLSFromFile(stack.Config().TLSCertFile, stack.Config().TLSKeyFile)
-			}
-
-			if err != nil {
-				return nil, err
+		if config.Miner.Enabled && config.Miner.StratumAddr != "" {
+			if backend.stratum, err = ethash.StartStratumServer(casted, config.Miner.StratumAddr, config.Miner.StratumDifficulty); err != nil {
+				return nil, fmt.Errorf("could not start stratum server: %w", err)
 			}
-			backend.privateAPI, err = remotedbserver.StartGrpc(
-				kvRPC,
-				ethBackendRPC,
-				txPoolRPC,
-				miningRPC,
-				stack.Config().PrivateApiAddr,
-				stack.Config().PrivateApiRateLimit,
-				&creds)
-			if err != nil {
+		}
+	} else if config.Miner.Enabled && config.Miner.StratumAddr != "" {
+		return nil, fmt.Errorf("stratum server requires ethash, chain uses %T", backend.engine)
+	}
+	var cliqueBackend remotedbserver.CliqueBackend
+	var devBackend remotedbserver.DevBackend
+	if casted, ok := backend.engine.(*clique.Clique); ok {
+		cliqueBackend = casted
+		if config.Miner.Enabled && config.Miner.InstantSeal {
+			if backend.devMiner, err = newDevMiner(casted, backend.notifications.Events); err != nil {
 				return nil, err
 			}
-		} else {
-			backend.privateAPI, err = remotedbserver.StartGrpc(
-				kvRPC,
-				ethBackendRPC,
-				txPoolRPC,
-				miningRPC,
-				stack.Config().PrivateApiAddr,
-				stack.Config().PrivateApiRateLimit,
-				nil)
-			if err != nil {
+			devBackend = backend.devMiner
+		}
+		if config.Beacon.Enabled && config.Miner.Enabled && backend.chainConfig.Clique.Period == 0 {
+			// blocks are sealed on request of the consensus client, with or without transactions
+			if err = casted.InstantSeal(); err != nil {
 				return nil, err
 			}
 		}
 	}
 
-	backend.downloadCtx, backend.downloadCancel = context.WithCancel(context.Background())
+	kvRPC := remotedbserver.NewKvServer(backend.chainKV)
+	if casted, ok := backend.engine.(*clique.Clique); ok {
+		kvRPC.ServeCliqueSnapshots(casted.SnapshotDB())
+	}
+	ethBackendRPC := remotedbserver.NewEthBackendServer(backend, backend.notifications.Events)
+	txPoolRPC := remotedbserver.NewTxPoolServer(context.Background(), backend.txPool)
+	miningRPC := remotedbserver.NewMiningServer(context.Background(), backend, ethashApi)
+	cliqueRPC := remotedbserver.NewCliqueServer(cliqueBackend)
+	devRPC := remotedbserver.NewDevServer(devBackend)
+
 	if len(stack.Config().P2P.SentryAddr) > 0 {
 		for _, addr := range stack.Config().P2P.SentryAddr {
 			sentry, err := download.GrpcSentryClient(backend.downloadCtx, addr)
@@ -388,6 +380,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		server65 := download.NewSentryServer(backend.downloadCtx, d65, readNodeInfo, &cfg65, eth.ETH65)
 		backend.sentryServers = append(backend.sentryServers, server65)
 		backend.sentries = append(backend.sentries, remote.NewSentryClientDirect(eth.ETH65, server65))
+		if auraEngine, ok := backend.engine.(*aura.AuRa); ok {
+			for _, srv := range backend.sentryServers {
+				srv.EnableConsensusMessages(auraEngine.HandleMessage)
+			}
+		}
 		go func() {
 			logEvery := time.NewTicker(120 * time.Second)
 			defer logEvery.Stop()
@@ -423,6 +420,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 
 	backend.txPoolP2PServer.TxFetcher = fetcher.NewTxFetcher(backend.txPool.Has, backend.txPool.AddRemotes, fetchTx)
+
+	if config.EthstatsURL != "" {
+		if err := ethstats.New(stack, backend.sentries, backend.chainKV, backend.engine, backend.txPool, &config.Miner, backend.notifications.Events, backend.networkID, config.EthstatsURL); err != nil {
+			return nil, err
+		}
+	}
 	config.BodyDownloadTimeoutSeconds = 30
 
 	backend.stagedSync, err = stages2.NewStagedSync2(
@@ -440,6 +443,93 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
+	var engineBackend remotedbserver.EngineBackend
+	if config.Beacon.Enabled {
+		// blocks assembled for the consensus client are not inserted by the mining loop, so the mining state is its own
+		var beaconMining *stagedsync.Sync
+		beaconMinerConfig := config.Miner
+		beaconMiner := stagedsync.NewMiningState(&beaconMinerConfig)
+		if config.Miner.Enabled {
+			beaconMining = stagedsync.New(
+				stagedsync.MiningStages(backend.downloadCtx,
+					stagedsync.StageMiningCreateBlockCfg(backend.chainKV, beaconMiner, *backend.chainConfig, backend.engine, backend.txPool, tmpdir),
+					stagedsync.StageMiningExecCfg(backend.chainKV, beaconMiner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, config.Fork, tmpdir),
+					stagedsync.StageHashStateCfg(backend.chainKV, tmpdir, config.EtlCompression),
+					stagedsync.StageTrieCfg(backend.chainKV, false, true, tmpdir),
+					stagedsync.StageMiningFinishCfg(backend.chainKV, *backend.chainConfig, backend.engine, beaconMiner, backend.miningSealingQuit),
+				), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder)
+		}
+		backend.executionEngine = stages2.NewExecutionEngine(backend.chainKV, backend.stagedSync, backend.downloadServer, backend.notifications, beaconMining, beaconMiner)
+		engineBackend = backend.executionEngine
+	}
+	engineRPC := remotedbserver.NewEngineServer(engineBackend)
+
+	if stack.Config().PrivateApiAddr != "" {
+
+		if stack.Config().TLSConnection {
+			// load peer cert/key, ca cert
+			var creds credentials.TransportCredentials
+
+			if stack.Config().TLSCACert != "" {
+				var peerCert tls.Certificate
+				var caCert []byte
+				peerCert, err = tls.LoadX509KeyPair(stack.Config().TLSCertFile, stack.Config().TLSKeyFile)
+				if err != nil {
+					log.Error("load peer cert/key error:%v", err)
+					return nil, err
+				}
+				caCert, err = ioutil.ReadFile(stack.Config().TLSCACert)
+				if err != nil {
+					log.Error("read ca cert file error:%v", err)
+					return nil, err
+				}
+				caCertPool := x509.NewCertPool()
+				caCertPool.AppendCertsFromPEM(caCert)
+				creds = credentials.NewTLS(&tls.Config{
+					Certificates: []tls.Certificate{peerCert},
+					ClientCAs:    caCertPool,
+					ClientAuth:   tls.RequireAndVerifyClientCert,
+					MinVersion:   tls.VersionTLS12,
+				})
+			} else {
+				creds, err = credentials.NewServerTLSFromFile(stack.Config().TLSCertFile, stack.Config().TLSKeyFile)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+			backend.privateAPI, err = remotedbserver.StartGrpc(
+				kvRPC,
+				ethBackendRPC,
+				txPoolRPC,
+				miningRPC,
+				cliqueRPC,
+				devRPC,
+				engineRPC,
+				stack.Config().PrivateApiAddr,
+				stack.Config().PrivateApiRateLimit,
+				&creds)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			backend.privateAPI, err = remotedbserver.StartGrpc(
+				kvRPC,
+				ethBackendRPC,
+				txPoolRPC,
+				miningRPC,
+				cliqueRPC,
+				devRPC,
+				engineRPC,
+				stack.Config().PrivateApiAddr,
+				stack.Config().PrivateApiRateLimit,
+				nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	go txpropagate.BroadcastPendingTxsToNetwork(backend.downloadCtx, backend.txPool, backend.txPoolP2PServer.RecentPeers, backend.downloadServer)
 
 	go func() {
@@ -447,8 +537,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		for {
 			select {
 			case b := <-backend.minedBlocks:
-				//p2p
-				//backend.downloadServer.BroadcastNewBlock(context.Background(), b, b.Difficulty())
+				// own chain and p2p, for all engines
+				if err := backend.downloadServer.PropagateMinedBlock(context.Background(), b); err != nil {
+					log.Error("mined block propagation", "err", err)
+				}
 				//rpcdaemon
 				if err := miningRPC.BroadcastMinedBlock(b); err != nil {
 					log.Error("txpool rpc mined block broadcast", "err", err)
@@ -557,8 +649,20 @@ func (s *Ethereum) StartMining(ctx context.Context, kv ethdb.RwKV, mining *stage
 		}
 
 		clique.Authorize(eb, func(_ common.Address, mimeType string, message []byte) ([]byte, error) {
+			return crypto.Sign(crypto.Keccak256(message), cfg.SigKey) // clique signs RLP of the header, see clique.SealHash
+		})
+	}
+	auraEngine, isAura := s.engine.(*aura.AuRa)
+	if isAura && cfg.SigKey != nil {
+		auraEngine.Authorize(eb, func(_ common.Address, mimeType string, message []byte) ([]byte, error) {
 			return crypto.Sign(message, cfg.SigKey)
 		})
+		client := &auraEngineClient{db: kv, chainConfig: s.chainConfig, engine: s.engine, txPool: s.txPool, key: cfg.SigKey}
+		if len(s.sentryServers) > 0 {
+			auraEngine.RegisterClient(&auraBroadcastingClient{auraEngineClient: client, sentries: s.sentryServers})
+		} else {
+			auraEngine.RegisterClient(client)
+		}
 	}
 
 	if s.chainConfig.ChainID.Uint64() != params.MainnetChainConfig.ChainID.Uint64() {
@@ -577,6 +681,11 @@ func (s *Ethereum) StartMining(ctx context.Context, kv ethdb.RwKV, mining *stage
 		}
 	}
 
+	if s.executionEngine != nil { // blocks are assembled on request of the consensus client
+		close(s.waitForMiningStop)
+		return nil
+	}
+
 	go func() {
 		defer debug.LogPanic()
 		defer close(s.waitForMiningStop)
@@ -589,13 +698,43 @@ func (s *Ethereum) StartMining(ctx context.Context, kv ethdb.RwKV, mining *stage
 		var hasWork bool
 		errc := make(chan error, 1)
 
+		// instant sealing: evm_mine requests, replied by the step which mines their block
+		var mineRequests chan chan<- devMineResult
+		var replies []chan<- devMineResult
+		var stepIncluded bool // the last step mined transactions, written before its result is sent to errc
+		if s.devMiner != nil {
+			mineRequests = s.devMiner.requests
+		}
+
+		// AuRa validators propose blocks in their steps, not only on new transactions
+		var stepTicker <-chan time.Time
+		if isAura && cfg.SigKey != nil {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			stepTicker = ticker.C
+		}
+
 		for {
 			select {
 			case <-newTransactions:
 				hasWork = true
+			case reply := <-mineRequests:
+				hasWork = true
+				replies = append(replies, reply)
+			case <-stepTicker:
+				if auraEngine.Step() {
+					hasWork = true
+				}
 			case err := <-errc:
 				works = false
-				hasWork = false
+				if s.devMiner != nil {
+					// instant sealing mines transactions which stayed pending during the step (e.g. over the gas limit
+					// of the block), as long as the steps make progress: no spinning on transactions which can't be included
+					pending, _ := s.txPool.Stats()
+					hasWork = hasWork || (err == nil && stepIncluded && pending > 0)
+				} else {
+					hasWork = false
+				}
 				if errors.Is(err, common.ErrStopped) {
 					return
 				}
@@ -610,7 +749,18 @@ func (s *Ethereum) StartMining(ctx context.Context, kv ethdb.RwKV, mining *stage
 
 			if !works && hasWork {
 				works = true
-				go func() { errc <- stages2.MiningStep(ctx, kv, mining) }()
+				if s.devMiner != nil {
+					hasWork = false
+					stepReplies := replies
+					replies = nil
+					go func() {
+						var err error
+						stepIncluded, err = s.devMiner.step(ctx, kv, mining, stepReplies)
+						errc <- err
+					}()
+				} else {
+					go func() { errc <- stages2.MiningStep(ctx, kv, mining) }()
+				}
 			}
 		}
 	}()
@@ -654,15 +804,21 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() error {
 	for i := range s.sentries {
-		go func(i int) {
-			download.RecvMessageLoop(s.downloadCtx, s.sentries[i], s.downloadServer, nil)
-		}(i)
+		if s.executionEngine == nil { // blocks of the --beacon chain come from the consensus client, not from peers
+			go func(i int) {
+				download.RecvMessageLoop(s.downloadCtx, s.sentries[i], s.downloadServer, nil)
+			}(i)
+		}
 		go func(i int) {
 			download.RecvUploadMessageLoop(s.downloadCtx, s.sentries[i], s.downloadServer, nil)
 		}(i)
 	}
 
-	go Loop(s.downloadCtx, s.chainKV, s.stagedSync, s.downloadServer, s.notifications, s.waitForStageLoopStop, s.config.SyncLoopThrottle)
+	if s.executionEngine != nil {
+		go beaconLoop(s.downloadCtx, s.executionEngine, s.config.Beacon.MockPeriod, s.waitForStageLoopStop)
+		return nil
+	}
+	go Loop(s.downloadCtx, s.chainKV, s.stagedSync, s.downloadServer, s.notifications, s.waitForStageLoopStop, s.config.SyncLoopThrottle, s.devMiner != nil)
 	return nil
 }
 
@@ -691,6 +847,9 @@ func (s *Ethereum) Stop() error {
 	}
 
 	//s.miner.Stop()
+	if s.stratum != nil {
+		s.stratum.Close()
+	}
 	s.engine.Close()
 	if s.txPool != nil {
 		s.txPool.Stop()
@@ -699,6 +858,9 @@ func (s *Ethereum) Stop() error {
 	if s.config.Miner.Enabled {
 		<-s.waitForMiningStop
 	}
+	if s.config.Fork != nil {
+		s.config.Fork.Close()
+	}
 	return nil
 }
 
@@ -710,6 +872,7 @@ func Loop(
 	notifications *stagedsync.Notifications,
 	waitForDone chan struct{},
 	loopMinTime time.Duration,
+	waitForMined bool,
 ) {
 	defer debug.LogPanic()
 	stages2.StageLoop(
@@ -721,5 +884,6 @@ func Loop(
 		controlServer.UpdateHead,
 		waitForDone,
 		loopMinTime,
+		waitForMined,
 	)
 }
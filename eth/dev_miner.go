@@ -0,0 +1,123 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+)
+
+// devMinedTimeout - how long a mining step of the instant sealing chain waits for its block to be in the chain
+const devMinedTimeout = 10 * time.Second
+
+type devMineResult struct {
+	number uint64
+	err    error
+}
+
+// devMiner - implementation of remotedbserver.DevBackend: instant sealing of the developer chain
+// (see params.MiningConfig.InstantSeal). Blocks are mined by the usual mining stages, triggered by
+// new transactions of the pool and by evm_mine requests. Every mining step waits for its block to be
+// imported by the stage loop, so that the next block is built on top of it.
+type devMiner struct {
+	engine   *clique.Clique
+	requests chan chan<- devMineResult // evm_mine, replied when the block is in the chain
+	heads    chan uint64               // latest head of the chain
+}
+
+var _ remotedbserver.DevBackend = (*devMiner)(nil)
+
+func newDevMiner(engine *clique.Clique, events *remotedbserver.Events) (*devMiner, error) {
+	if err := engine.InstantSeal(); err != nil {
+		return nil, err
+	}
+	m := &devMiner{
+		engine:   engine,
+		requests: make(chan chan<- devMineResult),
+		heads:    make(chan uint64, 1),
+	}
+	events.AddHeaderSubscription(func(h *types.Header) error {
+		select { // keep the latest head only
+		case <-m.heads:
+		default:
+		}
+		m.heads <- h.Number.Uint64()
+		return nil
+	})
+	return m, nil
+}
+
+// Mine requests a block from the mining loop, the block is mined even without transactions
+func (m *devMiner) Mine(ctx context.Context) (uint64, error) {
+	reply := make(chan devMineResult, 1)
+	select {
+	case m.requests <- reply:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	select {
+	case res := <-reply:
+		return res.number, res.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (m *devMiner) IncreaseTime(seconds uint64) (uint64, error) {
+	return m.engine.IncreaseTime(seconds), nil
+}
+
+// step runs the mining stages and replies to the evm_mine requests the step was started for.
+// Returns whether the mined block included transactions.
+func (m *devMiner) step(ctx context.Context, kv ethdb.RwKV, mining *stagedsync.Sync, replies []chan<- devMineResult) (bool, error) {
+	number, err := m.mine(ctx, kv, mining)
+	for _, reply := range replies {
+		reply <- devMineResult{number: number, err: err}
+	}
+	if err != nil {
+		return false, err
+	}
+	var txAmount uint32
+	if err = kv.View(ctx, func(tx ethdb.Tx) (err error) {
+		_, _, txAmount, err = rawdb.ReadBodyByNumber(tx, number)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	return txAmount > 0, nil
+}
+
+func (m *devMiner) mine(ctx context.Context, kv ethdb.RwKV, mining *stagedsync.Sync) (uint64, error) {
+	var parent uint64
+	if err := kv.View(ctx, func(tx ethdb.Tx) (err error) {
+		parent, err = stages.GetStageProgress(tx, stages.Execution)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	if err := stages2.MiningStep(ctx, kv, mining); err != nil {
+		return 0, err
+	}
+	timeout := time.NewTimer(devMinedTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case head := <-m.heads:
+			if head > parent {
+				return head, nil
+			}
+		case <-timeout.C:
+			return 0, fmt.Errorf("mined block %d is not in the chain after %s", parent+1, devMinedTimeout)
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
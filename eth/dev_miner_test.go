@@ -0,0 +1,88 @@
+package eth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/clique"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevMinerInstantSeal(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	genesis := core.DeveloperGenesisBlock(0, addr)
+	engine := clique.New(genesis.Config, params.CliqueSnapshot, kv.NewTestKV(t))
+	engine.Authorize(addr, func(_ common.Address, _ string, message []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(message), key)
+	})
+	m := stages.MockWithEverything(t, genesis, key, ethdb.DefaultStorageMode, engine)
+	miner, err := newDevMiner(engine, m.Notifications.Events)
+	require.NoError(t, err)
+
+	// the node: the stage loop waits for the mined blocks, which are handed over by the mining loop
+	m.StageLoop(true)
+	go func() {
+		for {
+			select {
+			case b := <-m.MinedBlocks:
+				if err := m.AnnounceMinedBlock(b); err != nil {
+					t.Error(err)
+				}
+			case <-m.PendingBlocks:
+			case <-m.Ctx.Done():
+				return
+			}
+		}
+	}()
+
+	step := func() (uint64, bool) {
+		reply := make(chan devMineResult, 1)
+		included, err := miner.step(m.Ctx, m.DB, m.MiningSync, []chan<- devMineResult{reply})
+		require.NoError(t, err)
+		res := <-reply
+		require.NoError(t, res.err)
+		return res.number, included
+	}
+	header := func(number uint64) *types.Header {
+		var h *types.Header
+		require.NoError(t, m.DB.View(context.Background(), func(tx ethdb.Tx) error {
+			h = rawdb.ReadHeaderByNumber(tx, number)
+			return nil
+		}))
+		require.NotNil(t, h, "block %d is not in the chain", number)
+		return h
+	}
+
+	// evm_mine: empty block
+	number, included := step()
+	require.Equal(t, uint64(1), number)
+	require.False(t, included)
+
+	// transaction of the pool
+	signer := types.LatestSignerForChainID(genesis.Config.ChainID)
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(2*params.GWei), nil), *signer, key)
+	require.NoError(t, err)
+	require.NoError(t, m.TxPoolP2PServer.TxPool.AddLocal(tx))
+	number, included = step()
+	require.Equal(t, uint64(2), number)
+	require.True(t, included)
+
+	// evm_increaseTime moves the timestamps of the next blocks
+	offset, err := miner.IncreaseTime(3600)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3600), offset)
+	number, _ = step()
+	require.Equal(t, uint64(3), number)
+	require.GreaterOrEqual(t, header(3).Time, header(2).Time+3600)
+}
@@ -31,11 +31,13 @@ import (
 	"github.com/ledgerwatch/erigon/consensus/aura/consensusconfig"
 
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/etl"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/clique"
 	"github.com/ledgerwatch/erigon/consensus/db"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
 	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/eth/gasprice"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
@@ -76,6 +78,8 @@ var Defaults = Config{
 		GasCeil:  8000000,
 		GasPrice: big.NewInt(params.GWei),
 		Recommit: 3 * time.Second,
+
+		StratumDifficulty: 4000000000,
 	},
 	TxPool:      core.DefaultTxPoolConfig,
 	RPCGasCap:   50000000,
@@ -116,6 +120,27 @@ type Snapshot struct {
 	Mode    snapshotsync.SnapshotMode
 	Dir     string
 	Seeding bool
+	Source  string // directory or http(s) mirror of snapshots, empty - bittorrent
+}
+
+// Checkpoint - trusted block to sync from (--checkpoint). Headers down to genesis are verified by their hashes
+// from the checkpoint, the state at the checkpoint is loaded from a snapshot and its root is verified.
+// Blocks are downloaded and executed after the checkpoint only.
+type Checkpoint struct {
+	Number uint64
+	Hash   common.Hash
+	Root   common.Hash
+	State  string // state snapshot (plain state, contract codes) at the checkpoint block, or at the block of the first StateDiffs
+	// StateDiffs - state diffs applied to the State snapshot in order of blocks, the last one ends at the checkpoint block
+	StateDiffs []string
+}
+
+// Beacon - blocks come from a consensus client through the engine API (--beacon) instead of the p2p block downloader.
+// The node assembles blocks for the consensus client if mining is enabled, transactions are still exchanged with peers.
+type Beacon struct {
+	Enabled bool
+	// MockPeriod - local stand-in of the consensus client assembles, executes and finalizes a block every period, 0 - no mock
+	MockPeriod time.Duration
 }
 
 // Config contains configuration options for ETH protocol.
@@ -124,6 +149,14 @@ type Config struct {
 	// If nil, the Ethereum main net block is used.
 	Genesis *core.Genesis `toml:",omitempty"`
 
+	// Custom chain loaded from a spec file (--chain=<file>), its Genesis is the one above.
+	// Nil for the built-in chains.
+	ChainSpec *core.ChainSpec `toml:"-"`
+
+	// Source of the dev chain forked from the state of another chain (--dev.fork.source), its Genesis is the one above.
+	// Nil for the other chains.
+	Fork *state.ForkSource `toml:"-"`
+
 	// Protocol options
 	NetworkID uint64 // Network ID to use for selecting peers to connect to
 
@@ -136,14 +169,26 @@ type Config struct {
 	StorageMode ethdb.StorageMode
 	BatchSize   datasize.ByteSize // Batch size for execution stage
 
+	// EtlCompression - compression of temporary files of the stages sorting whole state or indices (HashState, History, LogIndex).
+	// Less disk usage for more cpu, none by default
+	EtlCompression etl.Compression
+
+	// PersistJumpDests - write JUMPDEST analysis of executed contracts to the db, rpcdaemon reads it from there
+	PersistJumpDests bool
+
 	Snapshot Snapshot
 
+	// Nil if the node syncs from genesis
+	Checkpoint *Checkpoint `toml:"-"`
+
 	BlockDownloaderWindow int
 
 	// Address to connect to external snapshot downloader
 	// empty if you want to use internal bittorrent snapshot downloader
 	ExternalSnapshotDownloaderAddr string
 
+	Beacon Beacon
+
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
@@ -174,6 +219,9 @@ type Config struct {
 
 	// SyncLoopThrottle sets a minimum time between staged loop iterations
 	SyncLoopThrottle time.Duration
+
+	// Reporting URL of the ethstats service (nodename:secret@host:port), empty if not reporting
+	EthstatsURL string
 }
 
 func CreateConsensusEngine(chainConfig *params.ChainConfig, config interface{}, notify []string, noverify bool) consensus.Engine {
@@ -209,7 +257,11 @@ func CreateConsensusEngine(chainConfig *params.ChainConfig, config interface{},
 	case *params.AuRaConfig:
 		if chainConfig.Aura != nil {
 			var err error
-			eng, err = aura.NewAuRa(chainConfig.Aura, db.OpenDatabase(consensusCfg.DBPath, consensusCfg.InMemory), chainConfig.Aura.Etherbase, consensusconfig.Sokol)
+			spec := consensusconfig.Sokol
+			if len(chainConfig.Aura.Params) > 0 { // custom chain
+				spec = chainConfig.Aura.Params
+			}
+			eng, err = aura.NewAuRa(chainConfig.Aura, db.OpenDatabase(consensusCfg.DBPath, consensusCfg.InMemory), chainConfig.Aura.Etherbase, spec)
 			if err != nil {
 				panic(err)
 			}
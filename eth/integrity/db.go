@@ -0,0 +1,676 @@
+package integrity
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/changeset"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
+	"github.com/ledgerwatch/erigon/ethdb/cbor"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/rlp"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultChunkSize - amount of blocks checked by one worker in one read transaction
+const DefaultChunkSize = 10_000
+
+// Issue - violated invariant
+type Issue struct {
+	Check string `json:"check"`
+	Block uint64 `json:"block"`
+	Key   string `json:"key,omitempty"`
+	Msg   string `json:"msg"`
+}
+
+// CheckReport - summary of one check
+type CheckReport struct {
+	Name    string `json:"name"`
+	From    uint64 `json:"from"`
+	To      uint64 `json:"to"`
+	Issues  int    `json:"issues"`
+	Skipped string `json:"skipped,omitempty"` // reason why check was not run
+}
+
+// Report - machine-readable result of DB integrity check
+type Report struct {
+	OK      bool          `json:"ok"`
+	Checks  []CheckReport `json:"checks"`
+	Issues  []Issue       `json:"issues"`
+	Elapsed string        `json:"elapsed"`
+}
+
+// DBConfig - settings of DB integrity check
+type DBConfig struct {
+	Checks     []string // names of checks to run, all if empty
+	From, To   uint64   // range of blocks to check, To == 0 - up to progress of the stage which produces checked data
+	Workers    int      // runtime.NumCPU() if 0
+	ChunkSize  uint64   // DefaultChunkSize if 0
+	Checkpoint string   // file to store progress, check continues from it after restart and removes it when done. No checkpoints if empty.
+}
+
+// BlockCheck - invariant which must hold for each block produced by Stage
+type BlockCheck struct {
+	Name  string
+	Stage stages.SyncStage
+	// Enabled - returns false and reason if checked data is not kept by this database (for example, disabled by storage mode)
+	Enabled func(sm ethdb.StorageMode) (bool, string)
+	Check   func(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error
+}
+
+var always = func(ethdb.StorageMode) (bool, string) { return true, "" }
+
+// BlockChecks - all known per-block invariants
+var BlockChecks = []BlockCheck{
+	{Name: "headers", Stage: stages.Headers, Enabled: always, Check: checkHeaders},
+	{Name: "td", Stage: stages.Headers, Enabled: always, Check: checkTd},
+	{Name: "bodies", Stage: stages.Bodies, Enabled: always, Check: checkBodies},
+	{Name: "senders", Stage: stages.Senders, Enabled: always, Check: checkSenders},
+	{Name: "receipts", Stage: stages.Execution, Enabled: func(sm ethdb.StorageMode) (bool, string) {
+		return sm.Receipts, "receipts are disabled by storage mode"
+	}, Check: checkReceipts},
+	{Name: "logindex", Stage: stages.LogIndex, Enabled: func(sm ethdb.StorageMode) (bool, string) {
+		return sm.Receipts, "receipts are disabled by storage mode"
+	}, Check: checkLogIndex},
+	{Name: "accounthistory", Stage: stages.AccountHistoryIndex, Enabled: func(sm ethdb.StorageMode) (bool, string) {
+		return sm.History, "history is disabled by storage mode"
+	}, Check: checkAccountHistory},
+	{Name: "storagehistory", Stage: stages.StorageHistoryIndex, Enabled: func(sm ethdb.StorageMode) (bool, string) {
+		return sm.History, "history is disabled by storage mode"
+	}, Check: checkStorageHistory},
+	{Name: "txlookup", Stage: stages.TxLookup, Enabled: func(sm ethdb.StorageMode) (bool, string) {
+		return sm.TxIndex, "tx index is disabled by storage mode"
+	}, Check: checkTxLookup},
+}
+
+// StagesCheckName - name of the check of stages progress, it's not per-block and always runs first
+const StagesCheckName = "stages"
+
+// checkpoint - progress of DB integrity check, persisted after each chunk
+type checkpoint struct {
+	ChunkSize uint64                          `json:"chunkSize"`
+	Done      map[string]map[uint64][2]uint64 `json:"done"` // check name -> first block of chunk -> first and last checked blocks
+	Issues    []Issue                         `json:"issues"`
+}
+
+func loadCheckpoint(file string, chunkSize uint64) (*checkpoint, error) {
+	cp := &checkpoint{ChunkSize: chunkSize, Done: map[string]map[uint64][2]uint64{}}
+	if file == "" {
+		return cp, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", file, err)
+	}
+	if cp.ChunkSize != chunkSize {
+		return nil, fmt.Errorf("checkpoint %s was made with chunk size %d, now %d", file, cp.ChunkSize, chunkSize)
+	}
+	log.Info("[integrity] continue from checkpoint", "file", file, "issues", len(cp.Issues))
+	return cp, nil
+}
+
+// isDone - blocks first..last of the chunk are already checked. The last chunk could be checked before blocks
+// were added to it, or the chunk could be checked partially from DBConfig.From - then it's checked again.
+func (cp *checkpoint) isDone(name string, ch chunk) bool {
+	checked, ok := cp.Done[name][ch.from]
+	return ok && checked[0] <= ch.first && checked[1] >= ch.last
+}
+
+// markDone - the chunk is checked, issues of its previous check are replaced
+func (cp *checkpoint) markDone(name string, ch chunk, issues []Issue) {
+	if cp.Done[name] == nil {
+		cp.Done[name] = map[uint64][2]uint64{}
+	}
+	from := ch.from
+	if _, ok := cp.Done[name][from]; ok {
+		kept := cp.Issues[:0]
+		for _, issue := range cp.Issues {
+			if issue.Check != name || issue.Block < from || issue.Block >= from+cp.ChunkSize {
+				kept = append(kept, issue)
+			}
+		}
+		cp.Issues = kept
+	}
+	cp.Done[name][from] = [2]uint64{ch.first, ch.last}
+	cp.Issues = append(cp.Issues, issues...)
+}
+
+func (cp *checkpoint) save(file string) error {
+	if file == "" {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+func (cp *checkpoint) remove(file string) error {
+	if file == "" {
+		return nil
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+type chunk struct {
+	check       *BlockCheck
+	from, to    uint64 // aligned by ChunkSize
+	first, last uint64 // checked blocks of the chunk, limited by DBConfig.From and the stage progress
+}
+
+// DB - checks cross-bucket invariants of chaindata. Blocks are split into chunks, checked by parallel workers.
+// Returns error only if check couldn't be done, violated invariants are listed in the report.
+func DB(ctx context.Context, db ethdb.RoKV, cfg DBConfig) (*Report, error) {
+	start := time.Now()
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	enabled := map[string]bool{}
+	for _, name := range cfg.Checks {
+		enabled[name] = true
+	}
+	known := map[string]bool{StagesCheckName: true}
+	for _, c := range BlockChecks {
+		known[c.Name] = true
+	}
+	for name := range enabled {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown check: %s", name)
+		}
+	}
+	isEnabled := func(name string) bool { return len(enabled) == 0 || enabled[name] }
+
+	cp, err := loadCheckpoint(cfg.Checkpoint, cfg.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	var chunks []chunk
+	if err = db.View(ctx, func(tx ethdb.Tx) error {
+		if isEnabled(StagesCheckName) {
+			issues, err := checkStages(tx)
+			if err != nil {
+				return err
+			}
+			report.Checks = append(report.Checks, CheckReport{Name: StagesCheckName, Issues: len(issues)})
+			report.Issues = append(report.Issues, issues...)
+		}
+		sm, err := ethdb.GetStorageModeFromDB(tx)
+		if err != nil {
+			return err
+		}
+		for i := range BlockChecks {
+			c := &BlockChecks[i]
+			if !isEnabled(c.Name) {
+				continue
+			}
+			if ok, reason := c.Enabled(sm); !ok {
+				report.Checks = append(report.Checks, CheckReport{Name: c.Name, Skipped: reason})
+				continue
+			}
+			to, err := stages.GetStageProgress(tx, c.Stage)
+			if err != nil {
+				return err
+			}
+			if cfg.To > 0 && cfg.To < to {
+				to = cfg.To
+			}
+			report.Checks = append(report.Checks, CheckReport{Name: c.Name, From: cfg.From, To: to})
+			if cfg.From > to {
+				continue
+			}
+			// chunks are aligned by ChunkSize - to match checkpoint after restart with different To
+			for from := cfg.From - cfg.From%cfg.ChunkSize; from <= to; from += cfg.ChunkSize {
+				ch := chunk{check: c, from: from, to: from + cfg.ChunkSize - 1, first: from, last: from + cfg.ChunkSize - 1}
+				if ch.first < cfg.From {
+					ch.first = cfg.From
+				}
+				if ch.last > to {
+					ch.last = to
+				}
+				if cp.isDone(c.Name, ch) {
+					continue
+				}
+				chunks = append(chunks, ch)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var checked int
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	work := make(chan chunk)
+	g, gctx := errgroup.WithContext(ctx)
+	for i := 0; i < cfg.Workers; i++ {
+		g.Go(func() error {
+			for ch := range work {
+				issues, err := checkChunk(gctx, db, ch)
+				if err != nil {
+					return fmt.Errorf("%s %d-%d: %w", ch.check.Name, ch.from, ch.to, err)
+				}
+				mu.Lock()
+				cp.markDone(ch.check.Name, ch, issues)
+				err = cp.save(cfg.Checkpoint)
+				checked++
+				select {
+				case <-logEvery.C:
+					log.Info("[integrity] progress", "chunks", fmt.Sprintf("%d/%d", checked, len(chunks)), "issues", len(cp.Issues))
+				default:
+				}
+				mu.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	g.Go(func() error {
+		defer close(work)
+		for _, ch := range chunks {
+			select {
+			case work <- ch:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+		}
+		return nil
+	})
+	if err = g.Wait(); err != nil {
+		return nil, err
+	}
+	// all chunks are checked - next run starts from scratch
+	if err = cp.remove(cfg.Checkpoint); err != nil {
+		return nil, err
+	}
+
+	// issues of chunks out of [From, To] range could be in checkpoint of previous run with other range
+	for _, issue := range cp.Issues {
+		for _, c := range report.Checks {
+			if c.Name == issue.Check && c.Skipped == "" && issue.Block >= c.From && issue.Block <= c.To {
+				report.Issues = append(report.Issues, issue)
+				break
+			}
+		}
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		if report.Issues[i].Check != report.Issues[j].Check {
+			return report.Issues[i].Check < report.Issues[j].Check
+		}
+		return report.Issues[i].Block < report.Issues[j].Block
+	})
+	for i := range report.Checks {
+		if report.Checks[i].Name == StagesCheckName {
+			continue
+		}
+		for _, issue := range report.Issues {
+			if issue.Check == report.Checks[i].Name {
+				report.Checks[i].Issues++
+			}
+		}
+	}
+	report.OK = len(report.Issues) == 0
+	report.Elapsed = time.Since(start).String()
+	log.Info("[integrity] done", "chunks", len(chunks), "issues", len(report.Issues), "in", time.Since(start))
+	return report, nil
+}
+
+func checkChunk(ctx context.Context, db ethdb.RoKV, ch chunk) (issues []Issue, err error) {
+	err = db.View(ctx, func(tx ethdb.Tx) error {
+		for blockNum := ch.first; blockNum <= ch.last; blockNum++ {
+			if err := ch.check.Check(tx, blockNum, func(key []byte, format string, args ...interface{}) {
+				issue := Issue{Check: ch.check.Name, Block: blockNum, Msg: fmt.Sprintf(format, args...)}
+				if len(key) > 0 {
+					issue.Key = fmt.Sprintf("%x", key)
+				}
+				issues = append(issues, issue)
+			}); err != nil {
+				return fmt.Errorf("block %d: %w", blockNum, err)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// checkStages - progress of each stage must not be greater than progress of stages it depends on
+func checkStages(tx ethdb.Tx) ([]Issue, error) {
+	sm, err := ethdb.GetStorageModeFromDB(tx)
+	if err != nil {
+		return nil, err
+	}
+	progress := map[stages.SyncStage]uint64{}
+	for _, s := range stages.AllStages {
+		if progress[s], err = stages.GetStageProgress(tx, s); err != nil {
+			return nil, err
+		}
+	}
+	type dependency struct {
+		stage, on stages.SyncStage
+		enabled   bool
+	}
+	deps := []dependency{
+		{stages.BlockHashes, stages.Headers, true},
+		{stages.Bodies, stages.BlockHashes, true},
+		{stages.Senders, stages.Bodies, true},
+		{stages.Execution, stages.Senders, true},
+		{stages.HashState, stages.Execution, true},
+		{stages.IntermediateHashes, stages.HashState, true},
+		{stages.AccountHistoryIndex, stages.Execution, sm.History},
+		{stages.StorageHistoryIndex, stages.Execution, sm.History},
+		{stages.LogIndex, stages.Execution, sm.Receipts},
+		{stages.CallTraces, stages.Execution, sm.CallTraces},
+		{stages.TxLookup, stages.Bodies, sm.TxIndex},
+		{stages.Finish, stages.IntermediateHashes, true},
+		{stages.Finish, stages.AccountHistoryIndex, sm.History},
+		{stages.Finish, stages.StorageHistoryIndex, sm.History},
+		{stages.Finish, stages.LogIndex, sm.Receipts},
+		{stages.Finish, stages.CallTraces, sm.CallTraces},
+		{stages.Finish, stages.TxLookup, sm.TxIndex},
+	}
+	var issues []Issue
+	for _, d := range deps {
+		if d.enabled && progress[d.stage] > progress[d.on] {
+			issues = append(issues, Issue{Check: StagesCheckName, Block: progress[d.stage],
+				Msg: fmt.Sprintf("stage %s is ahead of %s: %d > %d", d.stage, d.on, progress[d.stage], progress[d.on])})
+		}
+	}
+	return issues, nil
+}
+
+func checkHeaders(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+	if err != nil {
+		return err
+	}
+	if hash == (common.Hash{}) {
+		issue(nil, "no canonical hash")
+		return nil
+	}
+	header := rawdb.ReadHeader(tx, hash, blockNum)
+	if header == nil {
+		issue(hash[:], "canonical header not found")
+		return nil
+	}
+	if header.Number.Uint64() != blockNum {
+		issue(hash[:], "header has number %d", header.Number.Uint64())
+	}
+	if number := rawdb.ReadHeaderNumber(tx, hash); number == nil {
+		issue(hash[:], "canonical hash not found in %s", dbutils.HeaderNumberBucket)
+	} else if *number != blockNum {
+		issue(hash[:], "%s has number %d", dbutils.HeaderNumberBucket, *number)
+	}
+	if blockNum == 0 {
+		return nil
+	}
+	parentHash, err := rawdb.ReadCanonicalHash(tx, blockNum-1)
+	if err != nil {
+		return err
+	}
+	if header.ParentHash != parentHash {
+		issue(hash[:], "parent hash %x, canonical hash of parent %x", header.ParentHash, parentHash)
+	}
+	return nil
+}
+
+func checkTd(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+	if err != nil {
+		return err
+	}
+	header := rawdb.ReadHeader(tx, hash, blockNum)
+	if header == nil {
+		return nil // reported by "headers"
+	}
+	td, err := rawdb.ReadTd(tx, hash, blockNum)
+	if err != nil {
+		return err
+	}
+	if td == nil {
+		issue(hash[:], "total difficulty not found")
+		return nil
+	}
+	if blockNum == 0 {
+		return nil // genesis total difficulty is taken from genesis spec as is
+	}
+	parentTd, err := rawdb.ReadTd(tx, header.ParentHash, blockNum-1)
+	if err != nil {
+		return err
+	}
+	if parentTd == nil {
+		issue(hash[:], "total difficulty of parent not found")
+		return nil
+	}
+	if expect := new(big.Int).Add(parentTd, header.Difficulty); td.Cmp(expect) != 0 {
+		issue(hash[:], "total difficulty %d, expected %d", td, expect)
+	}
+	return nil
+}
+
+// readBody - returns canonical hash, base tx id and tx amount, reports issue if body not found
+func readBody(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) (common.Hash, *types.BodyForStorage, error) {
+	hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+	if err != nil {
+		return hash, nil, err
+	}
+	data := rawdb.ReadStorageBodyRLP(tx, hash, blockNum)
+	if len(data) == 0 {
+		issue(hash[:], "body not found")
+		return hash, nil, nil
+	}
+	body := new(types.BodyForStorage)
+	if err = rlp.DecodeBytes(data, body); err != nil {
+		issue(hash[:], "invalid body rlp: %v", err)
+		return hash, nil, nil
+	}
+	return hash, body, nil
+}
+
+func checkBodies(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	hash, body, err := readBody(tx, blockNum, issue)
+	if err != nil || body == nil {
+		return err
+	}
+	var found uint32
+	if body.TxAmount > 0 {
+		if err = tx.ForAmount(dbutils.EthTx, dbutils.EncodeBlockNumber(body.BaseTxId), body.TxAmount, func(k, v []byte) error {
+			if binary.BigEndian.Uint64(k) != body.BaseTxId+uint64(found) {
+				issue(hash[:], "transaction %d not found", body.BaseTxId+uint64(found))
+				return nil
+			}
+			found++
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if found != body.TxAmount {
+		issue(hash[:], "found %d transactions, expected %d, base tx id %d", found, body.TxAmount, body.BaseTxId)
+	}
+	seq, err := tx.ReadSequence(dbutils.EthTx)
+	if err != nil {
+		return err
+	}
+	if body.BaseTxId+uint64(body.TxAmount) > seq {
+		issue(hash[:], "transactions %d-%d are beyond %s sequence %d", body.BaseTxId, body.BaseTxId+uint64(body.TxAmount), dbutils.EthTx, seq)
+	}
+	if blockNum == 0 {
+		return nil
+	}
+	_, parentBody, err := readBody(tx, blockNum-1, func([]byte, string, ...interface{}) {})
+	if err != nil || parentBody == nil {
+		return err
+	}
+	if parentBody.BaseTxId+uint64(parentBody.TxAmount) > body.BaseTxId {
+		issue(hash[:], "transactions of block overlap with parent: base tx id %d, parent %d+%d", body.BaseTxId, parentBody.BaseTxId, parentBody.TxAmount)
+	}
+	return nil
+}
+
+func checkSenders(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	hash, body, err := readBody(tx, blockNum, issue)
+	if err != nil || body == nil {
+		return err
+	}
+	senders, err := rawdb.ReadSenders(tx, hash, blockNum)
+	if err != nil {
+		return err
+	}
+	if len(senders) != int(body.TxAmount) {
+		issue(hash[:], "%d senders, %d transactions", len(senders), body.TxAmount)
+	}
+	return nil
+}
+
+func checkReceipts(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	hash, body, err := readBody(tx, blockNum, issue)
+	if err != nil || body == nil {
+		return err
+	}
+	data, err := tx.GetOne(dbutils.BlockReceiptsPrefix, dbutils.ReceiptsKey(blockNum))
+	if err != nil {
+		return err
+	}
+	var receipts types.Receipts
+	if len(data) > 0 {
+		if err = cbor.Unmarshal(&receipts, bytes.NewReader(data)); err != nil {
+			issue(hash[:], "invalid receipts cbor: %v", err)
+			return nil
+		}
+	} else if body.TxAmount > 0 {
+		issue(hash[:], "receipts not found")
+		return nil
+	}
+	if len(receipts) != int(body.TxAmount) {
+		issue(hash[:], "%d receipts, %d transactions", len(receipts), body.TxAmount)
+	}
+	return tx.ForPrefix(dbutils.Log, dbutils.EncodeBlockNumber(blockNum), func(k, v []byte) error {
+		if txIdx := binary.BigEndian.Uint32(k[8:]); int(txIdx) >= len(receipts) {
+			issue(k, "logs of transaction %d, but only %d receipts", txIdx, len(receipts))
+		}
+		return nil
+	})
+}
+
+func checkLogIndex(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	inIndex := func(bucket string, key []byte) error {
+		bm, err := bitmapdb.Get(tx, bucket, key, uint32(blockNum), uint32(blockNum))
+		if err != nil {
+			return err
+		}
+		if !bm.Contains(uint32(blockNum)) {
+			issue(key, "not found in %s", bucket)
+		}
+		return nil
+	}
+	return tx.ForPrefix(dbutils.Log, dbutils.EncodeBlockNumber(blockNum), func(k, v []byte) error {
+		var logs types.Logs
+		if err := cbor.Unmarshal(&logs, bytes.NewReader(v)); err != nil {
+			issue(k, "invalid logs cbor: %v", err)
+			return nil
+		}
+		for _, l := range logs {
+			if err := inIndex(dbutils.LogAddressIndex, l.Address[:]); err != nil {
+				return err
+			}
+			for _, topic := range l.Topics {
+				if err := inIndex(dbutils.LogTopicIndex, topic[:]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func checkHistory(tx ethdb.Tx, blockNum uint64, changeSetBucket, indexBucket string, issue func(key []byte, format string, args ...interface{})) error {
+	return changeset.Walk(tx, changeSetBucket, dbutils.EncodeBlockNumber(blockNum), 64, func(blockN uint64, k, v []byte) (bool, error) {
+		key := k
+		if changeSetBucket == dbutils.StorageChangeSetBucket {
+			key = dbutils.CompositeKeyWithoutIncarnation(k)
+		}
+		bm, err := bitmapdb.Get64(tx, indexBucket, key, blockN, blockN)
+		if err != nil {
+			return false, err
+		}
+		if !bm.Contains(blockN) {
+			issue(key, "not found in %s", indexBucket)
+		}
+		return true, nil
+	})
+}
+
+func checkAccountHistory(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	return checkHistory(tx, blockNum, dbutils.AccountChangeSetBucket, dbutils.AccountsHistoryBucket, issue)
+}
+
+func checkStorageHistory(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	return checkHistory(tx, blockNum, dbutils.StorageChangeSetBucket, dbutils.StorageHistoryBucket, issue)
+}
+
+func checkTxLookup(tx ethdb.Tx, blockNum uint64, issue func(key []byte, format string, args ...interface{})) error {
+	_, body, err := readBody(tx, blockNum, issue)
+	if err != nil || body == nil {
+		return err
+	}
+	txs, err := rawdb.ReadTransactions(tx, body.BaseTxId, body.TxAmount)
+	if err != nil {
+		return err
+	}
+	for _, txn := range txs {
+		txHash := txn.Hash()
+		n, err := rawdb.ReadTxLookupEntry(tx, txHash)
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			issue(txHash[:], "transaction not found in %s", dbutils.TxLookupPrefix)
+		} else if *n != blockNum {
+			issue(txHash[:], "%s has block %d", dbutils.TxLookupPrefix, *n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package integrity_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/integrity"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB(t *testing.T) {
+	m := stages2.Mock(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(m.Address), common.Address{1}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, m.Key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	ctx := context.Background()
+	require.NoError(t, m.DB.Update(ctx, func(tx ethdb.RwTx) error {
+		return ethdb.SetStorageModeIfNotExist(tx, ethdb.DefaultStorageMode)
+	}))
+	report, err := integrity.DB(ctx, m.DB, integrity.DBConfig{ChunkSize: 3})
+	require.NoError(t, err)
+	require.True(t, report.OK, "%+v", report.Issues)
+	require.Equal(t, len(integrity.BlockChecks)+1, len(report.Checks))
+	for _, c := range report.Checks[1:] {
+		require.Equal(t, uint64(10), c.To, c.Name)
+	}
+
+	txHash := chain.Blocks[4].Transactions()[0].Hash()
+	require.NoError(t, m.DB.Update(ctx, func(tx ethdb.RwTx) error {
+		if err := tx.Delete(dbutils.HeaderNumberBucket, chain.Blocks[2].Hash().Bytes(), nil); err != nil {
+			return err
+		}
+		if err := rawdb.DeleteTxLookupEntry(tx, txHash); err != nil {
+			return err
+		}
+		return stages.SaveStageProgress(tx, stages.Finish, 11)
+	}))
+
+	checkpoint := path.Join(t.TempDir(), "checkpoint.json")
+	for i := 0; i < 2; i++ { // each complete run removes checkpoint, second run checks everything again
+		report, err = integrity.DB(ctx, m.DB, integrity.DBConfig{Workers: 2, ChunkSize: 3, Checkpoint: checkpoint})
+		require.NoError(t, err)
+		require.NoFileExists(t, checkpoint)
+		require.False(t, report.OK)
+		require.Equal(t, []integrity.Issue{
+			{Check: "headers", Block: 3, Key: common.Bytes2Hex(chain.Blocks[2].Hash().Bytes()), Msg: "canonical hash not found in HeaderNumber"},
+			{Check: "stages", Block: 11, Msg: "stage Finish is ahead of IntermediateHashes: 11 > 10"},
+			{Check: "stages", Block: 11, Msg: "stage Finish is ahead of AccountHistoryIndex: 11 > 10"},
+			{Check: "stages", Block: 11, Msg: "stage Finish is ahead of StorageHistoryIndex: 11 > 10"},
+			{Check: "stages", Block: 11, Msg: "stage Finish is ahead of LogIndex: 11 > 10"},
+			{Check: "stages", Block: 11, Msg: "stage Finish is ahead of CallTraces: 11 > 10"},
+			{Check: "stages", Block: 11, Msg: "stage Finish is ahead of TxLookup: 11 > 10"},
+			{Check: "txlookup", Block: 5, Key: common.Bytes2Hex(txHash.Bytes()), Msg: "transaction not found in BlockTransactionLookup"},
+		}, report.Issues)
+	}
+
+	// interrupted run: chunks 3-5 and 9-10 of headers are already checked, their issues are taken from checkpoint,
+	// chunk 6-8 was checked only up to 7 - it's checked again and its issue from checkpoint is dropped
+	require.NoError(t, ioutil.WriteFile(checkpoint, []byte(`{"chunkSize":3,"done":{"headers":{"3":[3,5],"6":[6,7],"9":[9,10]}},"issues":[{"check":"headers","block":4,"msg":"from checkpoint"},{"check":"headers","block":7,"msg":"from checkpoint"},{"check":"headers","block":9,"msg":"from checkpoint"}]}`), 0644))
+	report, err = integrity.DB(ctx, m.DB, integrity.DBConfig{Workers: 2, ChunkSize: 3, Checkpoint: checkpoint, Checks: []string{"headers"}})
+	require.NoError(t, err)
+	require.NoFileExists(t, checkpoint)
+	require.Equal(t, []integrity.Issue{
+		{Check: "headers", Block: 4, Msg: "from checkpoint"},
+		{Check: "headers", Block: 9, Msg: "from checkpoint"},
+	}, report.Issues)
+
+	// run from block 5 checks only the end of chunk 3-5, issue at block 3 is found by a run without From
+	report, err = integrity.DB(ctx, m.DB, integrity.DBConfig{ChunkSize: 3, From: 5, Checkpoint: checkpoint, Checks: []string{"headers"}})
+	require.NoError(t, err)
+	require.True(t, report.OK, "%+v", report.Issues)
+	report, err = integrity.DB(ctx, m.DB, integrity.DBConfig{ChunkSize: 3, Checkpoint: checkpoint, Checks: []string{"headers"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Issues), "%+v", report.Issues)
+	require.Equal(t, uint64(3), report.Issues[0].Block)
+
+	_, err = integrity.DB(ctx, m.DB, integrity.DBConfig{Checks: []string{"unknown"}})
+	require.Error(t, err)
+}
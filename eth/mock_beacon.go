@@ -0,0 +1,89 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/log"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+)
+
+// mockBeaconFinalizedDistance - how many blocks behind the head the mock beacon finalizes
+const mockBeaconFinalizedDistance = 8
+
+// mockBeacon - local stand-in of the consensus client of the --beacon chain (see ethconfig.Beacon.MockPeriod).
+// Every period it does what a consensus client does with the engine API: assembles a block on top of the head,
+// executes it and makes it the head, finalizing an older block of the chain it built.
+type mockBeacon struct {
+	engine *stages2.ExecutionEngine
+	blocks []common.Hash // recent blocks built by the mock, the first one is finalized
+}
+
+// beaconLoop - stage loop of the --beacon chain: the initial cycle, then the stages run on requests of the consensus client only
+func beaconLoop(ctx context.Context, engine *stages2.ExecutionEngine, mockPeriod time.Duration, waitForDone chan struct{}) {
+	defer debug.LogPanic()
+	defer close(waitForDone)
+	if err := engine.Start(ctx); err != nil {
+		log.Error("Initial cycle of the beacon chain", "err", err)
+	}
+	if mockPeriod == 0 {
+		log.Info("Waiting for the consensus client")
+		<-ctx.Done()
+		return
+	}
+	m := &mockBeacon{engine: engine}
+	log.Info("Mock beacon started", "period", mockPeriod)
+	ticker := time.NewTicker(mockPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.step(ctx); err != nil {
+				log.Warn("Mock beacon", "err", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *mockBeacon) step(ctx context.Context) error {
+	head, err := m.engine.Head(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp := uint64(time.Now().Unix())
+	if timestamp <= head.Time {
+		timestamp = head.Time + 1
+	}
+	block, err := m.engine.AssembleBlock(ctx, head.Hash(), timestamp, common.Address{} /* etherbase of the node */)
+	if err != nil {
+		return err
+	}
+	status, err := m.engine.ExecutePayload(ctx, block)
+	if err != nil {
+		return err
+	}
+	if status != remotedbserver.PayloadValid {
+		return fmt.Errorf("assembled block %d %x is %s", block.NumberU64(), block.Hash(), status)
+	}
+
+	if len(m.blocks) > 0 && m.blocks[len(m.blocks)-1] != head.Hash() { // head was moved by another consensus client
+		m.blocks = m.blocks[:0]
+	}
+	m.blocks = append(m.blocks, block.Hash())
+	var finalized common.Hash
+	if len(m.blocks) > mockBeaconFinalizedDistance {
+		finalized = m.blocks[0]
+		m.blocks = m.blocks[1:]
+	}
+	if err = m.engine.ForkchoiceUpdated(ctx, block.Hash(), finalized); err != nil {
+		return err
+	}
+	log.Info("Mock beacon", "number", block.NumberU64(), "hash", block.Hash(), "txs", block.Transactions().Len(), "finalized", finalized)
+	return nil
+}
@@ -12,6 +12,7 @@ func DefaultStages(ctx context.Context,
 	headers HeadersCfg,
 	blockHashCfg BlockHashesCfg,
 	snapshotHeaders SnapshotHeadersCfg,
+	checkpoint CheckpointCfg,
 	bodies BodiesCfg,
 	snapshotBodies SnapshotBodiesCfg,
 	senders SendersCfg,
@@ -70,6 +71,19 @@ func DefaultStages(ctx context.Context,
 				return PruneHeadersSnapshotGenerationStage(p, tx, snapshotHeaders, ctx)
 			},
 		},
+		{
+			ID:                  stages.Checkpoint,
+			Description:         "Load state of the trusted checkpoint",
+			Disabled:            checkpoint.checkpoint == nil,
+			DisabledDescription: "Enable by --checkpoint",
+			Forward: func(firstCycle bool, s *StageState, u Unwinder, tx ethdb.RwTx) error {
+				return SpawnCheckpointStage(s, tx, checkpoint, ctx)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx ethdb.RwTx) error {
+				return UnwindCheckpointStage(u, s, tx, checkpoint, ctx)
+			},
+			Prune: func(firstCycle bool, p *PruneState, tx ethdb.RwTx) error { return nil },
+		},
 		{
 			ID:          stages.Bodies,
 			Description: "Download block bodies",
@@ -141,9 +155,9 @@ func DefaultStages(ctx context.Context,
 		},
 		{
 			ID:                  stages.CreateStateSnapshot,
-			Description:         "Create state snapshot",
-			Disabled:            true,
-			DisabledDescription: "Enable by --snapshot.layout",
+			Description:         "Create state diffs",
+			Disabled:            !snapshotState.enabled,
+			DisabledDescription: "Enable by --snapshot.layout and state in --snapshot.mode",
 			Forward: func(firstCycle bool, s *StageState, u Unwinder, tx ethdb.RwTx) error {
 				return SpawnStateSnapshotGenerationStage(s, tx, snapshotState, ctx)
 			},
@@ -289,6 +303,7 @@ var DefaultForwardOrder = UnwindOrder{
 	stages.Headers,
 	stages.BlockHashes,
 	stages.CreateHeadersSnapshot,
+	stages.Checkpoint,
 	stages.Bodies,
 	stages.CreateBodiesSnapshot,
 
@@ -338,6 +353,7 @@ var DefaultUnwindOrder = UnwindOrder{
 
 	stages.CreateBodiesSnapshot,
 	stages.Bodies,
+	stages.Checkpoint,
 	stages.CreateHeadersSnapshot,
 	stages.BlockHashes,
 	stages.Headers,
@@ -366,6 +382,7 @@ var DefaultPruneOrder = PruneOrder{
 
 	stages.CreateBodiesSnapshot,
 	stages.Bodies,
+	stages.Checkpoint,
 	stages.CreateHeadersSnapshot,
 	stages.BlockHashes,
 	stages.Headers,
@@ -0,0 +1,232 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+)
+
+// stages which start at the checkpoint: blocks before it are neither downloaded nor executed, and have no history
+var checkpointStages = []stages.SyncStage{
+	stages.Bodies,
+	stages.Senders,
+	stages.Execution,
+	stages.Translation,
+	stages.HashState,
+	stages.IntermediateHashes,
+	stages.CallTraces,
+	stages.AccountHistoryIndex,
+	stages.StorageHistoryIndex,
+	stages.LogIndex,
+	stages.TxLookup,
+	stages.TxPool,
+}
+
+type CheckpointCfg struct {
+	db         ethdb.RwKV
+	checkpoint *ethconfig.Checkpoint
+	hashState  HashStateCfg
+	trie       TrieCfg
+}
+
+func StageCheckpointCfg(db ethdb.RwKV, checkpoint *ethconfig.Checkpoint, tmpDir string, compression etl.Compression) CheckpointCfg {
+	return CheckpointCfg{
+		db:         db,
+		checkpoint: checkpoint,
+		hashState:  StageHashStateCfg(db, tmpDir, compression),
+		trie:       StageTrieCfg(db, true, true, tmpDir),
+	}
+}
+
+// SpawnCheckpointStage loads the state at the trusted checkpoint, once Headers stage has downloaded headers down to
+// genesis from it (see HeaderDownload.SetCheckpoint). The state comes from the snapshot, it is hashed and the trie
+// is regenerated to verify its root. The next stages start at the checkpoint.
+func SpawnCheckpointStage(s *StageState, tx ethdb.RwTx, cfg CheckpointCfg, ctx context.Context) (err error) {
+	checkpoint := cfg.checkpoint
+	if s.BlockNumber >= checkpoint.Number {
+		return nil
+	}
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+	logPrefix := s.LogPrefix()
+
+	headerProgress, err := stages.GetStageProgress(tx, stages.Headers)
+	if err != nil {
+		return err
+	}
+	if headerProgress < checkpoint.Number {
+		return fmt.Errorf("[%s] headers are downloaded to block %d, not to the checkpoint %d yet", logPrefix, headerProgress, checkpoint.Number)
+	}
+	hash, err := rawdb.ReadCanonicalHash(tx, checkpoint.Number)
+	if err != nil {
+		return err
+	}
+	if hash != checkpoint.Hash {
+		return fmt.Errorf("[%s] canonical block %d is %x, checkpoint is %x", logPrefix, checkpoint.Number, hash, checkpoint.Hash)
+	}
+	header := rawdb.ReadHeader(tx, hash, checkpoint.Number)
+	if header == nil {
+		return fmt.Errorf("[%s] header of the checkpoint %d not found", logPrefix, checkpoint.Number)
+	}
+	if header.Root != checkpoint.Root {
+		return fmt.Errorf("[%s] state root of the checkpoint %d is %x, expected %x", logPrefix, checkpoint.Number, header.Root, checkpoint.Root)
+	}
+
+	executed, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return err
+	}
+	if executed > 0 && executed < checkpoint.Number {
+		return fmt.Errorf("[%s] blocks are executed to %d, checkpoint %d can only be loaded into a new database", logPrefix, executed, checkpoint.Number)
+	}
+	if executed == 0 {
+		if err = loadCheckpointState(logPrefix, tx, cfg, ctx.Done()); err != nil {
+			return err
+		}
+		for _, stage := range checkpointStages {
+			if err = stages.SaveStageProgress(tx, stage, checkpoint.Number); err != nil {
+				return err
+			}
+		}
+		log.Info(fmt.Sprintf("[%s] Loaded state of the checkpoint", logPrefix), "block", checkpoint.Number, "root", checkpoint.Root)
+	}
+
+	if err = s.Update(tx, checkpoint.Number); err != nil {
+		return err
+	}
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCheckpointState replaces the genesis state by the state snapshot caught up by the state diffs and verifies its root
+func loadCheckpointState(logPrefix string, tx ethdb.RwTx, cfg CheckpointCfg, quit <-chan struct{}) error {
+	snapshot, err := kv.NewMDBX().Path(cfg.checkpoint.State).Readonly().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		return snapshotsync.BucketConfigs[snapshotsync.SnapshotType_state]
+	}).Open()
+	if err != nil {
+		return fmt.Errorf("[%s] state snapshot %s: %w", logPrefix, cfg.checkpoint.State, err)
+	}
+	defer snapshot.Close()
+
+	for _, bucket := range []string{
+		dbutils.PlainStateBucket,
+		dbutils.PlainContractCodeBucket,
+		dbutils.HashedAccountsBucket,
+		dbutils.HashedStorageBucket,
+		dbutils.ContractCodeBucket,
+	} {
+		if err = tx.(ethdb.BucketMigrator).ClearBucket(bucket); err != nil {
+			return err
+		}
+	}
+	if err = snapshot.View(context.Background(), func(snapshotTx ethdb.Tx) error {
+		for bucket := range snapshotsync.BucketConfigs[snapshotsync.SnapshotType_state] {
+			if err := copyBucket(logPrefix, snapshotTx, tx, bucket, quit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("[%s] state snapshot %s: %w", logPrefix, cfg.checkpoint.State, err)
+	}
+	if err = applyCheckpointStateDiffs(logPrefix, tx, cfg); err != nil {
+		return err
+	}
+
+	if err = PromoteHashedStateCleanly(logPrefix, tx, cfg.hashState, quit); err != nil {
+		return err
+	}
+	root, err := RegenerateIntermediateHashes(logPrefix, tx, cfg.trie, cfg.checkpoint.Root, quit)
+	if err != nil {
+		return err
+	}
+	if root != cfg.checkpoint.Root {
+		return fmt.Errorf("[%s] root of the state snapshot %s is %x, checkpoint root is %x", logPrefix, cfg.checkpoint.State, root, cfg.checkpoint.Root)
+	}
+	return nil
+}
+
+func applyCheckpointStateDiffs(logPrefix string, tx ethdb.RwTx, cfg CheckpointCfg) error {
+	if len(cfg.checkpoint.StateDiffs) == 0 {
+		return nil
+	}
+	var block uint64
+	for i, path := range cfg.checkpoint.StateDiffs {
+		if err := func() error {
+			diffKV, err := snapshotsync.OpenStateDiffSnapshot(path)
+			if err != nil {
+				return err
+			}
+			defer diffKV.Close()
+			return diffKV.View(context.Background(), func(diffTx ethdb.Tx) error {
+				if i == 0 {
+					// the base snapshot doesn't know its block, it's where the first diff starts
+					if block, _, err = snapshotsync.StateDiffRange(diffTx); err != nil {
+						return err
+					}
+				}
+				block, err = snapshotsync.ApplyStateDiff(tx, diffTx, block)
+				return err
+			})
+		}(); err != nil {
+			return fmt.Errorf("[%s] state diff %s: %w", logPrefix, path, err)
+		}
+		log.Info(fmt.Sprintf("[%s] Applied state diff", logPrefix), "diff", path, "block", block)
+	}
+	if block != cfg.checkpoint.Number {
+		return fmt.Errorf("[%s] state diffs end at block %d, checkpoint is %d", logPrefix, block, cfg.checkpoint.Number)
+	}
+	return nil
+}
+
+func copyBucket(logPrefix string, from ethdb.Tx, to ethdb.RwTx, bucket string, quit <-chan struct{}) error {
+	logEvery := time.NewTicker(logInterval)
+	defer logEvery.Stop()
+	c, err := from.Cursor(bucket)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	for k, v, err := c.First(); k != nil; k, v, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		if err = common.Stopped(quit); err != nil {
+			return err
+		}
+		if err = to.Put(bucket, k, v); err != nil {
+			return err
+		}
+		select {
+		default:
+		case <-logEvery.C:
+			log.Info(fmt.Sprintf("[%s] Copying state snapshot", logPrefix), "bucket", bucket, "key", fmt.Sprintf("%x", k))
+		}
+	}
+	return nil
+}
+
+func UnwindCheckpointStage(u *UnwindState, s *StageState, tx ethdb.RwTx, cfg CheckpointCfg, ctx context.Context) (err error) {
+	return fmt.Errorf("[%s] can't unwind to %d, below the checkpoint %d", s.LogPrefix(), u.UnwindPoint, s.BlockNumber)
+}
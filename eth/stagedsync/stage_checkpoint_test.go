@@ -0,0 +1,133 @@
+package stagedsync
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkpointState generates the state of 50 blocks, writes it to a state snapshot and returns its root
+func checkpointState(t *testing.T, expected ethdb.RwTx) (string, common.Hash) {
+	generateBlocks(t, 1, 50, plainWriterGen(expected), changeCodeWithIncarnations)
+	return writeStateSnapshot(t, expected), stateRoot(t, expected)
+}
+
+func stateRoot(t *testing.T, tx ethdb.RwTx) common.Hash {
+	tmpDir := t.TempDir()
+	require.NoError(t, PromoteHashedStateCleanly("", tx, StageHashStateCfg(nil, tmpDir, etl.CompressNone), nil))
+	root, err := RegenerateIntermediateHashes("", tx, StageTrieCfg(nil, false, true, tmpDir), common.Hash{}, nil)
+	require.NoError(t, err)
+	return root
+}
+
+func writeStateSnapshot(t *testing.T, expected ethdb.Tx) string {
+	path := filepath.Join(t.TempDir(), "state")
+	snapshot := kv.NewMDBX().Path(path).WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		return snapshotsync.BucketConfigs[snapshotsync.SnapshotType_state]
+	}).MustOpen()
+	defer snapshot.Close()
+	require.NoError(t, snapshot.Update(context.Background(), func(tx ethdb.RwTx) error {
+		for _, bucket := range []string{dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket, dbutils.CodeBucket} {
+			if err := copyBucket("", expected, tx, bucket, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	return path
+}
+
+func writeCheckpointHeader(t *testing.T, tx ethdb.RwTx, number uint64, root common.Hash) common.Hash {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Root: root}
+	rawdb.WriteHeader(tx, header)
+	require.NoError(t, rawdb.WriteCanonicalHash(tx, header.Hash(), number))
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Headers, number))
+	return header.Hash()
+}
+
+func TestCheckpointStage(t *testing.T) {
+	ctx := context.Background()
+	_, expected := kv.NewTestTx(t)
+	path, root := checkpointState(t, expected)
+
+	db, tx := kv.NewTestTx(t)
+	hash := writeCheckpointHeader(t, tx, 50, root)
+	checkpoint := &ethconfig.Checkpoint{Number: 50, Hash: hash, Root: root, State: path}
+	s := &StageState{ID: stages.Checkpoint}
+	require.NoError(t, SpawnCheckpointStage(s, tx, StageCheckpointCfg(db, checkpoint, t.TempDir(), etl.CompressNone), ctx))
+
+	progress, err := stages.GetStageProgress(tx, stages.Checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50), progress)
+	for _, stage := range checkpointStages {
+		progress, err = stages.GetStageProgress(tx, stage)
+		require.NoError(t, err)
+		assert.Equalf(t, uint64(50), progress, "stage %s", stage)
+	}
+	compareCurrentState(t, expected, tx, dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket, dbutils.HashedAccountsBucket, dbutils.HashedStorageBucket, dbutils.TrieOfAccountsBucket, dbutils.TrieOfStorageBucket)
+
+	err = UnwindCheckpointStage(&UnwindState{ID: stages.Checkpoint, UnwindPoint: 49}, &StageState{ID: stages.Checkpoint, BlockNumber: 50}, tx, StageCheckpointCfg(db, checkpoint, t.TempDir(), etl.CompressNone), ctx)
+	assert.Error(t, err)
+}
+
+func TestCheckpointStageWrongRoot(t *testing.T) {
+	ctx := context.Background()
+	_, expected := kv.NewTestTx(t)
+	path, root := checkpointState(t, expected)
+
+	// header of the checkpoint doesn't match the trusted root
+	db, tx := kv.NewTestTx(t)
+	hash := writeCheckpointHeader(t, tx, 50, root)
+	checkpoint := &ethconfig.Checkpoint{Number: 50, Hash: hash, Root: common.Hash{1}, State: path}
+	err := SpawnCheckpointStage(&StageState{ID: stages.Checkpoint}, tx, StageCheckpointCfg(db, checkpoint, t.TempDir(), etl.CompressNone), ctx)
+	assert.Error(t, err)
+
+	// state snapshot doesn't match the root of the checkpoint
+	db, tx = kv.NewTestTx(t)
+	hash = writeCheckpointHeader(t, tx, 50, common.Hash{1})
+	checkpoint = &ethconfig.Checkpoint{Number: 50, Hash: hash, Root: common.Hash{1}, State: path}
+	err = SpawnCheckpointStage(&StageState{ID: stages.Checkpoint}, tx, StageCheckpointCfg(db, checkpoint, t.TempDir(), etl.CompressNone), ctx)
+	assert.Error(t, err)
+	progress, err := stages.GetStageProgress(tx, stages.Execution)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), progress)
+}
+
+func TestCheckpointStageStateDiffs(t *testing.T) {
+	ctx := context.Background()
+	_, expected := kv.NewTestTx(t)
+	generateBlocks(t, 1, 40, plainWriterGen(expected), changeCodeWithIncarnations)
+	base := writeStateSnapshot(t, expected)
+	generateBlocks(t, 41, 10, plainWriterGen(expected), changeCodeWithIncarnations)
+	root := stateRoot(t, expected)
+	diff := snapshotsync.StateDiffName(t.TempDir(), 40, 50)
+	require.NoError(t, snapshotsync.CreateStateDiffSnapshot(ctx, expected, 40, 50, diff))
+
+	db, tx := kv.NewTestTx(t)
+	hash := writeCheckpointHeader(t, tx, 50, root)
+	checkpoint := &ethconfig.Checkpoint{Number: 50, Hash: hash, Root: root, State: base, StateDiffs: []string{diff}}
+	require.NoError(t, SpawnCheckpointStage(&StageState{ID: stages.Checkpoint}, tx, StageCheckpointCfg(db, checkpoint, t.TempDir(), etl.CompressNone), ctx))
+	compareCurrentState(t, expected, tx, dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket, dbutils.HashedAccountsBucket, dbutils.HashedStorageBucket, dbutils.TrieOfAccountsBucket, dbutils.TrieOfStorageBucket)
+
+	// diffs don't reach the checkpoint
+	short := snapshotsync.StateDiffName(t.TempDir(), 40, 45)
+	require.NoError(t, snapshotsync.CreateStateDiffSnapshot(ctx, expected, 40, 45, short))
+	db, tx = kv.NewTestTx(t)
+	hash = writeCheckpointHeader(t, tx, 50, root)
+	checkpoint = &ethconfig.Checkpoint{Number: 50, Hash: hash, Root: root, State: base, StateDiffs: []string{short}}
+	assert.Error(t, SpawnCheckpointStage(&StageState{ID: stages.Checkpoint}, tx, StageCheckpointCfg(db, checkpoint, t.TempDir(), etl.CompressNone), ctx))
+}
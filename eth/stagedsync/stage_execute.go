@@ -54,9 +54,11 @@ type ExecuteBlockCfg struct {
 	writeReceipts   bool
 	writeCallTraces bool
 	writeTEVM       bool
+	writeJumpDests  bool
 	pruningDistance uint64
 	stateStream     bool
 	accumulator     *shards.Accumulator
+	fork            *state.ForkSource
 }
 
 func StageExecuteBlocksCfg(
@@ -64,6 +66,7 @@ func StageExecuteBlocksCfg(
 	writeReceipts bool,
 	writeCallTraces bool,
 	writeTEVM bool,
+	writeJumpDests bool,
 	pruningDistance uint64,
 	batchSize datasize.ByteSize,
 	changeSetHook ChangeSetHook,
@@ -72,6 +75,7 @@ func StageExecuteBlocksCfg(
 	vmConfig *vm.Config,
 	accumulator *shards.Accumulator,
 	stateStream bool,
+	fork *state.ForkSource,
 	tmpdir string,
 ) ExecuteBlockCfg {
 	return ExecuteBlockCfg{
@@ -79,6 +83,7 @@ func StageExecuteBlocksCfg(
 		writeReceipts:   writeReceipts,
 		writeCallTraces: writeCallTraces,
 		writeTEVM:       writeTEVM,
+		writeJumpDests:  writeJumpDests,
 		pruningDistance: pruningDistance,
 		batchSize:       batchSize,
 		changeSetHook:   changeSetHook,
@@ -88,6 +93,7 @@ func StageExecuteBlocksCfg(
 		tmpdir:          tmpdir,
 		accumulator:     accumulator,
 		stateStream:     stateStream,
+		fork:            fork,
 	}
 }
 
@@ -106,11 +112,12 @@ func executeBlock(
 	batch ethdb.Database,
 	cfg ExecuteBlockCfg,
 	writeChangesets bool,
+	writeReceipts bool,
 	checkTEVM func(contractHash common.Hash) (bool, error),
 	initialCycle bool,
 ) error {
 	blockNum := block.NumberU64()
-	stateReader, stateWriter := newStateReaderWriter(batch, tx, blockNum, block.Hash(), writeChangesets, cfg.accumulator, initialCycle, cfg.stateStream)
+	stateReader, stateWriter := newStateReaderWriter(batch, tx, blockNum, block.Hash(), writeChangesets, cfg.accumulator, initialCycle, cfg.stateStream, cfg.fork)
 
 	// where the magic happens
 	getHeader := func(hash common.Hash, number uint64) *types.Header { return rawdb.ReadHeader(tx, hash, number) }
@@ -121,12 +128,23 @@ func executeBlock(
 		cfg.vmConfig.Tracer = callTracer
 	}
 
-	receipts, err := core.ExecuteBlockEphemerally(cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, block, stateReader, stateWriter, epochReader{tx: tx}, checkTEVM)
+	var jumpDests *core.DBJumpDestCache
+	if cfg.writeJumpDests {
+		jumpDests = core.NewDBJumpDestCache(vm.SharedJumpDests, batch, batch)
+		cfg.vmConfig.JumpDestCache = jumpDests
+	}
+
+	receipts, err := core.ExecuteBlockEphemerally(cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, block, stateReader, stateWriter, epochReader{tx: tx}, chainReader{config: cfg.chainConfig, tx: tx}, checkTEVM)
 	if err != nil {
 		return err
 	}
+	if jumpDests != nil {
+		if err = jumpDests.Err(); err != nil {
+			return err
+		}
+	}
 
-	if cfg.writeReceipts {
+	if writeReceipts {
 		if err = rawdb.AppendReceipts(tx, blockNum, receipts); err != nil {
 			return err
 		}
@@ -202,6 +220,7 @@ func newStateReaderWriter(
 	accumulator *shards.Accumulator,
 	initialCycle bool,
 	stateStream bool,
+	fork *state.ForkSource,
 ) (state.StateReader, state.WriterWithChangeSets) {
 
 	var stateReader state.StateReader
@@ -219,6 +238,10 @@ func newStateReaderWriter(
 	} else {
 		stateWriter = state.NewPlainStateWriterNoHistory(batch).SetAccumulator(accumulator)
 	}
+	if fork != nil { // dev chain forked from the state of another one
+		stateReader = fork.Reader(stateReader, batch)
+		stateWriter = state.NewForkStateWriter(stateWriter, batch)
+	}
 
 	return stateReader, stateWriter
 }
@@ -250,6 +273,20 @@ func SpawnExecuteBlocksStage(s *StageState, u Unwinder, tx ethdb.RwTx, toBlock u
 		log.Info(fmt.Sprintf("[%s] Blocks execution", logPrefix), "from", s.BlockNumber, "to", to)
 	}
 
+	// receipts of blocks which are in receipts snapshot are not written again, if the snapshot is mounted
+	var receiptsSnapshotBlock uint64
+	receiptsSnapshot, err := tx.GetOne(dbutils.BittorrentInfoBucket, dbutils.CurrentReceiptsSnapshotBlock)
+	if err != nil {
+		return err
+	}
+	if len(receiptsSnapshot) == 8 {
+		if snTx, ok := tx.(kv.SnapshotTX); ok && snTx.HasSnapshot(dbutils.BlockReceiptsPrefix) {
+			receiptsSnapshotBlock = binary.BigEndian.Uint64(receiptsSnapshot)
+		} else {
+			log.Warn(fmt.Sprintf("[%s] Receipts snapshot is not mounted, receipts are written", logPrefix), "snapshotBlock", binary.BigEndian.Uint64(receiptsSnapshot))
+		}
+	}
+
 	var batch ethdb.DbWithPendingMutations
 	batch = kv.NewBatch(tx, quit)
 	defer batch.Rollback()
@@ -278,7 +315,7 @@ Loop:
 			break
 		}
 
-		if err = cfg.engine.VerifyFamily(&chainReader{config: cfg.chainConfig, tx: tx}, block.Header()); err != nil {
+		if err = cfg.engine.VerifyFamily(&chainReader{config: cfg.chainConfig, tx: tx}, block.Header(), epochReader{tx: tx}); err != nil {
 			return err
 		}
 
@@ -289,13 +326,15 @@ Loop:
 			writeChangesets = false
 		}
 
+		writeReceipts := cfg.writeReceipts && blockNum > receiptsSnapshotBlock
+
 		var checkTEVMCode func(contractHash common.Hash) (bool, error)
 
 		if cfg.vmConfig.EnableTEMV {
-			checkTEVMCode = ethdb.GetCheckTEVM(tx)
+			checkTEVMCode = vm.GetCheckTEVM(tx)
 		}
 
-		if err = executeBlock(block, tx, batch, cfg, writeChangesets, checkTEVMCode, initialCycle); err != nil {
+		if err = executeBlock(block, tx, batch, cfg, writeChangesets, writeReceipts, checkTEVMCode, initialCycle); err != nil {
 			log.Error(fmt.Sprintf("[%s] Execution failed", logPrefix), "number", blockNum, "hash", block.Hash().String(), "error", err)
 			u.UnwindTo(blockNum-1, block.Hash())
 			break Loop
@@ -16,14 +16,16 @@ import (
 )
 
 type HashStateCfg struct {
-	db     ethdb.RwKV
-	tmpDir string
+	db          ethdb.RwKV
+	tmpDir      string
+	compression etl.Compression
 }
 
-func StageHashStateCfg(db ethdb.RwKV, tmpDir string) HashStateCfg {
+func StageHashStateCfg(db ethdb.RwKV, tmpDir string, compression etl.Compression) HashStateCfg {
 	return HashStateCfg{
-		db:     db,
-		tmpDir: tmpDir,
+		db:          db,
+		tmpDir:      tmpDir,
+		compression: compression,
 	}
 }
 
@@ -108,6 +110,7 @@ func unwindHashStateStageImpl(logPrefix string, u *UnwindState, s *StageState, t
 	// and recomputes the state root from scratch
 	prom := NewPromoter(tx, quit)
 	prom.TempDir = cfg.tmpDir
+	prom.Compression = cfg.compression
 	if err := prom.Unwind(logPrefix, s, u, false /* storage */, true /* codes */); err != nil {
 		return err
 	}
@@ -120,6 +123,13 @@ func unwindHashStateStageImpl(logPrefix string, u *UnwindState, s *StageState, t
 	return nil
 }
 
+// CleanPromoteSortWorkers - full rebuild sorts whole state: sorting of buffers overlaps with extraction,
+// memory usage is bounded by (CleanPromoteSortWorkers+1)*etl.BufferOptimalSize (--etl.bufferSize, not --batchSize).
+// 0 sorts buffers in place, as other stages do.
+var CleanPromoteSortWorkers = 2 /* var because we want to sometimes change it from tests or command-line flags */
+
+const cleanPromoteMergeThreshold = 128
+
 func PromoteHashedStateCleanly(logPrefix string, db ethdb.RwTx, cfg HashStateCfg, quit <-chan struct{}) error {
 	err := etl.Transform(
 		logPrefix,
@@ -130,7 +140,10 @@ func PromoteHashedStateCleanly(logPrefix string, db ethdb.RwTx, cfg HashStateCfg
 		keyTransformExtractAcc(transformPlainStateKey),
 		etl.IdentityLoadFunc,
 		etl.TransformArgs{
-			Quit: quit,
+			Compression:    cfg.compression,
+			SortWorkers:    CleanPromoteSortWorkers,
+			MergeThreshold: cleanPromoteMergeThreshold,
+			Quit:           quit,
 		},
 	)
 	if err != nil {
@@ -146,7 +159,10 @@ func PromoteHashedStateCleanly(logPrefix string, db ethdb.RwTx, cfg HashStateCfg
 		keyTransformExtractStorage(transformPlainStateKey),
 		etl.IdentityLoadFunc,
 		etl.TransformArgs{
-			Quit: quit,
+			Compression:    cfg.compression,
+			SortWorkers:    CleanPromoteSortWorkers,
+			MergeThreshold: cleanPromoteMergeThreshold,
+			Quit:           quit,
 		},
 	)
 	if err != nil {
@@ -162,7 +178,10 @@ func PromoteHashedStateCleanly(logPrefix string, db ethdb.RwTx, cfg HashStateCfg
 		keyTransformExtractFunc(transformContractCodeKey),
 		etl.IdentityLoadFunc,
 		etl.TransformArgs{
-			Quit: quit,
+			Compression:    cfg.compression,
+			SortWorkers:    CleanPromoteSortWorkers,
+			MergeThreshold: cleanPromoteMergeThreshold,
+			Quit:           quit,
 		},
 	)
 }
@@ -271,6 +290,7 @@ type Promoter struct {
 	db               ethdb.RwTx
 	ChangeSetBufSize uint64
 	TempDir          string
+	Compression      etl.Compression
 	quitCh           <-chan struct{}
 }
 
@@ -443,6 +463,7 @@ func (p *Promoter) Promote(logPrefix string, s *StageState, from, to uint64, sto
 		etl.TransformArgs{
 			BufferType:      etl.SortableOldestAppearedBuffer,
 			ExtractStartKey: startkey,
+			Compression:     p.Compression,
 			Quit:            p.quitCh,
 		},
 	); err != nil {
@@ -509,6 +530,7 @@ func (p *Promoter) Unwind(logPrefix string, s *StageState, u *UnwindState, stora
 func promoteHashedStateIncrementally(logPrefix string, s *StageState, from, to uint64, db ethdb.RwTx, cfg HashStateCfg, quit <-chan struct{}) error {
 	prom := NewPromoter(db, quit)
 	prom.TempDir = cfg.tmpDir
+	prom.Compression = cfg.compression
 	if err := prom.Promote(logPrefix, s, from, to, false /* storage */, true /* codes */); err != nil {
 		return err
 	}
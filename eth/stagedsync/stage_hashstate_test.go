@@ -10,6 +10,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
 )
 
 func TestPromoteHashedStateClearState(t *testing.T) {
@@ -19,7 +20,7 @@ func TestPromoteHashedStateClearState(t *testing.T) {
 	generateBlocks(t, 1, 50, hashedWriterGen(tx1), changeCodeWithIncarnations)
 	generateBlocks(t, 1, 50, plainWriterGen(tx2), changeCodeWithIncarnations)
 
-	err := PromoteHashedStateCleanly("logPrefix", tx2, StageHashStateCfg(db2, t.TempDir()), nil)
+	err := PromoteHashedStateCleanly("logPrefix", tx2, StageHashStateCfg(db2, t.TempDir(), etl.CompressNone), nil)
 	if err != nil {
 		t.Errorf("error while promoting state: %v", err)
 	}
@@ -27,6 +28,26 @@ func TestPromoteHashedStateClearState(t *testing.T) {
 	compareCurrentState(t, tx1, tx2, dbutils.HashedAccountsBucket, dbutils.HashedStorageBucket, dbutils.ContractCodeBucket)
 }
 
+func TestPromoteHashedStateClearStateCompressed(t *testing.T) {
+	for _, compression := range []etl.Compression{etl.CompressSnappy, etl.CompressZstd} {
+		compression := compression
+		t.Run(compression.String(), func(t *testing.T) {
+			_, tx1 := kv.NewTestTx(t)
+			db2, tx2 := kv.NewTestTx(t)
+
+			generateBlocks(t, 1, 50, hashedWriterGen(tx1), changeCodeWithIncarnations)
+			generateBlocks(t, 1, 50, plainWriterGen(tx2), changeCodeWithIncarnations)
+
+			err := PromoteHashedStateCleanly("logPrefix", tx2, StageHashStateCfg(db2, t.TempDir(), compression), nil)
+			if err != nil {
+				t.Errorf("error while promoting state: %v", err)
+			}
+
+			compareCurrentState(t, tx1, tx2, dbutils.HashedAccountsBucket, dbutils.HashedStorageBucket, dbutils.ContractCodeBucket)
+		})
+	}
+}
+
 func TestPromoteHashedStateIncremental(t *testing.T) {
 	_, tx1 := kv.NewTestTx(t)
 	db2, tx2 := kv.NewTestTx(t)
@@ -34,7 +55,7 @@ func TestPromoteHashedStateIncremental(t *testing.T) {
 	generateBlocks(t, 1, 50, hashedWriterGen(tx1), changeCodeWithIncarnations)
 	generateBlocks(t, 1, 50, plainWriterGen(tx2), changeCodeWithIncarnations)
 
-	cfg := StageHashStateCfg(db2, t.TempDir())
+	cfg := StageHashStateCfg(db2, t.TempDir(), etl.CompressNone)
 	err := PromoteHashedStateCleanly("logPrefix", tx2, cfg, nil)
 	if err != nil {
 		t.Errorf("error while promoting state: %v", err)
@@ -59,7 +80,7 @@ func TestPromoteHashedStateIncrementalMixed(t *testing.T) {
 	generateBlocks(t, 1, 50, hashedWriterGen(tx2), changeCodeWithIncarnations)
 	generateBlocks(t, 51, 50, plainWriterGen(tx2), changeCodeWithIncarnations)
 
-	err := promoteHashedStateIncrementally("logPrefix", &StageState{}, 50, 101, tx2, StageHashStateCfg(db2, t.TempDir()), nil)
+	err := promoteHashedStateIncrementally("logPrefix", &StageState{}, 50, 101, tx2, StageHashStateCfg(db2, t.TempDir(), etl.CompressNone), nil)
 	if err != nil {
 		t.Errorf("error while promoting state: %v", err)
 	}
@@ -73,13 +94,13 @@ func TestUnwindHashed(t *testing.T) {
 	generateBlocks(t, 1, 50, hashedWriterGen(tx1), changeCodeWithIncarnations)
 	generateBlocks(t, 1, 50, plainWriterGen(tx2), changeCodeWithIncarnations)
 
-	err := PromoteHashedStateCleanly("logPrefix", tx2, StageHashStateCfg(db2, t.TempDir()), nil)
+	err := PromoteHashedStateCleanly("logPrefix", tx2, StageHashStateCfg(db2, t.TempDir(), etl.CompressNone), nil)
 	if err != nil {
 		t.Errorf("error while promoting state: %v", err)
 	}
 	u := &UnwindState{UnwindPoint: 50}
 	s := &StageState{BlockNumber: 100}
-	err = unwindHashStateStageImpl("logPrefix", u, s, tx2, StageHashStateCfg(db2, t.TempDir()), nil)
+	err = unwindHashStateStageImpl("logPrefix", u, s, tx2, StageHashStateCfg(db2, t.TempDir(), etl.CompressNone), nil)
 	if err != nil {
 		t.Errorf("error while unwind state: %v", err)
 	}
@@ -108,7 +129,7 @@ func TestPromoteIncrementallyShutdown(t *testing.T) {
 			}
 			db, tx := kv.NewTestTx(t)
 			generateBlocks(t, 1, 10, plainWriterGen(tx), changeCodeWithIncarnations)
-			if err := promoteHashedStateIncrementally("logPrefix", &StageState{BlockNumber: 1}, 1, 10, tx, StageHashStateCfg(db, t.TempDir()), ctx.Done()); !errors.Is(err, tc.errExp) {
+			if err := promoteHashedStateIncrementally("logPrefix", &StageState{BlockNumber: 1}, 1, 10, tx, StageHashStateCfg(db, t.TempDir(), etl.CompressNone), ctx.Done()); !errors.Is(err, tc.errExp) {
 				t.Errorf("error does not match expected error while shutdown promoteHashedStateIncrementally, got: %v, expected: %v", err, tc.errExp)
 			}
 		})
@@ -142,7 +163,7 @@ func TestPromoteHashedStateCleanlyShutdown(t *testing.T) {
 
 			generateBlocks(t, 1, 10, plainWriterGen(tx), changeCodeWithIncarnations)
 
-			if err := PromoteHashedStateCleanly("logPrefix", tx, StageHashStateCfg(db, t.TempDir()), ctx.Done()); !errors.Is(err, tc.errExp) {
+			if err := PromoteHashedStateCleanly("logPrefix", tx, StageHashStateCfg(db, t.TempDir(), etl.CompressNone), ctx.Done()); !errors.Is(err, tc.errExp) {
 				t.Errorf("error does not match expected error while shutdown promoteHashedStateCleanly , got: %v, expected: %v", err, tc.errExp)
 			}
 
@@ -175,7 +196,7 @@ func TestUnwindHashStateShutdown(t *testing.T) {
 			db, tx := kv.NewTestTx(t)
 
 			generateBlocks(t, 1, 10, plainWriterGen(tx), changeCodeWithIncarnations)
-			cfg := StageHashStateCfg(db, t.TempDir())
+			cfg := StageHashStateCfg(db, t.TempDir(), etl.CompressNone)
 			err := PromoteHashedStateCleanly("logPrefix", tx, cfg, nil)
 			require.NoError(t, err)
 
@@ -13,6 +13,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
@@ -32,6 +33,11 @@ type HeadersCfg struct {
 	announceNewHashes func(context.Context, []headerdownload.Announce)
 	penalize          func(context.Context, []headerdownload.PenaltyItem)
 	batchSize         datasize.ByteSize
+	// headers don't come from peers: from the local miner of the instant sealing developer chain, the consensus
+	// client (--beacon) or the imported file. The stage doesn't wait for them holding the transaction (the miner
+	// needs the database between the cycles, StageLoop waits instead)
+	noPeers    bool
+	checkpoint *ethconfig.Checkpoint // headers are fetched backwards from the trusted checkpoint, nil - no checkpoint
 }
 
 func StageHeadersCfg(
@@ -42,6 +48,8 @@ func StageHeadersCfg(
 	announceNewHashes func(context.Context, []headerdownload.Announce),
 	penalize func(context.Context, []headerdownload.PenaltyItem),
 	batchSize datasize.ByteSize,
+	noPeers bool,
+	checkpoint *ethconfig.Checkpoint,
 ) HeadersCfg {
 	return HeadersCfg{
 		db:                db,
@@ -51,6 +59,8 @@ func StageHeadersCfg(
 		announceNewHashes: announceNewHashes,
 		penalize:          penalize,
 		batchSize:         batchSize,
+		noPeers:           noPeers,
+		checkpoint:        checkpoint,
 	}
 }
 
@@ -114,6 +124,10 @@ func HeadersForward(
 	stopped := false
 	prevProgress := headerProgress
 	for !stopped {
+		checkpointPending := cfg.checkpoint != nil && cfg.hd.Progress() < cfg.checkpoint.Number
+		if checkpointPending { // anchor of the checkpoint is dropped if peers don't have it for a while, restore it
+			cfg.hd.SetCheckpoint(cfg.checkpoint.Number, cfg.checkpoint.Hash)
+		}
 		currentTime := uint64(time.Now().Unix())
 		req, penalties := cfg.hd.RequestMoreHeaders(currentTime)
 		if req != nil {
@@ -161,11 +175,12 @@ func HeadersForward(
 				break
 			}
 			// if this is initial cycle, we want to make sure we insert all known headers (inSync)
-			if inSync {
+			// and all the headers down from the checkpoint
+			if inSync && !checkpointPending {
 				break
 			}
 		}
-		if test {
+		if test || cfg.noPeers {
 			break
 		}
 		timer := time.NewTimer(1 * time.Second)
@@ -364,6 +379,15 @@ func (cr epochReader) GetEpoch(hash common.Hash, number uint64) ([]byte, error)
 func (cr epochReader) PutEpoch(hash common.Hash, number uint64, proof []byte) error {
 	return rawdb.WriteEpoch(cr.tx, number, hash, proof)
 }
+func (cr epochReader) GetPendingEpoch(hash common.Hash, number uint64) ([]byte, error) {
+	return rawdb.ReadPendingEpoch(cr.tx, number, hash)
+}
+func (cr epochReader) PutPendingEpoch(hash common.Hash, number uint64, proof []byte) error {
+	return rawdb.WritePendingEpoch(cr.tx, number, hash, proof)
+}
+func (cr epochReader) FindBeforeOrEqualNumber(number uint64) (blockNum uint64, blockHash common.Hash, transitionProof []byte, err error) {
+	return rawdb.FindEpochBeforeOrEqualNumber(cr.tx, number)
+}
 
 func HeadersPrune(p *PruneState, tx ethdb.RwTx, cfg HeadersCfg, ctx context.Context) (err error) {
 	useExternalTx := tx != nil
@@ -22,18 +22,20 @@ import (
 )
 
 type HistoryCfg struct {
-	db         ethdb.RwKV
-	bufLimit   datasize.ByteSize
-	flushEvery time.Duration
-	tmpdir     string
+	db          ethdb.RwKV
+	bufLimit    datasize.ByteSize
+	flushEvery  time.Duration
+	tmpdir      string
+	compression etl.Compression
 }
 
-func StageHistoryCfg(db ethdb.RwKV, tmpDir string) HistoryCfg {
+func StageHistoryCfg(db ethdb.RwKV, tmpDir string, compression etl.Compression) HistoryCfg {
 	return HistoryCfg{
-		db:         db,
-		bufLimit:   bitmapsBufLimit,
-		flushEvery: bitmapsFlushEvery,
-		tmpdir:     tmpDir,
+		db:          db,
+		bufLimit:    bitmapsBufLimit,
+		flushEvery:  bitmapsFlushEvery,
+		tmpdir:      tmpDir,
+		compression: compression,
 	}
 }
 
@@ -131,7 +133,7 @@ func promoteHistory(logPrefix string, tx ethdb.RwTx, changesetBucket string, sta
 	checkFlushEvery := time.NewTicker(cfg.flushEvery)
 	defer checkFlushEvery.Stop()
 
-	collectorUpdates := etl.NewCollector(cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorUpdates := etl.NewCompressedCollector(cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize), cfg.compression)
 	defer collectorUpdates.Close(logPrefix)
 
 	if err := changeset.Walk(tx, changesetBucket, dbutils.EncodeBlockNumber(start), 0, func(blockN uint64, k, v []byte) (bool, error) {
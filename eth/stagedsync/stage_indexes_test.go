@@ -18,6 +18,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
@@ -26,7 +27,7 @@ import (
 func TestIndexGenerator_GenerateIndex_SimpleCase(t *testing.T) {
 	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
 	kv := kv2.NewTestKV(t)
-	cfg := StageHistoryCfg(kv, t.TempDir())
+	cfg := StageHistoryCfg(kv, t.TempDir(), etl.CompressNone)
 	test := func(blocksNum int, csBucket string) func(t *testing.T) {
 		return func(t *testing.T) {
 			tx, err := kv.BeginRw(context.Background())
@@ -67,7 +68,7 @@ func TestIndexGenerator_Truncate(t *testing.T) {
 	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
 	buckets := []string{dbutils.AccountChangeSetBucket, dbutils.StorageChangeSetBucket}
 	kv := kv2.NewTestKV(t)
-	cfg := StageHistoryCfg(kv, t.TempDir())
+	cfg := StageHistoryCfg(kv, t.TempDir(), etl.CompressNone)
 	for i := range buckets {
 		csbucket := buckets[i]
 
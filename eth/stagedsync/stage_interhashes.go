@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"math/bits"
 	"os"
+	"runtime"
 	"sort"
+	"sync"
 	"time"
 
+	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/changeset"
 	"github.com/ledgerwatch/erigon/common/dbutils"
@@ -78,7 +81,12 @@ func SpawnIntermediateHashesStage(s *StageState, u Unwinder, tx ethdb.RwTx, cfg
 		log.Info(fmt.Sprintf("[%s] Generating intermediate hashes", logPrefix), "from", s.BlockNumber, "to", to)
 	}
 	var root common.Hash
-	if s.BlockNumber == 0 {
+	if s.BlockNumber == 0 && !useExternalTx {
+		// own tx has no uncommitted state yet - it's visible to parallel readers
+		if root, err = RegenerateIntermediateHashesParallel(logPrefix, tx, cfg, RegenerateShards, expectedRootHash, quit); err != nil {
+			return trie.EmptyRoot, err
+		}
+	} else if s.BlockNumber == 0 {
 		if root, err = RegenerateIntermediateHashes(logPrefix, tx, cfg, expectedRootHash, quit); err != nil {
 			return trie.EmptyRoot, err
 		}
@@ -150,6 +158,269 @@ func RegenerateIntermediateHashes(logPrefix string, db ethdb.RwTx, cfg TrieCfg,
 	return hash, nil
 }
 
+// RegenerateShards - amount of nibble-prefix shards used by stage when it regenerates trie from scratch
+const RegenerateShards = 16
+
+// RegenerateIntermediateHashesParallel produces the same TrieAccount/TrieStorage and root as RegenerateIntermediateHashes.
+// Subtries of 16 (1 nibble) or 256 (2 nibbles) key prefixes are calculated in parallel, each by own loader
+// in own read transaction of cfg.db - so hashed state must be committed to cfg.db before this call.
+// Then branch nodes at the top are calculated from roots of subtries.
+// Falls back to RegenerateIntermediateHashes if committed trie buckets are not empty.
+func RegenerateIntermediateHashesParallel(logPrefix string, tx ethdb.RwTx, cfg TrieCfg, shards int, expectedRootHash common.Hash, quit <-chan struct{}) (common.Hash, error) {
+	var nibbles int
+	switch shards {
+	case 16:
+		nibbles = 1
+	case 256:
+		nibbles = 2
+	default:
+		return trie.EmptyRoot, fmt.Errorf("unsupported amount of trie shards: %d, expected 16 or 256", shards)
+	}
+	for _, bucket := range []string{dbutils.TrieOfAccountsBucket, dbutils.TrieOfStorageBucket} {
+		k, err := firstKey(tx, bucket, nil)
+		if err != nil {
+			return trie.EmptyRoot, err
+		}
+		if k != nil {
+			log.Info(fmt.Sprintf("[%s] Trie buckets are not empty, regenerating sequentially", logPrefix))
+			return RegenerateIntermediateHashes(logPrefix, tx, cfg, expectedRootHash, quit)
+		}
+	}
+	log.Info(fmt.Sprintf("[%s] Regeneration trie hashes started", logPrefix), "shards", shards)
+	defer log.Info(fmt.Sprintf("[%s] Regeneration ended", logPrefix))
+	calcStart := time.Now()
+
+	// shards are combined in order of prefixes, workers run ahead of combining by bounded amount of shards
+	workers := runtime.GOMAXPROCS(0)
+	bufferSize := etl.BufferOptimalSize / datasize.ByteSize(workers)
+	results := make([]chan *trieShard, shards)
+	for i := range results {
+		results[i] = make(chan *trieShard, 1)
+	}
+	sem := make(chan struct{}, 2*workers)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	loaded := 0
+	defer func() {
+		close(stop)
+		wg.Wait()
+		for i := loaded; i < shards; i++ { // remove temp files of shards which were not loaded because of error
+			select {
+			case shard := <-results[i]:
+				shard.close(logPrefix)
+			default:
+			}
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < shards; i++ {
+			select {
+			case sem <- struct{}{}:
+			case <-stop:
+				return
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] <- calcTrieShard(logPrefix, cfg, nibblesOfShard(i, nibbles), bufferSize, quit)
+			}(i)
+		}
+	}()
+
+	// top of the trie: root of each shard is received as intermediate hash, as FlatDBTrieLoader does with TrieAccount records.
+	// Shard which has no branch node at it's prefix (single account, or extension node) has no such root -
+	// it's records are not used, and state of this shard is streamed to the same aggregator.
+	accTrieCollector := etl.NewCollector(cfg.tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	defer accTrieCollector.Close(logPrefix)
+	stTrieCollector := etl.NewCollector(cfg.tmpDir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	defer stTrieCollector.Close(logPrefix)
+	hc, shc := accountTrieCollector(accTrieCollector), storageTrieCollector(stTrieCollector)
+	top := trie.NewRootHashAggregator()
+	top.Reset(hc, shc, false)
+	for i := 0; i < shards; i++ {
+		var shard *trieShard
+		select {
+		case shard = <-results[i]:
+		case <-quit:
+			return trie.EmptyRoot, common.ErrStopped
+		}
+		err := shard.combine(logPrefix, tx, top, hc, shc, quit)
+		shard.close(logPrefix)
+		loaded++
+		if err != nil {
+			return trie.EmptyRoot, err
+		}
+		<-sem
+	}
+	if err := top.Receive(trie.CutoffStreamItem, nil, nil, nil, nil, nil, false, 0); err != nil {
+		return trie.EmptyRoot, err
+	}
+	hash := top.Root()
+	if cfg.checkRoot && hash != expectedRootHash {
+		// leave trie buckets empty - same as RegenerateIntermediateHashes does
+		_ = tx.(ethdb.BucketMigrator).ClearBucket(dbutils.TrieOfAccountsBucket)
+		_ = tx.(ethdb.BucketMigrator).ClearBucket(dbutils.TrieOfStorageBucket)
+		return hash, nil
+	}
+	log.Info(fmt.Sprintf("[%s] Trie root", logPrefix), "hash", hash.Hex(),
+		"in", time.Since(calcStart))
+
+	if err := accTrieCollector.Load(logPrefix, tx, dbutils.TrieOfAccountsBucket, etl.IdentityLoadFunc, etl.TransformArgs{Quit: quit}); err != nil {
+		return trie.EmptyRoot, err
+	}
+	if err := stTrieCollector.Load(logPrefix, tx, dbutils.TrieOfStorageBucket, etl.IdentityLoadFunc, etl.TransformArgs{Quit: quit}); err != nil {
+		return trie.EmptyRoot, err
+	}
+	return hash, nil
+}
+
+// trieShard - records of subtrie under nibble prefix, not loaded into db yet
+type trieShard struct {
+	prefix  []byte
+	acc, st *etl.Collector
+	empty   bool
+	branch  bool        // subtrie is branch node at prefix
+	root    common.Hash // hash of branch node at prefix
+	hasTree bool        // TrieAccount record at prefix exists
+	err     error
+}
+
+func nibblesOfShard(i, nibbles int) []byte {
+	if nibbles == 1 {
+		return []byte{byte(i)}
+	}
+	return []byte{byte(i >> 4), byte(i & 0xf)}
+}
+
+func calcTrieShard(logPrefix string, cfg TrieCfg, prefix []byte, bufferSize datasize.ByteSize, quit <-chan struct{}) *trieShard {
+	shard := &trieShard{
+		prefix: prefix,
+		acc:    etl.NewCollector(cfg.tmpDir, etl.NewSortableBuffer(bufferSize)),
+		st:     etl.NewCollector(cfg.tmpDir, etl.NewSortableBuffer(bufferSize)),
+	}
+	shard.err = cfg.db.View(context.Background(), func(tx ethdb.Tx) error {
+		first, last, err := shardBounds(tx, prefix)
+		if err != nil {
+			return err
+		}
+		if first == nil { // loader doesn't expect empty subtrie
+			shard.empty = true
+			return nil
+		}
+		// subtrie is branch node at prefix if keys differ in next nibble
+		if len(prefix) == 1 {
+			shard.branch = first[0]&0xf != last[0]&0xf
+		} else {
+			shard.branch = first[1]>>4 != last[1]>>4
+		}
+		hc := accountTrieCollector(shard.acc)
+		loader := trie.NewFlatDBTrieLoader(logPrefix)
+		if err = loader.Reset(trie.NewRetainList(0), func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
+			if hasState != 0 && bytes.Equal(keyHex, prefix) {
+				shard.hasTree = true
+			}
+			return hc(keyHex, hasState, hasTree, hasHash, hashes, rootHash)
+		}, storageTrieCollector(shard.st), false); err != nil {
+			return err
+		}
+		shard.root, err = loader.CalcTrieRoot(tx, prefix, quit)
+		return err
+	})
+	return shard
+}
+
+// shardBounds - first and last keys of HashedAccounts under nibble prefix
+func shardBounds(tx ethdb.Tx, prefix []byte) ([]byte, []byte, error) {
+	c, err := tx.Cursor(dbutils.HashedAccountsBucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer c.Close()
+	from, to := prefix[0]<<4, int(prefix[0]+1)<<4
+	if len(prefix) == 2 {
+		from |= prefix[1]
+		to = int(from) + 1
+	}
+	first, _, err := c.Seek([]byte{from})
+	if err != nil {
+		return nil, nil, err
+	}
+	if first == nil || int(first[0]) >= to {
+		return nil, nil, nil
+	}
+	var next, last []byte
+	if to <= 0xff {
+		if next, _, err = c.Seek([]byte{byte(to)}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if next == nil {
+		last, _, err = c.Last()
+	} else {
+		last, _, err = c.Prev()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return common.CopyBytes(first), last, nil
+}
+
+func firstKey(tx ethdb.Tx, bucket string, seek []byte) ([]byte, error) {
+	c, err := tx.Cursor(bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	k, _, err := c.Seek(seek)
+	return k, err
+}
+
+// combine - loads records of shard and passes it's root to the top of the trie
+func (s *trieShard) combine(logPrefix string, tx ethdb.RwTx, top *trie.RootHashAggregator, hc trie.HashCollector2, shc trie.StorageHashCollector2, quit <-chan struct{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.empty {
+		return nil
+	}
+	if err := s.st.Load(logPrefix, tx, dbutils.TrieOfStorageBucket, etl.IdentityLoadFunc, etl.TransformArgs{Quit: quit}); err != nil {
+		return err
+	}
+	if s.branch {
+		if err := s.acc.Load(logPrefix, tx, dbutils.TrieOfAccountsBucket, etl.IdentityLoadFunc, etl.TransformArgs{Quit: quit}); err != nil {
+			return err
+		}
+		return top.Receive(trie.AHashStreamItem, s.prefix, nil, nil, nil, s.root[:], s.hasTree, 0)
+	}
+	// no TrieAccount records under prefix yet - loader streams whole state of shard, storage roots are taken from TrieStorage
+	loader := trie.NewFlatDBTrieLoader(logPrefix)
+	if err := loader.Reset(trie.NewRetainList(0), hc, shc, false); err != nil {
+		return err
+	}
+	loader.SetStreamReceiver(shardReceiver{top})
+	_, err := loader.CalcTrieRoot(tx, s.prefix, quit)
+	return err
+}
+
+func (s *trieShard) close(logPrefix string) {
+	s.acc.Close(logPrefix)
+	s.st.Close(logPrefix)
+}
+
+// shardReceiver - passes stream of one shard to the top of the trie, which continues after the shard
+type shardReceiver struct {
+	*trie.RootHashAggregator
+}
+
+func (r shardReceiver) Receive(itemType trie.StreamItem, accountKey, storageKey []byte, accountValue *accounts.Account, storageValue, hash []byte, hasTree bool, cutoff int) error {
+	if itemType == trie.CutoffStreamItem {
+		return nil
+	}
+	return r.RootHashAggregator.Receive(itemType, accountKey, storageKey, accountValue, storageValue, hash, hasTree, cutoff)
+}
+
 type HashPromoter struct {
 	db               ethdb.RwTx
 	ChangeSetBufSize uint64
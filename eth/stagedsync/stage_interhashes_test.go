@@ -1,7 +1,9 @@
 package stagedsync
 
 import (
+	"context"
 	"encoding/binary"
+	"math/rand"
 	"testing"
 
 	"github.com/ledgerwatch/erigon/common"
@@ -173,3 +175,62 @@ func TestAccountTrieAroundExtensionNode(t *testing.T) {
 	assert.Equal(t, common.HashLength, len(hashes2))
 	assert.Equal(t, 0, len(rootHash2))
 }
+
+func TestRegenerateIntermediateHashesParallel(t *testing.T) {
+	for _, accounts := range []int{0, 1, 3, 50, 2000} {
+		db := kv.NewTestKV(t)
+		rnd := rand.New(rand.NewSource(int64(accounts)))
+		require.NoError(t, db.Update(context.Background(), func(tx ethdb.RwTx) error {
+			for i := 0; i < accounts; i++ {
+				var hash common.Hash
+				rnd.Read(hash[:])
+				if accounts < 10 || i%10 == 0 { // subtries with extension nodes at the top
+					hash[0], hash[1] = 0xab, 0xcd
+				}
+				incarnation := uint64(0)
+				if i%3 == 0 {
+					incarnation = 1
+				}
+				if err := addTestAccount(tx, hash, uint64(i+1), incarnation); err != nil {
+					return err
+				}
+				for j := 0; incarnation != 0 && j < rnd.Intn(100); j++ {
+					var loc common.Hash
+					rnd.Read(loc[:])
+					if err := tx.Put(dbutils.HashedStorageBucket, dbutils.GenerateCompositeStorageKey(hash, incarnation, loc), []byte{byte(j + 1)}); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}))
+
+		regenerate := func(shards int) (common.Hash, map[string][]byte) {
+			tx, err := db.BeginRw(context.Background())
+			require.NoError(t, err)
+			defer tx.Rollback()
+			cfg := StageTrieCfg(db, false, true, t.TempDir())
+			var root common.Hash
+			if shards == 0 {
+				root, err = RegenerateIntermediateHashes("IH", tx, cfg, common.Hash{}, nil)
+			} else {
+				root, err = RegenerateIntermediateHashesParallel("IH", tx, cfg, shards, common.Hash{}, nil)
+			}
+			require.NoError(t, err)
+			records := map[string][]byte{}
+			for _, bucket := range []string{dbutils.TrieOfAccountsBucket, dbutils.TrieOfStorageBucket} {
+				require.NoError(t, tx.ForEach(bucket, nil, func(k, v []byte) error {
+					records[bucket+common.Bytes2Hex(k)] = common.CopyBytes(v)
+					return nil
+				}))
+			}
+			return root, records
+		}
+		expectedRoot, expectedRecords := regenerate(0)
+		for _, shards := range []int{16, 256} {
+			root, records := regenerate(shards)
+			require.Equal(t, expectedRoot, root, "accounts=%d, shards=%d", accounts, shards)
+			require.Equal(t, expectedRecords, records, "accounts=%d, shards=%d", accounts, shards)
+		}
+	}
+}
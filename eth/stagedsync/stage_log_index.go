@@ -27,18 +27,20 @@ const (
 )
 
 type LogIndexCfg struct {
-	tmpdir     string
-	db         ethdb.RwKV
-	bufLimit   datasize.ByteSize
-	flushEvery time.Duration
+	tmpdir      string
+	db          ethdb.RwKV
+	bufLimit    datasize.ByteSize
+	flushEvery  time.Duration
+	compression etl.Compression
 }
 
-func StageLogIndexCfg(db ethdb.RwKV, tmpDir string) LogIndexCfg {
+func StageLogIndexCfg(db ethdb.RwKV, tmpDir string, compression etl.Compression) LogIndexCfg {
 	return LogIndexCfg{
-		db:         db,
-		bufLimit:   bitmapsBufLimit,
-		flushEvery: bitmapsFlushEvery,
-		tmpdir:     tmpDir,
+		db:          db,
+		bufLimit:    bitmapsBufLimit,
+		flushEvery:  bitmapsFlushEvery,
+		tmpdir:      tmpDir,
+		compression: compression,
 	}
 }
 
@@ -98,9 +100,9 @@ func promoteLogIndex(logPrefix string, tx ethdb.RwTx, start uint64, cfg LogIndex
 	checkFlushEvery := time.NewTicker(cfg.flushEvery)
 	defer checkFlushEvery.Stop()
 
-	collectorTopics := etl.NewCollector(cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorTopics := etl.NewCompressedCollector(cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize), cfg.compression)
 	defer collectorTopics.Close(logPrefix)
-	collectorAddrs := etl.NewCollector(cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorAddrs := etl.NewCompressedCollector(cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize), cfg.compression)
 	defer collectorAddrs.Close(logPrefix)
 
 	reader := bytes.NewReader(nil)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
@@ -46,7 +47,7 @@ func TestLogIndex(t *testing.T) {
 
 	err = rawdb.AppendReceipts(tx, 2, receipts2)
 	require.NoError(err)
-	cfg := StageLogIndexCfg(db, "")
+	cfg := StageLogIndexCfg(db, "", etl.CompressNone)
 	cfgCopy := cfg
 	cfgCopy.bufLimit = 10
 	cfgCopy.flushEvery = time.Millisecond
@@ -29,6 +29,9 @@ type MiningBlock struct {
 
 	LocalTxs  types.TransactionsStream
 	RemoteTxs types.TransactionsStream
+
+	// Timestamp of the block requested by the consensus client (see stages.ExecutionEngine), current time if 0
+	Timestamp uint64
 }
 
 type MiningState struct {
@@ -146,6 +149,9 @@ func SpawnMiningCreateBlockStage(s *StageState, tx ethdb.RwTx, cfg MiningCreateB
 
 	// re-written miner/worker.go:commitNewWork
 	timestamp := time.Now().Unix()
+	if current.Timestamp != 0 {
+		timestamp = int64(current.Timestamp)
+	}
 	if parent.Time >= uint64(timestamp) {
 		timestamp = int64(parent.Time + 1)
 	}
@@ -163,7 +169,7 @@ func SpawnMiningCreateBlockStage(s *StageState, tx ethdb.RwTx, cfg MiningCreateB
 	header.Coinbase = coinbase
 	//}
 
-	if err = cfg.engine.Prepare(chain, header); err != nil {
+	if err = cfg.engine.Prepare(chain, header, epochReader{tx: tx}); err != nil {
 		log.Error("Failed to prepare header for mining",
 			"err", err,
 			"headerNumber", header.Number.Uint64(),
@@ -24,6 +24,7 @@ type MiningExecCfg struct {
 	chainConfig params.ChainConfig
 	engine      consensus.Engine
 	vmConfig    *vm.Config
+	fork        *state.ForkSource
 	tmpdir      string
 }
 
@@ -34,6 +35,7 @@ func StageMiningExecCfg(
 	chainConfig params.ChainConfig,
 	engine consensus.Engine,
 	vmConfig *vm.Config,
+	fork *state.ForkSource,
 	tmpdir string,
 ) MiningExecCfg {
 	return MiningExecCfg{
@@ -43,6 +45,7 @@ func StageMiningExecCfg(
 		chainConfig: chainConfig,
 		engine:      engine,
 		vmConfig:    vmConfig,
+		fork:        fork,
 		tmpdir:      tmpdir,
 	}
 }
@@ -58,11 +61,21 @@ func SpawnMiningExecStage(s *StageState, tx ethdb.RwTx, cfg MiningExecCfg, quit
 	remoteTxs := current.RemoteTxs
 	noempty := true
 
-	ibs := state.New(state.NewPlainStateReader(tx))
-	stateWriter := state.NewPlainStateWriter(tx, tx, current.Header.Number.Uint64())
+	var stateReader state.StateReader = state.NewPlainStateReader(tx)
+	var stateWriter state.WriterWithChangeSets = state.NewPlainStateWriter(tx, tx, current.Header.Number.Uint64())
+	if cfg.fork != nil { // dev chain forked from the state of another one
+		stateReader = cfg.fork.Reader(stateReader, tx)
+		stateWriter = state.NewForkStateWriter(stateWriter, tx)
+	}
+	ibs := state.New(stateReader)
 	if cfg.chainConfig.DAOForkSupport && cfg.chainConfig.DAOForkBlock != nil && cfg.chainConfig.DAOForkBlock.Cmp(current.Header.Number) == 0 {
 		misc.ApplyDAOHardFork(ibs)
 	}
+	// engine's system calls at the beginning of the block (e.g. finalizeChange of AuRa validator set contracts)
+	// must be part of the mined state the same way they are part of the executed one
+	if err := core.InitializeBlockExecution(cfg.engine, epochReader{tx: tx}, current.Header, current.Txs, current.Uncles, &cfg.chainConfig, ibs); err != nil {
+		return err
+	}
 
 	// Create an empty block based on temporary copied state for
 	// sealing in advance without waiting block execution finished.
@@ -72,7 +85,7 @@ func SpawnMiningExecStage(s *StageState, tx ethdb.RwTx, cfg MiningExecCfg, quit
 	}
 
 	getHeader := func(hash common.Hash, number uint64) *types.Header { return rawdb.ReadHeader(tx, hash, number) }
-	checkTEVM := ethdb.GetCheckTEVM(tx)
+	checkTEVM := vm.GetCheckTEVM(tx)
 
 	// Short circuit if there is no available pending transactions.
 	// But if we disable empty precommit already, ignore it. Since
@@ -104,7 +117,7 @@ func SpawnMiningExecStage(s *StageState, tx ethdb.RwTx, cfg MiningExecCfg, quit
 		}
 	}
 
-	if err := core.FinalizeBlockExecution(cfg.engine, current.Header, current.Txs, current.Uncles, stateWriter, &cfg.chainConfig, ibs, nil, nil); err != nil {
+	if err := core.FinalizeBlockExecution(cfg.engine, current.Header, current.Txs, current.Uncles, stateWriter, &cfg.chainConfig, ibs, nil, nil, nil); err != nil {
 		return err
 	}
 
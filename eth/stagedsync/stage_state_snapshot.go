@@ -2,14 +2,19 @@ package stagedsync
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/log"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 )
 
 type SnapshotStateCfg struct {
 	db               ethdb.RwKV
+	enabled          bool
 	snapshotDir      string
 	tmpDir           string
 	client           *snapshotsync.Client
@@ -19,6 +24,7 @@ type SnapshotStateCfg struct {
 func StageSnapshotStateCfg(db ethdb.RwKV, snapshot ethconfig.Snapshot, tmpDir string, client *snapshotsync.Client, snapshotMigrator *snapshotsync.SnapshotMigrator) SnapshotStateCfg {
 	return SnapshotStateCfg{
 		db:               db,
+		enabled:          snapshot.Enabled && snapshot.Mode.State && snapshot.Dir != "",
 		snapshotDir:      snapshot.Dir,
 		client:           client,
 		snapshotMigrator: snapshotMigrator,
@@ -36,9 +42,42 @@ func SpawnStateSnapshotGenerationStage(s *StageState, tx ethdb.RwTx, cfg Snapsho
 		defer tx.Rollback()
 	}
 
-	if err = s.Update(tx, 0); err != nil {
+	executed, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
 		return err
 	}
+	//it's too early for snapshot
+	if executed < snapshotsync.EpochSize {
+		return nil
+	}
+	// state diffs are made of immutable epochs only, they are not unwound
+	epoch := snapshotsync.CalculateEpoch(executed, snapshotsync.EpochSize)
+	if epoch < snapshotsync.EpochSize {
+		return nil
+	}
+	from := s.BlockNumber
+	if from == 0 {
+		// node without diffs starts from the last epoch, the base is made by `generate_snapshot state`
+		from = epoch - snapshotsync.EpochSize
+	}
+	for ; from < epoch; from += snapshotsync.EpochSize {
+		to := from + snapshotsync.EpochSize
+		path := snapshotsync.StateDiffName(cfg.snapshotDir, from, to)
+		log.Info(fmt.Sprintf("[%s] Creating state diff", s.LogPrefix()), "from", from, "to", to, "path", path)
+		if err = snapshotsync.CreateStateDiffSnapshot(ctx, tx, from, to, path); err != nil {
+			return fmt.Errorf("[%s] state diff (%d, %d]: %w", s.LogPrefix(), from, to, err)
+		}
+		if cfg.client != nil {
+			hash, err := cfg.client.SeedSnapshot(filepath.Base(path), path)
+			if err != nil {
+				return fmt.Errorf("[%s] seeding of state diff (%d, %d]: %w", s.LogPrefix(), from, to, err)
+			}
+			log.Info(fmt.Sprintf("[%s] Start seeding", s.LogPrefix()), "snapshot", filepath.Base(path), "hash", hash.String())
+		}
+		if err = s.Update(tx, to); err != nil {
+			return err
+		}
+	}
 	if !useExternalTx {
 		if err := tx.Commit(); err != nil {
 			return err
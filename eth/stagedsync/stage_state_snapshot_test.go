@@ -0,0 +1,55 @@
+package stagedsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSnapshotStageCreatesDiffs(t *testing.T) {
+	ctx := context.Background()
+	db, tx := kv.NewTestTx(t)
+	dir := t.TempDir()
+	cfg := StageSnapshotStateCfg(db, ethconfig.Snapshot{Enabled: true, Mode: snapshotsync.SnapshotMode{State: true}, Dir: dir}, t.TempDir(), nil, nil)
+
+	spawn := func(executed uint64) uint64 {
+		require.NoError(t, stages.SaveStageProgress(tx, stages.Execution, executed))
+		progress, err := stages.GetStageProgress(tx, stages.CreateStateSnapshot)
+		require.NoError(t, err)
+		require.NoError(t, SpawnStateSnapshotGenerationStage(&StageState{ID: stages.CreateStateSnapshot, BlockNumber: progress}, tx, cfg, ctx))
+		progress, err = stages.GetStageProgress(tx, stages.CreateStateSnapshot)
+		require.NoError(t, err)
+		return progress
+	}
+
+	// no complete epoch of immutable blocks yet
+	require.Equal(t, uint64(0), spawn(600_000))
+	// the first diff is of the last epoch only
+	require.Equal(t, uint64(1_410_000), spawn(1_500_000))
+	// then every epoch
+	require.Equal(t, uint64(2_410_000), spawn(2_500_000))
+
+	diffs, err := snapshotsync.StateDiffSnapshots(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		snapshotsync.StateDiffName(dir, 910_000, 1_410_000),
+		snapshotsync.StateDiffName(dir, 1_410_000, 1_910_000),
+		snapshotsync.StateDiffName(dir, 1_910_000, 2_410_000),
+	}, diffs)
+
+	diffKV, err := snapshotsync.OpenStateDiffSnapshot(diffs[2])
+	require.NoError(t, err)
+	defer diffKV.Close()
+	require.NoError(t, diffKV.View(ctx, func(diffTx ethdb.Tx) error {
+		from, to, err := snapshotsync.StateDiffRange(diffTx)
+		require.Equal(t, uint64(1_910_000), from)
+		require.Equal(t, uint64(2_410_000), to)
+		return err
+	}))
+}
@@ -0,0 +1,342 @@
+// Package backup makes consistent copies of a live database and restores them back.
+//
+// Whole copy is done inside one read transaction of the source database, so the result
+// represents the state of the database at the moment of the transaction start, while the
+// node keeps writing new data. Keep in mind: MDBX can't reuse pages freed after start of
+// the oldest read transaction - long backups of an actively written database make it grow.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/torquem-ch/mdbx-go/mdbx"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultCommitEvery - size of data written into target database by one transaction
+	DefaultCommitEvery = 512 * datasize.MB
+	throttleChunk      = 64 * datasize.KB
+	dataFileName       = "mdbx.dat"
+)
+
+// Opts - settings of Backup and Restore
+type Opts struct {
+	// Buckets to copy. All non-deprecated buckets of the source database if empty.
+	Buckets []string
+	// Compact - pages of the target database are filled densely by appending keys in sorted order.
+	// Without it keys are copied by Put - pages keep free space, it's better for the database
+	// which will receive random writes after restore.
+	Compact bool
+	// RateLimit - how many bytes per second can be read from the source database. 0 - unlimited.
+	RateLimit datasize.ByteSize
+	// CommitEvery - size of data written into target database by one transaction of Backup. DefaultCommitEvery if 0.
+	// Restore writes in one transaction.
+	CommitEvery datasize.ByteSize
+	// LogEvery - how often to log and report progress. 30 seconds if 0.
+	LogEvery time.Duration
+	// Progress - optional callback, called every LogEvery and once after each bucket
+	Progress func(Progress)
+}
+
+// Progress of Backup or Restore
+type Progress struct {
+	Bucket       string
+	BucketIdx    int    // index of Bucket in the list of copied buckets
+	BucketsTotal int    // amount of buckets to copy
+	BucketKeys   uint64 // amount of keys in Bucket (approximate for dupsort buckets)
+	BucketCopied uint64 // amount of copied keys of Bucket
+	Keys         uint64 // amount of keys copied in all buckets
+	Bytes        uint64 // amount of bytes (keys and values) copied in all buckets
+	Elapsed      time.Duration
+}
+
+// Backup - copies given buckets (or whole database) of `db` into new database in `to` directory.
+// Directory `to` must not contain database. The copy is written into a temporary directory next to `to`
+// and renamed to `to` when it's complete - failed backup leaves nothing behind and can be retried.
+func Backup(ctx context.Context, db ethdb.RoKV, to string, opts Opts) error {
+	if _, err := os.Stat(path.Join(to, dataFileName)); err == nil {
+		return fmt.Errorf("backup directory %s already contains database", to)
+	}
+	to = filepath.Clean(to)
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(to), filepath.Base(to)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	log.Info("[backup] start", "to", to, "compact", opts.Compact, "rateLimit", opts.RateLimit)
+	if err = backupInto(ctx, db, tmp, opts); err != nil {
+		return err
+	}
+	// `to` may be an empty directory
+	if err = os.Remove(to); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Rename(tmp, to)
+}
+
+func backupInto(ctx context.Context, db ethdb.RoKV, dir string, opts Opts) error {
+	buckets := db.AllBuckets()
+	dst, err := kv.NewMDBX().Path(dir).WithBucketsConfig(func(dbutils.BucketsCfg) dbutils.BucketsCfg { return buckets }).Open()
+	if err != nil {
+		return fmt.Errorf("open backup db: %w", err)
+	}
+	defer dst.Close()
+	return copyBuckets(ctx, db, dst, opts, true /* intermediateCommits */)
+}
+
+// Restore - copies given buckets (or whole database) from backup in `from` directory into database in `to` directory.
+// Restored buckets are cleared in `to` before copy. Database in `to` must not be used by other processes.
+// Whole restore is one transaction - if it fails, database in `to` stays as it was.
+func Restore(ctx context.Context, from, to string, opts Opts) error {
+	if _, err := os.Stat(path.Join(from, dataFileName)); err != nil {
+		return fmt.Errorf("backup not found in %s: %w", from, err)
+	}
+	src, err := kv.NewMDBX().Path(from).Flags(func(flags uint) uint { return flags | mdbx.Readonly | mdbx.Accede }).Open()
+	if err != nil {
+		return fmt.Errorf("open backup db: %w", err)
+	}
+	defer src.Close()
+	dst, err := kv.NewMDBX().Path(to).Exclusive().Open()
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer dst.Close()
+	log.Info("[restore] start", "from", from, "to", to, "compact", opts.Compact, "rateLimit", opts.RateLimit)
+	return copyBuckets(ctx, src, dst, opts, false /* intermediateCommits */)
+}
+
+// bucketsToCopy - returns sorted list of buckets to copy, checks that all requested buckets exist
+func bucketsToCopy(db ethdb.RoKV, requested []string) ([]string, error) {
+	all := db.AllBuckets()
+	if len(requested) == 0 {
+		res := make([]string, 0, len(all))
+		for name, cfg := range all {
+			if cfg.IsDeprecated {
+				continue
+			}
+			res = append(res, name)
+		}
+		sort.Strings(res)
+		return res, nil
+	}
+	res := make([]string, 0, len(requested))
+	for _, name := range requested {
+		cfg, ok := all[name]
+		if !ok || cfg.IsDeprecated {
+			return nil, fmt.Errorf("%w: %s", ethdb.ErrUnknownBucket, name)
+		}
+		res = append(res, name)
+	}
+	return res, nil
+}
+
+func copyBuckets(ctx context.Context, src ethdb.RoKV, dst ethdb.RwKV, opts Opts, intermediateCommits bool) error {
+	buckets, err := bucketsToCopy(src, opts.Buckets)
+	if err != nil {
+		return err
+	}
+	if opts.CommitEvery == 0 {
+		opts.CommitEvery = DefaultCommitEvery
+	}
+	if opts.LogEvery == 0 {
+		opts.LogEvery = 30 * time.Second
+	}
+
+	// source read transaction and target write transactions must be used by same thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	srcTx, err := src.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcTx.Rollback()
+
+	c := &copier{
+		opts:                opts,
+		intermediateCommits: intermediateCommits,
+		srcTx:               srcTx,
+		dst:                 dst,
+		start:               time.Now(),
+	}
+	if opts.RateLimit > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(opts.RateLimit), int(throttleChunk))
+	}
+	logEvery := time.NewTicker(opts.LogEvery)
+	defer logEvery.Stop()
+	c.logEvery = logEvery.C
+	c.progress.BucketsTotal = len(buckets)
+
+	if c.dstTx, err = dst.BeginRw(ctx); err != nil {
+		return err
+	}
+	defer func() { c.dstTx.Rollback() }()
+
+	for i, name := range buckets {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		c.progress.Bucket, c.progress.BucketIdx = name, i
+		if err = c.copyBucket(ctx, name); err != nil {
+			return fmt.Errorf("bucket %s: %w", name, err)
+		}
+		if opts.Progress != nil {
+			c.progress.Elapsed = time.Since(c.start)
+			opts.Progress(c.progress)
+		}
+	}
+	if len(opts.Buckets) > 0 {
+		if err = c.copySequences(buckets); err != nil {
+			return err
+		}
+	}
+	if err = c.dstTx.Commit(); err != nil {
+		return err
+	}
+	log.Info("[backup] done", "buckets", len(buckets), "keys", c.progress.Keys, "bytes", datasize.ByteSize(c.progress.Bytes).HumanReadable(), "in", time.Since(c.start))
+	return nil
+}
+
+type copier struct {
+	opts     Opts
+	srcTx    ethdb.Tx
+	dst      ethdb.RwKV
+	dstTx    ethdb.RwTx
+	limiter  *rate.Limiter
+	logEvery <-chan time.Time
+	start    time.Time
+	progress Progress
+
+	notThrottled uint64 // bytes read since last limiter call
+	notCommitted uint64 // bytes written since last commit of dstTx
+
+	intermediateCommits bool // commit dstTx every opts.CommitEvery, otherwise copy in one transaction
+}
+
+func (c *copier) copyBucket(ctx context.Context, name string) error {
+	if err := c.dstTx.ClearBucket(name); err != nil {
+		return err
+	}
+	srcC, err := c.srcTx.Cursor(name)
+	if err != nil {
+		return err
+	}
+	defer srcC.Close()
+	if c.progress.BucketKeys, err = srcC.Count(); err != nil {
+		return err
+	}
+	c.progress.BucketCopied = 0
+	dstC, err := c.dstTx.RwCursor(name)
+	if err != nil {
+		return err
+	}
+
+	for k, v, err := srcC.First(); k != nil; k, v, err = srcC.Next() {
+		if err != nil {
+			return err
+		}
+		if c.opts.Compact {
+			err = dstC.Append(k, v)
+		} else {
+			err = dstC.Put(k, v)
+		}
+		if err != nil {
+			return err
+		}
+		size := uint64(len(k) + len(v))
+		c.progress.Keys++
+		c.progress.BucketCopied++
+		c.progress.Bytes += size
+		c.notThrottled += size
+		c.notCommitted += size
+
+		if c.limiter != nil && c.notThrottled >= uint64(throttleChunk) {
+			if err = c.limiter.WaitN(ctx, int(throttleChunk)); err != nil {
+				return err
+			}
+			c.notThrottled -= uint64(throttleChunk)
+		}
+		if c.intermediateCommits && c.notCommitted >= uint64(c.opts.CommitEvery) {
+			if err = c.commit(ctx); err != nil {
+				return err
+			}
+			if dstC, err = c.dstTx.RwCursor(name); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.logEvery:
+			c.report()
+		default:
+		}
+	}
+	return nil
+}
+
+// copySequences - buckets sequences are stored in dbutils.Sequence bucket, if it's not copied explicitly -
+// need to copy sequences of copied buckets
+func (c *copier) copySequences(buckets []string) error {
+	for _, name := range buckets {
+		if name == dbutils.Sequence {
+			return nil
+		}
+	}
+	for _, name := range buckets {
+		v, err := c.srcTx.GetOne(dbutils.Sequence, []byte(name))
+		if err != nil {
+			return err
+		}
+		if len(v) == 0 {
+			if err = c.dstTx.Delete(dbutils.Sequence, []byte(name), nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = c.dstTx.Put(dbutils.Sequence, []byte(name), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *copier) commit(ctx context.Context) (err error) {
+	if err = c.dstTx.Commit(); err != nil {
+		return err
+	}
+	c.notCommitted = 0
+	c.dstTx, err = c.dst.BeginRw(ctx)
+	return err
+}
+
+func (c *copier) report() {
+	c.progress.Elapsed = time.Since(c.start)
+	p := c.progress
+	log.Info("[backup] progress",
+		"bucket", fmt.Sprintf("%s (%d/%d)", p.Bucket, p.BucketIdx+1, p.BucketsTotal),
+		"keys", fmt.Sprintf("%d/%d", p.BucketCopied, p.BucketKeys),
+		"total", datasize.ByteSize(p.Bytes).HumanReadable())
+	if c.opts.Progress != nil {
+		c.opts.Progress(p)
+	}
+}
@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func fill(t *testing.T, db ethdb.RwKV) {
+	require.NoError(t, db.Update(context.Background(), func(tx ethdb.RwTx) error {
+		for i := 0; i < 1000; i++ {
+			if err := tx.Put(dbutils.HeadersBucket, []byte(fmt.Sprintf("header%04d", i)), []byte(fmt.Sprintf("value%d", i))); err != nil {
+				return err
+			}
+			// PlainState has auto-dupsort keys conversion
+			if err := tx.Put(dbutils.PlainStateBucket, make([]byte, 20), []byte{byte(i)}); err != nil {
+				return err
+			}
+			storageKey := dbutils.PlainGenerateCompositeStorageKey(make([]byte, 20), 1, make([]byte, 32))
+			storageKey[len(storageKey)-1] = byte(i)
+			storageKey[len(storageKey)-2] = byte(i >> 8)
+			if err := tx.Put(dbutils.PlainStateBucket, storageKey, []byte{byte(i), 1}); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.IncrementSequence(dbutils.EthTx, 42); err != nil {
+			return err
+		}
+		return nil
+	}))
+}
+
+func requireEqualBuckets(t *testing.T, a, b ethdb.RoKV, buckets ...string) {
+	require.NoError(t, a.View(context.Background(), func(txA ethdb.Tx) error {
+		return b.View(context.Background(), func(txB ethdb.Tx) error {
+			for _, name := range buckets {
+				var kvA, kvB [][]byte
+				require.NoError(t, txA.ForEach(name, nil, func(k, v []byte) error {
+					kvA = append(kvA, append(append([]byte{}, k...), v...))
+					return nil
+				}))
+				require.NoError(t, txB.ForEach(name, nil, func(k, v []byte) error {
+					kvB = append(kvB, append(append([]byte{}, k...), v...))
+					return nil
+				}))
+				require.Equal(t, kvA, kvB, name)
+			}
+			return nil
+		})
+	}))
+}
+
+func TestBackupRestore(t *testing.T) {
+	for _, compact := range []bool{true, false} {
+		compact := compact
+		t.Run(fmt.Sprintf("compact=%t", compact), func(t *testing.T) {
+			ctx, dir := context.Background(), t.TempDir()
+			db := kv.NewTestKV(t)
+			fill(t, db)
+
+			var reported int
+			opts := Opts{Compact: compact, CommitEvery: 1024, Progress: func(Progress) { reported++ }}
+			require.NoError(t, Backup(ctx, db, dir+"/backup", opts))
+			require.Equal(t, len(db.AllBuckets())-len(dbutils.DeprecatedBuckets), reported)
+			require.Error(t, Backup(ctx, db, dir+"/backup", opts))
+
+			require.NoError(t, Restore(ctx, dir+"/backup", dir+"/restored", opts))
+			restored := kv.NewMDBX().Path(dir + "/restored").MustOpen()
+			defer restored.Close()
+			requireEqualBuckets(t, db, restored, dbutils.HeadersBucket, dbutils.PlainStateBucket, dbutils.Sequence)
+		})
+	}
+}
+
+func TestBackupBuckets(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	db := kv.NewTestKV(t)
+	fill(t, db)
+
+	opts := Opts{Buckets: []string{dbutils.HeadersBucket, dbutils.EthTx}, RateLimit: 1024 * 1024}
+	require.NoError(t, Backup(ctx, db, dir, opts))
+	bkp := kv.NewMDBX().Path(dir).MustOpen()
+	defer bkp.Close()
+	requireEqualBuckets(t, db, bkp, dbutils.HeadersBucket, dbutils.Sequence)
+	require.NoError(t, bkp.View(ctx, func(tx ethdb.Tx) error {
+		c, err := tx.Cursor(dbutils.PlainStateBucket)
+		require.NoError(t, err)
+		defer c.Close()
+		k, _, err := c.First()
+		require.NoError(t, err)
+		require.Nil(t, k)
+		return nil
+	}))
+
+	require.Error(t, Backup(ctx, db, t.TempDir(), Opts{Buckets: []string{"unknown"}}))
+}
+
+func TestBackupRestoreFailure(t *testing.T) {
+	dir := t.TempDir()
+	db := kv.NewTestKV(t)
+	fill(t, db)
+
+	// cancelled after the first bucket
+	cancelled := func() (context.Context, Opts) {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, Opts{Buckets: []string{dbutils.HeadersBucket, dbutils.PlainStateBucket}, CommitEvery: 1, Progress: func(Progress) { cancel() }}
+	}
+
+	// failed backup leaves nothing behind, so it can be retried
+	ctx, opts := cancelled()
+	require.Error(t, Backup(ctx, db, dir+"/backup", opts))
+	_, err := os.Stat(dir + "/backup")
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, Backup(context.Background(), db, dir+"/backup", Opts{}))
+
+	// failed restore leaves the database as it was
+	target := kv.NewMDBX().Path(dir + "/restored").MustOpen()
+	require.NoError(t, target.Update(context.Background(), func(tx ethdb.RwTx) error {
+		return tx.Put(dbutils.HeadersBucket, []byte("header"), []byte("value"))
+	}))
+	target.Close()
+	ctx, opts = cancelled()
+	require.Error(t, Restore(ctx, dir+"/backup", dir+"/restored", opts))
+	restored := kv.NewMDBX().Path(dir + "/restored").MustOpen()
+	defer restored.Close()
+	require.NoError(t, restored.View(context.Background(), func(tx ethdb.Tx) error {
+		v, err := tx.GetOne(dbutils.HeadersBucket, []byte("header"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+		return nil
+	}))
+}
@@ -177,6 +177,41 @@ func TestRemoteKvVersion(t *testing.T) {
 	require.False(t, a.EnsureVersionCompatibility())
 }
 
+func TestRemoteKvCliqueSnapshots(t *testing.T) {
+	chainDb, cliqueDb := kv.NewTestKV(t), kv.NewTestKV(t)
+	ctx := context.Background()
+	require.NoError(t, cliqueDb.Update(ctx, func(tx ethdb.RwTx) error {
+		return tx.Put(dbutils.CliqueSeparateBucket, []byte{1}, []byte("snapshot"))
+	}))
+	require.NoError(t, chainDb.Update(ctx, func(tx ethdb.RwTx) error {
+		return tx.Put(dbutils.HeadersBucket, []byte{1}, []byte("header"))
+	}))
+
+	conn := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	kvServer := remotedbserver.NewKvServer(chainDb)
+	kvServer.ServeCliqueSnapshots(cliqueDb)
+	go func() {
+		remote.RegisterKVServer(grpcServer, kvServer)
+		if err := grpcServer.Serve(conn); err != nil {
+			log.Error("private RPC server fail", "err", err)
+		}
+	}()
+	defer grpcServer.Stop()
+	rdb := kv.NewRemote(gointerfaces.VersionFromProto(remotedbserver.KvServiceAPIVersion)).InMem(conn).MustOpen()
+	defer rdb.Close()
+
+	require.NoError(t, rdb.View(ctx, func(tx ethdb.Tx) error {
+		v, err := tx.GetOne(dbutils.CliqueSeparateBucket, []byte{1})
+		require.NoError(t, err)
+		require.Equal(t, []byte("snapshot"), v)
+		v, err = tx.GetOne(dbutils.HeadersBucket, []byte{1})
+		require.NoError(t, err)
+		require.Equal(t, []byte("header"), v)
+		return nil
+	}))
+}
+
 func setupDatabases(t *testing.T, f kv.BucketConfigsFunc) (writeDBs []ethdb.RwKV, readDBs []ethdb.RwKV) {
 	writeDBs = []ethdb.RwKV{
 		kv.NewMDBX().InMem().WithBucketsConfig(f).MustOpen(),
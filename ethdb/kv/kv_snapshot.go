@@ -28,6 +28,7 @@ type SnapshotUpdater interface {
 	HeadersSnapshot() ethdb.RoKV
 	BodiesSnapshot() ethdb.RoKV
 	StateSnapshot() ethdb.RoKV
+	ReceiptsSnapshot() ethdb.RoKV
 }
 
 type WriteDB interface {
@@ -39,10 +40,11 @@ func NewSnapshotKV() snapshotOpts {
 }
 
 type snapshotOpts struct {
-	db              ethdb.RwKV
-	headersSnapshot ethdb.RoKV
-	bodiesSnapshot  ethdb.RoKV
-	stateSnapshot   ethdb.RoKV
+	db               ethdb.RwKV
+	headersSnapshot  ethdb.RoKV
+	bodiesSnapshot   ethdb.RoKV
+	stateSnapshot    ethdb.RoKV
+	receiptsSnapshot ethdb.RoKV
 }
 
 func (opts snapshotOpts) HeadersSnapshot(kv ethdb.RoKV) snapshotOpts {
@@ -57,6 +59,10 @@ func (opts snapshotOpts) StateSnapshot(kv ethdb.RoKV) snapshotOpts {
 	opts.stateSnapshot = kv
 	return opts
 }
+func (opts snapshotOpts) ReceiptsSnapshot(kv ethdb.RoKV) snapshotOpts {
+	opts.receiptsSnapshot = kv
+	return opts
+}
 
 func (opts snapshotOpts) DB(db ethdb.RwKV) snapshotOpts {
 	opts.db = db
@@ -65,19 +71,21 @@ func (opts snapshotOpts) DB(db ethdb.RwKV) snapshotOpts {
 
 func (opts snapshotOpts) Open() *SnapshotKV {
 	return &SnapshotKV{
-		headersSnapshot: opts.headersSnapshot,
-		bodiesSnapshot:  opts.bodiesSnapshot,
-		stateSnapshot:   opts.stateSnapshot,
-		db:              opts.db,
+		headersSnapshot:  opts.headersSnapshot,
+		bodiesSnapshot:   opts.bodiesSnapshot,
+		stateSnapshot:    opts.stateSnapshot,
+		receiptsSnapshot: opts.receiptsSnapshot,
+		db:               opts.db,
 	}
 }
 
 type SnapshotKV struct {
-	db              ethdb.RwKV
-	headersSnapshot ethdb.RoKV
-	bodiesSnapshot  ethdb.RoKV
-	stateSnapshot   ethdb.RoKV
-	mtx             sync.RWMutex
+	db               ethdb.RwKV
+	headersSnapshot  ethdb.RoKV
+	bodiesSnapshot   ethdb.RoKV
+	stateSnapshot    ethdb.RoKV
+	receiptsSnapshot ethdb.RoKV
+	mtx              sync.RWMutex
 }
 
 func (s *SnapshotKV) View(ctx context.Context, f func(tx ethdb.Tx) error) error {
@@ -116,6 +124,9 @@ func (s *SnapshotKV) Close() {
 	if s.stateSnapshot != nil {
 		defer s.stateSnapshot.Close()
 	}
+	if s.receiptsSnapshot != nil {
+		defer s.receiptsSnapshot.Close()
+	}
 }
 
 func (s *SnapshotKV) UpdateSnapshots(tp string, snapshotKV ethdb.RoKV, done chan struct{}) {
@@ -132,6 +143,9 @@ func (s *SnapshotKV) UpdateSnapshots(tp string, snapshotKV ethdb.RoKV, done chan
 	case tp == "state":
 		toClose = s.stateSnapshot
 		s.stateSnapshot = snapshotKV
+	case tp == "receipts":
+		toClose = s.receiptsSnapshot
+		s.receiptsSnapshot = snapshotKV
 	default:
 		log.Error("incorrect type", "tp", tp)
 	}
@@ -159,9 +173,12 @@ func (s *SnapshotKV) BodiesSnapshot() ethdb.RoKV {
 func (s *SnapshotKV) StateSnapshot() ethdb.RoKV {
 	return s.stateSnapshot
 }
+func (s *SnapshotKV) ReceiptsSnapshot() ethdb.RoKV {
+	return s.receiptsSnapshot
+}
 
-func (s *SnapshotKV) snapsthotsTx(ctx context.Context) (ethdb.Tx, ethdb.Tx, ethdb.Tx, error) {
-	var headersTX, bodiesTX, stateTX ethdb.Tx
+func (s *SnapshotKV) snapsthotsTx(ctx context.Context) (ethdb.Tx, ethdb.Tx, ethdb.Tx, ethdb.Tx, error) {
+	var headersTX, bodiesTX, stateTX, receiptsTX ethdb.Tx
 	var err error
 	defer func() {
 		if err != nil {
@@ -174,42 +191,52 @@ func (s *SnapshotKV) snapsthotsTx(ctx context.Context) (ethdb.Tx, ethdb.Tx, ethd
 			if stateTX != nil {
 				stateTX.Rollback()
 			}
+			if receiptsTX != nil {
+				receiptsTX.Rollback()
+			}
 		}
 	}()
 	if s.headersSnapshot != nil {
 		headersTX, err = s.headersSnapshot.BeginRo(ctx)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 	}
 	if s.bodiesSnapshot != nil {
 		bodiesTX, err = s.bodiesSnapshot.BeginRo(ctx)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 	}
 	if s.stateSnapshot != nil {
 		stateTX, err = s.stateSnapshot.BeginRo(ctx)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 	}
-	return headersTX, bodiesTX, stateTX, nil
+	if s.receiptsSnapshot != nil {
+		receiptsTX, err = s.receiptsSnapshot.BeginRo(ctx)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+	return headersTX, bodiesTX, stateTX, receiptsTX, nil
 }
 func (s *SnapshotKV) BeginRo(ctx context.Context) (ethdb.Tx, error) {
 	dbTx, err := s.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
 	}
-	headersTX, bodiesTX, stateTX, err := s.snapsthotsTx(ctx)
+	headersTX, bodiesTX, stateTX, receiptsTX, err := s.snapsthotsTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return &snTX{
-		dbTX:      dbTx,
-		headersTX: headersTX,
-		bodiesTX:  bodiesTX,
-		stateTX:   stateTX,
+		dbTX:       dbTx,
+		headersTX:  headersTX,
+		bodiesTX:   bodiesTX,
+		stateTX:    stateTX,
+		receiptsTX: receiptsTX,
 	}, nil
 }
 
@@ -219,16 +246,17 @@ func (s *SnapshotKV) BeginRw(ctx context.Context) (ethdb.RwTx, error) {
 		return nil, err
 	}
 
-	headersTX, bodiesTX, stateTX, err := s.snapsthotsTx(ctx)
+	headersTX, bodiesTX, stateTX, receiptsTX, err := s.snapsthotsTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	return &snTX{
-		dbTX:      dbTx,
-		headersTX: headersTX,
-		bodiesTX:  bodiesTX,
-		stateTX:   stateTX,
+		dbTX:       dbTx,
+		headersTX:  headersTX,
+		bodiesTX:   bodiesTX,
+		stateTX:    stateTX,
+		receiptsTX: receiptsTX,
 	}, nil
 }
 
@@ -239,10 +267,11 @@ func (s *SnapshotKV) AllBuckets() dbutils.BucketsCfg {
 var ErrUnavailableSnapshot = errors.New("unavailable snapshot")
 
 type snTX struct {
-	dbTX      ethdb.Tx
-	headersTX ethdb.Tx
-	bodiesTX  ethdb.Tx
-	stateTX   ethdb.Tx
+	dbTX       ethdb.Tx
+	headersTX  ethdb.Tx
+	bodiesTX   ethdb.Tx
+	stateTX    ethdb.Tx
+	receiptsTX ethdb.Tx
 }
 
 type DBTX interface {
@@ -252,6 +281,16 @@ type DBTX interface {
 func (s *snTX) DBTX() ethdb.RwTx {
 	return s.dbTX.(ethdb.RwTx)
 }
+
+type SnapshotTX interface {
+	// HasSnapshot - snapshot with the bucket is mounted
+	HasSnapshot(bucket string) bool
+}
+
+func (s *snTX) HasSnapshot(bucket string) bool {
+	_, err := s.getSnapshotTX(bucket)
+	return err == nil
+}
 func (s *snTX) RwCursor(bucket string) (ethdb.RwCursor, error) {
 	tx, err := s.getSnapshotTX(bucket)
 	if err != nil && !errors.Is(err, ErrUnavailableSnapshot) {
@@ -405,6 +444,8 @@ func (s *snTX) getSnapshotTX(bucket string) (ethdb.Tx, error) {
 		tx = s.bodiesTX
 	case dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket, dbutils.CodeBucket:
 		tx = s.stateTX
+	case dbutils.BlockReceiptsPrefix, dbutils.Log:
+		tx = s.receiptsTX
 	}
 	if tx == nil {
 		return nil, fmt.Errorf("%s  %w", bucket, ErrUnavailableSnapshot)
@@ -511,6 +552,9 @@ func (s *snTX) snapshotsRollback() {
 	if s.stateTX != nil {
 		defer s.stateTX.Rollback()
 	}
+	if s.receiptsTX != nil {
+		defer s.receiptsTX.Rollback()
+	}
 }
 func (s *snTX) Rollback() {
 	defer s.snapshotsRollback()
@@ -2,12 +2,9 @@ package ethdb
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/ledgerwatch/erigon/common"
-	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/log"
 )
 
@@ -125,34 +122,6 @@ func MultiPut(tx RwTx, tuples ...[]byte) error {
 	return nil
 }
 
-// todo: return TEVM code and use it
-func GetCheckTEVM(db KVGetter) func(contractHash common.Hash) (bool, error) {
-	checked := map[common.Hash]struct{}{}
-	var ok bool
-
-	return func(contractHash common.Hash) (bool, error) {
-		if contractHash == (common.Hash{}) {
-			return true, nil
-		}
-
-		if _, ok = checked[contractHash]; ok {
-			return true, nil
-		}
-
-		ok, err := db.Has(dbutils.ContractTEVMCodeBucket, contractHash.Bytes())
-		if err != nil && !errors.Is(err, ErrKeyNotFound) {
-			return false, fmt.Errorf("can't check TEVM bucket by contract %q hash: %w",
-				contractHash.String(), err)
-		}
-
-		if !ok {
-			checked[contractHash] = struct{}{}
-		}
-
-		return ok, nil
-	}
-}
-
 func Bytesmask(fixedbits int) (fixedbytes int, mask byte) {
 	fixedbytes = (fixedbits + 7) / 8
 	shiftbits := fixedbits & 7
@@ -0,0 +1,71 @@
+package remotedbserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon/common"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CliqueAPIVersion
+// 1.0.0 - Proposals, Propose and Discard of the clique engine
+var CliqueAPIVersion = &types2.VersionReply{Major: 1, Minor: 0, Patch: 0}
+
+// CliqueBackend is the part of the clique engine which is controlled by the clique_ RPC namespace.
+// Signers and snapshots are read by rpcdaemon from the database, only the proposals
+// the node votes on live in the engine.
+type CliqueBackend interface {
+	Proposals() map[common.Address]bool
+	Propose(address common.Address, auth bool)
+	Discard(address common.Address)
+}
+
+type CliqueServer struct {
+	UnimplementedCLIQUEServer // must be embedded to have forward compatible implementations.
+
+	engine CliqueBackend
+}
+
+// NewCliqueServer - engine is nil when the node doesn't run clique, then all methods but Version fail
+func NewCliqueServer(engine CliqueBackend) *CliqueServer {
+	return &CliqueServer{engine: engine}
+}
+
+var errNotClique = errors.New("not supported, consensus engine is not clique")
+
+func (s *CliqueServer) Version(context.Context, *emptypb.Empty) (*types2.VersionReply, error) {
+	return CliqueAPIVersion, nil
+}
+
+func (s *CliqueServer) Proposals(context.Context, *emptypb.Empty) (*ProposalsReply, error) {
+	if s.engine == nil {
+		return nil, errNotClique
+	}
+	reply := &ProposalsReply{}
+	for address, auth := range s.engine.Proposals() {
+		reply.Proposals = append(reply.Proposals, &Proposal{Address: gointerfaces.ConvertAddressToH160(address), Auth: auth})
+	}
+	return reply, nil
+}
+
+func (s *CliqueServer) Propose(_ context.Context, req *Proposal) (*emptypb.Empty, error) {
+	if s.engine == nil {
+		return nil, errNotClique
+	}
+	if req.Address == nil {
+		return nil, errors.New("proposal without address")
+	}
+	s.engine.Propose(gointerfaces.ConvertH160toAddress(req.Address), req.Auth)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *CliqueServer) Discard(_ context.Context, req *types2.H160) (*emptypb.Empty, error) {
+	if s.engine == nil {
+		return nil, errNotClique
+	}
+	s.engine.Discard(gointerfaces.ConvertH160toAddress(req))
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,61 @@
+package remotedbserver
+
+import (
+	"context"
+	"errors"
+
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// DevAPIVersion
+// 1.0.0 - Mine and IncreaseTime of the instant sealing developer chain
+var DevAPIVersion = &types2.VersionReply{Major: 1, Minor: 0, Patch: 0}
+
+// DevBackend is the miner of the instant sealing developer chain, controlled by the evm_ RPC namespace
+type DevBackend interface {
+	// Mine a block now, with or without transactions. Returns its number once it is in the chain.
+	Mine(ctx context.Context) (uint64, error)
+	// IncreaseTime moves the clock of the chain forward. Returns the total offset in seconds.
+	IncreaseTime(seconds uint64) (uint64, error)
+}
+
+type DevServer struct {
+	UnimplementedDEVServer // must be embedded to have forward compatible implementations.
+
+	miner DevBackend
+}
+
+// NewDevServer - miner is nil unless the node mines the developer chain with instant sealing,
+// then all methods but Version fail
+func NewDevServer(miner DevBackend) *DevServer {
+	return &DevServer{miner: miner}
+}
+
+var errNotInstantSeal = errors.New("not supported, node doesn't mine developer chain with instant sealing (--chain dev --dev.period 0 --dev.instantseal --mine)")
+
+func (s *DevServer) Version(context.Context, *emptypb.Empty) (*types2.VersionReply, error) {
+	return DevAPIVersion, nil
+}
+
+func (s *DevServer) Mine(ctx context.Context, _ *emptypb.Empty) (*MineReply, error) {
+	if s.miner == nil {
+		return nil, errNotInstantSeal
+	}
+	number, err := s.miner.Mine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MineReply{BlockNumber: number}, nil
+}
+
+func (s *DevServer) IncreaseTime(_ context.Context, req *IncreaseTimeRequest) (*IncreaseTimeReply, error) {
+	if s.miner == nil {
+		return nil, errNotInstantSeal
+	}
+	offset, err := s.miner.IncreaseTime(req.GetSeconds())
+	if err != nil {
+		return nil, err
+	}
+	return &IncreaseTimeReply{Offset: offset}, nil
+}
@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: remote/dev.proto
+
+package remotedbserver
+
+import (
+	types "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MineReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockNumber uint64 `protobuf:"varint,1,opt,name=blockNumber,proto3" json:"blockNumber,omitempty"`
+}
+
+func (x *MineReply) Reset() {
+	*x = MineReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_dev_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MineReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MineReply) ProtoMessage() {}
+
+func (x *MineReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_dev_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MineReply.ProtoReflect.Descriptor instead.
+func (*MineReply) Descriptor() ([]byte, []int) {
+	return file_remote_dev_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MineReply) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+type IncreaseTimeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Seconds uint64 `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+}
+
+func (x *IncreaseTimeRequest) Reset() {
+	*x = IncreaseTimeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_dev_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IncreaseTimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncreaseTimeRequest) ProtoMessage() {}
+
+func (x *IncreaseTimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_dev_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncreaseTimeRequest.ProtoReflect.Descriptor instead.
+func (*IncreaseTimeRequest) Descriptor() ([]byte, []int) {
+	return file_remote_dev_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IncreaseTimeRequest) GetSeconds() uint64 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+// total offset of the clock in seconds
+type IncreaseTimeReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Offset uint64 `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *IncreaseTimeReply) Reset() {
+	*x = IncreaseTimeReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_dev_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IncreaseTimeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncreaseTimeReply) ProtoMessage() {}
+
+func (x *IncreaseTimeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_dev_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncreaseTimeReply.ProtoReflect.Descriptor instead.
+func (*IncreaseTimeReply) Descriptor() ([]byte, []int) {
+	return file_remote_dev_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IncreaseTimeReply) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+var File_remote_dev_proto protoreflect.FileDescriptor
+
+var file_remote_dev_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x64, 0x65, 0x76, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74,
+	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2f, 0x74,
+	0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2d, 0x0a, 0x09, 0x4d, 0x69,
+	0x6e, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x2f, 0x0a, 0x13, 0x49, 0x6e, 0x63,
+	0x72, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x2b, 0x0a, 0x11, 0x49, 0x6e,
+	0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x32, 0xb8, 0x01, 0x0a, 0x03, 0x44, 0x45, 0x56, 0x12,
+	0x36, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x1a, 0x13, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x31, 0x0a, 0x04, 0x4d, 0x69, 0x6e, 0x65, 0x12,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x2e, 0x4d, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x46, 0x0a, 0x0c, 0x49, 0x6e,
+	0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1b, 0x2e, 0x72, 0x65, 0x6d,
+	0x6f, 0x74, 0x65, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2f, 0x65, 0x72, 0x69,
+	0x67, 0x6f, 0x6e, 0x2f, 0x65, 0x74, 0x68, 0x64, 0x62, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x64, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_remote_dev_proto_rawDescOnce sync.Once
+	file_remote_dev_proto_rawDescData = file_remote_dev_proto_rawDesc
+)
+
+func file_remote_dev_proto_rawDescGZIP() []byte {
+	file_remote_dev_proto_rawDescOnce.Do(func() {
+		file_remote_dev_proto_rawDescData = protoimpl.X.CompressGZIP(file_remote_dev_proto_rawDescData)
+	})
+	return file_remote_dev_proto_rawDescData
+}
+
+var file_remote_dev_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_remote_dev_proto_goTypes = []interface{}{
+	(*MineReply)(nil),           // 0: remote.MineReply
+	(*IncreaseTimeRequest)(nil), // 1: remote.IncreaseTimeRequest
+	(*IncreaseTimeReply)(nil),   // 2: remote.IncreaseTimeReply
+	(*emptypb.Empty)(nil),       // 3: google.protobuf.Empty
+	(*types.VersionReply)(nil),  // 4: types.VersionReply
+}
+var file_remote_dev_proto_depIdxs = []int32{
+	3, // 0: remote.DEV.Version:input_type -> google.protobuf.Empty
+	3, // 1: remote.DEV.Mine:input_type -> google.protobuf.Empty
+	1, // 2: remote.DEV.IncreaseTime:input_type -> remote.IncreaseTimeRequest
+	4, // 3: remote.DEV.Version:output_type -> types.VersionReply
+	0, // 4: remote.DEV.Mine:output_type -> remote.MineReply
+	2, // 5: remote.DEV.IncreaseTime:output_type -> remote.IncreaseTimeReply
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_remote_dev_proto_init() }
+func file_remote_dev_proto_init() {
+	if File_remote_dev_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_remote_dev_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MineReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_dev_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IncreaseTimeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_dev_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IncreaseTimeReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_remote_dev_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remote_dev_proto_goTypes,
+		DependencyIndexes: file_remote_dev_proto_depIdxs,
+		MessageInfos:      file_remote_dev_proto_msgTypes,
+	}.Build()
+	File_remote_dev_proto = out.File
+	file_remote_dev_proto_rawDesc = nil
+	file_remote_dev_proto_goTypes = nil
+	file_remote_dev_proto_depIdxs = nil
+}
@@ -0,0 +1,110 @@
+package remotedbserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rlp"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// EngineAPIVersion
+// 1.0.0 - ExecutePayload, ForkchoiceUpdated and AssembleBlock of the chain driven by a consensus client
+var EngineAPIVersion = &types2.VersionReply{Major: 1, Minor: 0, Patch: 0}
+
+// PayloadStatus - validity of the payload executed by EngineBackend.ExecutePayload
+type PayloadStatus string
+
+const (
+	PayloadValid   PayloadStatus = "VALID"
+	PayloadInvalid PayloadStatus = "INVALID"
+	PayloadSyncing PayloadStatus = "SYNCING" // parent of the payload is unknown, the payload is not executed
+)
+
+// EngineBackend - execution side of the chain driven by a consensus client (see --beacon), used by the engine_ RPC namespace
+type EngineBackend interface {
+	// ExecutePayload inserts the block through the staged sync, the head of the chain is left for ForkchoiceUpdated
+	ExecutePayload(ctx context.Context, block *types.Block) (PayloadStatus, error)
+	// ForkchoiceUpdated makes the known block the head of the chain, unwinding if necessary, and marks the finalized block.
+	// Zero finalized hash keeps the finalized block.
+	ForkchoiceUpdated(ctx context.Context, head, finalized common.Hash) error
+	// AssembleBlock builds a sealed block on top of the head of the chain with the mining stages. The block is not inserted.
+	AssembleBlock(ctx context.Context, parent common.Hash, timestamp uint64, feeRecipient common.Address) (*types.Block, error)
+}
+
+type EngineServer struct {
+	UnimplementedENGINEServer // must be embedded to have forward compatible implementations.
+
+	backend EngineBackend
+}
+
+// NewEngineServer - backend is nil unless the chain is driven by a consensus client,
+// then all methods but Version fail
+func NewEngineServer(backend EngineBackend) *EngineServer {
+	return &EngineServer{backend: backend}
+}
+
+var errNotBeacon = errors.New("not supported, chain of the node is not driven by a consensus client (--beacon)")
+
+func (s *EngineServer) Version(context.Context, *emptypb.Empty) (*types2.VersionReply, error) {
+	return EngineAPIVersion, nil
+}
+
+func (s *EngineServer) ExecutePayload(ctx context.Context, req *ExecutePayloadRequest) (*ExecutePayloadReply, error) {
+	if s.backend == nil {
+		return nil, errNotBeacon
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(req.GetBlock(), block); err != nil {
+		return nil, err
+	}
+	status, err := s.backend.ExecutePayload(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	executionStatus, ok := ExecutionStatus_value[string(status)]
+	if !ok {
+		return nil, fmt.Errorf("unknown payload status %s", status)
+	}
+	return &ExecutePayloadReply{Status: ExecutionStatus(executionStatus)}, nil
+}
+
+func (s *EngineServer) ForkchoiceUpdated(ctx context.Context, req *ForkchoiceUpdatedRequest) (*emptypb.Empty, error) {
+	if s.backend == nil {
+		return nil, errNotBeacon
+	}
+	if req.Head == nil {
+		return nil, errors.New("forkchoice without head")
+	}
+	var finalized common.Hash
+	if req.Finalized != nil {
+		finalized = gointerfaces.ConvertH256ToHash(req.Finalized)
+	}
+	if err := s.backend.ForkchoiceUpdated(ctx, gointerfaces.ConvertH256ToHash(req.Head), finalized); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *EngineServer) AssembleBlock(ctx context.Context, req *AssembleBlockRequest) (*AssembleBlockReply, error) {
+	if s.backend == nil {
+		return nil, errNotBeacon
+	}
+	if req.Parent == nil || req.FeeRecipient == nil {
+		return nil, errors.New("assemble request without parent or fee recipient")
+	}
+	block, err := s.backend.AssembleBlock(ctx, gointerfaces.ConvertH256ToHash(req.Parent), req.Timestamp, gointerfaces.ConvertH160toAddress(req.FeeRecipient))
+	if err != nil {
+		return nil, err
+	}
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AssembleBlockReply{Block: data}, nil
+}
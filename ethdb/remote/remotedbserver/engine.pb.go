@@ -0,0 +1,530 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: remote/engine.proto
+
+package remotedbserver
+
+import (
+	types "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ExecutionStatus int32
+
+const (
+	ExecutionStatus_VALID   ExecutionStatus = 0
+	ExecutionStatus_INVALID ExecutionStatus = 1
+	ExecutionStatus_SYNCING ExecutionStatus = 2 // parent of the payload is unknown, the payload is not executed
+)
+
+// Enum value maps for ExecutionStatus.
+var (
+	ExecutionStatus_name = map[int32]string{
+		0: "VALID",
+		1: "INVALID",
+		2: "SYNCING",
+	}
+	ExecutionStatus_value = map[string]int32{
+		"VALID":   0,
+		"INVALID": 1,
+		"SYNCING": 2,
+	}
+)
+
+func (x ExecutionStatus) Enum() *ExecutionStatus {
+	p := new(ExecutionStatus)
+	*p = x
+	return p
+}
+
+func (x ExecutionStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExecutionStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_engine_proto_enumTypes[0].Descriptor()
+}
+
+func (ExecutionStatus) Type() protoreflect.EnumType {
+	return &file_remote_engine_proto_enumTypes[0]
+}
+
+func (x ExecutionStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExecutionStatus.Descriptor instead.
+func (ExecutionStatus) EnumDescriptor() ([]byte, []int) {
+	return file_remote_engine_proto_rawDescGZIP(), []int{0}
+}
+
+// RLP of the block
+type ExecutePayloadRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Block []byte `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+func (x *ExecutePayloadRequest) Reset() {
+	*x = ExecutePayloadRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_engine_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecutePayloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutePayloadRequest) ProtoMessage() {}
+
+func (x *ExecutePayloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_engine_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutePayloadRequest.ProtoReflect.Descriptor instead.
+func (*ExecutePayloadRequest) Descriptor() ([]byte, []int) {
+	return file_remote_engine_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExecutePayloadRequest) GetBlock() []byte {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+type ExecutePayloadReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status ExecutionStatus `protobuf:"varint,1,opt,name=status,proto3,enum=remote.ExecutionStatus" json:"status,omitempty"`
+}
+
+func (x *ExecutePayloadReply) Reset() {
+	*x = ExecutePayloadReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_engine_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecutePayloadReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutePayloadReply) ProtoMessage() {}
+
+func (x *ExecutePayloadReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_engine_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutePayloadReply.ProtoReflect.Descriptor instead.
+func (*ExecutePayloadReply) Descriptor() ([]byte, []int) {
+	return file_remote_engine_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExecutePayloadReply) GetStatus() ExecutionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ExecutionStatus_VALID
+}
+
+type ForkchoiceUpdatedRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Head      *types.H256 `protobuf:"bytes,1,opt,name=head,proto3" json:"head,omitempty"`
+	Finalized *types.H256 `protobuf:"bytes,2,opt,name=finalized,proto3" json:"finalized,omitempty"` // zero keeps the finalized block
+}
+
+func (x *ForkchoiceUpdatedRequest) Reset() {
+	*x = ForkchoiceUpdatedRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_engine_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForkchoiceUpdatedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForkchoiceUpdatedRequest) ProtoMessage() {}
+
+func (x *ForkchoiceUpdatedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_engine_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForkchoiceUpdatedRequest.ProtoReflect.Descriptor instead.
+func (*ForkchoiceUpdatedRequest) Descriptor() ([]byte, []int) {
+	return file_remote_engine_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ForkchoiceUpdatedRequest) GetHead() *types.H256 {
+	if x != nil {
+		return x.Head
+	}
+	return nil
+}
+
+func (x *ForkchoiceUpdatedRequest) GetFinalized() *types.H256 {
+	if x != nil {
+		return x.Finalized
+	}
+	return nil
+}
+
+type AssembleBlockRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Parent       *types.H256 `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Timestamp    uint64      `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	FeeRecipient *types.H160 `protobuf:"bytes,3,opt,name=feeRecipient,proto3" json:"feeRecipient,omitempty"`
+}
+
+func (x *AssembleBlockRequest) Reset() {
+	*x = AssembleBlockRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_engine_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssembleBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssembleBlockRequest) ProtoMessage() {}
+
+func (x *AssembleBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_engine_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssembleBlockRequest.ProtoReflect.Descriptor instead.
+func (*AssembleBlockRequest) Descriptor() ([]byte, []int) {
+	return file_remote_engine_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AssembleBlockRequest) GetParent() *types.H256 {
+	if x != nil {
+		return x.Parent
+	}
+	return nil
+}
+
+func (x *AssembleBlockRequest) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *AssembleBlockRequest) GetFeeRecipient() *types.H160 {
+	if x != nil {
+		return x.FeeRecipient
+	}
+	return nil
+}
+
+// RLP of the block
+type AssembleBlockReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Block []byte `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+func (x *AssembleBlockReply) Reset() {
+	*x = AssembleBlockReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_engine_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssembleBlockReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssembleBlockReply) ProtoMessage() {}
+
+func (x *AssembleBlockReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_engine_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssembleBlockReply.ProtoReflect.Descriptor instead.
+func (*AssembleBlockReply) Descriptor() ([]byte, []int) {
+	return file_remote_engine_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AssembleBlockReply) GetBlock() []byte {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+var File_remote_engine_proto protoreflect.FileDescriptor
+
+var file_remote_engine_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x1a, 0x1b, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65,
+	0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2d, 0x0a,
+	0x15, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x46, 0x0a, 0x13,
+	0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x45, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x22, 0x66, 0x0a, 0x18, 0x46, 0x6f, 0x72, 0x6b, 0x63, 0x68, 0x6f, 0x69,
+	0x63, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1f, 0x0a, 0x04, 0x68, 0x65, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35, 0x36, 0x52, 0x04, 0x68, 0x65, 0x61,
+	0x64, 0x12, 0x29, 0x0a, 0x09, 0x66, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32, 0x35,
+	0x36, 0x52, 0x09, 0x66, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x22, 0x8a, 0x01, 0x0a,
+	0x14, 0x41, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x32,
+	0x35, 0x36, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x2f, 0x0a, 0x0c, 0x66, 0x65, 0x65, 0x52,
+	0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x31, 0x36, 0x30, 0x52, 0x0c, 0x66, 0x65, 0x65,
+	0x52, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x2a, 0x0a, 0x12, 0x41, 0x73, 0x73,
+	0x65, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2a, 0x36, 0x0a, 0x0f, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x09, 0x0a, 0x05, 0x56, 0x41, 0x4c, 0x49,
+	0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x01,
+	0x12, 0x0b, 0x0a, 0x07, 0x53, 0x59, 0x4e, 0x43, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x32, 0xa8, 0x02,
+	0x0a, 0x06, 0x45, 0x4e, 0x47, 0x49, 0x4e, 0x45, 0x12, 0x36, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x4c, 0x0a, 0x0e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f,
+	0x61, 0x64, 0x12, 0x1d, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x45, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75,
+	0x74, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x4d,
+	0x0a, 0x11, 0x46, 0x6f, 0x72, 0x6b, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x12, 0x20, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x46, 0x6f, 0x72,
+	0x6b, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x49, 0x0a,
+	0x0d, 0x41, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1c,
+	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x77, 0x61, 0x74,
+	0x63, 0x68, 0x2f, 0x65, 0x72, 0x69, 0x67, 0x6f, 0x6e, 0x2f, 0x65, 0x74, 0x68, 0x64, 0x62, 0x2f,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x64, 0x62, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_remote_engine_proto_rawDescOnce sync.Once
+	file_remote_engine_proto_rawDescData = file_remote_engine_proto_rawDesc
+)
+
+func file_remote_engine_proto_rawDescGZIP() []byte {
+	file_remote_engine_proto_rawDescOnce.Do(func() {
+		file_remote_engine_proto_rawDescData = protoimpl.X.CompressGZIP(file_remote_engine_proto_rawDescData)
+	})
+	return file_remote_engine_proto_rawDescData
+}
+
+var file_remote_engine_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_remote_engine_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_remote_engine_proto_goTypes = []interface{}{
+	(ExecutionStatus)(0),             // 0: remote.ExecutionStatus
+	(*ExecutePayloadRequest)(nil),    // 1: remote.ExecutePayloadRequest
+	(*ExecutePayloadReply)(nil),      // 2: remote.ExecutePayloadReply
+	(*ForkchoiceUpdatedRequest)(nil), // 3: remote.ForkchoiceUpdatedRequest
+	(*AssembleBlockRequest)(nil),     // 4: remote.AssembleBlockRequest
+	(*AssembleBlockReply)(nil),       // 5: remote.AssembleBlockReply
+	(*types.H256)(nil),               // 6: types.H256
+	(*types.H160)(nil),               // 7: types.H160
+	(*emptypb.Empty)(nil),            // 8: google.protobuf.Empty
+	(*types.VersionReply)(nil),       // 9: types.VersionReply
+}
+var file_remote_engine_proto_depIdxs = []int32{
+	0, // 0: remote.ExecutePayloadReply.status:type_name -> remote.ExecutionStatus
+	6, // 1: remote.ForkchoiceUpdatedRequest.head:type_name -> types.H256
+	6, // 2: remote.ForkchoiceUpdatedRequest.finalized:type_name -> types.H256
+	6, // 3: remote.AssembleBlockRequest.parent:type_name -> types.H256
+	7, // 4: remote.AssembleBlockRequest.feeRecipient:type_name -> types.H160
+	8, // 5: remote.ENGINE.Version:input_type -> google.protobuf.Empty
+	1, // 6: remote.ENGINE.ExecutePayload:input_type -> remote.ExecutePayloadRequest
+	3, // 7: remote.ENGINE.ForkchoiceUpdated:input_type -> remote.ForkchoiceUpdatedRequest
+	4, // 8: remote.ENGINE.AssembleBlock:input_type -> remote.AssembleBlockRequest
+	9, // 9: remote.ENGINE.Version:output_type -> types.VersionReply
+	2, // 10: remote.ENGINE.ExecutePayload:output_type -> remote.ExecutePayloadReply
+	8, // 11: remote.ENGINE.ForkchoiceUpdated:output_type -> google.protobuf.Empty
+	5, // 12: remote.ENGINE.AssembleBlock:output_type -> remote.AssembleBlockReply
+	9, // [9:13] is the sub-list for method output_type
+	5, // [5:9] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_remote_engine_proto_init() }
+func file_remote_engine_proto_init() {
+	if File_remote_engine_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_remote_engine_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecutePayloadRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_engine_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecutePayloadReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_engine_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ForkchoiceUpdatedRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_engine_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AssembleBlockRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_engine_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AssembleBlockReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_remote_engine_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remote_engine_proto_goTypes,
+		DependencyIndexes: file_remote_engine_proto_depIdxs,
+		EnumInfos:         file_remote_engine_proto_enumTypes,
+		MessageInfos:      file_remote_engine_proto_msgTypes,
+	}.Build()
+	File_remote_engine_proto = out.File
+	file_remote_engine_proto_rawDesc = nil
+	file_remote_engine_proto_goTypes = nil
+	file_remote_engine_proto_depIdxs = nil
+}
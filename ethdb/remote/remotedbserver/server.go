@@ -1,5 +1,8 @@
 package remotedbserver
 
+// Services of the node which are not (yet) in erigon-lib interfaces, types.proto is taken from erigon-lib
+//go:generate sh -c "protoc --go_out=../../.. --go_opt=module=github.com/ledgerwatch/erigon --go_opt=Mtypes/types.proto=github.com/ledgerwatch/erigon-lib/gointerfaces/types --go-grpc_out=../../.. --go-grpc_opt=module=github.com/ledgerwatch/erigon --go-grpc_opt=Mtypes/types.proto=github.com/ledgerwatch/erigon-lib/gointerfaces/types --proto_path=../.. --proto_path=$(go list -m -f {{.Dir}} github.com/ledgerwatch/erigon-lib)/interfaces remote/clique.proto remote/dev.proto remote/engine.proto"
+
 import (
 	"context"
 	"fmt"
@@ -35,10 +38,11 @@ var KvServiceAPIVersion = &types.VersionReply{Major: 3, Minor: 0, Patch: 0}
 type KvServer struct {
 	remote.UnimplementedKVServer // must be embedded to have forward compatible implementations.
 
-	kv ethdb.RwKV
+	kv       ethdb.RwKV
+	cliqueKV ethdb.RoKV // separate db of the clique engine, see ServeCliqueSnapshots
 }
 
-func StartGrpc(kv *KvServer, ethBackendSrv *EthBackendServer, txPoolServer *TxPoolServer, miningServer *MiningServer, addr string, rateLimit uint32, creds *credentials.TransportCredentials) (*grpc.Server, error) {
+func StartGrpc(kv *KvServer, ethBackendSrv *EthBackendServer, txPoolServer *TxPoolServer, miningServer *MiningServer, cliqueServer *CliqueServer, devServer *DevServer, engineServer *EngineServer, addr string, rateLimit uint32, creds *credentials.TransportCredentials) (*grpc.Server, error) {
 	log.Info("Starting private RPC server", "on", addr)
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -82,6 +86,9 @@ func StartGrpc(kv *KvServer, ethBackendSrv *EthBackendServer, txPoolServer *TxPo
 	remote.RegisterETHBACKENDServer(grpcServer, ethBackendSrv)
 	txpool.RegisterTxpoolServer(grpcServer, txPoolServer)
 	txpool.RegisterMiningServer(grpcServer, miningServer)
+	RegisterCLIQUEServer(grpcServer, cliqueServer)
+	RegisterDEVServer(grpcServer, devServer)
+	RegisterENGINEServer(grpcServer, engineServer)
 	remote.RegisterKVServer(grpcServer, kv)
 
 	if metrics.Enabled {
@@ -101,6 +108,20 @@ func NewKvServer(kv ethdb.RwKV) *KvServer {
 	return &KvServer{kv: kv}
 }
 
+// ServeCliqueSnapshots makes the server read the buckets of clique snapshots from the database of the clique
+// engine, so remote clients (rpcdaemon) can serve clique_ methods. Must be called before StartGrpc.
+func (s *KvServer) ServeCliqueSnapshots(cliqueKV ethdb.RoKV) {
+	s.cliqueKV = cliqueKV
+}
+
+func isCliqueBucket(bucket string) bool {
+	switch bucket {
+	case dbutils.CliqueSeparateBucket, dbutils.CliqueSnapshotBucket, dbutils.CliqueLastSnapshotBucket:
+		return true
+	}
+	return false
+}
+
 // Version returns the service-side interface version number
 func (s *KvServer) Version(context.Context, *emptypb.Empty) (*types.VersionReply, error) {
 	dbSchemaVersion := &dbutils.DBSchemaVersion
@@ -124,10 +145,26 @@ func (s *KvServer) Tx(stream remote.KV_TxServer) error {
 	if errBegin != nil {
 		return fmt.Errorf("server-side error: %w", errBegin)
 	}
+	var cliqueTx ethdb.Tx // opened by the first cursor of a clique bucket
 	rollback := func() {
 		tx.Rollback()
+		if cliqueTx != nil {
+			cliqueTx.Rollback()
+		}
 	}
 	defer rollback()
+	txOf := func(bucket string) (ethdb.Tx, error) {
+		if s.cliqueKV == nil || !isCliqueBucket(bucket) {
+			return tx, nil
+		}
+		if cliqueTx == nil {
+			var err error
+			if cliqueTx, err = s.cliqueKV.BeginRo(stream.Context()); err != nil {
+				return nil, fmt.Errorf("server-side error, BeginRo of clique db: %w", err)
+			}
+		}
+		return cliqueTx, nil
+	}
 
 	var CursorID uint32
 	type CursorInfo struct {
@@ -164,14 +201,21 @@ func (s *KvServer) Tx(stream remote.KV_TxServer) error {
 			}
 
 			tx.Rollback()
+			if cliqueTx != nil {
+				cliqueTx.Rollback()
+				cliqueTx = nil
+			}
 			tx, errBegin = s.kv.BeginRo(stream.Context())
 			if errBegin != nil {
 				return fmt.Errorf("server-side error, BeginRo: %w", errBegin)
 			}
 
 			for _, c := range cursors { // restore all cursors position
-				var err error
-				c.c, err = tx.Cursor(c.bucket)
+				cTx, err := txOf(c.bucket)
+				if err != nil {
+					return err
+				}
+				c.c, err = cTx.Cursor(c.bucket)
 				if err != nil {
 					return err
 				}
@@ -207,8 +251,11 @@ func (s *KvServer) Tx(stream remote.KV_TxServer) error {
 		switch in.Op {
 		case remote.Op_OPEN:
 			CursorID++
-			var err error
-			c, err = tx.Cursor(in.BucketName)
+			cTx, err := txOf(in.BucketName)
+			if err != nil {
+				return err
+			}
+			c, err = cTx.Cursor(in.BucketName)
 			if err != nil {
 				return err
 			}
@@ -17,13 +17,13 @@
 // Package ethstats implements the network stats reporting service.
 package ethstats
 
-/*
 import (
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"regexp"
 	"runtime"
@@ -33,19 +33,21 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/mclock"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
-	"github.com/ledgerwatch/erigon/eth"
-	"github.com/ledgerwatch/erigon/eth/downloader"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
 	"github.com/ledgerwatch/erigon/event"
 	"github.com/ledgerwatch/erigon/log"
-	"github.com/ledgerwatch/erigon/miner"
 	"github.com/ledgerwatch/erigon/node"
-	"github.com/ledgerwatch/erigon/p2p"
-	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/remote"
 )
 
 const (
@@ -56,45 +58,36 @@ const (
 	// txChanSize is the size of channel listening to NewTxsEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
-	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	// chainHeadChanSize is the size of channel listening to the headers finished by the stage loop.
 	chainHeadChanSize = 10
 )
 
-// backend encompasses the bare-minimum functionality needed for ethstats reporting
-type backend interface {
-	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
-	SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription
-	CurrentHeader() *types.Header
-	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
-	GetTd(ctx context.Context, hash common.Hash) *big.Int
+// txPool encompasses the functionality of the transaction pool needed for ethstats reporting
+type txPool interface {
 	Stats() (pending int, queued int)
-	Downloader() *downloader.Downloader
-}
-
-// fullNodeBackend encompasses the functionality necessary for a full node
-// reporting to ethstats
-type fullNodeBackend interface {
-	backend
-	Miner() *miner.Miner
-	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error)
-	CurrentBlock() *types.Block
-	SuggestPrice(ctx context.Context) (*big.Int, error)
+	SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription
 }
 
 // Service implements an Ethereum netstats reporting daemon that pushes local
 // chain statistics up to a monitoring server.
 type Service struct {
-	server  *p2p.Server // Peer-to-peer server to retrieve networking infos
-	backend backend
-	engine  consensus.Engine // Consensus engine to retrieve variadic block fields
-
-	node string // Name of the node to display on the monitoring page
-	pass string // Password to authorize access to the monitoring page
-	host string // Remote address of the monitoring service
-
-	pongCh chan struct{} // Pong notifications are fed into this channel
-	histCh chan []uint64 // History request block numbers are fed into this channel
-
+	sentries  []remote.SentryClient // Sentries to retrieve networking infos
+	chainDB   ethdb.RoKV            // Database to read the finished blocks from
+	engine    consensus.Engine      // Consensus engine to retrieve variadic block fields
+	txPool    txPool                // Transaction pool to retrieve the pending transactions count
+	mining    *params.MiningConfig  // Miner settings to report the mining status and gas price
+	networkID uint64
+
+	node     string // Name of the node to display on the monitoring page
+	pass     string // Password to authorize access to the monitoring page
+	host     string // Remote address of the monitoring service
+	nodeName string // Name of the client reported on login
+	port     int    // Listening port of the node reported on login
+
+	headCh chan *types.Header // Headers finished by the stage loop are fed into this channel
+	pongCh chan struct{}      // Pong notifications are fed into this channel
+	histCh chan []uint64      // History request block numbers are fed into this channel
+	quitCh chan struct{}      // Closed when the service stops
 }
 
 // connWrapper is a wrapper to prevent concurrent-write or concurrent-read on the
@@ -142,27 +135,54 @@ func (w *connWrapper) Close() error {
 	return w.conn.Close()
 }
 
-// New returns a monitoring service ready for stats reporting.
-func New(node *node.Node, backend backend, engine consensus.Engine, url string) error {
+// New returns a monitoring service ready for stats reporting. Blocks are reported when the Finish stage of
+// the stage loop notifies about them, pending transactions come from the txpool and peers from the sentries.
+func New(node *node.Node, sentries []remote.SentryClient, chainDB ethdb.RoKV, engine consensus.Engine, txPool txPool, mining *params.MiningConfig, events *remotedbserver.Events, networkID uint64, url string) error {
+	var port int
+	if _, p, err := net.SplitHostPort(node.Config().P2P.ListenAddr); err == nil {
+		port, _ = strconv.Atoi(p)
+	}
+	ethstats, err := newService(node.Config().NodeName(), port, sentries, chainDB, engine, txPool, mining, events, networkID, url)
+	if err != nil {
+		return err
+	}
+	node.RegisterLifecycle(ethstats)
+	return nil
+}
+
+func newService(nodeName string, port int, sentries []remote.SentryClient, chainDB ethdb.RoKV, engine consensus.Engine, txPool txPool, mining *params.MiningConfig, events *remotedbserver.Events, networkID uint64, url string) (*Service, error) {
 	// Parse the netstats connection url
 	re := regexp.MustCompile("([^:@]*)(:([^@]*))?@(.+)")
 	parts := re.FindStringSubmatch(url)
 	if len(parts) != 5 {
-		return fmt.Errorf("invalid netstats url: \"%s\", should be nodename:secret@host:port", url)
+		return nil, fmt.Errorf("invalid netstats url: \"%s\", should be nodename:secret@host:port", url)
 	}
 	ethstats := &Service{
-		backend: backend,
-		engine:  engine,
-		server:  node.Server(),
-		node:    parts[1],
-		pass:    parts[3],
-		host:    parts[4],
-		pongCh:  make(chan struct{}),
-		histCh:  make(chan []uint64, 1),
-	}
-
-	node.RegisterLifecycle(ethstats)
-	return nil
+		sentries:  sentries,
+		chainDB:   chainDB,
+		engine:    engine,
+		txPool:    txPool,
+		mining:    mining,
+		networkID: networkID,
+		node:      parts[1],
+		pass:      parts[3],
+		host:      parts[4],
+		nodeName:  nodeName,
+		port:      port,
+		headCh:    make(chan *types.Header, chainHeadChanSize),
+		pongCh:    make(chan struct{}),
+		histCh:    make(chan []uint64, 1),
+		quitCh:    make(chan struct{}),
+	}
+	events.AddHeaderSubscription(func(h *types.Header) error {
+		// Drop the header if reporting lags behind, the subscription must not block the stage loop
+		select {
+		case ethstats.headCh <- h:
+		default:
+		}
+		return nil
+	})
+	return ethstats, nil
 }
 
 // Start implements node.Lifecycle, starting up the monitoring and reporting daemon.
@@ -175,6 +195,7 @@ func (s *Service) Start() error {
 
 // Stop implements node.Lifecycle, terminating the monitoring and reporting daemon.
 func (s *Service) Stop() error {
+	close(s.quitCh)
 	log.Info("Stats daemon stopped")
 	return nil
 }
@@ -182,19 +203,15 @@ func (s *Service) Stop() error {
 // loop keeps trying to connect to the netstats server, reporting chain events
 // until termination.
 func (s *Service) loop() {
-	// Subscribe to chain events to execute updates on
-	chainHeadCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
-	headSub := s.backend.SubscribeChainHeadEvent(chainHeadCh)
-	defer headSub.Unsubscribe()
-
+	// Subscribe to pending transactions to execute updates on
 	txEventCh := make(chan core.NewTxsEvent, txChanSize)
-	txSub := s.backend.SubscribeNewTxsEvent(txEventCh)
+	txSub := s.txPool.SubscribeNewTxsEvent(txEventCh)
 	defer txSub.Unsubscribe()
 
 	// Start a goroutine that exhausts the subscriptions to avoid events piling up
 	var (
 		quitCh = make(chan struct{})
-		headCh = make(chan *types.Block, 1)
+		headCh = make(chan *types.Header, 1)
 		txCh   = make(chan struct{}, 1)
 	)
 	go func() {
@@ -203,12 +220,14 @@ func (s *Service) loop() {
 	HandleLoop:
 		for {
 			select {
-			// Notify of chain head events, but drop if too frequent
-			case head := <-chainHeadCh:
+			// Notify of finished headers, but replace the unreported one if too frequent:
+			// the Finish stage notifies about all the blocks of the cycle at once
+			case head := <-s.headCh:
 				select {
-				case headCh <- head.Block:
+				case <-headCh:
 				default:
 				}
+				headCh <- head
 
 			// Notify of new transaction events, but drop if too frequent
 			case <-txEventCh:
@@ -225,7 +244,7 @@ func (s *Service) loop() {
 			// node stopped
 			case <-txSub.Err():
 				break HandleLoop
-			case <-headSub.Err():
+			case <-s.quitCh:
 				break HandleLoop
 			}
 		}
@@ -442,23 +461,17 @@ type authMsg struct {
 // login tries to authorize the client at the remote server.
 func (s *Service) login(conn *connWrapper) error {
 	// Construct and send the login authentication
-	infos := s.server.NodeInfo()
-
 	var protocols []string
-	for _, proto := range s.server.Protocols {
-		protocols = append(protocols, fmt.Sprintf("%s/%d", proto.Name, proto.Version))
-	}
-	var network string
-	if info := infos.Protocols["eth"]; info != nil {
-		network = fmt.Sprintf("%d", info.(*ethproto.NodeInfo).Network)
+	for _, sentry := range s.sentries {
+		protocols = append(protocols, fmt.Sprintf("eth/%d", sentry.Protocol()))
 	}
 	auth := &authMsg{
 		ID: s.node,
 		Info: nodeInfo{
 			Name:     s.node,
-			Node:     infos.Name,
-			Port:     infos.Ports.Listener,
-			Network:  network,
+			Node:     s.nodeName,
+			Port:     s.port,
+			Network:  strconv.FormatUint(s.networkID, 10),
 			Protocol: strings.Join(protocols, ", "),
 			API:      "No",
 			Os:       runtime.GOOS,
@@ -571,10 +584,27 @@ func (s uncleStats) MarshalJSON() ([]byte, error) {
 	return []byte("[]"), nil
 }
 
-// reportBlock retrieves the current chain head and reports it to the stats server.
-func (s *Service) reportBlock(conn *connWrapper, block *types.Block) error {
-	// Gather the block details from the header or block chain
-	details := s.assembleBlockStats(block)
+// reportBlock retrieves the block of the header (or the current chain head if the header is nil)
+// and reports it to the stats server.
+func (s *Service) reportBlock(conn *connWrapper, header *types.Header) error {
+	// Gather the block details from the database
+	var details *blockStats
+	if err := s.chainDB.View(context.Background(), func(tx ethdb.Tx) error {
+		var block *types.Block
+		if header == nil {
+			block = rawdb.ReadCurrentBlock(tx)
+		} else if block = rawdb.ReadBlock(tx, header.Hash(), header.Number.Uint64()); block == nil {
+			block = types.NewBlockWithHeader(header)
+		}
+		if block == nil {
+			return errors.New("no head block")
+		}
+		var err error
+		details, err = s.assembleBlockStats(tx, block)
+		return err
+	}); err != nil {
+		return err
+	}
 
 	// Assemble the block report and send it to the server
 	log.Trace("Sending new block to ethstats", "number", details.Number, "hash", details.Hash)
@@ -590,30 +620,19 @@ func (s *Service) reportBlock(conn *connWrapper, block *types.Block) error {
 }
 
 // assembleBlockStats retrieves any required metadata to report a single block
-// and assembles the block stats. If block is nil, the current head is processed.
-func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
-	// Gather the block infos from the local blockchain
-	var (
-		header *types.Header
-		td     *big.Int
-		txs    []txStats
-		uncles []*types.Header
-	)
-
-	// check if backend is a full node
-	fullBackend, ok := s.backend.(fullNodeBackend)
-	if ok {
-		if block == nil {
-			block = fullBackend.CurrentBlock()
-		}
-		header = block.Header()
-		td = fullBackend.GetTd(context.Background(), header.Hash())
-
-		txs = make([]txStats, len(block.Transactions()))
-		for i, tx := range block.Transactions() {
-			txs[i].Hash = tx.Hash()
-		}
-		uncles = block.Uncles()
+// and assembles the block stats.
+func (s *Service) assembleBlockStats(tx ethdb.Tx, block *types.Block) (*blockStats, error) {
+	header := block.Header()
+	td, err := rawdb.ReadTd(tx, header.Hash(), header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if td == nil {
+		td = new(big.Int)
+	}
+	txs := make([]txStats, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		txs[i].Hash = tx.Hash()
 	}
 
 	// Assemble and return the block stats
@@ -632,51 +651,54 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		Txs:        txs,
 		TxHash:     header.TxHash,
 		Root:       header.Root,
-		Uncles:     uncles,
-	}
+		Uncles:     block.Uncles(),
+	}, nil
 }
 
 // reportHistory retrieves the most recent batch of blocks and reports it to the
 // stats server.
 func (s *Service) reportHistory(conn *connWrapper, list []uint64) error {
-	// Figure out the indexes that need reporting
-	indexes := make([]uint64, 0, historyUpdateRange)
-	if len(list) > 0 {
-		// Specific indexes requested, send them back in particular
-		indexes = append(indexes, list...)
-	} else {
-		// No indexes requested, send back the top ones
-		head := s.backend.CurrentHeader().Number.Int64()
-		start := head - historyUpdateRange + 1
-		if start < 0 {
-			start = 0
-		}
-		for i := uint64(start); i <= uint64(head); i++ {
-			indexes = append(indexes, i)
-		}
-	}
-	// Gather the batch of blocks to report
-	history := make([]*blockStats, len(indexes))
-	for i, number := range indexes {
-		fullBackend, ok := s.backend.(fullNodeBackend)
-		// Retrieve the next block if it's known to us
-		var block *types.Block
-		if ok {
-			block, _ = fullBackend.BlockByNumber(context.Background(), rpc.BlockNumber(number)) // TODO ignore error here ?
-		} else {
-			if header, _ := s.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(number)); header != nil {
-				block = types.NewBlockWithHeader(header)
+	var history []*blockStats
+	if err := s.chainDB.View(context.Background(), func(tx ethdb.Tx) error {
+		// Figure out the indexes that need reporting
+		indexes := make([]uint64, 0, historyUpdateRange)
+		if len(list) > 0 {
+			// Specific indexes requested, send them back in particular
+			indexes = append(indexes, list...)
+		} else if head := rawdb.ReadCurrentBlock(tx); head != nil {
+			// No indexes requested, send back the top ones
+			start := int64(head.NumberU64()) - historyUpdateRange + 1
+			if start < 0 {
+				start = 0
+			}
+			for i := uint64(start); i <= head.NumberU64(); i++ {
+				indexes = append(indexes, i)
 			}
 		}
-		// If we do have the block, add to the history and continue
-		if block != nil {
-			history[len(history)-1-i] = s.assembleBlockStats(block)
-			continue
+		// Gather the batch of blocks to report
+		history = make([]*blockStats, len(indexes))
+		for i, number := range indexes {
+			// Retrieve the next block if it's known to us
+			block, err := rawdb.ReadBlockByNumber(tx, number)
+			if err != nil {
+				return err
+			}
+			// If we do have the block, add to the history and continue
+			if block != nil {
+				if history[len(history)-1-i], err = s.assembleBlockStats(tx, block); err != nil {
+					return err
+				}
+				continue
+			}
+			// Ran out of blocks, cut the report short and send
+			history = history[len(history)-i:]
+			break
 		}
-		// Ran out of blocks, cut the report short and send
-		history = history[len(history)-i:]
-		break
+		return nil
+	}); err != nil {
+		return err
 	}
+
 	// Assemble the history report and send it to the server
 	if len(history) > 0 {
 		log.Trace("Sending historical blocks to ethstats", "first", history[0].Number, "last", history[len(history)-1].Number)
@@ -701,8 +723,8 @@ type pendStats struct {
 // reportPending retrieves the current number of pending transactions and reports
 // it to the stats server.
 func (s *Service) reportPending(conn *connWrapper) error {
-	// Retrieve the pending count from the local blockchain
-	pending, _ := s.backend.Stats()
+	// Retrieve the pending count from the transaction pool
+	pending, _ := s.txPool.Stats()
 	// Assemble the transaction stats and send it to the server
 	log.Trace("Sending pending transactions to ethstats", "count", pending)
 
@@ -724,32 +746,57 @@ type nodeStats struct {
 	Syncing  bool `json:"syncing"`
 	Mining   bool `json:"mining"`
 	Hashrate int  `json:"hashrate"`
-	GoodPeers    int  `json:"peers"`
+	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
 }
 
 // reportStats retrieves various stats about the node at the networking and
-// mining layer and reports it to the stats server.
+// syncing layer and reports it to the stats server.
 func (s *Service) reportStats(conn *connWrapper) error {
-	// Gather the syncing and mining infos from the local miner instance
+	// Count the peers of all the sentries
+	var peers uint64
+	for _, sc := range s.sentries {
+		reply, err := sc.PeerCount(context.Background(), &sentry.PeerCountRequest{})
+		if err != nil {
+			log.Warn("Stats peer count failed", "err", err)
+			continue
+		}
+		peers += reply.Count
+	}
+	// The node is syncing until the stage loop has finished the downloaded headers
+	var syncing bool
+	if err := s.chainDB.View(context.Background(), func(tx ethdb.Tx) error {
+		headers, err := stages.GetStageProgress(tx, stages.Headers)
+		if err != nil {
+			return err
+		}
+		finish, err := stages.GetStageProgress(tx, stages.Finish)
+		if err != nil {
+			return err
+		}
+		syncing = finish < headers
+		return nil
+	}); err != nil {
+		return err
+	}
+	// Gather the mining stats. The gas price oracle is only served by the rpcdaemon,
+	// so the minimum gas price accepted by the miner is reported instead.
 	var (
 		mining   bool
 		hashrate int
-		syncing  bool
 		gasprice int
 	)
-	// check if backend is a full node
-	fullBackend, ok := s.backend.(fullNodeBackend)
-	if ok {
-		mining = fullBackend.Miner().Mining()
-		hashrate = int(fullBackend.Miner().HashRate())
-
-		sync := fullBackend.Downloader().Progress()
-		syncing = fullBackend.CurrentHeader().Number.Uint64() >= sync.HighestBlock
-
-		price, _ := fullBackend.SuggestPrice(context.Background())
-		gasprice = int(price.Uint64())
+	if s.mining != nil {
+		mining = s.mining.Enabled
+		if s.mining.GasPrice != nil {
+			gasprice = int(s.mining.GasPrice.Int64())
+		}
+	}
+	if mining {
+		if engine, ok := s.engine.(interface{ Hashrate() float64 }); ok {
+			hashrate = int(engine.Hashrate())
+		}
 	}
 	// Assemble the node stats and send it to the server
 	log.Trace("Sending node details to ethstats")
@@ -760,7 +807,7 @@ func (s *Service) reportStats(conn *connWrapper) error {
 			Active:   true,
 			Mining:   mining,
 			Hashrate: hashrate,
-			GoodPeers:    s.server.PeerCount(),
+			Peers:    int(peers),
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
@@ -771,4 +818,3 @@ func (s *Service) reportStats(conn *connWrapper) error {
 	}
 	return conn.WriteJSON(report)
 }
-*/
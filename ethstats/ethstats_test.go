@@ -0,0 +1,200 @@
+package ethstats
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/u256"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/remote"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type testSentry struct {
+	remote.SentryClient
+	peers uint64
+}
+
+func (s *testSentry) Protocol() uint { return 66 }
+func (s *testSentry) PeerCount(context.Context, *sentry.PeerCountRequest, ...grpc.CallOption) (*sentry.PeerCountReply, error) {
+	return &sentry.PeerCountReply{Count: s.peers}, nil
+}
+
+type statsMsg struct {
+	name string
+	data json.RawMessage
+}
+
+// statsServer is a stand-in of the ethstats server: it accepts the login, answers pings and records the reports
+type statsServer struct {
+	*httptest.Server
+	t      *testing.T
+	secret string
+	msgs   chan statsMsg
+
+	lock sync.Mutex
+	conn *websocket.Conn
+}
+
+func newStatsServer(t *testing.T, secret string) *statsServer {
+	s := &statsServer{t: t, secret: secret, msgs: make(chan statsMsg, 100)}
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		var hello struct {
+			Emit []json.RawMessage `json:"emit"`
+		}
+		if err = conn.ReadJSON(&hello); err != nil || len(hello.Emit) != 2 {
+			t.Errorf("login: %v %v", err, hello)
+			return
+		}
+		var auth authMsg
+		if err = json.Unmarshal(hello.Emit[1], &auth); err != nil || auth.Secret != s.secret {
+			t.Errorf("auth: %v %v", err, auth)
+			return
+		}
+		s.lock.Lock()
+		s.conn = conn
+		err = conn.WriteJSON(map[string][]string{"emit": {"ready"}})
+		s.lock.Unlock()
+		if err != nil {
+			return
+		}
+		for {
+			var msg struct {
+				Emit []json.RawMessage `json:"emit"`
+			}
+			if err = conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			var name string
+			if err = json.Unmarshal(msg.Emit[0], &name); err != nil {
+				t.Errorf("message name: %v", err)
+				return
+			}
+			if name == "node-ping" {
+				s.emit("node-pong", msg.Emit[1])
+				continue
+			}
+			s.msgs <- statsMsg{name: name, data: msg.Emit[1]}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *statsServer) emit(name string, data interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.conn.WriteJSON(map[string][]interface{}{"emit": {name, data}}); err != nil {
+		s.t.Errorf("emit %s: %v", name, err)
+	}
+}
+
+// expect waits for the report matching the condition, skipping other reports
+func (s *statsServer) expect(name string, match func(data json.RawMessage) bool) {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg := <-s.msgs:
+			if msg.name == name && match(msg.data) {
+				return
+			}
+		case <-timeout:
+			s.t.Fatalf("no %s report", name)
+		}
+	}
+}
+
+func blockNumber(number uint64) func(json.RawMessage) bool {
+	return func(data json.RawMessage) bool {
+		var report struct {
+			Block blockStats `json:"block"`
+		}
+		return json.Unmarshal(data, &report) == nil && report.Block.Number.Uint64() == number
+	}
+}
+
+func TestReports(t *testing.T) {
+	m := stages.Mock(t)
+	server := newStatsServer(t, "secret")
+	url := "test:secret@" + strings.TrimPrefix(server.URL, "http://")
+	txPool := m.TxPoolP2PServer.TxPool
+	mining := &params.MiningConfig{Enabled: true, GasPrice: big.NewInt(params.GWei)}
+	s, err := newService("erigon", 30303, []remote.SentryClient{&testSentry{peers: 3}}, m.DB, m.Engine, txPool, mining, m.Notifications.Events, 1337, url)
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	// initial report of the genesis
+	server.expect("block", blockNumber(0))
+	server.expect("stats", func(data json.RawMessage) bool {
+		var report struct {
+			Stats nodeStats `json:"stats"`
+		}
+		return json.Unmarshal(data, &report) == nil && report.Stats.Peers == 3 && !report.Stats.Syncing &&
+			report.Stats.Mining && report.Stats.GasPrice == params.GWei
+	})
+
+	// blocks finished by the stage loop
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	server.expect("block", blockNumber(3))
+
+	// pending transactions of the txpool
+	txn, err := types.SignTx(types.NewTransaction(0, common.Address{1}, u256.Num1, params.TxGas, u256.Num1, nil), *types.LatestSignerForChainID(m.ChainConfig.ChainID), m.Key)
+	require.NoError(t, err)
+	require.NoError(t, txPool.AddLocal(txn))
+	server.expect("pending", func(data json.RawMessage) bool {
+		var report struct {
+			Stats pendStats `json:"stats"`
+		}
+		return json.Unmarshal(data, &report) == nil && report.Stats.Pending == 1
+	})
+
+	// history requested by the server
+	server.emit("history", map[string]interface{}{"list": []uint64{1, 2}})
+	server.expect("history", func(data json.RawMessage) bool {
+		var report struct {
+			History []blockStats `json:"history"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil || len(report.History) != 2 {
+			return false
+		}
+		return report.History[0].Number.Uint64() == 2 && report.History[1].Number.Uint64() == 1 &&
+			report.History[0].Miner == common.Address{1} && report.History[1].Hash == chain.Blocks[0].Hash()
+	})
+}
+
+func TestInvalidURL(t *testing.T) {
+	m := stages.Mock(t)
+	if _, err := newService("erigon", 30303, nil, m.DB, m.Engine, m.TxPoolP2PServer.TxPool, nil, m.Notifications.Events, 1337, "localhost:3000"); err == nil {
+		t.Errorf("service created without node name")
+	}
+}
@@ -55,4 +55,9 @@ var rebuilCallTraceIndex = Migration{
 		}
 		return CommitProgress(db, nil, true)
 	},
+	// Down is no-op: the format of the index is not changed, rebuilt index is valid for older binaries
+	Down: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		return CommitProgress(db, nil, true)
+	},
+	Estimate: bucketsEstimate(dbutils.CallTraceSet),
 }
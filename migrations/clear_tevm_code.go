@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+)
+
+// clearTEVMCode - TEVM code stored before it got versioned encoding is a copy of EVM code, it can't be decoded.
+// Translation stage translates contracts again.
+var clearTEVMCode = Migration{
+	Name: "clear_tevm_code",
+	Up: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		if err := db.(ethdb.BucketsMigrator).ClearBuckets(dbutils.ContractTEVMCodeBucket); err != nil {
+			return err
+		}
+		if err := stages.SaveStageProgress(db, stages.Translation, 0); err != nil {
+			return err
+		}
+		return CommitProgress(db, nil, true)
+	},
+	// Down does the same: binaries before this migration can't execute versioned TEVM code
+	Down: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		if err := db.(ethdb.BucketsMigrator).ClearBuckets(dbutils.ContractTEVMCodeBucket); err != nil {
+			return err
+		}
+		if err := stages.SaveStageProgress(db, stages.Translation, 0); err != nil {
+			return err
+		}
+		return CommitProgress(db, nil, true)
+	},
+	Estimate: bucketsEstimate(dbutils.ContractTEVMCodeBucket),
+}
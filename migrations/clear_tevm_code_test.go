@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClearTEVMCode(t *testing.T) {
+	require, db := require.New(t), kv.NewTestKV(t)
+
+	// legacy TEVM code is a copy of EVM code
+	code := common.FromHex("6001600101")
+	codeHash := crypto.Keccak256Hash(code)
+	err := db.Update(context.Background(), func(tx ethdb.RwTx) error {
+		if err := tx.Put(dbutils.ContractTEVMCodeBucket, codeHash.Bytes(), code); err != nil {
+			return err
+		}
+		return stages.SaveStageProgress(tx, stages.Translation, 100)
+	})
+	require.NoError(err)
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		_, err := vm.GetCheckTEVM(tx)(codeHash)
+		require.ErrorIs(err, vm.ErrInvalidTEVMCode)
+		return nil
+	})
+	require.NoError(err)
+
+	migrator := NewMigrator(ethdb.Chain)
+	migrator.Migrations = []Migration{clearTEVMCode}
+	err = migrator.Apply(db, t.TempDir())
+	require.NoError(err)
+
+	// contract is executed by EVM until Translation stage translates it again
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		ok, err := vm.GetCheckTEVM(tx)(codeHash)
+		require.NoError(err)
+		require.False(ok)
+		progress, err := stages.GetStageProgress(tx, stages.Translation)
+		require.NoError(err)
+		require.Equal(uint64(0), progress)
+		return nil
+	})
+	require.NoError(err)
+
+	// apply again
+	err = migrator.Apply(db, t.TempDir())
+	require.NoError(err)
+}
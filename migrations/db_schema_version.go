@@ -11,4 +11,7 @@ var dbSchemaVersion = Migration{
 		// This migration is no-op, but it forces the migration mechanism to apply it and thus write the DB schema version info
 		return CommitProgress(db, nil, true)
 	},
+	Down: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		return CommitProgress(db, nil, true)
+	},
 }
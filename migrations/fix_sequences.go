@@ -27,6 +27,24 @@ var fixSequences = Migration{
 			}
 		}
 
+		return CommitProgress(db, nil, true)
+	},
+	// Down copies sequences back to the old names: ids allocated after Up must not be reused by older binaries
+	Down: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		for bkt, oldbkt := range oldSequences {
+			seq, getErr := db.GetOne(dbutils.Sequence, []byte(bkt))
+			if getErr != nil {
+				return getErr
+			}
+
+			if seq != nil {
+				putErr := db.Put(dbutils.Sequence, []byte(oldbkt), seq)
+				if putErr != nil {
+					return putErr
+				}
+			}
+		}
+
 		return CommitProgress(db, nil, true)
 	},
 }
@@ -125,6 +125,41 @@ var headerPrefixToSeparateBuckets = Migration{
 		}
 		return CommitProgress(db, nil, true)
 	},
+	Down: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		tx := db.(ethdb.HasTx).Tx().(ethdb.RwTx)
+		if err = tx.(ethdb.BucketMigrator).CreateBucket(dbutils.HeaderPrefixOld); err != nil {
+			return err
+		}
+		if err = tx.(ethdb.BucketMigrator).ClearBucket(dbutils.HeaderPrefixOld); err != nil {
+			return err
+		}
+		logPrefix := "join_header_prefix_bucket"
+		collector := etl.NewCollector(tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+		defer collector.Close(logPrefix)
+		if err = tx.ForEach(dbutils.HeaderCanonicalBucket, nil, func(k, v []byte) error {
+			return collector.Collect(append(common.CopyBytes(k), HeaderHashSuffix...), v)
+		}); err != nil {
+			return err
+		}
+		if err = tx.ForEach(dbutils.HeaderTDBucket, nil, func(k, v []byte) error {
+			return collector.Collect(append(common.CopyBytes(k), HeaderTDSuffix...), v)
+		}); err != nil {
+			return err
+		}
+		if err = tx.ForEach(dbutils.HeadersBucket, nil, func(k, v []byte) error {
+			return collector.Collect(k, v)
+		}); err != nil {
+			return err
+		}
+		if err = collector.Load(logPrefix, tx, dbutils.HeaderPrefixOld, etl.IdentityLoadFunc, etl.TransformArgs{}); err != nil {
+			return fmt.Errorf("loading headers into the old table: %w", err)
+		}
+		if err = db.(ethdb.BucketsMigrator).ClearBuckets(dbutils.HeaderCanonicalBucket, dbutils.HeaderTDBucket, dbutils.HeadersBucket); err != nil {
+			return err
+		}
+		return CommitProgress(db, nil, true)
+	},
+	Estimate: bucketsEstimate(dbutils.HeaderPrefixOld),
 }
 
 func IsHeaderKey(k []byte) bool {
@@ -83,6 +83,38 @@ func TestHeaderPrefix(t *testing.T) {
 	require.NoError(err)
 	require.Equal(num, 10)
 
+	// Down joins buckets back and Up can be applied again
+	err = migrator.Revert(db, t.TempDir(), headerPrefixToSeparateBuckets.Name)
+	require.NoError(err)
+	num = 0
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		for _, bucket := range []string{dbutils.HeaderCanonicalBucket, dbutils.HeaderTDBucket, dbutils.HeadersBucket} {
+			c, err := tx.Cursor(bucket)
+			require.NoError(err)
+			count, err := c.Count()
+			require.NoError(err)
+			require.Zero(count, bucket)
+		}
+		v, err := tx.GetOne(dbutils.HeaderPrefixOld, HeaderHashKey(3))
+		require.NoError(err)
+		require.Equal(common.Hash{3}.Bytes(), v)
+		return tx.ForEach(dbutils.HeaderPrefixOld, []byte{}, func(k, v []byte) error {
+			num++
+			return nil
+		})
+	})
+	require.NoError(err)
+	require.Equal(30, num)
+
+	err = migrator.Apply(db, t.TempDir())
+	require.NoError(err)
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		v, err := tx.GetOne(dbutils.HeaderTDBucket, dbutils.HeaderKey(5, common.Hash{5}))
+		require.NoError(err)
+		require.Equal([]byte{5}, v)
+		return nil
+	})
+	require.NoError(err)
 }
 
 func TestHeaderTypeDetection(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"path"
 
+	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/etl"
@@ -14,6 +15,7 @@ import (
 	"github.com/ledgerwatch/erigon/ethdb"
 	kv2 "github.com/ledgerwatch/erigon/ethdb/kv"
 	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/metrics"
 	"github.com/ugorji/go/codec"
 )
 
@@ -55,6 +57,9 @@ import (
 //	},
 // - if you need migrate multiple buckets - create separate migration for each bucket
 // - write test where apply migration twice
+//
+// Optional Down reverts Up (see Migrator.Revert) - same rules of Idempotency apply to it.
+// Optional Estimate tells how much data Up will touch (see Migrator.DryRun).
 var migrations = map[ethdb.Label][]Migration{
 	ethdb.Chain: {
 		headerPrefixToSeparateBuckets,
@@ -62,20 +67,49 @@ var migrations = map[ethdb.Label][]Migration{
 		dbSchemaVersion,
 		rebuilCallTraceIndex,
 		fixSequences,
+		removePlaceholderEpochs,
+		clearTEVMCode,
 	},
 	ethdb.TxPool: {},
 	ethdb.Sentry: {},
 }
 
 type Migration struct {
-	Name string
-	Up   func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommitOnLoadCommit etl.LoadCommitHandler) error
+	Name     string
+	Up       func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommitOnLoadCommit etl.LoadCommitHandler) error
+	Down     func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommitOnLoadCommit etl.LoadCommitHandler) error
+	Estimate func(tx ethdb.Tx) (Estimate, error) // must not modify db
+}
+
+// Estimate - amount of data which migration will read or write
+type Estimate struct {
+	Keys  uint64
+	Bytes uint64
+}
+
+// Plan - result of dry-run for one pending migration
+type Plan struct {
+	Name       string
+	Reversible bool
+	Estimated  bool // false if migration doesn't provide Estimate
+	Estimate
 }
 
 var (
 	ErrMigrationNonUniqueName   = fmt.Errorf("please provide unique migration name")
 	ErrMigrationCommitNotCalled = fmt.Errorf("migration commit function was not called")
 	ErrMigrationETLFilesDeleted = fmt.Errorf("db migration progress was interrupted after extraction step and ETL files was deleted, please contact development team for help or re-sync from scratch")
+	ErrMigrationNotReversible   = fmt.Errorf("migration has no Down step")
+	ErrMigrationNotLast         = fmt.Errorf("only the last applied migration can be reverted")
+)
+
+var (
+	pendingGauge       = metrics.NewRegisteredGauge("db/migrations/pending", nil)
+	appliedCounter     = metrics.NewRegisteredCounter("db/migrations/applied", nil)
+	revertedCounter    = metrics.NewRegisteredCounter("db/migrations/reverted", nil)
+	commitsCounter     = metrics.NewRegisteredCounter("db/migrations/commits", nil) // intermediate commits of running migration
+	estimateKeysGauge  = metrics.NewRegisteredGauge("db/migrations/current/estimate/keys", nil)
+	estimateBytesGauge = metrics.NewRegisteredGauge("db/migrations/current/estimate/bytes", nil)
 )
 
 func NewMigrator(label ethdb.Label) *Migrator {
@@ -176,6 +210,13 @@ func (m *Migrator) Apply(kv ethdb.RwKV, datadir string) error {
 		uniqueNameCheck[m.Migrations[i].Name] = true
 	}
 
+	pending := 0
+	for i := range m.Migrations {
+		if _, ok := applied[m.Migrations[i].Name]; !ok {
+			pending++
+		}
+	}
+	pendingGauge.Update(int64(pending))
 	for i := range m.Migrations {
 		v := m.Migrations[i]
 		if _, ok := applied[v.Name]; ok {
@@ -184,53 +225,41 @@ func (m *Migrator) Apply(kv ethdb.RwKV, datadir string) error {
 
 		commitFuncCalled := false // commit function must be called if no error, protection against people's mistake
 
-		log.Info("Apply migration", "name", v.Name)
-		progress, err := tx.GetOne(dbutils.Migrations, []byte("_progress_"+v.Name))
+		logArgs := []interface{}{"name", v.Name}
+		if v.Estimate != nil {
+			estimate, err := v.Estimate(tx.(ethdb.HasTx).Tx())
+			if err != nil {
+				return fmt.Errorf("estimating migration %s: %w", v.Name, err)
+			}
+			estimateKeysGauge.Update(int64(estimate.Keys))
+			estimateBytesGauge.Update(int64(estimate.Bytes))
+			logArgs = append(logArgs, "keys", estimate.Keys, "size", datasize.ByteSize(estimate.Bytes).HumanReadable())
+		}
+		log.Info("Apply migration", logArgs...)
+		progressKey := []byte("_progress_" + v.Name)
+		progress, err := tx.GetOne(dbutils.Migrations, progressKey)
 		if err != nil {
 			return err
 		}
 
-		if err = v.Up(tx, path.Join(datadir, "migrations", v.Name), progress, func(_ ethdb.Putter, key []byte, isDone bool) error {
-			if !isDone {
-				if key != nil {
-					err = tx.Put(dbutils.Migrations, []byte("_progress_"+v.Name), key)
-					if err != nil {
-						return err
-					}
-				}
-				// do commit, but don't save partial progress
-				if err := tx.CommitAndBegin(context.Background()); err != nil {
-					return err
-				}
-				return nil
-			}
+		if err = v.Up(tx, path.Join(datadir, "migrations", v.Name), progress, commitHandler(tx, progressKey, func() error {
 			commitFuncCalled = true
-
 			stagesProgress, err := MarshalMigrationPayload(tx)
 			if err != nil {
 				return err
 			}
-			err = tx.Put(dbutils.Migrations, []byte(v.Name), stagesProgress)
-			if err != nil {
-				return err
-			}
-
-			err = tx.Delete(dbutils.Migrations, []byte("_progress_"+v.Name), nil)
-			if err != nil {
-				return err
-			}
-
-			if err := tx.CommitAndBegin(context.Background()); err != nil {
-				return err
-			}
-			return nil
-		}); err != nil {
+			return tx.Put(dbutils.Migrations, []byte(v.Name), stagesProgress)
+		})); err != nil {
 			return err
 		}
 
 		if !commitFuncCalled {
 			return fmt.Errorf("%w: %s", ErrMigrationCommitNotCalled, v.Name)
 		}
+		appliedCounter.Inc(1)
+		pendingGauge.Dec(1)
+		estimateKeysGauge.Update(0)
+		estimateBytesGauge.Update(0)
 		log.Info("Applied migration", "name", v.Name)
 	}
 	// Write DB schema version
@@ -248,6 +277,143 @@ func (m *Migrator) Apply(kv ethdb.RwKV, datadir string) error {
 	return nil
 }
 
+// commitHandler - commits partial progress of migration under progressKey.
+// When migration is done: onDone is called and progressKey is removed in the same transaction.
+func commitHandler(tx ethdb.DbWithPendingMutations, progressKey []byte, onDone func() error) etl.LoadCommitHandler {
+	return func(_ ethdb.Putter, key []byte, isDone bool) error {
+		if !isDone {
+			if key != nil {
+				if err := tx.Put(dbutils.Migrations, progressKey, key); err != nil {
+					return err
+				}
+			}
+			commitsCounter.Inc(1)
+			// do commit, but don't save partial progress
+			return tx.CommitAndBegin(context.Background())
+		}
+		if err := onDone(); err != nil {
+			return err
+		}
+		if err := tx.Delete(dbutils.Migrations, progressKey, nil); err != nil {
+			return err
+		}
+		return tx.CommitAndBegin(context.Background())
+	}
+}
+
+// DryRun - plan of pending migrations, doesn't modify db
+func (m *Migrator) DryRun(kv ethdb.RoKV) ([]Plan, error) {
+	var plans []Plan
+	if err := kv.View(context.Background(), func(tx ethdb.Tx) error {
+		pending, err := m.PendingMigrations(tx)
+		if err != nil {
+			return err
+		}
+		for _, v := range pending {
+			plan := Plan{Name: v.Name, Reversible: v.Down != nil}
+			if v.Estimate != nil {
+				if plan.Estimate, err = v.Estimate(tx); err != nil {
+					return fmt.Errorf("estimating migration %s: %w", v.Name, err)
+				}
+				plan.Estimated = true
+			}
+			plans = append(plans, plan)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// Revert - runs Down of the given migration and removes it from applied migrations.
+// Only the last applied migration can be reverted, to keep the order in which migrations touched the data.
+// Reverted migration becomes pending: it will be applied again by binary which knows about it.
+func (m *Migrator) Revert(kv ethdb.RwKV, datadir string, name string) error {
+	var applied map[string][]byte
+	if err := kv.View(context.Background(), func(tx ethdb.Tx) error {
+		var err error
+		applied, err = AppliedMigrations(tx, false)
+		return err
+	}); err != nil {
+		return err
+	}
+	var last *Migration
+	for i := range m.Migrations {
+		if _, ok := applied[m.Migrations[i].Name]; ok {
+			last = &m.Migrations[i]
+		}
+	}
+	if _, ok := applied[name]; !ok {
+		return fmt.Errorf("migration %s is not applied", name)
+	}
+	if last == nil || last.Name != name {
+		return fmt.Errorf("%w: %s", ErrMigrationNotLast, name)
+	}
+	if last.Down == nil {
+		return fmt.Errorf("%w: %s", ErrMigrationNotReversible, name)
+	}
+
+	db := kv2.NewObjectDatabase(kv)
+	tx, err := db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	log.Info("Revert migration", "name", name)
+	progressKey := []byte("_progress_down_" + name)
+	progress, err := tx.GetOne(dbutils.Migrations, progressKey)
+	if err != nil {
+		return err
+	}
+	commitFuncCalled := false
+	if err = last.Down(tx, path.Join(datadir, "migrations", name+"_down"), progress, commitHandler(tx, progressKey, func() error {
+		commitFuncCalled = true
+		return tx.Delete(dbutils.Migrations, []byte(name), nil)
+	})); err != nil {
+		return err
+	}
+	if !commitFuncCalled {
+		return fmt.Errorf("%w: %s", ErrMigrationCommitNotCalled, name)
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	revertedCounter.Inc(1)
+	pendingGauge.Inc(1)
+	log.Info("Reverted migration", "name", name)
+	return nil
+}
+
+// bucketsEstimate - Estimate of migrations which rewrite whole buckets, absent buckets are skipped
+func bucketsEstimate(buckets ...string) func(tx ethdb.Tx) (Estimate, error) {
+	return func(tx ethdb.Tx) (Estimate, error) {
+		var e Estimate
+		for _, bucket := range buckets {
+			if migrator, ok := tx.(ethdb.BucketMigrator); ok && !migrator.ExistsBucket(bucket) {
+				continue
+			}
+			c, err := tx.Cursor(bucket)
+			if err != nil {
+				return e, err
+			}
+			keys, err := c.Count()
+			c.Close()
+			if err != nil {
+				return e, err
+			}
+			size, err := tx.BucketSize(bucket)
+			if err != nil {
+				return e, err
+			}
+			e.Keys += keys
+			e.Bytes += size
+		}
+		return e, nil
+	}
+}
+
 func MarshalMigrationPayload(db ethdb.KVGetter) ([]byte, error) {
 	s := map[string][]byte{}
 
@@ -18,14 +18,14 @@ func TestApplyWithInit(t *testing.T) {
 	require, db := require.New(t), kv.NewTestKV(t)
 	m := []Migration{
 		{
-			"one",
-			func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+			Name: "one",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
 				return OnLoadCommit(db, nil, true)
 			},
 		},
 		{
-			"two",
-			func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+			Name: "two",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
 				return OnLoadCommit(db, nil, true)
 			},
 		},
@@ -64,15 +64,15 @@ func TestApplyWithoutInit(t *testing.T) {
 	require, db := require.New(t), kv.NewTestKV(t)
 	m := []Migration{
 		{
-			"one",
-			func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+			Name: "one",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
 				t.Fatal("shouldn't been executed")
 				return nil
 			},
 		},
 		{
-			"two",
-			func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+			Name: "two",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
 				return OnLoadCommit(db, nil, true)
 			},
 		},
@@ -119,14 +119,14 @@ func TestWhenNonFirstMigrationAlreadyApplied(t *testing.T) {
 	require, db := require.New(t), kv.NewTestKV(t)
 	m := []Migration{
 		{
-			"one",
-			func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+			Name: "one",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
 				return OnLoadCommit(db, nil, true)
 			},
 		},
 		{
-			"two",
-			func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+			Name: "two",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
 				t.Fatal("shouldn't been executed")
 				return nil
 			},
@@ -242,3 +242,142 @@ func TestCommitCallRequired(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func TestRevert(t *testing.T) {
+	require, db := require.New(t), kv.NewTestKV(t)
+	up := func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+		if err := db.Put(dbutils.DatabaseInfoBucket, []byte("key"), []byte("value")); err != nil {
+			return err
+		}
+		return OnLoadCommit(db, nil, true)
+	}
+	down := func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+		if err := db.Delete(dbutils.DatabaseInfoBucket, []byte("key"), nil); err != nil {
+			return err
+		}
+		return OnLoadCommit(db, nil, true)
+	}
+	migrator := NewMigrator(ethdb.Chain)
+	migrator.Migrations = []Migration{
+		{Name: "one", Up: up},
+		{Name: "two", Up: up, Down: down},
+	}
+	require.NoError(migrator.Apply(db, ""))
+
+	err := migrator.Revert(db, "", "one")
+	require.True(errors.Is(err, ErrMigrationNotLast))
+	require.NoError(migrator.Revert(db, "", "two"))
+	err = migrator.Revert(db, "", "two")
+	require.Error(err)
+
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		applied, err := AppliedMigrations(tx, false)
+		require.NoError(err)
+		require.Equal(1, len(applied))
+		_, ok := applied["one"]
+		require.True(ok)
+		v, err := tx.GetOne(dbutils.DatabaseInfoBucket, []byte("key"))
+		require.NoError(err)
+		require.Nil(v)
+		return nil
+	})
+	require.NoError(err)
+
+	err = migrator.Revert(db, "", "one")
+	require.True(errors.Is(err, ErrMigrationNotReversible))
+
+	// reverted migration is pending again
+	has, err := migrator.HasPendingMigrations(db)
+	require.NoError(err)
+	require.True(has)
+}
+
+func TestRevertChainMigrations(t *testing.T) {
+	require, db := require.New(t), kv.NewTestKV(t)
+	err := db.Update(context.Background(), func(tx ethdb.RwTx) error {
+		return tx.Put(dbutils.Sequence, []byte(oldSequences[dbutils.EthTx]), dbutils.EncodeBlockNumber(5))
+	})
+	require.NoError(err)
+
+	migrator := NewMigrator(ethdb.Chain)
+	require.NoError(migrator.Apply(db, t.TempDir()))
+	err = db.Update(context.Background(), func(tx ethdb.RwTx) error {
+		_, err := tx.IncrementSequence(dbutils.EthTx, 3)
+		return err
+	})
+	require.NoError(err)
+
+	// trailing migrations are reverted one by one, until the first irreversible one
+	reverted := 0
+	for i := len(migrator.Migrations) - 1; i >= 0; i-- {
+		err = migrator.Revert(db, t.TempDir(), migrator.Migrations[i].Name)
+		if migrator.Migrations[i].Down == nil {
+			require.True(errors.Is(err, ErrMigrationNotReversible), migrator.Migrations[i].Name)
+			break
+		}
+		require.NoError(err, migrator.Migrations[i].Name)
+		reverted++
+	}
+	require.True(reverted >= 3)
+
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		applied, err := AppliedMigrations(tx, false)
+		require.NoError(err)
+		require.Equal(len(migrator.Migrations)-reverted, len(applied))
+		v, err := tx.GetOne(dbutils.Sequence, []byte(oldSequences[dbutils.EthTx]))
+		require.NoError(err)
+		require.Equal(dbutils.EncodeBlockNumber(8), v)
+		return nil
+	})
+	require.NoError(err)
+
+	// reverted migrations are applied again
+	require.NoError(migrator.Apply(db, t.TempDir()))
+	has, err := migrator.HasPendingMigrations(db)
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestDryRun(t *testing.T) {
+	require, db := require.New(t), kv.NewTestKV(t)
+	err := db.Update(context.Background(), func(tx ethdb.RwTx) error {
+		for i := 0; i < 10; i++ {
+			if err := tx.Put(dbutils.BlockReceiptsPrefix, dbutils.EncodeBlockNumber(uint64(i)), []byte{1, 2, 3}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(err)
+
+	migrator := NewMigrator(ethdb.Chain)
+	migrator.Migrations = []Migration{
+		{
+			Name: "one",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+				t.Fatal("shouldn't been executed")
+				return nil
+			},
+			Estimate: bucketsEstimate(dbutils.BlockReceiptsPrefix, dbutils.HeaderPrefixOld),
+		},
+		{
+			Name: "two",
+			Up: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+				t.Fatal("shouldn't been executed")
+				return nil
+			},
+			Down: func(db ethdb.Database, tmpdir string, progress []byte, OnLoadCommit etl.LoadCommitHandler) error {
+				return OnLoadCommit(db, nil, true)
+			},
+		},
+	}
+	plans, err := migrator.DryRun(db)
+	require.NoError(err)
+	require.Equal(2, len(plans))
+	require.Equal("one", plans[0].Name)
+	require.True(plans[0].Estimated)
+	require.False(plans[0].Reversible)
+	require.Equal(uint64(10), plans[0].Keys)
+	require.True(plans[0].Bytes > 0)
+	require.Equal(Plan{Name: "two", Reversible: true}, plans[1])
+}
@@ -22,4 +22,5 @@ var removeCliqueBucket = Migration{
 
 		return CommitProgress(db, nil, true)
 	},
+	Estimate: bucketsEstimate(dbutils.CliqueBucket),
 }
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/etl"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb"
+)
+
+// placeholderEpoch - transition proof which AuRa used to store for genesis instead of the real proof
+var placeholderEpoch = []byte{1}
+
+var removePlaceholderEpochs = Migration{
+	Name: "remove_placeholder_epochs",
+	Up: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		for _, bucket := range []string{dbutils.Epoch, dbutils.PendingEpoch} {
+			var placeholders [][]byte
+			if err := db.ForEach(bucket, nil, func(k, v []byte) error {
+				if bytes.Equal(v, placeholderEpoch) {
+					placeholders = append(placeholders, common.CopyBytes(k))
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, k := range placeholders {
+				if err := db.Delete(bucket, k, nil); err != nil {
+					return err
+				}
+			}
+		}
+
+		return CommitProgress(db, nil, true)
+	},
+	// Down puts back the placeholder of genesis: binaries before this migration consider a block as epoch begin
+	// if an epoch is stored for its parent, and write the genesis one only when block 1 is executed.
+	// Other placeholders are not read after the blocks which stored them are executed.
+	Down: func(db ethdb.Database, tmpdir string, progress []byte, CommitProgress etl.LoadCommitHandler) (err error) {
+		tx := db.(ethdb.HasTx).Tx().(ethdb.RwTx)
+		c, err := tx.Cursor(dbutils.Epoch)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		k, _, err := c.First()
+		if err != nil {
+			return err
+		}
+		if k == nil || binary.BigEndian.Uint64(k) == 0 { // not AuRa or genesis epoch is stored
+			return CommitProgress(db, nil, true)
+		}
+		genesisHash, err := rawdb.ReadCanonicalHash(tx, 0)
+		if err != nil {
+			return err
+		}
+		if err := rawdb.WriteEpoch(tx, 0, genesisHash, placeholderEpoch); err != nil {
+			return err
+		}
+		return CommitProgress(db, nil, true)
+	},
+	Estimate: bucketsEstimate(dbutils.Epoch, dbutils.PendingEpoch),
+}
@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovePlaceholderEpochs(t *testing.T) {
+	require := require.New(t)
+	db := kv.NewTestKV(t)
+
+	err := db.Update(context.Background(), func(tx ethdb.RwTx) error {
+		if err := rawdb.WriteCanonicalHash(tx, common.Hash{1}, 0); err != nil {
+			return err
+		}
+		if err := rawdb.WriteEpoch(tx, 0, common.Hash{1}, []byte{1}); err != nil {
+			return err
+		}
+		if err := rawdb.WriteEpoch(tx, 10, common.Hash{10}, []byte("proof 10")); err != nil {
+			return err
+		}
+		if err := rawdb.WritePendingEpoch(tx, 0, common.Hash{1}, []byte{1}); err != nil {
+			return err
+		}
+		return rawdb.WritePendingEpoch(tx, 20, common.Hash{20}, []byte("proof 20"))
+	})
+	require.NoError(err)
+
+	migrator := NewMigrator(ethdb.Chain)
+	migrator.Migrations = []Migration{removePlaceholderEpochs}
+	err = migrator.Apply(db, t.TempDir())
+	require.NoError(err)
+
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		v, err := rawdb.ReadEpoch(tx, 0, common.Hash{1})
+		require.NoError(err)
+		require.Nil(v)
+		v, err = rawdb.ReadEpoch(tx, 10, common.Hash{10})
+		require.NoError(err)
+		require.Equal([]byte("proof 10"), v)
+		v, err = rawdb.ReadPendingEpoch(tx, 0, common.Hash{1})
+		require.NoError(err)
+		require.Nil(v)
+		v, err = rawdb.ReadPendingEpoch(tx, 20, common.Hash{20})
+		require.NoError(err)
+		require.Equal([]byte("proof 20"), v)
+		return nil
+	})
+	require.NoError(err)
+
+	// apply again
+	err = migrator.Apply(db, t.TempDir())
+	require.NoError(err)
+
+	// revert puts back placeholder of genesis only
+	err = migrator.Revert(db, t.TempDir(), removePlaceholderEpochs.Name)
+	require.NoError(err)
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		v, err := rawdb.ReadEpoch(tx, 0, common.Hash{1})
+		require.NoError(err)
+		require.Equal([]byte{1}, v)
+		v, err = rawdb.ReadEpoch(tx, 10, common.Hash{10})
+		require.NoError(err)
+		require.Equal([]byte("proof 10"), v)
+		v, err = rawdb.ReadPendingEpoch(tx, 0, common.Hash{1})
+		require.NoError(err)
+		require.Nil(v)
+		return nil
+	})
+	require.NoError(err)
+
+	err = migrator.Apply(db, t.TempDir())
+	require.NoError(err)
+	err = db.View(context.Background(), func(tx ethdb.Tx) error {
+		v, err := rawdb.ReadEpoch(tx, 0, common.Hash{1})
+		require.NoError(err)
+		require.Nil(v)
+		return nil
+	})
+	require.NoError(err)
+}
@@ -21,4 +21,9 @@ type MiningConfig struct {
 	GasCeil   uint64            // Target gas ceiling for mined blocks.
 	GasPrice  *big.Int          // Minimum gas price for mining a transaction
 	Recommit  time.Duration     // The time interval for miner to re-create mining work.
+
+	StratumAddr       string `toml:",omitempty"` // TCP address of the stratum server of ethash miners, disabled if empty
+	StratumDifficulty uint64 // Pool difficulty of stratum shares
+
+	InstantSeal bool // Developer chain: seal a block as soon as a transaction enters the pool or on demand (evm_mine)
 }
@@ -18,6 +18,7 @@ package tests
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -30,6 +31,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/params"
 )
 
@@ -44,6 +47,18 @@ var (
 	difficultyTestDir  = filepath.Join(baseDir, "BasicTests")
 )
 
+// storageMode - with 'e' VM and state tests are executed by TEVM interpreter
+var storageMode = flag.String("storage-mode", "default", "storage mode of VM and state tests, 'e' enables TEVM")
+
+// testVMConfig - VM config of tests for storage mode from command line
+func testVMConfig() vm.Config {
+	mode, err := ethdb.StorageModeFromString(*storageMode)
+	if err != nil {
+		panic(err)
+	}
+	return vm.Config{EnableTEMV: mode.TEVM}
+}
+
 func readJSON(reader io.Reader, value interface{}) error {
 	data, err := ioutil.ReadAll(reader)
 	if err != nil {
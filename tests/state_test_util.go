@@ -216,7 +216,7 @@ func (t *StateTest) RunNoVerify(rules params.Rules, kvtx ethdb.RwTx, subtest Sta
 
 	// Prepare the EVM.
 	txContext := core.NewEVMTxContext(msg)
-	checkTEVM := func(common.Hash) (bool, error) { return false, nil }
+	checkTEVM := func(common.Hash) (bool, error) { return vmconfig.EnableTEMV, nil }
 	context := core.NewEVMBlockContext(block.Header(), nil, nil, &t.json.Env.Coinbase, checkTEVM)
 	context.GetHash = vmTestBlockHash
 	if baseFee != nil {
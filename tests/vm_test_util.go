@@ -154,7 +154,7 @@ func (t *VMTest) newEVM(state vm.IntraBlockState, vmconfig vm.Config) *vm.EVM {
 		CanTransfer: canTransfer,
 		Transfer:    transfer,
 		GetHash:     vmTestBlockHash,
-		CheckTEVM:   func(common.Hash) (bool, error) { return false, nil },
+		CheckTEVM:   func(common.Hash) (bool, error) { return vmconfig.EnableTEMV, nil },
 		Coinbase:    t.json.Env.Coinbase,
 		BlockNumber: t.json.Env.Number,
 		Time:        t.json.Env.Timestamp,
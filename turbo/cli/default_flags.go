@@ -24,18 +24,27 @@ var DefaultFlags = []cli.Flag{
 	StorageModeFlag,
 	SnapshotModeFlag,
 	SeedSnapshotsFlag,
+	SnapshotSourceFlag,
 	SnapshotDatabaseLayoutFlag,
 	ExternalSnapshotDownloaderAddrFlag,
+	utils.CheckpointFlag,
+	utils.CheckpointStateFlag,
+	utils.CheckpointStateDiffsFlag,
+	utils.BeaconFlag,
+	utils.MockBeaconPeriodFlag,
 	BatchSizeFlag,
 	BlockDownloaderWindowFlag,
 	DatabaseVerbosityFlag,
 	PrivateApiAddr,
 	EtlBufferSizeFlag,
+	EtlSortWorkersFlag,
+	EtlCompressionFlag,
 	TLSFlag,
 	TLSCertFlag,
 	TLSKeyFlag,
 	TLSCACertFlag,
 	SyncLoopThrottleFlag,
+	JumpDestPersistFlag,
 	utils.ListenPortFlag,
 	utils.ListenPort65Flag,
 	utils.NATFlag,
@@ -48,6 +57,10 @@ var DefaultFlags = []cli.Flag{
 	utils.StaticPeersFlag,
 	utils.MaxPeersFlag,
 	utils.ChainFlag,
+	utils.DeveloperPeriodFlag,
+	utils.DeveloperInstantSealFlag,
+	utils.DeveloperForkSourceFlag,
+	utils.DeveloperForkBlockFlag,
 	utils.VMEnableDebugFlag,
 	utils.NetworkIdFlag,
 	utils.FakePoWFlag,
@@ -58,6 +71,7 @@ var DefaultFlags = []cli.Flag{
 	utils.MetricsEnabledExpensiveFlag,
 	utils.MetricsHTTPFlag,
 	utils.MetricsPortFlag,
+	utils.EthStatsURLFlag,
 	utils.IdentityFlag,
 	utils.CliqueSnapshotCheckpointIntervalFlag,
 	utils.CliqueSnapshotInmemorySnapshotsFlag,
@@ -68,7 +82,10 @@ var DefaultFlags = []cli.Flag{
 	utils.MinerGasTargetFlag,
 	utils.MinerGasLimitFlag,
 	utils.MinerEtherbaseFlag,
+	utils.MinerSigningKeyFlag,
 	utils.MinerExtraDataFlag,
 	utils.MinerNoVerfiyFlag,
+	utils.MinerStratumAddrFlag,
+	utils.MinerStratumDifficultyFlag,
 	utils.SentryAddrFlag,
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/common/etl"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
 	"github.com/ledgerwatch/erigon/node"
@@ -32,6 +33,16 @@ var (
 		Usage: "Buffer size for ETL operations.",
 		Value: etl.BufferOptimalSize.String(),
 	}
+	EtlSortWorkersFlag = cli.IntFlag{
+		Name:  "etl.sortWorkers",
+		Usage: "Amount of ETL buffers sorted in background while HashState stage rebuilds whole state. Memory usage is bounded by (etl.sortWorkers+1)*etl.bufferSize",
+		Value: stagedsync.CleanPromoteSortWorkers,
+	}
+	EtlCompressionFlag = cli.StringFlag{
+		Name:  "etl.compression",
+		Usage: "Compression of temporary files of ETL operations: none, snappy or zstd (less disk usage, more cpu)",
+		Value: "none",
+	}
 	BlockDownloaderWindowFlag = cli.IntFlag{
 		Name:  "blockDownloaderWindow",
 		Usage: "Outstanding limit of block bodies being downloaded",
@@ -80,6 +91,10 @@ var (
 		Name:  "snapshot.seed",
 		Usage: `Seed snapshot seeding(default: true)`,
 	}
+	SnapshotSourceFlag = cli.StringFlag{
+		Name:  "snapshot.source",
+		Usage: `Download snapshots from directory or http(s) mirror instead of bittorrent`,
+	}
 	//todo replace to BoolT
 	SnapshotDatabaseLayoutFlag = cli.BoolFlag{
 		Name:  "snapshot.layout",
@@ -111,6 +126,10 @@ var (
 		Usage: "Specify certificate authority",
 		Value: "",
 	}
+	JumpDestPersistFlag = cli.BoolFlag{
+		Name:  "jumpdest.persist",
+		Usage: "Write JUMPDEST analysis of contracts to the DB during Execution stage, it's reused by rpcdaemon and after restarts",
+	}
 	StateStreamFlag = cli.BoolFlag{
 		Name:  "state.stream",
 		Usage: "Enable streaming of state changes from core to RPC daemon",
@@ -136,6 +155,7 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 	}
 	cfg.Snapshot.Mode = snMode
 	cfg.Snapshot.Seeding = ctx.GlobalBool(SeedSnapshotsFlag.Name)
+	cfg.Snapshot.Source = ctx.GlobalString(SnapshotSourceFlag.Name)
 	cfg.Snapshot.Enabled = ctx.GlobalBool(SnapshotDatabaseLayoutFlag.Name)
 
 	if ctx.GlobalString(BatchSizeFlag.Name) != "" {
@@ -154,9 +174,16 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 		}
 		etl.BufferOptimalSize = *size
 	}
+	stagedsync.CleanPromoteSortWorkers = ctx.GlobalInt(EtlSortWorkersFlag.Name)
+	compression, err := etl.ParseCompression(ctx.GlobalString(EtlCompressionFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid %s provided: %v", EtlCompressionFlag.Name, err)
+	}
+	cfg.EtlCompression = compression
 
 	cfg.ExternalSnapshotDownloaderAddr = ctx.GlobalString(ExternalSnapshotDownloaderAddrFlag.Name)
 	cfg.StateStream = ctx.GlobalBool(StateStreamFlag.Name)
+	cfg.PersistJumpDests = ctx.GlobalBool(JumpDestPersistFlag.Name)
 	cfg.BlockDownloaderWindow = ctx.GlobalInt(BlockDownloaderWindowFlag.Name)
 
 	if ctx.GlobalString(SyncLoopThrottleFlag.Name) != "" {
@@ -186,6 +213,9 @@ func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 	if v := f.Bool(SeedSnapshotsFlag.Name, false, SeedSnapshotsFlag.Usage); v != nil {
 		cfg.Snapshot.Seeding = *v
 	}
+	if v := f.String(SnapshotSourceFlag.Name, SnapshotSourceFlag.Value, SnapshotSourceFlag.Usage); v != nil {
+		cfg.Snapshot.Source = *v
+	}
 	if v := f.String(BatchSizeFlag.Name, BatchSizeFlag.Value, BatchSizeFlag.Usage); v != nil {
 		err := cfg.BatchSize.UnmarshalText([]byte(*v))
 		if err != nil {
@@ -201,6 +231,16 @@ func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 		}
 		etl.BufferOptimalSize = *size
 	}
+	if v := f.Int(EtlSortWorkersFlag.Name, EtlSortWorkersFlag.Value, EtlSortWorkersFlag.Usage); v != nil {
+		stagedsync.CleanPromoteSortWorkers = *v
+	}
+	if v := f.String(EtlCompressionFlag.Name, EtlCompressionFlag.Value, EtlCompressionFlag.Usage); v != nil {
+		compression, err := etl.ParseCompression(*v)
+		if err != nil {
+			utils.Fatalf("Invalid %s provided: %v", EtlCompressionFlag.Name, err)
+		}
+		cfg.EtlCompression = compression
+	}
 
 	if v := f.String(ExternalSnapshotDownloaderAddrFlag.Name, ExternalSnapshotDownloaderAddrFlag.Value, ExternalSnapshotDownloaderAddrFlag.Usage); v != nil {
 		cfg.ExternalSnapshotDownloaderAddr = *v
@@ -208,6 +248,9 @@ func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 	if v := f.Bool(StateStreamFlag.Name, false, StateStreamFlag.Usage); v != nil {
 		cfg.StateStream = *v
 	}
+	if v := f.Bool(JumpDestPersistFlag.Name, false, JumpDestPersistFlag.Usage); v != nil {
+		cfg.PersistJumpDests = *v
+	}
 }
 
 func ApplyFlagsForNodeConfig(ctx *cli.Context, cfg *node.Config) {
@@ -16,7 +16,6 @@ import (
 	"github.com/anacrolix/torrent/metainfo"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/dbutils"
-	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
 	"golang.org/x/sync/errgroup"
@@ -24,6 +23,7 @@ import (
 
 type Client struct {
 	Cli          *torrent.Client
+	source       SnapshotSource
 	snapshotsDir string
 	trackers     [][]string
 }
@@ -43,11 +43,18 @@ func New(snapshotsDir string, seeding bool, peerID string) (*Client, error) {
 
 	return &Client{
 		Cli:          torrentClient,
+		source:       &torrentSource{cli: torrentClient},
 		snapshotsDir: snapshotsDir,
 		trackers:     Trackers,
 	}, nil
 }
 
+// SetSource replaces bittorrent by other source of snapshots. Snapshots downloaded from it are not seeded,
+// torrent client seeds only snapshots added by SeedSnapshot (built by this node)
+func (cli *Client) SetSource(source SnapshotSource) {
+	cli.source = source
+}
+
 func DefaultTorrentConfig() *torrent.ClientConfig {
 	torrentConfig := torrent.NewDefaultClientConfig()
 	torrentConfig.ListenPort = 0
@@ -82,12 +89,7 @@ func (cli *Client) Load(db ethdb.Database) error {
 		}
 
 		log.Info("Add torrent", "snapshot", snapshotName, "hash", infoHash.String(), "infobytes", len(infoBytes) > 0)
-		_, err = cli.AddTorrentSpec(snapshotName, infoHash, infoBytes)
-		if err != nil {
-			return err
-		}
-
-		return nil
+		return cli.source.AddSnapshot(snapshotName, infoHash, infoBytes)
 	})
 }
 
@@ -104,17 +106,7 @@ func (cli *Client) PeerID() []byte {
 	return peerID[:]
 }
 func (cli *Client) AddTorrentSpec(snapshotName string, snapshotHash metainfo.Hash, infoBytes []byte) (*torrent.Torrent, error) {
-	t, ok := cli.Cli.Torrent(snapshotHash)
-	if ok {
-		return t, nil
-	}
-	t, _, err := cli.Cli.AddTorrentSpec(&torrent.TorrentSpec{
-		Trackers:    Trackers,
-		InfoHash:    snapshotHash,
-		DisplayName: snapshotName,
-		InfoBytes:   infoBytes,
-	})
-	return t, err
+	return addTorrentSpec(cli.Cli, snapshotName, snapshotHash, infoBytes)
 }
 
 func (cli *Client) AddTorrent(ctx context.Context, db ethdb.Database, snapshotType SnapshotType, networkID uint64) error { //nolint: interfacer
@@ -136,19 +128,18 @@ func (cli *Client) AddTorrent(ctx context.Context, db ethdb.Database, snapshotTy
 		}
 	}
 	log.Info("Added torrent spec", "snapshot", snapshotType.String(), "hash", infoHash.String())
-	t, err := cli.AddTorrentSpec(snapshotType.String(), infoHash, infoBytes)
+	err = cli.source.AddSnapshot(snapshotType.String(), infoHash, infoBytes)
 	if err != nil {
 		return fmt.Errorf("error on add snapshot: %w", err)
 	}
 	log.Info("Getting infobytes", "snapshot", snapshotType.String())
-	infoBytes, err = cli.GetInfoBytes(context.Background(), infoHash)
+	infoBytes, err = cli.source.StartDownload(context.Background(), infoHash)
 	if err != nil {
 		log.Warn("Init failure", "snapshot", snapshotType.String(), "err", ctx.Err())
 		return fmt.Errorf("error on get info bytes: %w", err)
 	}
-	t.AllowDataDownload()
-	t.DownloadAll()
-	log.Info("Got infobytes", "snapshot", snapshotType.String(), "file", t.Files()[0].Path())
+	path, _, _, _ := cli.source.SnapshotInfo(infoHash)
+	log.Info("Got infobytes", "snapshot", snapshotType.String(), "file", path)
 
 	if newTorrent {
 		log.Info("Save spec", "snapshot", snapshotType.String())
@@ -160,24 +151,6 @@ func (cli *Client) AddTorrent(ctx context.Context, db ethdb.Database, snapshotTy
 	return nil
 }
 
-func (cli *Client) GetInfoBytes(ctx context.Context, snapshotHash metainfo.Hash) ([]byte, error) {
-	t, ok := cli.Cli.Torrent(snapshotHash)
-	if !ok {
-		return nil, errors.New("torrent not added")
-	}
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("add torrent timeout: %w", ctx.Err())
-		case <-t.GotInfo():
-			return common.CopyBytes(t.Metainfo().InfoBytes), nil
-		default:
-			log.Info("Searching infobytes", "seeders", t.Stats().ConnectedSeeders, "active peers", t.Stats().ActivePeers)
-			time.Sleep(time.Second * 60)
-		}
-	}
-}
-
 func (cli *Client) AddSnapshotsTorrents(ctx context.Context, db ethdb.Database, networkId uint64, mode SnapshotMode) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute*10)
 	defer cancel()
@@ -214,46 +187,9 @@ func (cli *Client) AddSnapshotsTorrents(ctx context.Context, db ethdb.Database,
 	return nil
 }
 
-func (cli *Client) Download() {
+func (cli *Client) Download() error {
 	log.Info("Start snapshot downloading")
-	torrents := cli.Cli.Torrents()
-	for i := range torrents {
-		t := torrents[i]
-		go func(t *torrent.Torrent) {
-			defer debug.LogPanic()
-			t.AllowDataDownload()
-			t.DownloadAll()
-
-			tt := time.Now()
-			prev := t.BytesCompleted()
-		dwn:
-			for {
-				if t.Info().TotalLength()-t.BytesCompleted() == 0 {
-					log.Info("Dowloaded", "snapshot", t.Name(), "t", time.Since(tt))
-					break dwn
-				} else {
-					stats := t.Stats()
-					log.Info("Downloading snapshot",
-						"snapshot", t.Name(),
-						"%", int(100*(float64(t.BytesCompleted())/float64(t.Info().TotalLength()))),
-						"mb", t.BytesCompleted()/1024/1024,
-						"diff(kb)", (t.BytesCompleted()-prev)/1024,
-						"seeders", stats.ConnectedSeeders,
-						"active", stats.ActivePeers,
-						"total", stats.TotalPeers)
-					prev = t.BytesCompleted()
-					time.Sleep(time.Second * 10)
-
-				}
-
-			}
-		}(t)
-	}
-	cli.Cli.WaitAll()
-
-	for _, t := range cli.Cli.Torrents() {
-		log.Info("Snapshot seeding", "name", t.Name(), "seeding", t.Seeding())
-	}
+	return cli.source.WaitAll()
 }
 
 func (cli *Client) GetSnapshots(db ethdb.Database, networkID uint64) (map[SnapshotType]*SnapshotsInfo, error) {
@@ -266,20 +202,11 @@ func (cli *Client) GetSnapshots(db ethdb.Database, networkID uint64) (map[Snapsh
 			return nil
 		}
 		copy(hash[:], v)
-		t, ok := cli.Cli.Torrent(hash)
+		path, gotInfo, readiness, ok := cli.source.SnapshotInfo(hash)
 		if !ok {
 			return nil
 		}
 
-		var gotInfo bool
-		readiness := int32(0)
-		select {
-		case <-t.GotInfo():
-			gotInfo = true
-			readiness = int32(100 * (float64(t.BytesCompleted()) / float64(t.Info().TotalLength())))
-		default:
-		}
-
 		_, tpStr := ParseInfoHashKey(k)
 		tp, ok := SnapshotType_value[tpStr]
 		if !ok {
@@ -291,7 +218,7 @@ func (cli *Client) GetSnapshots(db ethdb.Database, networkID uint64) (map[Snapsh
 			GotInfoByte:   gotInfo,
 			Readiness:     readiness,
 			SnapshotBlock: SnapshotBlock,
-			Dbpath:        filepath.Join(cli.snapshotsDir, t.Files()[0].Path()),
+			Dbpath:        filepath.Join(cli.snapshotsDir, path),
 		}
 		mp[SnapshotType(tp)] = val
 		return nil
@@ -0,0 +1,322 @@
+package snapshotsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/log"
+)
+
+var _ SnapshotSource = &MirrorSource{}
+
+var ErrSnapshotVerification = errors.New("snapshot data doesn't match metainfo")
+
+const (
+	mirrorDialTimeout     = 30 * time.Second
+	mirrorResponseTimeout = time.Minute
+	// mirrorReadTimeout - download is aborted if mirror sends nothing for this time, whole download isn't limited
+	mirrorReadTimeout = time.Minute
+)
+
+// MirrorSource - downloads snapshots from local directory or HTTP(S) mirror, for networks where bittorrent is not available.
+// Mirror layout is:
+//
+//	<infohash hex>.torrent - metainfo of snapshot, needed only if info bytes are not known yet
+//	<info name>/<file path> - files of snapshot, same as torrent client stores them
+//
+// Downloaded data is verified by piece hashes of metainfo.
+type MirrorSource struct {
+	snapshotsDir string
+	url          string // directory or http(s):// url of mirror
+	client       *http.Client
+	readTimeout  time.Duration
+
+	lock      sync.Mutex
+	snapshots map[metainfo.Hash]*mirrorSnapshot
+	wg        sync.WaitGroup
+	err       error
+}
+
+type mirrorSnapshot struct {
+	name      string
+	info      *metainfo.Info
+	infoBytes []byte
+	completed int64 // atomic
+	total     int64
+}
+
+func NewMirrorSource(snapshotsDir string, url string) *MirrorSource {
+	return &MirrorSource{
+		snapshotsDir: snapshotsDir,
+		url:          strings.TrimSuffix(url, "/"),
+		client:       newMirrorClient(),
+		readTimeout:  mirrorReadTimeout,
+		snapshots:    map[metainfo.Hash]*mirrorSnapshot{},
+	}
+}
+
+func newMirrorClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: mirrorDialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+			TLSHandshakeTimeout:   mirrorDialTimeout,
+			ResponseHeaderTimeout: mirrorResponseTimeout,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+}
+
+func (s *MirrorSource) AddSnapshot(name string, infoHash metainfo.Hash, infoBytes []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.snapshots[infoHash]; ok {
+		return nil
+	}
+	sn := &mirrorSnapshot{name: name}
+	if infoBytes != nil {
+		info, err := unmarshalInfo(infoHash, infoBytes)
+		if err != nil {
+			return err
+		}
+		sn.info, sn.infoBytes = info, infoBytes
+	}
+	s.snapshots[infoHash] = sn
+	return nil
+}
+
+// StartDownload - download is stopped when ctx is done
+func (s *MirrorSource) StartDownload(ctx context.Context, infoHash metainfo.Hash) ([]byte, error) {
+	s.lock.Lock()
+	sn, ok := s.snapshots[infoHash]
+	s.lock.Unlock()
+	if !ok {
+		return nil, errors.New("snapshot not added")
+	}
+	if sn.info == nil {
+		r, err := s.open(ctx, infoHash.HexString()+".torrent")
+		if err != nil {
+			return nil, fmt.Errorf("get metainfo of %s: %w", sn.name, err)
+		}
+		mi, err := metainfo.Load(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode metainfo of %s: %w", sn.name, err)
+		}
+		info, err := unmarshalInfo(infoHash, mi.InfoBytes)
+		if err != nil {
+			return nil, err
+		}
+		s.lock.Lock()
+		sn.info, sn.infoBytes = info, mi.InfoBytes
+		s.lock.Unlock()
+	}
+	s.lock.Lock()
+	atomic.StoreInt64(&sn.completed, 0)
+	sn.total = sn.info.TotalLength()
+	infoBytes := sn.infoBytes
+	s.lock.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer debug.LogPanic()
+		defer s.wg.Done()
+		if err := s.download(ctx, sn); err != nil {
+			log.Error("Snapshot download failed", "snapshot", sn.name, "err", err)
+			s.lock.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.lock.Unlock()
+			return
+		}
+		log.Info("Downloaded", "snapshot", sn.name, "from", s.url)
+	}()
+	return infoBytes, nil
+}
+
+func (s *MirrorSource) WaitAll() error {
+	s.wg.Wait()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+func (s *MirrorSource) SnapshotInfo(infoHash metainfo.Hash) (string, bool, int32, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sn, ok := s.snapshots[infoHash]
+	if !ok {
+		return "", false, 0, false
+	}
+	if sn.info == nil || sn.total == 0 {
+		return "", false, 0, true
+	}
+	files := sn.info.UpvertedFiles()
+	return filepath.Join(sn.info.Name, filepath.Join(files[0].Path...)), true, int32(100 * atomic.LoadInt64(&sn.completed) / sn.total), true
+}
+
+// download - fetches files of snapshot into ".part" files, and renames them after verification.
+// Already downloaded and valid snapshot is not fetched again.
+func (s *MirrorSource) download(ctx context.Context, sn *mirrorSnapshot) error {
+	info := sn.info
+	files := info.UpvertedFiles()
+	if err := verifyPieces(info, func(fi metainfo.FileInfo) (io.ReadCloser, error) {
+		return os.Open(s.localPath(info, fi))
+	}); err == nil {
+		atomic.StoreInt64(&sn.completed, sn.total)
+		return nil
+	}
+
+	for _, fi := range files {
+		if err := s.downloadFile(ctx, sn, fi); err != nil {
+			return err
+		}
+	}
+	if err := verifyPieces(info, func(fi metainfo.FileInfo) (io.ReadCloser, error) {
+		return os.Open(s.localPath(info, fi) + ".part")
+	}); err != nil {
+		for _, fi := range files {
+			_ = os.Remove(s.localPath(info, fi) + ".part")
+		}
+		return fmt.Errorf("%s: %w", sn.name, err)
+	}
+	for _, fi := range files {
+		if err := os.Rename(s.localPath(info, fi)+".part", s.localPath(info, fi)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MirrorSource) downloadFile(ctx context.Context, sn *mirrorSnapshot, fi metainfo.FileInfo) error {
+	path := s.localPath(sn.info, fi)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	r, err := s.open(ctx, strings.Join(append([]string{sn.info.Name}, fi.Path...), "/"))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := os.Create(path + ".part")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(f, &progressReader{r: r, completed: &sn.completed})
+	if err != nil {
+		return fmt.Errorf("download %s: %w", strings.Join(fi.Path, "/"), err)
+	}
+	if n != fi.Length {
+		return fmt.Errorf("download %s: %w, length %d, expected %d", strings.Join(fi.Path, "/"), ErrSnapshotVerification, n, fi.Length)
+	}
+	return f.Sync()
+}
+
+func (s *MirrorSource) localPath(info *metainfo.Info, fi metainfo.FileInfo) string {
+	return filepath.Join(append([]string{s.snapshotsDir, info.Name}, fi.Path...)...)
+}
+
+// open - reads file of mirror by relative path
+func (s *MirrorSource) open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(s.url, "http://") && !strings.HasPrefix(s.url, "https://") {
+		return os.Open(filepath.Join(s.url, filepath.FromSlash(path)))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/"+path, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("get %s: %s", path, resp.Status)
+	}
+	r := &timeoutReader{r: resp.Body, timeout: s.readTimeout, cancel: cancel}
+	r.timer = time.AfterFunc(s.readTimeout, func() {
+		atomic.StoreInt32(&r.timedOut, 1)
+		cancel()
+	})
+	return r, nil
+}
+
+// timeoutReader - cancels request if nothing is read for timeout
+type timeoutReader struct {
+	r        io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	cancel   context.CancelFunc
+	timedOut int32 // atomic
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && atomic.LoadInt32(&r.timedOut) == 1 {
+		return n, fmt.Errorf("nothing received for %s: %w", r.timeout, err)
+	}
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+func (r *timeoutReader) Close() error {
+	r.timer.Stop()
+	r.cancel()
+	return r.r.Close()
+}
+
+func unmarshalInfo(infoHash metainfo.Hash, infoBytes []byte) (*metainfo.Info, error) {
+	if metainfo.HashBytes(infoBytes) != infoHash {
+		return nil, fmt.Errorf("%w: info bytes hash %s, expected %s", ErrSnapshotVerification, metainfo.HashBytes(infoBytes), infoHash)
+	}
+	info := &metainfo.Info{}
+	if err := bencode.Unmarshal(infoBytes, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// verifyPieces - compares piece hashes of files with metainfo
+func verifyPieces(info *metainfo.Info, open func(fi metainfo.FileInfo) (io.ReadCloser, error)) error {
+	actual := *info
+	if err := actual.GeneratePieces(open); err != nil {
+		return err
+	}
+	if !bytes.Equal(actual.Pieces, info.Pieces) {
+		return ErrSnapshotVerification
+	}
+	return nil
+}
+
+type progressReader struct {
+	r         io.Reader
+	completed *int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(r.completed, int64(n))
+	return n, err
+}
@@ -0,0 +1,139 @@
+package snapshotsync
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMirror - creates mirror directory with one snapshot, returns it's infohash and data
+func newTestMirror(t *testing.T, withMetainfo bool) (string, metainfo.Hash, []byte) {
+	mirror := t.TempDir()
+	snapshotDir := filepath.Join(mirror, "headers")
+	require.NoError(t, os.MkdirAll(snapshotDir, 0755))
+	data := make([]byte, 3*DefaultChunkSize+100)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(snapshotDir, MdbxFilename), data, 0600))
+
+	info, err := BuildInfoBytesForSnapshot(snapshotDir, MdbxFilename)
+	require.NoError(t, err)
+	infoBytes, err := bencode.Marshal(info)
+	require.NoError(t, err)
+	infoHash := metainfo.HashBytes(infoBytes)
+	if withMetainfo {
+		f, err := os.Create(filepath.Join(mirror, infoHash.HexString()+".torrent"))
+		require.NoError(t, err)
+		require.NoError(t, (&metainfo.MetaInfo{InfoBytes: infoBytes}).Write(f))
+		require.NoError(t, f.Close())
+	}
+	return mirror, infoHash, data
+}
+
+func TestMirrorSource(t *testing.T) {
+	mirror, infoHash, data := newTestMirror(t, true)
+	server := httptest.NewServer(http.FileServer(http.Dir(mirror)))
+	defer server.Close()
+
+	for name, url := range map[string]string{"dir": mirror, "http": server.URL} {
+		t.Run(name, func(t *testing.T) {
+			snapshotsDir := t.TempDir()
+			source := NewMirrorSource(snapshotsDir, url)
+			require.NoError(t, source.AddSnapshot("headers", infoHash, nil))
+			infoBytes, err := source.StartDownload(context.Background(), infoHash)
+			require.NoError(t, err)
+			require.Equal(t, infoHash, metainfo.HashBytes(infoBytes))
+			require.NoError(t, source.WaitAll())
+
+			path, gotInfo, readiness, ok := source.SnapshotInfo(infoHash)
+			require.True(t, ok)
+			require.True(t, gotInfo)
+			require.Equal(t, int32(100), readiness)
+			require.Equal(t, filepath.Join("headers", MdbxFilename), path)
+			downloaded, err := ioutil.ReadFile(filepath.Join(snapshotsDir, path))
+			require.NoError(t, err)
+			require.Equal(t, data, downloaded)
+
+			// known info bytes and valid files - nothing to download
+			source = NewMirrorSource(snapshotsDir, filepath.Join(mirror, "nonexistent"))
+			require.NoError(t, source.AddSnapshot("headers", infoHash, infoBytes))
+			_, err = source.StartDownload(context.Background(), infoHash)
+			require.NoError(t, err)
+			require.NoError(t, source.WaitAll())
+		})
+	}
+}
+
+func TestMirrorSourceVerification(t *testing.T) {
+	mirror, infoHash, data := newTestMirror(t, true)
+	data[DefaultChunkSize+1]++
+	require.NoError(t, ioutil.WriteFile(filepath.Join(mirror, "headers", MdbxFilename), data, 0600))
+
+	snapshotsDir := t.TempDir()
+	source := NewMirrorSource(snapshotsDir, mirror)
+	require.NoError(t, source.AddSnapshot("headers", infoHash, nil))
+	_, err := source.StartDownload(context.Background(), infoHash)
+	require.NoError(t, err)
+	require.True(t, errors.Is(source.WaitAll(), ErrSnapshotVerification))
+	_, err = os.Stat(filepath.Join(snapshotsDir, "headers", MdbxFilename))
+	require.True(t, os.IsNotExist(err))
+
+	// metainfo of other snapshot
+	other := metainfo.Hash{1}
+	require.NoError(t, os.Rename(filepath.Join(mirror, infoHash.HexString()+".torrent"), filepath.Join(mirror, other.HexString()+".torrent")))
+	source = NewMirrorSource(t.TempDir(), mirror)
+	require.NoError(t, source.AddSnapshot("headers", other, nil))
+	_, err = source.StartDownload(context.Background(), other)
+	require.True(t, errors.Is(err, ErrSnapshotVerification))
+}
+
+func TestMirrorSourceStalled(t *testing.T) {
+	mirror, infoHash, _ := newTestMirror(t, true)
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Ext(r.URL.Path) == ".torrent" {
+			http.ServeFile(w, r, filepath.Join(mirror, filepath.FromSlash(r.URL.Path)))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte{1, 2, 3})
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-stalled:
+		}
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	// mirror sends nothing
+	source := NewMirrorSource(t.TempDir(), server.URL)
+	source.readTimeout = 100 * time.Millisecond
+	require.NoError(t, source.AddSnapshot("headers", infoHash, nil))
+	_, err := source.StartDownload(context.Background(), infoHash)
+	require.NoError(t, err)
+	err = source.WaitAll()
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Contains(t, err.Error(), "nothing received")
+
+	// download is stopped by ctx of caller
+	ctx, cancel := context.WithCancel(context.Background())
+	source = NewMirrorSource(t.TempDir(), server.URL)
+	require.NoError(t, source.AddSnapshot("headers", infoHash, nil))
+	_, err = source.StartDownload(ctx, infoHash)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	require.True(t, errors.Is(source.WaitAll(), context.Canceled))
+}
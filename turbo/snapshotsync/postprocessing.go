@@ -62,6 +62,13 @@ func PostProcessing(db ethdb.Database, downloadedSnapshots map[SnapshotType]*Sna
 		}
 	}
 
+	if _, ok := downloadedSnapshots[SnapshotType_receipts]; ok {
+		err := PostProcessReceipts(db, downloadedSnapshots[SnapshotType_receipts])
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -141,6 +148,11 @@ func PostProcessState(db ethdb.GetterPutter, info *SnapshotsInfo) error {
 	return nil
 }
 
+// PostProcessReceipts - marks receipts of snapshot blocks as available, so execution stage doesn't write them again
+func PostProcessReceipts(db ethdb.Putter, info *SnapshotsInfo) error {
+	return db.Put(dbutils.BittorrentInfoBucket, dbutils.CurrentReceiptsSnapshotBlock, dbutils.EncodeBlockNumber(info.SnapshotBlock))
+}
+
 //It'll be enabled later
 func PostProcessNoBlocksSync(db ethdb.Database, blockNum uint64, blockHash common.Hash, blockHeaderBytes, blockBodyBytes []byte) error {
 	v, err := stages.GetStageProgress(db, stages.Execution)
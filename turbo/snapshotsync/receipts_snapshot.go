@@ -0,0 +1,95 @@
+package snapshotsync
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/log"
+)
+
+func CreateReceiptsSnapshot(ctx context.Context, readTX ethdb.Tx, toBlock uint64, snapshotPath string) error {
+	// remove created snapshot if it's not saved in main db(to avoid append error)
+	err := os.RemoveAll(snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	snKV, err := kv.NewMDBX().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		return BucketConfigs[SnapshotType_receipts]
+	}).Path(snapshotPath).Open()
+	if err != nil {
+		return err
+	}
+	defer snKV.Close()
+	sntx, err := snKV.BeginRw(context.Background())
+	if err != nil {
+		return fmt.Errorf("begin err: %w", err)
+	}
+	defer sntx.Rollback()
+
+	err = GenerateReceiptsSnapshot(ctx, readTX, sntx, toBlock)
+	if err != nil {
+		return fmt.Errorf("generate err: %w", err)
+	}
+	err = sntx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit err: %w", err)
+	}
+	return nil
+}
+
+// GenerateReceiptsSnapshot - copies receipts and logs of blocks [0, toBlock] to snapshot.
+// Only canonical receipts are stored in db, so no canonical check is needed.
+func GenerateReceiptsSnapshot(ctx context.Context, db ethdb.Tx, sntx ethdb.RwTx, toBlock uint64) error {
+	t := time.NewTicker(time.Second * 30)
+	defer t.Stop()
+	tt := time.Now()
+	for _, bucket := range []string{dbutils.BlockReceiptsPrefix, dbutils.Log} {
+		c, err := db.Cursor(bucket)
+		if err != nil {
+			return err
+		}
+		snC, err := sntx.RwCursor(bucket)
+		if err != nil {
+			c.Close()
+			return err
+		}
+		err = ethdb.Walk(c, nil, 0, func(k, v []byte) (bool, error) {
+			blockNum := binary.BigEndian.Uint64(k[:8])
+			if blockNum > toBlock {
+				return false, nil
+			}
+			if common.IsCanceled(ctx) {
+				return false, common.ErrStopped
+			}
+			select {
+			case <-t.C:
+				log.Info("Receipts snapshot generation", "t", time.Since(tt), "bucket", bucket, "block", blockNum)
+			default:
+			}
+			if err = snC.Append(common.CopyBytes(k), common.CopyBytes(v)); err != nil {
+				return false, err
+			}
+			return true, nil
+		})
+		c.Close()
+		snC.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func OpenReceiptsSnapshot(dbPath string) (ethdb.RoKV, error) {
+	return kv.NewMDBX().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		return BucketConfigs[SnapshotType_receipts]
+	}).Readonly().Path(dbPath).Open()
+}
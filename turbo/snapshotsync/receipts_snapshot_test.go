@@ -0,0 +1,91 @@
+package snapshotsync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptsSnapshot(t *testing.T) {
+	ctx := context.Background()
+	receipts := func(blockNum uint64) types.Receipts {
+		return types.Receipts{
+			{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: blockNum * 100},
+			{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: blockNum * 200, Logs: []*types.Log{
+				{Address: common.Address{byte(blockNum)}, Topics: []common.Hash{{byte(blockNum)}}, Data: []byte{byte(blockNum)}},
+			}},
+		}
+	}
+
+	db := kv.NewTestKV(t)
+	require.NoError(t, db.Update(ctx, func(tx ethdb.RwTx) error {
+		for i := uint64(0); i <= 5; i++ {
+			if err := rawdb.AppendReceipts(tx, i, receipts(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	snapshotPath := filepath.Join(t.TempDir(), "receipts3")
+	require.NoError(t, db.View(ctx, func(tx ethdb.Tx) error {
+		return CreateReceiptsSnapshot(ctx, tx, 3, snapshotPath)
+	}))
+	snKV, err := OpenReceiptsSnapshot(snapshotPath)
+	require.NoError(t, err)
+	require.NoError(t, snKV.View(ctx, func(tx ethdb.Tx) error {
+		for bucket, lastKey := range map[string][]byte{
+			dbutils.BlockReceiptsPrefix: dbutils.ReceiptsKey(3),
+			dbutils.Log:                 dbutils.LogKey(3, 1),
+		} {
+			c, err := tx.Cursor(bucket)
+			require.NoError(t, err)
+			k, _, err := c.Last()
+			require.NoError(t, err)
+			require.Equal(t, lastKey, k, bucket)
+			c.Close()
+		}
+		return nil
+	}))
+
+	// new node has receipts of snapshot blocks only in snapshot
+	mainDB := kv.NewTestKV(t)
+	require.NoError(t, mainDB.Update(ctx, func(tx ethdb.RwTx) error {
+		for i := uint64(4); i <= 5; i++ {
+			if err := rawdb.AppendReceipts(tx, i, receipts(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	snapshotKV := kv.NewSnapshotKV().DB(mainDB).ReceiptsSnapshot(snKV).Open()
+	defer snapshotKV.Close()
+	require.NoError(t, snapshotKV.View(ctx, func(tx ethdb.Tx) error {
+		snTx, ok := tx.(kv.SnapshotTX)
+		require.True(t, ok)
+		require.True(t, snTx.HasSnapshot(dbutils.BlockReceiptsPrefix))
+		require.False(t, snTx.HasSnapshot(dbutils.HeadersBucket))
+		for i := uint64(0); i <= 5; i++ {
+			got := rawdb.ReadRawReceipts(tx, i)
+			expected := receipts(i)
+			require.Len(t, got, len(expected), "block %d", i)
+			for j := range expected {
+				require.Equal(t, expected[j].CumulativeGasUsed, got[j].CumulativeGasUsed, "block %d", i)
+				require.Equal(t, len(expected[j].Logs), len(got[j].Logs), "block %d", i)
+				for l := range expected[j].Logs {
+					require.Equal(t, expected[j].Logs[l].Address, got[j].Logs[l].Address, "block %d", i)
+					require.Equal(t, expected[j].Logs[l].Data, got[j].Logs[l].Data, "block %d", i)
+				}
+			}
+		}
+		return nil
+	}))
+}
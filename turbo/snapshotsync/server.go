@@ -61,6 +61,12 @@ func (s *SNDownloaderServer) Download(ctx context.Context, request *DownloadSnap
 	}
 	return &empty.Empty{}, nil
 }
+
+// SetSource - downloads snapshots from given source instead of bittorrent
+func (s *SNDownloaderServer) SetSource(source SnapshotSource) {
+	s.t.SetSource(source)
+}
+
 func (s *SNDownloaderServer) Load() error {
 	return s.t.Load(s.db)
 }
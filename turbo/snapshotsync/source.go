@@ -0,0 +1,131 @@
+package snapshotsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/log"
+)
+
+// SnapshotSource - the way Client obtains snapshot files. Snapshots are identified by infohash of their torrent,
+// so any source must verify data against the same metainfo.
+type SnapshotSource interface {
+	// AddSnapshot registers snapshot, infoBytes is nil if it's not known yet
+	AddSnapshot(name string, infoHash metainfo.Hash, infoBytes []byte) error
+	// StartDownload starts downloading of registered snapshot and returns it's info bytes, verified against infohash
+	StartDownload(ctx context.Context, infoHash metainfo.Hash) ([]byte, error)
+	// WaitAll blocks until all started downloads are finished
+	WaitAll() error
+	// SnapshotInfo returns state of registered snapshot: path of it's first file relative to snapshots dir
+	// and readiness in percents. ok is false if snapshot is not registered.
+	SnapshotInfo(infoHash metainfo.Hash) (path string, gotInfo bool, readiness int32, ok bool)
+}
+
+var _ SnapshotSource = &torrentSource{}
+
+// torrentSource - downloads snapshots by bittorrent
+type torrentSource struct {
+	cli *torrent.Client
+}
+
+func (s *torrentSource) AddSnapshot(name string, infoHash metainfo.Hash, infoBytes []byte) error {
+	_, err := addTorrentSpec(s.cli, name, infoHash, infoBytes)
+	return err
+}
+
+func (s *torrentSource) StartDownload(ctx context.Context, infoHash metainfo.Hash) ([]byte, error) {
+	t, ok := s.cli.Torrent(infoHash)
+	if !ok {
+		return nil, errors.New("torrent not added")
+	}
+	var infoBytes []byte
+	for infoBytes == nil {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("add torrent timeout: %w", ctx.Err())
+		case <-t.GotInfo():
+			infoBytes = common.CopyBytes(t.Metainfo().InfoBytes)
+		default:
+			log.Info("Searching infobytes", "seeders", t.Stats().ConnectedSeeders, "active peers", t.Stats().ActivePeers)
+			time.Sleep(time.Second * 60)
+		}
+	}
+	t.AllowDataDownload()
+	t.DownloadAll()
+	return infoBytes, nil
+}
+
+func (s *torrentSource) WaitAll() error {
+	torrents := s.cli.Torrents()
+	for i := range torrents {
+		t := torrents[i]
+		go func(t *torrent.Torrent) {
+			defer debug.LogPanic()
+			t.AllowDataDownload()
+			t.DownloadAll()
+
+			tt := time.Now()
+			prev := t.BytesCompleted()
+		dwn:
+			for {
+				if t.Info().TotalLength()-t.BytesCompleted() == 0 {
+					log.Info("Dowloaded", "snapshot", t.Name(), "t", time.Since(tt))
+					break dwn
+				} else {
+					stats := t.Stats()
+					log.Info("Downloading snapshot",
+						"snapshot", t.Name(),
+						"%", int(100*(float64(t.BytesCompleted())/float64(t.Info().TotalLength()))),
+						"mb", t.BytesCompleted()/1024/1024,
+						"diff(kb)", (t.BytesCompleted()-prev)/1024,
+						"seeders", stats.ConnectedSeeders,
+						"active", stats.ActivePeers,
+						"total", stats.TotalPeers)
+					prev = t.BytesCompleted()
+					time.Sleep(time.Second * 10)
+
+				}
+
+			}
+		}(t)
+	}
+	s.cli.WaitAll()
+
+	for _, t := range s.cli.Torrents() {
+		log.Info("Snapshot seeding", "name", t.Name(), "seeding", t.Seeding())
+	}
+	return nil
+}
+
+func (s *torrentSource) SnapshotInfo(infoHash metainfo.Hash) (string, bool, int32, bool) {
+	t, ok := s.cli.Torrent(infoHash)
+	if !ok {
+		return "", false, 0, false
+	}
+	select {
+	case <-t.GotInfo():
+		return t.Files()[0].Path(), true, int32(100 * (float64(t.BytesCompleted()) / float64(t.Info().TotalLength()))), true
+	default:
+		return "", false, 0, true
+	}
+}
+
+func addTorrentSpec(cli *torrent.Client, snapshotName string, snapshotHash metainfo.Hash, infoBytes []byte) (*torrent.Torrent, error) {
+	t, ok := cli.Torrent(snapshotHash)
+	if ok {
+		return t, nil
+	}
+	t, _, err := cli.AddTorrentSpec(&torrent.TorrentSpec{
+		Trackers:    Trackers,
+		InfoHash:    snapshotHash,
+		DisplayName: snapshotName,
+		InfoBytes:   infoBytes,
+	})
+	return t, err
+}
@@ -0,0 +1,231 @@
+package snapshotsync
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/changeset"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/log"
+)
+
+// State diff segment - changes of PlainState in blocks (from, to], built from changesets.
+// Base state snapshot at block N plus diffs (N, N+EpochSize], (N+EpochSize, N+2*EpochSize]... give state at the last block.
+// PlainState of segment has values at the end of block `to`, keys deleted in these blocks are in StateDiffDeletedBucket
+// (mdbx can't store empty values). New contracts code is in PlainContractCode/Code buckets.
+const StateDiffDeletedBucket = "StateDiffDeleted"
+
+var (
+	StateDiffBucketsCfg = dbutils.BucketsCfg{
+		dbutils.PlainStateBucket:        dbutils.BucketConfigItem{},
+		StateDiffDeletedBucket:          dbutils.BucketConfigItem{},
+		dbutils.PlainContractCodeBucket: dbutils.BucketConfigItem{},
+		dbutils.CodeBucket:              dbutils.BucketConfigItem{},
+		dbutils.SnapshotInfoBucket:      dbutils.BucketConfigItem{},
+	}
+	stateDiffRangeKey = []byte("stateDiffRange")
+
+	ErrStateDiffOrder = errors.New("state diff doesn't continue state")
+)
+
+func StateDiffName(baseDir string, from, to uint64) string {
+	return filepath.Join(baseDir, fmt.Sprintf("state_diff%d-%d", from, to))
+}
+
+// StateDiffSnapshots - paths of state diffs in the dir, in order of blocks
+func StateDiffSnapshots(baseDir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(baseDir, "state_diff*-*"))
+	if err != nil {
+		return nil, err
+	}
+	type diffPath struct {
+		path string
+		from uint64
+	}
+	diffs := make([]diffPath, 0, len(paths))
+	for _, path := range paths {
+		var from, to uint64
+		if _, err = fmt.Sscanf(filepath.Base(path), "state_diff%d-%d", &from, &to); err != nil {
+			continue
+		}
+		diffs = append(diffs, diffPath{path: path, from: from})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].from < diffs[j].from })
+	paths = paths[:0]
+	for _, d := range diffs {
+		paths = append(paths, d.path)
+	}
+	return paths, nil
+}
+
+// StateDiffSeeding - seeds state diffs created by CreateStateSnapshot stage before restart
+func StateDiffSeeding(cli *Client, snapshotsDir string) error {
+	paths, err := StateDiffSnapshots(snapshotsDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		hash, err := cli.SeedSnapshot(filepath.Base(path), path)
+		if err != nil {
+			return fmt.Errorf("seeding of %s: %w", path, err)
+		}
+		log.Info("Start seeding", "snapshot", filepath.Base(path), "hash", hash.String())
+	}
+	return nil
+}
+
+func CreateStateDiffSnapshot(ctx context.Context, readTX ethdb.Tx, from, to uint64, snapshotPath string) error {
+	err := os.RemoveAll(snapshotPath)
+	if err != nil {
+		return err
+	}
+	snKV, err := kv.NewMDBX().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		return StateDiffBucketsCfg
+	}).Path(snapshotPath).Open()
+	if err != nil {
+		return err
+	}
+	defer snKV.Close()
+	return snKV.Update(ctx, func(sntx ethdb.RwTx) error {
+		return GenerateStateDiff(ctx, readTX, sntx, from, to)
+	})
+}
+
+func OpenStateDiffSnapshot(dbPath string) (ethdb.RoKV, error) {
+	return kv.NewMDBX().WithBucketsConfig(func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+		return StateDiffBucketsCfg
+	}).Readonly().Path(dbPath).Open()
+}
+
+// GenerateStateDiff - writes to diff values of all keys changed in blocks (from, to], as of block `to`
+func GenerateStateDiff(ctx context.Context, tx ethdb.Tx, diff ethdb.RwTx, from, to uint64) error {
+	if to <= from {
+		return fmt.Errorf("empty range of state diff: (%d, %d]", from, to)
+	}
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	for _, csBucket := range []string{dbutils.AccountChangeSetBucket, dbutils.StorageChangeSetBucket} {
+		storage := csBucket == dbutils.StorageChangeSetBucket
+		if err := changeset.Walk(tx, csBucket, dbutils.EncodeBlockNumber(from+1), 0, func(blockN uint64, k, _ []byte) (bool, error) {
+			if blockN > to {
+				return false, nil
+			}
+			if common.IsCanceled(ctx) {
+				return false, common.ErrStopped
+			}
+			select {
+			case <-logEvery.C:
+				log.Info("State diff generation", "bucket", csBucket, "block", blockN)
+			default:
+			}
+			for _, bucket := range []string{dbutils.PlainStateBucket, StateDiffDeletedBucket} {
+				if has, err := diff.Has(bucket, k); err != nil || has {
+					return err == nil, err
+				}
+			}
+			v, err := state.GetAsOf(tx, storage, k, to+1)
+			if err != nil {
+				return false, err
+			}
+			if len(v) == 0 {
+				return true, diff.Put(StateDiffDeletedBucket, common.CopyBytes(k), []byte{})
+			}
+			if err = diff.Put(dbutils.PlainStateBucket, common.CopyBytes(k), common.CopyBytes(v)); err != nil {
+				return false, err
+			}
+			if storage {
+				return true, nil
+			}
+			return true, copyCode(tx, diff, k, v)
+		}); err != nil {
+			return err
+		}
+	}
+	rangeV := make([]byte, 16)
+	binary.BigEndian.PutUint64(rangeV, from)
+	binary.BigEndian.PutUint64(rangeV[8:], to)
+	return diff.Put(dbutils.SnapshotInfoBucket, stateDiffRangeKey, rangeV)
+}
+
+func copyCode(tx ethdb.Tx, diff ethdb.RwTx, address, encodedAccount []byte) error {
+	var acc accounts.Account
+	if err := acc.DecodeForStorage(encodedAccount); err != nil {
+		return fmt.Errorf("decoding %x for %x: %w", encodedAccount, address, err)
+	}
+	if acc.Incarnation == 0 || acc.IsEmptyCodeHash() {
+		return nil
+	}
+	storagePrefix := dbutils.PlainGenerateStoragePrefix(address, acc.Incarnation)
+	codeHash, err := tx.GetOne(dbutils.PlainContractCodeBucket, storagePrefix)
+	if err != nil {
+		return err
+	}
+	if len(codeHash) == 0 {
+		return nil
+	}
+	code, err := tx.GetOne(dbutils.CodeBucket, codeHash)
+	if err != nil {
+		return err
+	}
+	if err = diff.Put(dbutils.CodeBucket, common.CopyBytes(codeHash), common.CopyBytes(code)); err != nil {
+		return err
+	}
+	return diff.Put(dbutils.PlainContractCodeBucket, storagePrefix, common.CopyBytes(codeHash))
+}
+
+// StateDiffRange - blocks (from, to] of diff
+func StateDiffRange(diff ethdb.Tx) (uint64, uint64, error) {
+	v, err := diff.GetOne(dbutils.SnapshotInfoBucket, stateDiffRangeKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(v) != 16 {
+		return 0, 0, errors.New("state diff range not found")
+	}
+	return binary.BigEndian.Uint64(v), binary.BigEndian.Uint64(v[8:]), nil
+}
+
+// WalkStateDiff - walks over PlainState and code changes of diff, deleted PlainState keys are passed with nil value
+func WalkStateDiff(diff ethdb.Tx, walker func(bucket string, k, v []byte) error) error {
+	for _, bucket := range []string{dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket, dbutils.CodeBucket} {
+		if err := diff.ForEach(bucket, nil, func(k, v []byte) error {
+			return walker(bucket, k, v)
+		}); err != nil {
+			return err
+		}
+	}
+	return diff.ForEach(StateDiffDeletedBucket, nil, func(k, _ []byte) error {
+		return walker(dbutils.PlainStateBucket, k, nil)
+	})
+}
+
+// ApplyStateDiff - moves state of `tx` from block `stateBlock` to the end of diff, returns new block of state
+func ApplyStateDiff(tx ethdb.RwTx, diff ethdb.Tx, stateBlock uint64) (uint64, error) {
+	from, to, err := StateDiffRange(diff)
+	if err != nil {
+		return 0, err
+	}
+	if from != stateBlock {
+		return 0, fmt.Errorf("%w: diff (%d, %d], state at block %d", ErrStateDiffOrder, from, to, stateBlock)
+	}
+	if err = WalkStateDiff(diff, func(bucket string, k, v []byte) error {
+		if len(v) == 0 {
+			return tx.Delete(bucket, k, nil)
+		}
+		return tx.Put(bucket, common.CopyBytes(k), common.CopyBytes(v))
+	}); err != nil {
+		return 0, err
+	}
+	return to, nil
+}
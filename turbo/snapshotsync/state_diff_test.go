@@ -0,0 +1,136 @@
+package snapshotsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDiff(t *testing.T) {
+	ctx := context.Background()
+	addrA, addrB, addrC, addrD := common.Address{1}, common.Address{2}, common.Address{3}, common.Address{4}
+	slot1, slot2 := common.Hash{1}, common.Hash{2}
+	code, codeD := []byte{0x60, 0x00}, []byte{0x60, 0x01}
+	codeHash, codeHashD := crypto.Keccak256Hash(code), crypto.Keccak256Hash(codeD)
+	account := func(balance uint64, incarnation uint64) *accounts.Account {
+		acc := accounts.NewAccount()
+		acc.Balance.SetUint64(balance)
+		acc.Initialised = true
+		acc.Incarnation = incarnation
+		if incarnation > 0 {
+			acc.CodeHash = codeHash
+		}
+		return &acc
+	}
+	contractD := account(4, 1)
+	contractD.CodeHash = codeHashD
+	empty := &accounts.Account{}
+
+	// block 1 - base state, it's in snapshot
+	writeBase := func(w *state.PlainStateWriter) {
+		require.NoError(t, w.UpdateAccountData(addrA, empty, account(1, 0)))
+		require.NoError(t, w.UpdateAccountData(addrB, empty, account(2, 0)))
+		require.NoError(t, w.UpdateAccountData(addrC, empty, account(3, 1)))
+		require.NoError(t, w.UpdateAccountCode(addrC, 1, codeHash, code))
+		require.NoError(t, w.WriteAccountStorage(addrC, 1, &slot1, uint256.NewInt(0), uint256.NewInt(1)))
+		require.NoError(t, w.WriteAccountStorage(addrC, 1, &slot2, uint256.NewInt(0), uint256.NewInt(2)))
+	}
+	db := kv.NewTestKV(t)
+	require.NoError(t, db.Update(ctx, func(tx ethdb.RwTx) error {
+		w := state.NewPlainStateWriter(tx, tx, 1)
+		writeBase(w)
+		require.NoError(t, w.WriteChangeSets())
+		require.NoError(t, w.WriteHistory())
+
+		w = state.NewPlainStateWriter(tx, tx, 2)
+		require.NoError(t, w.UpdateAccountData(addrA, account(1, 0), account(10, 0)))
+		require.NoError(t, w.DeleteAccount(addrB, account(2, 0)))
+		require.NoError(t, w.WriteAccountStorage(addrC, 1, &slot1, uint256.NewInt(1), uint256.NewInt(5)))
+		require.NoError(t, w.WriteAccountStorage(addrC, 1, &slot2, uint256.NewInt(2), uint256.NewInt(0)))
+		require.NoError(t, w.WriteChangeSets())
+		require.NoError(t, w.WriteHistory())
+
+		w = state.NewPlainStateWriter(tx, tx, 3)
+		require.NoError(t, w.UpdateAccountData(addrD, empty, contractD))
+		require.NoError(t, w.UpdateAccountCode(addrD, 1, codeHashD, codeD))
+		require.NoError(t, w.WriteChangeSets())
+		require.NoError(t, w.WriteHistory())
+
+		// block 4 is out of diff
+		w = state.NewPlainStateWriter(tx, tx, 4)
+		require.NoError(t, w.UpdateAccountData(addrA, account(10, 0), account(20, 0)))
+		require.NoError(t, w.WriteChangeSets())
+		return w.WriteHistory()
+	}))
+
+	diffPath := StateDiffName(t.TempDir(), 1, 3)
+	require.NoError(t, db.View(ctx, func(tx ethdb.Tx) error {
+		return CreateStateDiffSnapshot(ctx, tx, 1, 3, diffPath)
+	}))
+	diffKV, err := OpenStateDiffSnapshot(diffPath)
+	require.NoError(t, err)
+	defer diffKV.Close()
+
+	baseKV := kv.NewTestKV(t)
+	require.NoError(t, baseKV.Update(ctx, func(tx ethdb.RwTx) error {
+		writeBase(state.NewPlainStateWriter(tx, nil, 1))
+		return nil
+	}))
+	snapshotKV := kv.NewSnapshotKV().DB(kv.NewTestKV(t)).StateSnapshot(baseKV).Open()
+	defer snapshotKV.Close()
+
+	require.NoError(t, diffKV.View(ctx, func(diffTx ethdb.Tx) error {
+		// only code of contract created in diff blocks
+		var codes int
+		require.NoError(t, WalkStateDiff(diffTx, func(bucket string, k, v []byte) error {
+			switch bucket {
+			case dbutils.CodeBucket:
+				require.Equal(t, codeHashD[:], k)
+				require.Equal(t, codeD, v)
+				codes++
+			case dbutils.PlainContractCodeBucket:
+				require.Equal(t, dbutils.PlainGenerateStoragePrefix(addrD[:], 1), k)
+				require.Equal(t, codeHashD[:], v)
+			}
+			return nil
+		}))
+		require.Equal(t, 1, codes)
+		from, to, err := StateDiffRange(diffTx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), from)
+		require.Equal(t, uint64(3), to)
+
+		return snapshotKV.Update(ctx, func(tx ethdb.RwTx) error {
+			_, err := ApplyStateDiff(tx, diffTx, 2)
+			require.ErrorIs(t, err, ErrStateDiffOrder)
+			block, err := ApplyStateDiff(tx, diffTx, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(3), block)
+
+			expected := map[string][]byte{}
+			for addr, acc := range map[common.Address]*accounts.Account{addrA: account(10, 0), addrC: account(3, 1), addrD: contractD} {
+				v := make([]byte, acc.EncodingLengthForStorage())
+				acc.EncodeForStorage(v)
+				expected[string(addr[:])] = v
+			}
+			expected[string(dbutils.PlainGenerateCompositeStorageKey(addrC[:], 1, slot1[:]))] = []byte{5}
+			got := map[string][]byte{}
+			require.NoError(t, tx.ForEach(dbutils.PlainStateBucket, nil, func(k, v []byte) error {
+				got[string(k)] = common.CopyBytes(v)
+				return nil
+			}))
+			require.Equal(t, expected, got)
+
+			return nil
+		})
+	}))
+}
@@ -31,6 +31,10 @@ var (
 			dbutils.PlainContractCodeBucket: dbutils.BucketConfigItem{},
 			dbutils.CodeBucket:              dbutils.BucketConfigItem{},
 		},
+		SnapshotType_receipts: {
+			dbutils.BlockReceiptsPrefix: dbutils.BucketConfigItem{},
+			dbutils.Log:                 dbutils.BucketConfigItem{},
+		},
 	}
 )
 
@@ -60,6 +64,8 @@ func WrapBySnapshotsFromDownloader(kv ethdb.RwKV, snapshots map[SnapshotType]*Sn
 				snKV = snKV.BodiesSnapshot(snapshotKV)
 			case SnapshotType_state:
 				snKV = snKV.StateSnapshot(snapshotKV)
+			case SnapshotType_receipts:
+				snKV = snKV.ReceiptsSnapshot(snapshotKV)
 			}
 		}
 	}
@@ -182,7 +188,9 @@ func DownloadSnapshots(torrentClient *Client, ExternalSnapshotDownloaderAddr str
 		}
 		err = torrentClient.AddSnapshotsTorrents(context.Background(), chainDb, networkID, snapshotMode)
 		if err == nil {
-			torrentClient.Download()
+			if err = torrentClient.Download(); err != nil {
+				return err
+			}
 			var innerErr error
 			snapshotKV := chainDb.(ethdb.HasRwKV).RwKV()
 			downloadedSnapshots, innerErr := torrentClient.GetSnapshots(chainDb, networkID)
@@ -0,0 +1,241 @@
+package stages
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/cmd/sentry/download"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/log"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/remote"
+	"github.com/ledgerwatch/erigon/turbo/shards"
+	"github.com/ledgerwatch/erigon/turbo/stages/bodydownload"
+	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+)
+
+// ImportBatchSize - amount of blocks passed through all stages at once by BlockImporter.
+// Bodies stage takes at most that amount of prefetched blocks per cycle.
+const ImportBatchSize = bodydownload.BlockBufferSize
+
+const importPeerID = "import"
+
+// BlockImporter inserts blocks which come not from the network (for example from RLP files)
+// through the same staged sync pipeline as downloaded blocks: headers are verified by the
+// header downloader, bodies are delivered as prefetched blocks, then blocks are executed.
+type BlockImporter struct {
+	db            ethdb.RwKV
+	sync          *stagedsync.Sync
+	cs            *download.ControlServerImpl
+	notifications *stagedsync.Notifications
+}
+
+func NewBlockImporter(ctx context.Context, db ethdb.RwKV, cfg ethconfig.Config, chainConfig *params.ChainConfig, engine consensus.Engine, tmpdir string) (*BlockImporter, error) {
+	var genesisHash common.Hash
+	if err := db.View(ctx, func(tx ethdb.Tx) error {
+		var err error
+		genesisHash, err = rawdb.ReadCanonicalHash(tx, 0)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if genesisHash == (common.Hash{}) {
+		return nil, fmt.Errorf("genesis block not found in db")
+	}
+	cs, err := download.NewControlServer(db, importPeerID, chainConfig, genesisHash, engine, cfg.NetworkID, []remote.SentryClient{}, ImportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	var pruningDistance uint64
+	if !cfg.StorageMode.History {
+		pruningDistance = params.FullImmutabilityThreshold
+	}
+	noHeaderRequests := func(context.Context, *headerdownload.HeaderRequest) []byte { return nil }
+	noBodyRequests := func(context.Context, *bodydownload.BodyRequest) []byte { return nil }
+	noAnnounces := func(context.Context, []headerdownload.Announce) {}
+	noPenalties := func(context.Context, []headerdownload.PenaltyItem) {}
+	noPropagation := func(context.Context, *types.Block, *big.Int) {}
+	bi := &BlockImporter{
+		db: db,
+		cs: cs,
+		notifications: &stagedsync.Notifications{
+			Events:      remotedbserver.NewEvents(),
+			Accumulator: &shards.Accumulator{},
+		},
+	}
+	bi.sync = stagedsync.New(
+		stagedsync.DefaultStages(
+			ctx,
+			cfg.StorageMode,
+			stagedsync.StageHeadersCfg(db, cs.Hd, *chainConfig, noHeaderRequests, noAnnounces, noPenalties, cfg.BatchSize, true /* noPeers */, nil),
+			stagedsync.StageBlockHashesCfg(db, tmpdir),
+			stagedsync.StageSnapshotHeadersCfg(db, ethconfig.Snapshot{Enabled: false}, nil, nil),
+			stagedsync.StageCheckpointCfg(db, nil, tmpdir, cfg.EtlCompression),
+			stagedsync.StageBodiesCfg(db, cs.Bd, noBodyRequests, noPenalties, noPropagation, cfg.BodyDownloadTimeoutSeconds, *chainConfig, cfg.BatchSize),
+			stagedsync.StageSnapshotBodiesCfg(db, ethconfig.Snapshot{Enabled: false}, nil, nil, tmpdir),
+			stagedsync.StageSendersCfg(db, chainConfig, tmpdir),
+			stagedsync.StageExecuteBlocksCfg(
+				db,
+				cfg.StorageMode.Receipts,
+				cfg.StorageMode.CallTraces,
+				cfg.StorageMode.TEVM,
+				cfg.PersistJumpDests,
+				pruningDistance,
+				cfg.BatchSize,
+				nil,
+				chainConfig,
+				engine,
+				&vm.Config{NoReceipts: !cfg.StorageMode.Receipts, EnableTEMV: cfg.StorageMode.TEVM},
+				bi.notifications.Accumulator,
+				cfg.StateStream,
+				cfg.Fork,
+				tmpdir,
+			),
+			stagedsync.StageTranspileCfg(db, cfg.BatchSize, chainConfig),
+			stagedsync.StageSnapshotStateCfg(db, ethconfig.Snapshot{Enabled: false}, tmpdir, nil, nil),
+			stagedsync.StageHashStateCfg(db, tmpdir, cfg.EtlCompression),
+			stagedsync.StageTrieCfg(db, true, true, tmpdir),
+			stagedsync.StageHistoryCfg(db, tmpdir, cfg.EtlCompression),
+			stagedsync.StageLogIndexCfg(db, tmpdir, cfg.EtlCompression),
+			stagedsync.StageCallTracesCfg(db, 0, tmpdir),
+			stagedsync.StageTxLookupCfg(db, tmpdir),
+			stagedsync.StageTxPoolCfg(db, nil, nil),
+			stagedsync.StageFinishCfg(db, tmpdir, nil, nil),
+			true, /* test - stages must not wait for blocks from peers, missing bodies fail runUntil */
+		),
+		stagedsync.DefaultUnwindOrder,
+		stagedsync.DefaultPruneOrder,
+	)
+	return bi, nil
+}
+
+// InsertBlocks - imports chain segment, blocks must be ordered by number and be not longer than ImportBatchSize.
+// Blocks which are already in canonical chain are skipped. Returns error if any block didn't pass validation.
+func (bi *BlockImporter) InsertBlocks(ctx context.Context, blocks []*types.Block) error {
+	if len(blocks) > ImportBatchSize {
+		return fmt.Errorf("too many blocks in one batch: %d, limit %d", len(blocks), ImportBatchSize)
+	}
+	var err error
+	if blocks, err = bi.skipKnown(ctx, blocks); err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	if err = insertBlocks(ctx, bi.db, bi.sync, bi.cs, bi.notifications, bi.updateHead, blocks); err != nil {
+		return err
+	}
+
+	top := blocks[len(blocks)-1]
+	for _, block := range blocks {
+		if bi.cs.Hd.IsBadHeader(block.Hash()) {
+			return fmt.Errorf("block %d %x was invalid", block.NumberU64(), block.Hash())
+		}
+	}
+	return bi.db.View(ctx, func(tx ethdb.Tx) error {
+		hash, err := rawdb.ReadCanonicalHash(tx, top.NumberU64())
+		if err != nil {
+			return err
+		}
+		if hash != top.Hash() {
+			return fmt.Errorf("block %d %x was not inserted into canonical chain, canonical: %x", top.NumberU64(), top.Hash(), hash)
+		}
+		return nil
+	})
+}
+
+// insertBlocks feeds blocks to the header and body downloaders as if they came from a peer,
+// then runs the stage loop until Finish stage reaches the last of them.
+// Blocks must be ordered by number, their parent must be in the db.
+func insertBlocks(ctx context.Context, db ethdb.RwKV, sync *stagedsync.Sync, cs *download.ControlServerImpl, notifications *stagedsync.Notifications,
+	updateHead func(ctx context.Context, head uint64, hash common.Hash, td *uint256.Int), blocks []*types.Block) error {
+	var err error
+	headersRaw := make([][]byte, len(blocks))
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+		if headersRaw[i], err = rlp.EncodeToBytes(headers[i]); err != nil {
+			return err
+		}
+	}
+	segments, penalty, err := cs.Hd.SplitIntoSegments(headersRaw, headers)
+	if err != nil {
+		return err
+	}
+	if penalty != headerdownload.NoPenalty {
+		return fmt.Errorf("invalid chain segment %d-%d: %s", blocks[0].NumberU64(), blocks[len(blocks)-1].NumberU64(), penalty)
+	}
+	for _, segment := range segments {
+		cs.Hd.ProcessSegment(segment, false /* newBlock */, importPeerID)
+	}
+	for _, block := range blocks {
+		cs.Bd.AddToPrefetch(block)
+	}
+
+	return runUntil(ctx, db, sync, blocks[len(blocks)-1].NumberU64(), notifications, updateHead)
+}
+
+// runUntil runs the stage loop until Finish stage reaches the block, fails if a cycle doesn't move it forward
+func runUntil(ctx context.Context, db ethdb.RwKV, sync *stagedsync.Sync, top uint64, notifications *stagedsync.Notifications,
+	updateHead func(ctx context.Context, head uint64, hash common.Hash, td *uint256.Int)) error {
+	var finished uint64
+	for {
+		if err := StageLoopStep(ctx, db, sync, top, notifications, false /* initialCycle */, updateHead, nil); err != nil {
+			return err
+		}
+		var progress uint64
+		if err := db.View(ctx, func(tx ethdb.Tx) (err error) {
+			progress, err = stages.GetStageProgress(tx, stages.Finish)
+			return err
+		}); err != nil {
+			return err
+		}
+		if progress >= top {
+			return nil
+		}
+		if progress <= finished {
+			return fmt.Errorf("import stuck at block %d", progress+1)
+		}
+		finished = progress
+	}
+}
+
+// skipKnown - removes prefix of blocks which are already in canonical chain
+func (bi *BlockImporter) skipKnown(ctx context.Context, blocks []*types.Block) ([]*types.Block, error) {
+	if err := bi.db.View(ctx, func(tx ethdb.Tx) error {
+		for len(blocks) > 0 {
+			hash, err := rawdb.ReadCanonicalHash(tx, blocks[0].NumberU64())
+			if err != nil {
+				return err
+			}
+			if blocks[0].NumberU64() == 0 && hash != blocks[0].Hash() {
+				return fmt.Errorf("genesis mismatch: db %x, imported %x", hash, blocks[0].Hash())
+			}
+			if hash != blocks[0].Hash() {
+				return nil
+			}
+			blocks = blocks[1:]
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (bi *BlockImporter) updateHead(_ context.Context, head uint64, hash common.Hash, _ *uint256.Int) {
+	log.Debug("Imported", "head", head, "hash", hash)
+}
@@ -0,0 +1,347 @@
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/cmd/sentry/download"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/erigon/log"
+)
+
+// assembleTimeout - how long AssembleBlock waits for the consensus engine to seal the block
+const assembleTimeout = time.Minute
+
+var errNotAssembling = errors.New("node doesn't assemble blocks, start it with --mine")
+
+// ExecutionEngine - implementation of remotedbserver.EngineBackend: the chain is driven by a consensus client
+// instead of the p2p block downloader. Payloads go through the same staged sync pipeline as downloaded blocks
+// (see BlockImporter), the head of the chain is moved by unwinding the stages and re-inserting the blocks of
+// the chosen branch, which are kept in the db. The chain is never unwound below the finalized block.
+type ExecutionEngine struct {
+	lock          sync.Mutex // one payload, fork choice or assembly at a time - they all run the stages
+	db            ethdb.RwKV
+	sync          *stagedsync.Sync
+	cs            *download.ControlServerImpl
+	notifications *stagedsync.Notifications
+
+	mining    *stagedsync.Sync
+	miner     stagedsync.MiningState
+	etherbase common.Address
+}
+
+var _ remotedbserver.EngineBackend = (*ExecutionEngine)(nil)
+
+// NewExecutionEngine - mining is nil if the node doesn't assemble blocks. Otherwise the mining stages and
+// the mining state are dedicated to the engine: the fee recipient of every block is written into miner.MiningConfig.
+func NewExecutionEngine(db ethdb.RwKV, sync *stagedsync.Sync, cs *download.ControlServerImpl, notifications *stagedsync.Notifications,
+	mining *stagedsync.Sync, miner stagedsync.MiningState) *ExecutionEngine {
+	e := &ExecutionEngine{
+		db:            db,
+		sync:          sync,
+		cs:            cs,
+		notifications: notifications,
+		mining:        mining,
+		miner:         miner,
+	}
+	if miner.MiningConfig != nil {
+		e.etherbase = miner.MiningConfig.Etherbase
+	}
+	return e
+}
+
+// Start runs the initial cycle of the stages, the chain doesn't move until the consensus client sends payloads
+func (e *ExecutionEngine) Start(ctx context.Context) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return StageLoopStep(ctx, e.db, e.sync, 0, e.notifications, true /* initialCycle */, e.updateHead, nil)
+}
+
+// Head returns the header of the last executed block
+func (e *ExecutionEngine) Head(ctx context.Context) (head *types.Header, err error) {
+	err = e.db.View(ctx, func(tx ethdb.Tx) error {
+		head, err = readHead(tx)
+		return err
+	})
+	return head, err
+}
+
+func (e *ExecutionEngine) ExecutePayload(ctx context.Context, block *types.Block) (remotedbserver.PayloadStatus, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if block.NumberU64() == 0 {
+		return remotedbserver.PayloadInvalid, nil
+	}
+	if e.cs.Hd.IsBadHeader(block.Hash()) || e.cs.Hd.IsBadHeader(block.ParentHash()) {
+		return remotedbserver.PayloadInvalid, nil
+	}
+	var executed, parentKnown bool
+	var head uint64
+	var headHash common.Hash
+	if err := e.db.View(ctx, func(tx ethdb.Tx) error {
+		var err error
+		if head, err = stages.GetStageProgress(tx, stages.Finish); err != nil {
+			return err
+		}
+		if headHash, err = rawdb.ReadCanonicalHash(tx, head); err != nil {
+			return err
+		}
+		hash, err := rawdb.ReadCanonicalHash(tx, block.NumberU64())
+		if err != nil {
+			return err
+		}
+		executed = hash == block.Hash() && block.NumberU64() <= head
+		parentKnown = rawdb.ReadHeader(tx, block.ParentHash(), block.NumberU64()-1) != nil
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if executed {
+		return remotedbserver.PayloadValid, nil
+	}
+	if !parentKnown {
+		return remotedbserver.PayloadSyncing, nil
+	}
+
+	status, err := e.execute(ctx, block)
+	// The payload is executed on top of its parent, which moves the head to the branch of the payload.
+	// The head only moves by fork choice, so the previous head is restored whether the payload is valid or not.
+	if restoreErr := e.setHead(ctx, headHash, head); restoreErr != nil {
+		if err != nil {
+			log.Warn("Failed to restore the head", "number", head, "hash", headHash, "err", restoreErr)
+			return "", err
+		}
+		return "", restoreErr
+	}
+	return status, err
+}
+
+// execute inserts the payload on top of its parent, the parent becomes the head of the chain
+func (e *ExecutionEngine) execute(ctx context.Context, block *types.Block) (remotedbserver.PayloadStatus, error) {
+	if err := e.setHead(ctx, block.ParentHash(), block.NumberU64()-1); err != nil {
+		return "", err
+	}
+	if err := insertBlocks(ctx, e.db, e.sync, e.cs, e.notifications, e.updateHead, []*types.Block{block}); err != nil {
+		if e.cs.Hd.IsBadHeader(block.Hash()) {
+			log.Warn("Invalid payload", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+			return remotedbserver.PayloadInvalid, nil
+		}
+		return "", err
+	}
+	if e.cs.Hd.IsBadHeader(block.Hash()) {
+		return remotedbserver.PayloadInvalid, nil
+	}
+	var hash common.Hash
+	if err := e.db.View(ctx, func(tx ethdb.Tx) (err error) {
+		hash, err = rawdb.ReadCanonicalHash(tx, block.NumberU64())
+		return err
+	}); err != nil {
+		return "", err
+	}
+	if hash != block.Hash() {
+		return remotedbserver.PayloadInvalid, nil
+	}
+	return remotedbserver.PayloadValid, nil
+}
+
+func (e *ExecutionEngine) ForkchoiceUpdated(ctx context.Context, head, finalized common.Hash) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var number *uint64
+	if err := e.db.View(ctx, func(tx ethdb.Tx) error {
+		if number = rawdb.ReadHeaderNumber(tx, head); number == nil {
+			return fmt.Errorf("unknown head %x", head)
+		}
+		if finalized == (common.Hash{}) {
+			return nil
+		}
+		finalizedNumber := rawdb.ReadHeaderNumber(tx, finalized)
+		if finalizedNumber == nil {
+			return fmt.Errorf("unknown finalized block %x", finalized)
+		}
+		ancestor, err := readAncestor(tx, head, *number, *finalizedNumber)
+		if err != nil {
+			return err
+		}
+		if ancestor != finalized {
+			return fmt.Errorf("finalized block %d %x is not an ancestor of the head %d %x", *finalizedNumber, finalized, *number, head)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := e.setHead(ctx, head, *number); err != nil {
+		return err
+	}
+	if finalized == (common.Hash{}) {
+		return nil
+	}
+	return e.db.Update(ctx, func(tx ethdb.RwTx) error {
+		return rawdb.WriteFinalizedBlockHash(tx, finalized)
+	})
+}
+
+func (e *ExecutionEngine) AssembleBlock(ctx context.Context, parent common.Hash, timestamp uint64, feeRecipient common.Address) (*types.Block, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.mining == nil {
+		return nil, errNotAssembling
+	}
+	head, err := e.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.Hash() != parent {
+		return nil, fmt.Errorf("parent %x is not the head of the chain %d %x", parent, head.Number.Uint64(), head.Hash())
+	}
+	if timestamp <= head.Time {
+		return nil, fmt.Errorf("timestamp %d is not after the parent's %d", timestamp, head.Time)
+	}
+
+	// results of the assemblies which timed out
+	for len(e.miner.PendingResultCh) > 0 {
+		<-e.miner.PendingResultCh
+	}
+	for len(e.miner.MiningResultCh) > 0 {
+		<-e.miner.MiningResultCh
+	}
+	e.miner.MiningBlock.Timestamp = timestamp
+	e.miner.MiningConfig.Etherbase = e.etherbase
+	if feeRecipient != (common.Address{}) {
+		e.miner.MiningConfig.Etherbase = feeRecipient
+	}
+	if err = MiningStep(ctx, e.db, e.mining); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(assembleTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-e.miner.PendingResultCh: // the block before sealing
+		case block := <-e.miner.MiningResultCh:
+			return block, nil
+		case <-timeout.C:
+			return nil, fmt.Errorf("block %d is not sealed after %s", head.Number.Uint64()+1, assembleTimeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// setHead makes the known block the last executed one. The Headers stage chooses the canonical chain by total
+// difficulty only, so the canonical chain is switched to the branch of the block here: stages are unwound to
+// the fork point, then the blocks of the branch, which are kept in the db, are executed again.
+func (e *ExecutionEngine) setHead(ctx context.Context, hash common.Hash, number uint64) error {
+	var branch []*types.Block // from the block down to the fork point
+	var forkPoint, head, finalized uint64
+	var forkHash common.Hash
+	if err := e.db.View(ctx, func(tx ethdb.Tx) error {
+		var err error
+		if head, err = stages.GetStageProgress(tx, stages.Finish); err != nil {
+			return err
+		}
+		finalizedHash, err := rawdb.ReadFinalizedBlockHash(tx)
+		if err != nil {
+			return err
+		}
+		if n := rawdb.ReadHeaderNumber(tx, finalizedHash); n != nil {
+			finalized = *n
+		}
+		for forkPoint, forkHash = number, hash; ; forkPoint-- {
+			canonical, err := rawdb.ReadCanonicalHash(tx, forkPoint)
+			if err != nil {
+				return err
+			}
+			if canonical == forkHash && forkPoint <= head {
+				return nil
+			}
+			block := rawdb.ReadBlock(tx, forkHash, forkPoint)
+			if block == nil {
+				return fmt.Errorf("block %d %x is not in the db", forkPoint, forkHash)
+			}
+			branch = append(branch, block)
+			forkHash = block.ParentHash()
+		}
+	}); err != nil {
+		return err
+	}
+	if forkPoint < finalized {
+		return fmt.Errorf("block %d %x is not a descendant of the finalized block %d", number, hash, finalized)
+	}
+	if forkPoint == head && len(branch) == 0 {
+		return nil
+	}
+
+	// Headers stage restores the canonical chain from the head header when it is not marked canonical (see fixCanonicalChain)
+	if err := e.db.Update(ctx, func(tx ethdb.RwTx) error {
+		progress, err := stages.GetStageProgress(tx, stages.Headers)
+		if err != nil {
+			return err
+		}
+		for n := progress; n > number; n-- {
+			if err = rawdb.DeleteCanonicalHash(tx, n); err != nil {
+				return err
+			}
+		}
+		if err = rawdb.WriteHeadHeaderHash(tx, hash); err != nil {
+			return err
+		}
+		return stages.SaveStageProgress(tx, stages.Headers, number)
+	}); err != nil {
+		return err
+	}
+	if forkPoint < head {
+		e.sync.UnwindTo(forkPoint, common.Hash{})
+	}
+	for _, block := range branch {
+		e.cs.Bd.AddToPrefetch(block)
+	}
+	return runUntil(ctx, e.db, e.sync, number, e.notifications, e.updateHead)
+}
+
+func (e *ExecutionEngine) updateHead(ctx context.Context, head uint64, hash common.Hash, td *uint256.Int) {
+	log.Info("Head of the chain", "number", head, "hash", hash)
+	e.cs.UpdateHead(ctx, head, hash, td)
+}
+
+// readAncestor returns the hash of the ancestor of the block at the given height. The branch of the block is
+// walked down until it joins the canonical chain, the rest of the ancestors are canonical.
+func readAncestor(tx ethdb.Tx, hash common.Hash, number uint64, ancestor uint64) (common.Hash, error) {
+	for ; number > ancestor; number-- {
+		canonical, err := rawdb.ReadCanonicalHash(tx, number)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if canonical == hash {
+			return rawdb.ReadCanonicalHash(tx, ancestor)
+		}
+		header := rawdb.ReadHeader(tx, hash, number)
+		if header == nil {
+			return common.Hash{}, fmt.Errorf("header %d %x not found", number, hash)
+		}
+		hash = header.ParentHash
+	}
+	return hash, nil
+}
+
+func readHead(tx ethdb.Tx) (*types.Header, error) {
+	number, err := stages.GetStageProgress(tx, stages.Finish)
+	if err != nil {
+		return nil, err
+	}
+	header := rawdb.ReadHeaderByNumber(tx, number)
+	if header == nil {
+		return nil, fmt.Errorf("header of the head %d not found", number)
+	}
+	return header, nil
+}
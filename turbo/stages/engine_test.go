@@ -0,0 +1,180 @@
+package stages_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/ethdb/remote/remotedbserver"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+// requireHead checks the last executed block and the canonical chain
+func requireHead(t *testing.T, m *stages2.MockSentry, head *types.Block) {
+	require.NoError(t, m.DB.View(context.Background(), func(tx ethdb.Tx) error {
+		progress, err := stages.GetStageProgress(tx, stages.Finish)
+		require.NoError(t, err)
+		require.Equal(t, head.NumberU64(), progress)
+		for block := head.Header(); block.Number.Uint64() > 0; block = rawdb.ReadHeader(tx, block.ParentHash, block.Number.Uint64()-1) {
+			hash, err := rawdb.ReadCanonicalHash(tx, block.Number.Uint64())
+			require.NoError(t, err)
+			require.Equal(t, block.Hash(), hash)
+		}
+		hash, err := rawdb.ReadCanonicalHash(tx, head.NumberU64()+1)
+		require.NoError(t, err)
+		require.Equal(t, common.Hash{}, hash)
+		return nil
+	}))
+}
+
+func TestExecutionEngine(t *testing.T) {
+	ctx := context.Background()
+	m := stages2.Mock(t)
+	e := m.ExecutionEngine()
+
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	fork, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{2})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+
+	// the head moves only by fork choice
+	for _, block := range chain.Blocks {
+		status, err := e.ExecutePayload(ctx, block)
+		require.NoError(t, err)
+		require.Equal(t, remotedbserver.PayloadValid, status)
+		requireHead(t, m, m.Genesis)
+	}
+	require.NoError(t, e.ForkchoiceUpdated(ctx, chain.TopBlock.Hash(), common.Hash{}))
+	requireHead(t, m, chain.TopBlock)
+	status, err := e.ExecutePayload(ctx, chain.Blocks[1])
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadValid, status)
+	requireHead(t, m, chain.TopBlock)
+
+	// valid payloads of a side branch are executed on top of the fork point, the head stays on the chain
+	for _, block := range fork.Blocks {
+		status, err = e.ExecutePayload(ctx, block)
+		require.NoError(t, err)
+		require.Equal(t, remotedbserver.PayloadValid, status)
+		head, err := e.Head(ctx)
+		require.NoError(t, err)
+		require.Equal(t, chain.TopBlock.Hash(), head.Hash())
+		requireHead(t, m, chain.TopBlock)
+	}
+	require.NoError(t, e.ForkchoiceUpdated(ctx, fork.TopBlock.Hash(), common.Hash{}))
+	requireHead(t, m, fork.TopBlock)
+
+	// unknown parent
+	orphan := types.NewBlock(&types.Header{ParentHash: common.Hash{1}, Number: chain.TopBlock.Number()}, nil, nil, nil)
+	status, err = e.ExecutePayload(ctx, orphan)
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadSyncing, status)
+
+	// finalized block of another branch doesn't move the head
+	require.Error(t, e.ForkchoiceUpdated(ctx, chain.TopBlock.Hash(), fork.Blocks[0].Hash()))
+	requireHead(t, m, fork.TopBlock)
+
+	// back to the first branch, its blocks are re-inserted from the db
+	require.NoError(t, e.ForkchoiceUpdated(ctx, chain.TopBlock.Hash(), chain.Blocks[1].Hash()))
+	requireHead(t, m, chain.TopBlock)
+	require.NoError(t, m.DB.View(ctx, func(tx ethdb.Tx) error {
+		finalized, err := rawdb.ReadFinalizedBlockHash(tx)
+		require.NoError(t, err)
+		require.Equal(t, chain.Blocks[1].Hash(), finalized)
+		return nil
+	}))
+
+	// unwind to the finalized block, but not below it
+	require.NoError(t, e.ForkchoiceUpdated(ctx, chain.Blocks[1].Hash(), common.Hash{}))
+	requireHead(t, m, chain.Blocks[1])
+	require.Error(t, e.ForkchoiceUpdated(ctx, fork.TopBlock.Hash(), common.Hash{}))
+	_, err = e.ExecutePayload(ctx, fork.Blocks[1])
+	require.Error(t, err)
+	require.Error(t, e.ForkchoiceUpdated(ctx, common.Hash{1}, common.Hash{}))
+	requireHead(t, m, chain.Blocks[1])
+}
+
+func TestExecutionEngineInvalidPayload(t *testing.T) {
+	ctx := context.Background()
+	m := stages2.Mock(t)
+	e := m.ExecutionEngine()
+
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	fork, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{2})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	status, err := e.ExecutePayload(ctx, chain.Blocks[0])
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadValid, status)
+
+	header := chain.Blocks[1].Header()
+	header.Root = common.Hash{1}
+	status, err = e.ExecutePayload(ctx, chain.Blocks[1].WithSeal(header))
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadInvalid, status)
+	requireHead(t, m, m.Genesis)
+
+	status, err = e.ExecutePayload(ctx, chain.Blocks[1])
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadValid, status)
+	requireHead(t, m, m.Genesis)
+	require.NoError(t, e.ForkchoiceUpdated(ctx, chain.Blocks[1].Hash(), common.Hash{}))
+	requireHead(t, m, chain.Blocks[1])
+
+	// invalid payload of a side branch doesn't move the head to the branch
+	for _, block := range fork.Blocks[:2] {
+		status, err = e.ExecutePayload(ctx, block)
+		require.NoError(t, err)
+		require.Equal(t, remotedbserver.PayloadValid, status)
+		requireHead(t, m, chain.Blocks[1])
+	}
+	header = fork.TopBlock.Header()
+	header.Root = common.Hash{1}
+	status, err = e.ExecutePayload(ctx, fork.TopBlock.WithSeal(header))
+	require.NoError(t, err)
+	require.Equal(t, remotedbserver.PayloadInvalid, status)
+	requireHead(t, m, chain.Blocks[1])
+}
+
+func TestExecutionEngineAssembleBlock(t *testing.T) {
+	ctx := context.Background()
+	m := stages2.Mock(t)
+	e := m.ExecutionEngine()
+
+	_, err := e.AssembleBlock(ctx, common.Hash{1}, m.Genesis.Time()+10, common.Address{5})
+	require.Error(t, err)
+	_, err = e.AssembleBlock(ctx, m.Genesis.Hash(), m.Genesis.Time(), common.Address{5})
+	require.Error(t, err)
+
+	parent := m.Genesis
+	for i := 0; i < 2; i++ {
+		block, err := e.AssembleBlock(ctx, parent.Hash(), parent.Time()+10, common.Address{5})
+		require.NoError(t, err)
+		require.Equal(t, parent.NumberU64()+1, block.NumberU64())
+		require.Equal(t, parent.Hash(), block.ParentHash())
+		require.Equal(t, parent.Time()+10, block.Time())
+		require.Equal(t, common.Address{5}, block.Coinbase())
+		requireHead(t, m, parent) // assembled block is not inserted
+
+		status, err := e.ExecutePayload(ctx, block)
+		require.NoError(t, err)
+		require.Equal(t, remotedbserver.PayloadValid, status)
+		require.NoError(t, e.ForkchoiceUpdated(ctx, block.Hash(), parent.Hash()))
+		requireHead(t, m, block)
+		parent = block
+	}
+}
@@ -21,3 +21,27 @@ func TestInserter1(t *testing.T) {
 		t.Errorf("feed empty header: %v", err)
 	}
 }
+
+func TestSetCheckpoint(t *testing.T) {
+	hd := NewHeaderDownload(100, 100, nil)
+	checkpoint := common.Hash{1}
+	hd.SetCheckpoint(1000, checkpoint)
+	hd.SetCheckpoint(1000, checkpoint) // repeated by every cycle of Headers stage
+	if _, ok := hd.preverifiedHashes[checkpoint]; !ok {
+		t.Errorf("checkpoint is not preverified")
+	}
+	if hd.preverifiedHeight != 1000 {
+		t.Errorf("preverified height %d, expected 1000", hd.preverifiedHeight)
+	}
+	req, penalties := hd.RequestMoreHeaders(0)
+	if len(penalties) != 0 {
+		t.Errorf("penalties %v for the checkpoint anchor", penalties)
+	}
+	expected := HeaderRequest{Hash: checkpoint, Number: 1000, Length: 192, Reverse: true}
+	if req == nil || *req != expected {
+		t.Errorf("request %+v, expected %+v", req, expected)
+	}
+	if hd.anchorQueue.Len() != 1 {
+		t.Errorf("%d anchors, expected 1", hd.anchorQueue.Len())
+	}
+}
@@ -413,20 +413,25 @@ func (hd *HeaderDownload) anchorState() string {
 }
 
 func InitPreverifiedHashes(chain string) (map[common.Hash]struct{}, uint64) {
-	var encodings []string
-	var height uint64
+	encodings, height, ok := preverifiedHashes(chain)
+	if !ok {
+		log.Warn("Preverified hashes are not generated for the chain, headers are verified one by one", "chain", chain)
+		return nil, 0
+	}
+	return DecodeHashes(encodings), height
+}
+
+// preverifiedHashes returns the generated list of the chain, see WritePreverifiedHashes.
+// Lists of the other known chains are not generated yet, their headers are verified one by one.
+func preverifiedHashes(chain string) ([]string, uint64, bool) {
 	switch chain {
 	case params.MainnetChainName:
-		encodings = mainnetPreverifiedHashes
-		height = mainnetPreverifiedHeight
+		return mainnetPreverifiedHashes, mainnetPreverifiedHeight, true
 	case params.RopstenChainName:
-		encodings = ropstenPreverifiedHashes
-		height = ropstenPreverifiedHeight
+		return ropstenPreverifiedHashes, ropstenPreverifiedHeight, true
 	default:
-		log.Warn("Preverified hashes not found for", "chain", chain)
-		return nil, 0
+		return nil, 0, false
 	}
-	return DecodeHashes(encodings), height
 }
 
 func DecodeHashes(encodings []string) map[common.Hash]struct{} {
@@ -446,6 +451,32 @@ func (hd *HeaderDownload) SetPreverifiedHashes(preverifiedHashes map[common.Hash
 	hd.preverifiedHeight = preverifiedHeight
 }
 
+// SetCheckpoint makes the trusted checkpoint hash preverified (with all its ancestors) and, while the checkpoint
+// is not in the database, keeps an anchor at it: headers are requested backwards from the checkpoint to the genesis
+func (hd *HeaderDownload) SetCheckpoint(number uint64, hash common.Hash) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	if hd.preverifiedHashes == nil {
+		hd.preverifiedHashes = make(map[common.Hash]struct{})
+	}
+	hd.preverifiedHashes[hash] = struct{}{}
+	if number > hd.preverifiedHeight {
+		hd.preverifiedHeight = number
+	}
+	if number <= hd.highestInDb {
+		return
+	}
+	if _, ok := hd.links[hash]; ok {
+		return
+	}
+	if _, ok := hd.anchors[hash]; ok {
+		return
+	}
+	anchor := &Anchor{parentHash: hash, blockHeight: number + 1}
+	hd.anchors[hash] = anchor
+	heap.Push(hd.anchorQueue, anchor)
+}
+
 func (hd *HeaderDownload) RecoverFromDb(db ethdb.RoKV) error {
 	hd.lock.Lock()
 	defer hd.lock.Unlock()
@@ -521,7 +552,9 @@ func (hd *HeaderDownload) RequestMoreHeaders(currentTime uint64) (*HeaderRequest
 			} else {
 				// Ancestors of this anchor seem to be unavailable, invalidate and move on
 				hd.invalidateAnchor(anchor)
-				penalties = append(penalties, PenaltyItem{Penalty: AbandonedAnchorPenalty, PeerID: anchor.peerID})
+				if anchor.peerID != "" { // checkpoint anchors don't come from peers
+					penalties = append(penalties, PenaltyItem{Penalty: AbandonedAnchorPenalty, PeerID: anchor.peerID})
+				}
 			}
 		}
 		// Anchor disappeared or unavailable, pop from the queue and move on
@@ -0,0 +1,63 @@
+package headerdownload
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// PreverifiedHashesStep - interval (in blocks) between the hashes of the generated preverified lists
+const PreverifiedHashesStep uint64 = 192
+
+// PreverifiedHashesFile - name of the generated file with the preverified hashes of the chain
+func PreverifiedHashesFile(chain string) string {
+	return fmt.Sprintf("preverified_hashes_%s.go", chain)
+}
+
+// WritePreverifiedHashes generates the preverified hashes list of the chain (source of PreverifiedHashesFile):
+// canonical hashes of the blocks 0, step, 2*step, ... up to the block `to` or the last canonical block.
+// The genesis of the database has to be the genesis of the chain. Returns the height of the list.
+// Lists are regenerated from synced databases by `state preverifiedHashes --chain <name> --output turbo/stages/headerdownload`
+func WritePreverifiedHashes(w io.Writer, tx ethdb.Tx, chain string, step uint64, to uint64) (uint64, error) {
+	if step == 0 {
+		return 0, fmt.Errorf("step of preverified hashes has to be positive")
+	}
+	expected := params.GenesisHashByChainName(chain)
+	if expected == nil {
+		return 0, fmt.Errorf("preverified hashes are not supported for chain %q", chain)
+	}
+	genesis, err := rawdb.ReadCanonicalHash(tx, 0)
+	if err != nil {
+		return 0, err
+	}
+	if genesis != *expected {
+		return 0, fmt.Errorf("genesis of the database %x is not the genesis of %s %x", genesis, chain, *expected)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "package headerdownload\n\n")
+	fmt.Fprintf(bw, "var %sPreverifiedHashes = []string{\n", chain)
+	var height uint64
+	for b := uint64(0); b <= to; b += step {
+		hash, err := rawdb.ReadCanonicalHash(tx, b)
+		if err != nil {
+			return 0, err
+		}
+		if hash == (common.Hash{}) {
+			break
+		}
+		fmt.Fprintf(bw, "\t\"%x\",\n", hash)
+		height = b
+		if b+step < b { // overflow
+			break
+		}
+	}
+	fmt.Fprintf(bw, "}\n\n")
+	fmt.Fprintf(bw, "const %sPreverifiedHeight uint64 = %d\n", chain, height)
+	return height, bw.Flush()
+}
@@ -0,0 +1,73 @@
+package headerdownload
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb/kv"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// Generated lists have to start at the genesis of their chains and be evenly spaced up to their heights.
+// Only mainnet and ropsten have lists so far, the other known chains are checked once theirs are generated.
+func TestPreverifiedHashesMatchGenesis(t *testing.T) {
+	for _, chain := range []string{
+		params.MainnetChainName,
+		params.RopstenChainName,
+		params.RinkebyChainName,
+		params.GoerliChainName,
+		params.ErigonMineName,
+		params.CalaverasChainName,
+		params.SokolChainName,
+	} {
+		encodings, height, ok := preverifiedHashes(chain)
+		if !ok {
+			if chain == params.MainnetChainName || chain == params.RopstenChainName {
+				t.Errorf("%s: no preverified hashes, generate %s", chain, PreverifiedHashesFile(chain))
+			}
+			continue
+		}
+		if len(encodings) == 0 {
+			continue
+		}
+		if genesis := params.GenesisHashByChainName(chain); common.HexToHash(encodings[0]) != *genesis {
+			t.Errorf("%s: first preverified hash %s is not the genesis %x", chain, encodings[0], *genesis)
+		}
+		if expected := uint64(len(encodings)-1) * PreverifiedHashesStep; height != expected {
+			t.Errorf("%s: preverified height %d, expected %d for %d hashes", chain, height, expected, len(encodings))
+		}
+		if hashes := DecodeHashes(encodings); len(hashes) != len(encodings) {
+			t.Errorf("%s: %d duplicate preverified hashes", chain, len(encodings)-len(hashes))
+		}
+	}
+}
+
+func TestWritePreverifiedHashes(t *testing.T) {
+	_, tx := kv.NewTestTx(t)
+	hashes := []common.Hash{params.GoerliGenesisHash, {1}, {2}, {3}, {4}}
+	for i, hash := range hashes {
+		if err := rawdb.WriteCanonicalHash(tx, hash, uint64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	height, err := WritePreverifiedHashes(&buf, tx, params.GoerliChainName, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 4 {
+		t.Errorf("height %d, expected 4", height)
+	}
+	expected := fmt.Sprintf("package headerdownload\n\nvar goerliPreverifiedHashes = []string{\n\t\"%x\",\n\t\"%x\",\n\t\"%x\",\n}\n\nconst goerliPreverifiedHeight uint64 = 4\n", hashes[0], hashes[2], hashes[4])
+	if buf.String() != expected {
+		t.Errorf("generated\n%s\nexpected\n%s", buf.String(), expected)
+	}
+
+	if _, err := WritePreverifiedHashes(&buf, tx, params.RinkebyChainName, 2, 10); err == nil {
+		t.Errorf("list of rinkeby generated from goerli db")
+	}
+}
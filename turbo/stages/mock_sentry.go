@@ -54,6 +54,7 @@ type MockSentry struct {
 	ChainConfig     *params.ChainConfig
 	Sync            *stagedsync.Sync
 	MiningSync      *stagedsync.Sync
+	miningState     stagedsync.MiningState
 	PendingBlocks   chan *types.Block
 	MinedBlocks     chan *types.Block
 	downloader      *download.ControlServerImpl
@@ -70,6 +71,8 @@ type MockSentry struct {
 	Address         common.Address
 
 	Notifications *stagedsync.Notifications
+
+	sentMessagesLock sync.Mutex // sends come from the stage loop, the mining loop and the tx propagation concurrently
 }
 
 // Stream returns stream, waiting if necessary
@@ -90,22 +93,32 @@ func (ms *MockSentry) PeerMinBlock(context.Context, *proto_sentry.PeerMinBlockRe
 	return nil, nil
 }
 func (ms *MockSentry) SendMessageByMinBlock(_ context.Context, r *proto_sentry.SendMessageByMinBlockRequest) (*proto_sentry.SentPeers, error) {
+	ms.sentMessagesLock.Lock()
+	defer ms.sentMessagesLock.Unlock()
 	ms.sentMessages = append(ms.sentMessages, r.Data)
-	return nil, nil
+	return &proto_sentry.SentPeers{}, nil
 }
 func (ms *MockSentry) SendMessageById(_ context.Context, r *proto_sentry.SendMessageByIdRequest) (*proto_sentry.SentPeers, error) {
+	ms.sentMessagesLock.Lock()
+	defer ms.sentMessagesLock.Unlock()
 	ms.sentMessages = append(ms.sentMessages, r.Data)
-	return nil, nil
+	return &proto_sentry.SentPeers{}, nil
 }
 func (ms *MockSentry) SendMessageToRandomPeers(_ context.Context, r *proto_sentry.SendMessageToRandomPeersRequest) (*proto_sentry.SentPeers, error) {
+	ms.sentMessagesLock.Lock()
+	defer ms.sentMessagesLock.Unlock()
 	ms.sentMessages = append(ms.sentMessages, r.Data)
-	return nil, nil
+	return &proto_sentry.SentPeers{}, nil
 }
 func (ms *MockSentry) SendMessageToAll(_ context.Context, r *proto_sentry.OutboundMessageData) (*proto_sentry.SentPeers, error) {
+	ms.sentMessagesLock.Lock()
+	defer ms.sentMessagesLock.Unlock()
 	ms.sentMessages = append(ms.sentMessages, r)
-	return nil, nil
+	return &proto_sentry.SentPeers{}, nil
 }
 func (ms *MockSentry) SentMessage(i int) *proto_sentry.OutboundMessageData {
+	ms.sentMessagesLock.Lock()
+	defer ms.sentMessagesLock.Unlock()
 	return ms.sentMessages[i]
 }
 func (ms *MockSentry) SetStatus(context.Context, *proto_sentry.StatusData) (*proto_sentry.SetStatusReply, error) {
@@ -238,9 +251,12 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 				propagateNewBlockHashes,
 				penalize,
 				cfg.BatchSize,
+				false,
+				nil,
 			),
 			stagedsync.StageBlockHashesCfg(mock.DB, mock.tmpdir),
 			stagedsync.StageSnapshotHeadersCfg(mock.DB, ethconfig.Snapshot{Enabled: false}, nil, nil),
+			stagedsync.StageCheckpointCfg(mock.DB, nil, mock.tmpdir, cfg.EtlCompression),
 			stagedsync.StageBodiesCfg(
 				mock.DB,
 				mock.downloader.Bd,
@@ -263,6 +279,7 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 				sm.Receipts,
 				sm.CallTraces,
 				sm.TEVM,
+				cfg.PersistJumpDests,
 				0,
 				cfg.BatchSize,
 				nil,
@@ -271,6 +288,7 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 				&vm.Config{NoReceipts: !sm.Receipts},
 				nil,
 				cfg.StateStream,
+				nil,
 				mock.tmpdir,
 			),
 			stagedsync.StageTranspileCfg(
@@ -284,10 +302,10 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 				"",
 				nil, nil,
 			),
-			stagedsync.StageHashStateCfg(mock.DB, mock.tmpdir),
+			stagedsync.StageHashStateCfg(mock.DB, mock.tmpdir, cfg.EtlCompression),
 			stagedsync.StageTrieCfg(mock.DB, true, true, mock.tmpdir),
-			stagedsync.StageHistoryCfg(mock.DB, mock.tmpdir),
-			stagedsync.StageLogIndexCfg(mock.DB, mock.tmpdir),
+			stagedsync.StageHistoryCfg(mock.DB, mock.tmpdir, cfg.EtlCompression),
+			stagedsync.StageLogIndexCfg(mock.DB, mock.tmpdir, cfg.EtlCompression),
 			stagedsync.StageCallTracesCfg(mock.DB, 0, mock.tmpdir),
 			stagedsync.StageTxLookupCfg(mock.DB, mock.tmpdir),
 			stagedsync.StageTxPoolCfg(mock.DB, txPool, func() {
@@ -311,14 +329,15 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 	miningConfig.SigKey = mock.Key
 
 	miner := stagedsync.NewMiningState(&miningConfig)
+	mock.miningState = miner
 	mock.PendingBlocks = miner.PendingResultCh
 	mock.MinedBlocks = miner.MiningResultCh
 
 	mock.MiningSync = stagedsync.New(
 		stagedsync.MiningStages(mock.Ctx,
 			stagedsync.StageMiningCreateBlockCfg(mock.DB, miner, *mock.ChainConfig, mock.Engine, txPool, mock.tmpdir),
-			stagedsync.StageMiningExecCfg(mock.DB, miner, nil, *mock.ChainConfig, mock.Engine, &vm.Config{}, mock.tmpdir),
-			stagedsync.StageHashStateCfg(mock.DB, mock.tmpdir),
+			stagedsync.StageMiningExecCfg(mock.DB, miner, nil, *mock.ChainConfig, mock.Engine, &vm.Config{}, nil, mock.tmpdir),
+			stagedsync.StageHashStateCfg(mock.DB, mock.tmpdir, cfg.EtlCompression),
 			stagedsync.StageTrieCfg(mock.DB, false, true, mock.tmpdir),
 			stagedsync.StageMiningFinishCfg(mock.DB, *mock.ChainConfig, mock.Engine, miner, mock.Ctx.Done()),
 		),
@@ -357,6 +376,38 @@ func Mock(t *testing.T) *MockSentry {
 	return MockWithGenesis(t, gspec, key)
 }
 
+// PropagateMinedBlock - handles the block sealed by MiningSync the way the node does: announces it to the peers
+// and inserts it into the own chain
+func (ms *MockSentry) PropagateMinedBlock(b *types.Block) error {
+	if err := ms.AnnounceMinedBlock(b); err != nil {
+		return err
+	}
+	initialCycle := false
+	return StageLoopStep(ms.Ctx, ms.DB, ms.Sync, b.NumberU64(), ms.Notifications, initialCycle, ms.UpdateHead, nil)
+}
+
+// AnnounceMinedBlock - hands the block sealed by MiningSync to the downloaders and announces it to the peers,
+// the block is inserted by the next cycle of the stage loop (see StageLoop)
+func (ms *MockSentry) AnnounceMinedBlock(b *types.Block) error {
+	return ms.downloader.PropagateMinedBlock(ms.Ctx, b)
+}
+
+// StageLoop runs the stage loop of the node in background until the end of the test,
+// waitForMined - the loop of the instant sealing developer chain, which cycles for the announced mined blocks only
+func (ms *MockSentry) StageLoop(waitForMined bool) {
+	done := make(chan struct{})
+	go StageLoop(ms.Ctx, ms.DB, ms.Sync, ms.downloader.Hd, ms.Notifications, ms.UpdateHead, done, 0, waitForMined)
+	ms.t.Cleanup(func() {
+		ms.cancel()
+		<-done
+	})
+}
+
+// ExecutionEngine drives the chain of the mock as a consensus client does, blocks are assembled by MiningSync
+func (ms *MockSentry) ExecutionEngine() *ExecutionEngine {
+	return NewExecutionEngine(ms.DB, ms.Sync, ms.downloader, ms.Notifications, ms.MiningSync, ms.miningState)
+}
+
 func (ms *MockSentry) EnableLogs() {
 	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
 	ms.t.Cleanup(func() {
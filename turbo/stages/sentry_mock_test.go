@@ -10,8 +10,10 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/u256"
 	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/log"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
@@ -515,3 +517,31 @@ func TestAnchorReplace2(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPropagateMinedBlock(t *testing.T) {
+	require, m := require.New(t), stages.Mock(t)
+
+	require.NoError(stages.MiningStep(m.Ctx, m.DB, m.MiningSync))
+	<-m.PendingBlocks
+	b := <-m.MinedBlocks
+	require.Equal(uint64(1), b.NumberU64())
+	require.NoError(m.PropagateMinedBlock(b))
+
+	// announced to the peers with the total difficulty of the chain
+	msg := m.SentMessage(0)
+	require.Equal(sentry.MessageId_NEW_BLOCK_66, msg.Id)
+	var packet eth.NewBlockPacket
+	require.NoError(rlp.DecodeBytes(msg.Data, &packet))
+	require.Equal(b.Hash(), packet.Block.Hash())
+
+	// inserted into the own chain
+	require.NoError(m.DB.View(m.Ctx, func(tx ethdb.Tx) error {
+		hash, err := rawdb.ReadCanonicalHash(tx, 1)
+		require.NoError(err)
+		require.Equal(b.Hash(), hash)
+		td, err := rawdb.ReadTd(tx, b.Hash(), 1)
+		require.NoError(err)
+		require.Equal(td, packet.TD)
+		return nil
+	}))
+}
@@ -36,6 +36,7 @@ func StageLoop(
 	updateHead func(ctx context.Context, head uint64, hash common.Hash, td *uint256.Int),
 	waitForDone chan struct{},
 	loopMinTime time.Duration,
+	waitForMined bool, // instant sealing developer chain: cycles are only needed for the blocks of the local miner
 ) {
 	defer close(waitForDone)
 	initialCycle := true
@@ -66,6 +67,14 @@ func StageLoop(
 		initialCycle = false
 		hd.EnableRequestChaining()
 
+		if waitForMined {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hd.DeliveryNotify:
+			}
+		}
+
 		if loopMinTime != 0 {
 			waitTime := loopMinTime - time.Since(start)
 			log.Info("Wait time until next loop", "for", waitTime)
@@ -223,9 +232,12 @@ func NewStagedSync2(
 				controlServer.PropagateNewBlockHashes,
 				controlServer.Penalize,
 				cfg.BatchSize,
+				cfg.Miner.Enabled && cfg.Miner.InstantSeal || cfg.Beacon.Enabled,
+				cfg.Checkpoint,
 			),
 			stagedsync.StageBlockHashesCfg(db, tmpdir),
 			stagedsync.StageSnapshotHeadersCfg(db, cfg.Snapshot, client, snapshotMigrator),
+			stagedsync.StageCheckpointCfg(db, cfg.Checkpoint, tmpdir, cfg.EtlCompression),
 			stagedsync.StageBodiesCfg(
 				db,
 				controlServer.Bd,
@@ -243,6 +255,7 @@ func NewStagedSync2(
 				cfg.StorageMode.Receipts,
 				cfg.StorageMode.CallTraces,
 				cfg.StorageMode.TEVM,
+				cfg.PersistJumpDests,
 				pruningDistance,
 				cfg.BatchSize,
 				nil,
@@ -251,6 +264,7 @@ func NewStagedSync2(
 				&vm.Config{NoReceipts: !cfg.StorageMode.Receipts, EnableTEMV: cfg.StorageMode.TEVM},
 				accumulator,
 				cfg.StateStream,
+				cfg.Fork,
 				tmpdir,
 			),
 			stagedsync.StageTranspileCfg(
@@ -259,10 +273,10 @@ func NewStagedSync2(
 				controlServer.ChainConfig,
 			),
 			stagedsync.StageSnapshotStateCfg(db, cfg.Snapshot, tmpdir, client, snapshotMigrator),
-			stagedsync.StageHashStateCfg(db, tmpdir),
+			stagedsync.StageHashStateCfg(db, tmpdir, cfg.EtlCompression),
 			stagedsync.StageTrieCfg(db, true, true, tmpdir),
-			stagedsync.StageHistoryCfg(db, tmpdir),
-			stagedsync.StageLogIndexCfg(db, tmpdir),
+			stagedsync.StageHistoryCfg(db, tmpdir, cfg.EtlCompression),
+			stagedsync.StageLogIndexCfg(db, tmpdir, cfg.EtlCompression),
 			stagedsync.StageCallTracesCfg(db, 0, tmpdir),
 			stagedsync.StageTxLookupCfg(db, tmpdir),
 			stagedsync.StageTxPoolCfg(db, txPool, func() {
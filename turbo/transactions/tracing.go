@@ -43,7 +43,7 @@ func ComputeTxEnv(ctx context.Context, block *types.Block, cfg *params.ChainConf
 	signer := types.MakeSigner(cfg, block.NumberU64())
 
 	BlockContext := core.NewEVMBlockContext(block.Header(), getHeader, engine, nil, checkTEVM)
-	vmenv := vm.NewEVM(BlockContext, vm.TxContext{}, statedb, cfg, vm.Config{})
+	vmenv := vm.NewEVM(BlockContext, vm.TxContext{}, statedb, cfg, vm.Config{JumpDestCache: core.NewDBJumpDestCache(vm.SharedJumpDests, dbtx, nil)})
 	for idx, tx := range block.Transactions() {
 		select {
 		default:
@@ -81,6 +81,7 @@ func TraceTx(
 	ibs vm.IntraBlockState,
 	config *tracers.TraceConfig,
 	chainConfig *params.ChainConfig,
+	jumpDests vm.JumpDestCache,
 	stream *jsoniter.Stream,
 ) error {
 	// Assemble the structured logger or the JavaScript tracer
@@ -122,7 +123,7 @@ func TraceTx(
 		streaming = true
 	}
 	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: true, Tracer: tracer})
+	vmenv := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{Debug: true, Tracer: tracer, JumpDestCache: jumpDests})
 
 	var refunds bool = true
 	if config != nil && config.NoRefunds != nil && *config.NoRefunds {